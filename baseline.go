@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"linter/pkg/baseline"
+)
+
+type baselineCmd struct {
+	File string `arg:"positional" help:"baseline file to write [default: --baseline]"`
+}
+
+// runBaseline records every current issue, so it needs no diff at all.
+func runBaseline(ctx context.Context, cmd *baselineCmd) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	path := firstNonEmpty(cmd.File, args.Baseline)
+	if path == "" {
+		return errors.New("baseline requires a file, or --baseline")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	issues, err := lintIssues(ctx, args.Pwd, args.JsonFile, args.InspectDes, false)
+	if err := timedOut(err); err != nil {
+		return err
+	}
+	return baseline.New(issues).Save(path)
+}
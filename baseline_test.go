@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/baseline"
+)
+
+func TestSubcommands(t *testing.T) {
+	o := parseOptions(t, "run", "--base-ref", "origin/main")
+	if o.Run == nil || o.BaseRef != "origin/main" {
+		t.Errorf("run did not keep the global flags: %+v", o)
+	}
+	o = parseOptions(t, "--pwd", "sub", "baseline", "lint-baseline.json")
+	if o.WriteBaseline == nil || o.WriteBaseline.File != "lint-baseline.json" || o.Pwd != "sub" {
+		t.Errorf("baseline = %+v, pwd %q", o.WriteBaseline, o.Pwd)
+	}
+	if o = parseOptions(t, "version"); o.ShowVersion == nil {
+		t.Error("version subcommand not selected")
+	}
+	if o = parseOptions(t, "--base-ref", "origin/main"); o.Run != nil || o.Report != nil {
+		t.Error("flags alone selected a subcommand")
+	}
+}
+
+func TestRunBaseline(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	args = options{
+		Pwd:        dir,
+		JsonFile:   filepath.Join(dir, "report.json"),
+		InspectDes: []string{"./..."},
+		Bin:        reportingLinter(t, "errcheck"),
+	}
+	path := filepath.Join(dir, "baseline.json")
+	if err := runBaseline(context.Background(), &baselineCmd{File: path}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := baseline.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := result.Issue{FromLinter: "errcheck", Text: "x", Pos: token.Position{Filename: "a.go", Line: 1}}
+	if kept := recorded.Filter([]result.Issue{issue}); len(kept) != 0 {
+		t.Errorf("baseline does not hold the current issue, kept %v", kept)
+	}
+
+	args.Baseline = ""
+	if err := runBaseline(context.Background(), &baselineCmd{}); err == nil {
+		t.Error("baseline without a file expected an error")
+	}
+}
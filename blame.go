@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/blame"
+)
+
+// attribute looks up who last changed the line of every issue when --blame
+// or --author needs it. It keeps only the issues of --author, and with
+// --blame names the author in the message.
+func attribute(ctx context.Context, issues []result.Issue) ([]result.Issue, error) {
+	if !args.Blame && len(args.Author) == 0 {
+		return issues, nil
+	}
+
+	var files []string
+	lines := make(map[string][]int)
+	for _, issue := range issues {
+		path := issue.FilePath()
+		if _, ok := lines[path]; !ok {
+			files = append(files, path)
+		}
+		lines[path] = append(lines[path], issue.Line())
+	}
+	authors := make(map[string]map[int]blame.Author, len(files))
+	for _, file := range files {
+		fileAuthors, err := blame.Lines(ctx, args.Pwd, file, lines[file])
+		if err != nil {
+			return nil, err
+		}
+		authors[file] = fileAuthors
+	}
+
+	kept := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		author, ok := authors[issue.FilePath()][issue.Line()]
+		if len(args.Author) > 0 && !(ok && matchesAuthor(author)) {
+			continue
+		}
+		if args.Blame && ok {
+			issue.Text = blame.Annotate(issue.Text, author)
+		}
+		kept = append(kept, issue)
+	}
+	return kept, nil
+}
+
+func matchesAuthor(author blame.Author) bool {
+	for _, who := range args.Author {
+		if author.Matches(who) {
+			return true
+		}
+	}
+	return false
+}
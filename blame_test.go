@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestAttribute(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	git := func(argv ...string) {
+		t.Helper()
+		cmd := exec.Command("git", argv...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+	}
+	git("init", "-q")
+	git("config", "commit.gpgsign", "false")
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("-c", "user.name=Ada", "-c", "user.email=ada@example.com", "commit", "-q", "-m", "first")
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nvar X = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("-c", "user.name=Bob", "-c", "user.email=bob@example.com", "commit", "-q", "-am", "second")
+
+	issues := []result.Issue{
+		{FromLinter: "stylecheck", Text: "package comment", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "gochecknoglobals", Text: "X is a global", Pos: token.Position{Filename: "a.go", Line: 3}},
+	}
+
+	args = options{Pwd: dir, Author: []string{"BOB@example.com"}}
+	got, err := attribute(context.Background(), issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Text != "X is a global" {
+		t.Errorf("--author kept %v, want Bob's issue only", got)
+	}
+
+	args = options{Pwd: dir, Blame: true}
+	got, err = attribute(context.Background(), issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Text != "package comment [blame: Ada <ada@example.com>]" {
+		t.Errorf("--blame = %v", got)
+	}
+	if issues[0].Text != "package comment" {
+		t.Error("attribute modified its input")
+	}
+}
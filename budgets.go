@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"linter/pkg/budget"
+)
+
+// checkBudgets lints the whole repository and returns by how many issues
+// the paths of the budgets of the config file went over them.
+func checkBudgets(ctx context.Context) (int, error) {
+	issues, err := lintIssues(ctx, args.Pwd, args.JsonFile, args.InspectDes, false)
+	if err != nil {
+		return 0, err
+	}
+
+	over := 0
+	for _, usage := range budget.Check(args.budgets, issues) {
+		if !usage.Over() {
+			slog.Info("within budget", "paths", usage.Paths, "issues", usage.Count, "budget", usage.Max)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s: %d issue(s), over the budget of %d\n", usage.Paths, usage.Count, usage.Max)
+		over += usage.Count - usage.Max
+	}
+	return over, nil
+}
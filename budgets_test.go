@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckBudgets(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	tests := []struct {
+		budgets  map[string]int
+		wantOver int
+	}{
+		{budgets: map[string]int{"a.go": 3, "pkg/**": 0}},
+		{budgets: map[string]int{"a.go": 1, "*.go": 2}, wantOver: 3},
+	}
+	for _, tt := range tests {
+		args = options{
+			Pwd:        dir,
+			JsonFile:   filepath.Join(dir, "report.json"),
+			InspectDes: []string{"./..."},
+			Bin:        reportingLinter(t, "errcheck", "errcheck", "govet"),
+			budgets:    tt.budgets,
+		}
+		over, err := checkBudgets(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if over != tt.wantOver {
+			t.Errorf("budgets %v: over by %d, want %d", tt.budgets, over, tt.wantOver)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/cache"
+	"linter/pkg/config"
+	"linter/pkg/lint"
+)
+
+type cacheCmd struct {
+	Clean *struct{} `arg:"subcommand:clean" help:"remove every cached lint result"`
+}
+
+// golangciConfigNames are the files golangci-lint reads its configuration
+// from when no --lint-config is given.
+var golangciConfigNames = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+
+func runCache(ctx context.Context, cmd *cacheCmd) error {
+	if cmd.Clean == nil {
+		return errors.New("cache requires clean")
+	}
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	release, err := lockFiles(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := cache.New(dir).Clean(); err != nil {
+		return err
+	}
+	slog.Info("cache removed", "dir", dir)
+	return nil
+}
+
+func cacheDir() (string, error) {
+	if args.CacheDir != "" {
+		return args.CacheDir, nil
+	}
+	return cache.DefaultDir()
+}
+
+// cacheLookup holds the cached issues of the packages of changed files,
+// and the keys to store the fresh issues of the others under.
+type cacheLookup struct {
+	cache *cache.Cache
+	// pwd is --pwd made absolute, the directory issue paths are relative to.
+	pwd    string
+	cached []result.Issue
+	hits   int
+	// missed maps the package directories without cached issues to their
+	// keys, and missedFiles holds a changed file of each.
+	missed      map[string]string
+	missedFiles []string
+}
+
+// lookupCache finds the cached issues of the packages containing files,
+// given relative to the repository root.
+func lookupCache(ctx context.Context, files []string) (*cacheLookup, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	if pwd, err = filepath.EvalSymlinks(pwd); err != nil {
+		return nil, err
+	}
+	settings, err := lintSettings(ctx, pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var packages, packageFiles []string
+	for _, file := range files {
+		pkg := filepath.Dir(filepath.Join(root, filepath.FromSlash(file)))
+		if !strings.HasSuffix(file, ".go") || seen[pkg] {
+			continue
+		}
+		seen[pkg] = true
+		if info, err := os.Stat(pkg); err != nil || !info.IsDir() {
+			continue
+		}
+		packages = append(packages, pkg)
+		packageFiles = append(packageFiles, file)
+	}
+	deps, err := lint.PackageDeps(ctx, pwd, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := &cacheLookup{cache: cache.New(dir), pwd: pwd, missed: make(map[string]string)}
+	for i, pkg := range packages {
+		key, err := cache.PackageKey(settings, pkg, deps[pkg]...)
+		if err != nil {
+			return nil, err
+		}
+		if issues, ok := lookup.cache.Get(key); ok {
+			lookup.cached = append(lookup.cached, issues...)
+			lookup.hits++
+			continue
+		}
+		lookup.missed[pkg] = key
+		lookup.missedFiles = append(lookup.missedFiles, packageFiles[i])
+	}
+	return lookup, nil
+}
+
+// store caches issues for every missed package, including those without
+// any issue.
+func (l *cacheLookup) store(issues []result.Issue) error {
+	byPackage := make(map[string][]result.Issue)
+	for _, issue := range issues {
+		path := issue.FilePath()
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(l.pwd, path)
+		}
+		byPackage[filepath.Dir(path)] = append(byPackage[filepath.Dir(path)], issue)
+	}
+	for pkg, key := range l.missed {
+		if err := l.cache.Put(key, byPackage[pkg]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lintSettings hashes everything outside the package itself that decides
+// its issues: the golangci-lint binary, its configuration and flags, the
+// module requirements and what is linted from where.
+func lintSettings(ctx context.Context, pwd string) (string, error) {
+	values := []string{
+		version,
+		pwd,
+		strings.Join(args.lintArgs, "\x00"),
+		strings.Join(args.lintEnv, "\x00"),
+		fmt.Sprint(args.IsolateEnv),
+		strings.Join(args.InspectDes, "\x00"),
+		fmt.Sprint(args.ChangedPackages),
+	}
+
+	if args.Runner == lint.RunnerDocker {
+		// Unlike a replaced binary, a tag moved to another release goes
+		// unnoticed.
+		values = append(values, args.Image)
+	} else {
+		bin, err := golangciBin(ctx)
+		if err != nil {
+			return "", err
+		}
+		info, err := os.Stat(bin)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, fmt.Sprintf("%s %d %d", bin, info.Size(), info.ModTime().UnixNano()))
+	}
+
+	configs := []string{args.LintConfig}
+	if args.LintConfig == "" {
+		configs = nil
+		for _, name := range golangciConfigNames {
+			path, err := config.FindFile(pwd, name)
+			if err != nil {
+				return "", err
+			}
+			configs = append(configs, path)
+		}
+	}
+	goMod, err := config.FindFile(pwd, "go.mod")
+	if err != nil {
+		return "", err
+	}
+	if goMod != "" {
+		configs = append(configs, goMod, filepath.Join(filepath.Dir(goMod), "go.sum"))
+	}
+	for _, path := range configs {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		values = append(values, path, string(content))
+	}
+	return cache.Settings(values...), nil
+}
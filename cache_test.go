@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"linter/pkg/diff"
+)
+
+func TestLintPwdReusesCachedPackages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/x\n\ngo 1.19\n")
+	write("a/a.go", "package a\n\nimport _ \"example.com/x/c\"\n")
+	write("b/b.go", "package b\n")
+	write("c/c.go", "package c\n")
+
+	// The stand-in linter counts its runs and reports one issue in a/a.go.
+	runs := filepath.Join(t.TempDir(), "runs")
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := `#!/bin/sh
+if [ "$1" = --version ]; then
+	echo "golangci-lint has version 1.51.1"
+	exit 0
+fi
+echo run >> ` + runs + `
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+printf '%s' '{"Issues":[{"FromLinter":"errcheck","Text":"x","Pos":{"Filename":"a/a.go","Line":1}}]}' > "$out"
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	args = options{
+		Pwd:        repo,
+		JsonFile:   filepath.Join(t.TempDir(), "report.json"),
+		InspectDes: []string{"./..."},
+		Bin:        bin,
+		CacheDir:   t.TempDir(),
+		vcs:        diff.Git,
+	}
+
+	lintRuns := func() int {
+		content, _ := os.ReadFile(runs)
+		return strings.Count(string(content), "run")
+	}
+	files := []string{"a/a.go", "b/b.go"}
+	for i, want := range []int{1, 1} {
+		issues, err := lintPwd(context.Background(), files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 1 || issues[0].FilePath() != "a/a.go" {
+			t.Errorf("run %d: issues = %v", i+1, issues)
+		}
+		if got := lintRuns(); got != want {
+			t.Errorf("run %d: golangci-lint ran %d times, want %d", i+1, got, want)
+		}
+	}
+
+	write("b/b.go", "package b\n\nvar X int\n")
+	if _, err := lintPwd(context.Background(), files); err != nil {
+		t.Fatal(err)
+	}
+	if got := lintRuns(); got != 2 {
+		t.Errorf("after an edit golangci-lint ran %d times, want 2", got)
+	}
+
+	// a imports c, which did not change itself.
+	write("c/c.go", "package c\n\nvar Y int\n")
+	if _, err := lintPwd(context.Background(), files); err != nil {
+		t.Fatal(err)
+	}
+	if got := lintRuns(); got != 3 {
+		t.Errorf("after an edit of a dependency golangci-lint ran %d times, want 3", got)
+	}
+
+	args.NoCache = true
+	if _, err := lintPwd(context.Background(), files); err != nil {
+		t.Fatal(err)
+	}
+	if got := lintRuns(); got != 4 {
+		t.Errorf("with --no-cache golangci-lint ran %d times, want 4", got)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/baseline"
+	"linter/pkg/diff"
+	"linter/pkg/output"
+	"linter/pkg/worktree"
+)
+
+type compareCmd struct {
+	From string `arg:"--from" help:"ref linted as the starting point"`
+	To   string `arg:"--to"   help:"ref linted as the result [default: HEAD]"`
+}
+
+// runCompare lints both refs, each in a worktree of its own, and prints
+// the issues --to has but --from had not, wherever they are; the issues it
+// fixed are logged. It returns how many issues were introduced.
+func runCompare(ctx context.Context, cmd *compareCmd) (int, error) {
+	if cmd.From == "" {
+		return 0, errors.New("compare requires --from")
+	}
+	to := firstNonEmpty(cmd.To, "HEAD")
+	if err := loadConfig(); err != nil {
+		return 0, err
+	}
+	if args.vcs.Name != diff.Git.Name {
+		return 0, errors.New("compare needs git worktrees")
+	}
+	release, err := lockShared(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	stdout, closePager := pagedStdout()
+	defer closePager()
+	printer, err := output.NewSinks(args.Out, stdout)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	before, err := lintRef(ctx, cmd.From)
+	if err != nil {
+		return 0, timedOut(err)
+	}
+	after, err := lintRef(ctx, to)
+	if err != nil {
+		return 0, timedOut(err)
+	}
+
+	introduced, fixed := compareIssues(before, after)
+	stats.SetFound(len(after))
+	stats.SetReported(introduced)
+	for _, issue := range fixed {
+		slog.Info("fixed issue", "file", issue.FilePath(), "line", issue.Line(), "linter", issue.FromLinter, "text", issue.Text)
+	}
+	slog.Info("refs compared", "from", cmd.From, "to", to, "introduced", len(introduced), "fixed", len(fixed))
+
+	return failing(introduced), printer.Print(introduced)
+}
+
+// compareIssues matches issues by fingerprint, so that moved code keeps
+// its issues, and returns those only after has and those only before has.
+func compareIssues(before, after []result.Issue) (introduced, fixed []result.Issue) {
+	return baseline.New(before).Filter(after), baseline.New(after).Filter(before)
+}
+
+// lintRef lints ref checked out in a temporary worktree, from the directory
+// matching --pwd, so that issue paths and fingerprints line up across refs.
+func lintRef(ctx context.Context, ref string) ([]result.Issue, error) {
+	tree, pwd, err := checkoutRef(ctx, args.Pwd, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer removeWorktree(tree)
+
+	slog.Debug("linting ref", "ref", ref, "commit", tree.Commit)
+	return lintIssues(ctx, pwd, args.JsonFile, args.InspectDes, false)
+}
+
+// checkoutRef checks ref out in a temporary worktree of the repository
+// containing dir, and returns it with the directory in it matching dir.
+func checkoutRef(ctx context.Context, dir, ref string) (*worktree.Worktree, string, error) {
+	root, err := diff.Git.Root(ctx, dir)
+	if err != nil {
+		return nil, "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if abs, err = filepath.EvalSymlinks(abs); err != nil {
+		return nil, "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tree, err := worktree.Add(ctx, root, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return tree, filepath.Join(tree.Dir, rel), nil
+}
+
+func removeWorktree(tree *worktree.Worktree) {
+	if err := tree.Remove(); err != nil {
+		slog.Warn("worktree left behind", "error", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLintRefsAndCompare(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	git := func(argv ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+		}, argv...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+	}
+	// The stand-in linter reports whatever issues.json of the checkout holds.
+	commit := func(linters ...string) {
+		t.Helper()
+		issues := make([]string, 0, len(linters))
+		for _, linter := range linters {
+			issues = append(issues, `{"FromLinter":"`+linter+`","Text":"x","Pos":{"Filename":"svc/a.go","Line":1}}`)
+		}
+		path := filepath.Join(repo, "svc", "issues.json")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(`{"Issues":[`+strings.Join(issues, ",")+`]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", ".")
+		git("commit", "-q", "-m", strings.Join(linters, " "))
+	}
+	git("init", "-q")
+	commit("errcheck", "lll")
+	commit("lll", "govet")
+
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+cat issues.json > "$out"
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	args = options{
+		Pwd:        filepath.Join(repo, "svc"),
+		JsonFile:   filepath.Join(t.TempDir(), "report.json"),
+		InspectDes: []string{"./..."},
+		Bin:        bin,
+		Out:        []string{"text"},
+	}
+
+	ctx := context.Background()
+	before, err := lintRef(ctx, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := lintRef(ctx, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 2 || before[0].FromLinter != "errcheck" || len(after) != 2 || after[1].FromLinter != "govet" {
+		t.Fatalf("before = %v, after = %v", before, after)
+	}
+
+	introduced, fixed := compareIssues(before, after)
+	if len(introduced) != 1 || introduced[0].FromLinter != "govet" {
+		t.Errorf("introduced = %v, want the govet issue", introduced)
+	}
+	if len(fixed) != 1 || fixed[0].FromLinter != "errcheck" {
+		t.Errorf("fixed = %v, want the errcheck issue", fixed)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"io"
+
+	"linter/pkg/completion"
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/output"
+)
+
+type completionCmd struct {
+	Shell string `arg:"positional,required" help:"bash, zsh, fish or powershell"`
+}
+
+// runCompletion writes the completion script of the command line for the
+// shell, with the values of the flags that only take a few.
+func runCompletion(w io.Writer, cmd *completionCmd) error {
+	values := map[string][]string{
+		"--out":          output.Formats(),
+		"--color":        output.ColorModes,
+		"--vcs":          diff.VCSNames(),
+		"--scope":        filter.Scopes,
+		"--severity-min": filter.Severities,
+		"--group-by":     {"owner"},
+		"completion":     completion.Shells,
+	}
+	root := completion.FromStruct("linter", &options{}, values, true)
+	return completion.Write(w, cmd.Shell, root)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runCompletion(&buf, &completionCmd{Shell: "bash"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"'linter hooks install')", "--scope) COMPREPLY=($(compgen -W 'line hunk file package'", "--vcs) COMPREPLY=($(compgen -W 'git hg jj'"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("bash completion does not contain %q", want)
+		}
+	}
+
+	if err := runCompletion(&buf, &completionCmd{Shell: "cmd.exe"}); err == nil {
+		t.Error("unknown shell expected an error")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/coverage"
+	"linter/pkg/diff"
+	"linter/pkg/lint"
+)
+
+// coverageLinter is the linter name uncovered lines are reported under.
+const coverageLinter = "coverage"
+
+type coverageCmd struct {
+	Profile string `arg:"--profile" help:"coverage profile written by go test -coverprofile"`
+}
+
+// runCoverage lints the changes like run, also reporting the changed lines
+// the tests of --profile never ran. It returns how many issues there were.
+func runCoverage(ctx context.Context, cmd *coverageCmd) (int, error) {
+	if cmd.Profile == "" {
+		return 0, errors.New("coverage requires --profile")
+	}
+	profile, err := coverage.Load(cmd.Profile)
+	if err != nil {
+		return 0, err
+	}
+	args.profile = profile
+	return run(ctx)
+}
+
+// coverageIssues reports every run of changed lines the coverage profile
+// has as not run, with paths relative to --pwd like those of golangci-lint.
+// Without a profile there are none.
+func coverageIssues(ctx context.Context, changes []diff.FileChange) ([]result.Issue, error) {
+	if args.profile == nil {
+		return nil, nil
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := lint.ChangedModules(root, diff.Paths(changes))
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]diff.FileChange, len(changes))
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+
+	var issues []result.Issue
+	for _, module := range modules {
+		modulePath, err := coverage.ModulePath(module.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range module.Files {
+			rel, err := filepath.Rel(module.Dir, filepath.Join(root, filepath.FromSlash(file)))
+			if err != nil {
+				return nil, err
+			}
+			uncovered := args.profile.Uncovered(modulePath + "/" + filepath.ToSlash(rel))
+			issues = append(issues, uncoveredIssues(file, byPath[file], uncovered)...)
+		}
+	}
+	return relativeTo(pwd, root, issues)
+}
+
+// uncoveredIssues reports the uncovered lines that change added, one issue
+// for every run of consecutive lines.
+func uncoveredIssues(path string, change diff.FileChange, uncovered []int) []result.Issue {
+	changed := make(map[int]bool)
+	for _, c := range change.Changes {
+		for line := c.Start; line <= c.End; line++ {
+			changed[line] = true
+		}
+	}
+
+	var issues []result.Issue
+	for i := 0; i < len(uncovered); {
+		if !changed[uncovered[i]] {
+			i++
+			continue
+		}
+		from, to := uncovered[i], uncovered[i]
+		for i++; i < len(uncovered) && uncovered[i] == to+1 && changed[uncovered[i]]; i++ {
+			to = uncovered[i]
+		}
+		issue := result.Issue{
+			FromLinter: coverageLinter,
+			Text:       "changed line is not covered by tests",
+			Pos:        token.Position{Filename: path, Line: from},
+		}
+		if to > from {
+			issue.Text = fmt.Sprintf("%d changed lines are not covered by tests", to-from+1)
+			issue.LineRange = &result.Range{From: from, To: to}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
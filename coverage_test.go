@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"linter/pkg/config"
+	"linter/pkg/coverage"
+	"linter/pkg/diff"
+)
+
+func TestCoverageIssues(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "svc", "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "svc", "go.mod"), []byte("module example.com/svc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/svc/pkg/a.go:3.13,6.2 2 0
+example.com/svc/pkg/a.go:8.13,12.2 3 0
+example.com/svc/pkg/a.go:14.13,15.2 1 1
+example.com/svc/pkg/b.go:3.13,4.2 1 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args = parseOptions(t, "--pwd", filepath.Join(repo, "svc"), "coverage", "--profile", "cover.out")
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	args.profile = profile
+
+	changes := []diff.FileChange{
+		{Path: "svc/pkg/a.go", Changes: []*diff.Change{{Start: 1, End: 4}, {Start: 9, End: 10}, {Start: 12, End: 15}}},
+		{Path: "svc/pkg/c.go", Changes: []*diff.Change{{Start: 1, End: 9}}},
+	}
+	issues, err := coverageIssues(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		lines := issue.GetLineRange()
+		got = append(got, fmt.Sprintf("%s:%d-%d %s: %s", issue.FilePath(), lines.From, lines.To, issue.FromLinter, issue.Text))
+	}
+	want := []string{
+		"pkg/a.go:3-4 coverage: 2 changed lines are not covered by tests",
+		"pkg/a.go:9-10 coverage: 2 changed lines are not covered by tests",
+		"pkg/a.go:12-12 coverage: changed line is not covered by tests",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCoverageNeedsProfile(t *testing.T) {
+	o := parseOptions(t, "coverage")
+	if o.Coverage == nil {
+		t.Fatal("coverage subcommand not selected")
+	}
+	if _, err := runCoverage(context.Background(), o.Coverage); err == nil {
+		t.Error("coverage without --profile expected an error")
+	}
+	missing := filepath.Join(t.TempDir(), "cover.out")
+	if _, err := runCoverage(context.Background(), &coverageCmd{Profile: missing}); err == nil {
+		t.Error("coverage with a missing profile expected an error")
+	}
+}
@@ -1,16 +1,19 @@
 module linter
 
-go 1.19
+go 1.21
 
 require (
 	github.com/alexflint/go-arg v1.4.3
+	github.com/fatih/color v1.14.1
 	github.com/golangci/golangci-lint v1.51.1
+	golang.org/x/mod v0.7.0
+	golang.org/x/sys v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/alexflint/go-scalar v1.1.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/fatih/color v1.14.1 // indirect
 	github.com/go-xmlfmt/xmlfmt v1.1.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.17 // indirect
@@ -18,8 +21,5 @@ require (
 	github.com/sirupsen/logrus v1.9.0 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
 	github.com/stretchr/testify v1.8.1 // indirect
-	golang.org/x/mod v0.7.0 // indirect
-	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/tools v0.5.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
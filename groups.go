@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/output"
+)
+
+// printGroups prints the issues once per --group-by group under a heading.
+func printGroups(ctx context.Context, w io.Writer, printer output.Printer, issues []result.Issue) error {
+	groups := output.GroupIssues(issues, args.GroupBy)
+	if args.GroupBy == "owner" {
+		var err error
+		if groups, err = ownerGroups(ctx, issues); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range groups {
+		heading := fmt.Sprintf("%s: %d issue(s)\n", group.Name, len(group.Issues))
+		if args.Out[0] == "markdown" {
+			heading = fmt.Sprintf("## %s\n\n", group.Name)
+		}
+		if _, err := io.WriteString(w, heading); err != nil {
+			return err
+		}
+		if err := printer.Print(group.Issues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkGroupBy validates --group-by, which only makes sense for formats
+// that can be printed several times in a row.
+func (o *options) checkGroupBy() error {
+	switch {
+	case o.GroupBy == "":
+		return nil
+	case o.GroupBy != "owner" && !output.ValidGroup(o.GroupBy):
+		return fmt.Errorf("--group-by %q is not one of %s, owner", o.GroupBy, strings.Join(output.GroupKeys, ", "))
+	case len(o.Out) != 1 || (o.Out[0] != "text" && o.Out[0] != "markdown"):
+		return fmt.Errorf("--group-by %s works with a single --out, text or markdown, only", o.GroupBy)
+	}
+	return nil
+}
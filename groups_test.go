@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/config"
+	"linter/pkg/output"
+)
+
+func TestPrintGroups(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	issues := []result.Issue{
+		{FromLinter: "govet", Text: "unreachable code", Severity: "warning", Pos: token.Position{Filename: "pkg/b.go", Line: 9}},
+		{FromLinter: "errcheck", Text: "unchecked", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 3}},
+		{FromLinter: "govet", Text: "printf: wrong verb", Severity: "error", Pos: token.Position{Filename: "main.go", Line: 4}},
+	}
+	tests := []struct {
+		groupBy, sort, out string
+		want               string
+	}{
+		{
+			groupBy: "linter", out: "text",
+			want: "govet: 2 issue(s)\npkg/b.go:9: unreachable code (govet)\nmain.go:4: printf: wrong verb (govet)\n" +
+				"errcheck: 1 issue(s)\npkg/a.go:3: unchecked (errcheck)\n",
+		},
+		{
+			groupBy: "package", sort: "line", out: "text",
+			want: ".: 1 issue(s)\nmain.go:4: printf: wrong verb (govet)\n" +
+				"pkg: 2 issue(s)\npkg/a.go:3: unchecked (errcheck)\npkg/b.go:9: unreachable code (govet)\n",
+		},
+		{
+			groupBy: "file", sort: "severity", out: "markdown",
+			want: "## main.go\n\n## pkg/a.go\n\n## pkg/b.go\n\n",
+		},
+	}
+	for _, tt := range tests {
+		flags := []string{"--group-by", tt.groupBy, "--out", tt.out}
+		if tt.sort != "" {
+			flags = append(flags, "--sort", tt.sort)
+		}
+		args = parseOptions(t, flags...)
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		printer := output.NewText(&out)
+		if tt.out == "markdown" {
+			printer = printerFunc(func([]result.Issue) error { return nil })
+		}
+		if err := printGroups(context.Background(), &out, printer, output.Sort(issues, args.Sort)); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("--group-by %s --sort %q printed\n%s\nwant\n%s", tt.groupBy, tt.sort, out.String(), tt.want)
+		}
+	}
+}
+
+type printerFunc func([]result.Issue) error
+
+func (f printerFunc) Print(issues []result.Issue) error {
+	return f(issues)
+}
+
+func TestCheckGroupBy(t *testing.T) {
+	tests := []struct {
+		groupBy, out string
+		wantErr      bool
+	}{
+		{"", "sarif", false},
+		{"owner", "text", false},
+		{"owner", "markdown", false},
+		{"linter", "text", false},
+		{"package", "markdown", false},
+		{"file", "text", false},
+		{"owner", "sarif", true},
+		{"linter", "json", true},
+		{"team", "text", true},
+	}
+	for _, tt := range tests {
+		o := options{GroupBy: tt.groupBy, Out: []string{tt.out}}
+		if err := o.checkGroupBy(); (err != nil) != tt.wantErr {
+			t.Errorf("checkGroupBy(%q, %q) = %v, want error %v", tt.groupBy, tt.out, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSortOption(t *testing.T) {
+	o := parseOptions(t, "--sort", "severity")
+	if err := o.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	o = parseOptions(t, "--sort", "age")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--sort age expected an error")
+	}
+}
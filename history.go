@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/history"
+)
+
+type trendsCmd struct {
+	Limit int `arg:"--limit" help:"how many runs, files and rules to list [default: 10]"`
+}
+
+// recordHistory adds the run that reported issues to --history. A commit
+// or branch that cannot be found is recorded empty.
+func recordHistory(ctx context.Context, issues []result.Issue) error {
+	db, err := history.Open(ctx, args.History)
+	if err != nil {
+		return err
+	}
+	commit, err := diff.ResolveCommit(ctx, args.Pwd, "HEAD")
+	if err != nil {
+		slog.Debug("no commit to record in the history", "err", err)
+	}
+	branch, err := currentBranch(ctx)
+	if err != nil {
+		slog.Debug("no branch to record in the history", "err", err)
+	}
+	return db.Record(history.Run{
+		Time:   time.Now(),
+		Commit: commit,
+		Branch: branch,
+		Found:  stats.Found,
+		Issues: issues,
+	})
+}
+
+// runTrends prints the issue counts of the last runs in --history, and the
+// files and rules with the most issues across them all.
+func runTrends(ctx context.Context, w io.Writer, cmd *trendsCmd) error {
+	if args.History == "" {
+		return errors.New("trends requires --history")
+	}
+	limit := cmd.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	db, err := history.Open(ctx, args.History)
+	if err != nil {
+		return err
+	}
+
+	counts, err := db.Counts(limit)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "No run recorded yet.")
+		return nil
+	}
+	fmt.Fprintln(w, "Issues per run:")
+	for _, count := range counts {
+		fmt.Fprintf(w, "  %s  %-7.7s  %4d reported of %4d found  %s\n",
+			count.Time.Local().Format("2006-01-02 15:04"), count.Commit, count.Reported, count.Found, count.Branch)
+	}
+
+	files, err := db.TopFiles(limit)
+	if err != nil {
+		return err
+	}
+	rules, err := db.TopRules(limit)
+	if err != nil {
+		return err
+	}
+	for _, section := range []struct {
+		title   string
+		tallies []history.Tally
+	}{
+		{"Top offending files:", files},
+		{"Most violated rules:", rules},
+	} {
+		if len(section.tallies) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, "\n"+section.title)
+		for _, tally := range section.tallies {
+			fmt.Fprintf(w, "  %5d  %s\n", tally.Issues, tally.Name)
+		}
+	}
+	return nil
+}
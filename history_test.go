@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/summary"
+)
+
+func TestRunTrends(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 binary not available")
+	}
+	ctx := context.Background()
+	args = options{Pwd: t.TempDir()}
+	var buf bytes.Buffer
+	if err := runTrends(ctx, &buf, &trendsCmd{}); err == nil {
+		t.Error("trends without --history expected an error")
+	}
+
+	args.History = filepath.Join(t.TempDir(), "history.sqlite")
+	if err := runTrends(ctx, &buf, &trendsCmd{}); err != nil || buf.String() != "No run recorded yet.\n" {
+		t.Errorf("trends of an empty history = %q, %v", buf.String(), err)
+	}
+
+	stats = summary.New()
+	stats.SetFound(2)
+	issues := []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}}
+	if err := recordHistory(ctx, issues); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := runTrends(ctx, &buf, &trendsCmd{Limit: 5}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1 reported of    2 found", "Top offending files:\n      1  a.go\n", "Most violated rules:\n      1  errcheck\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trends output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
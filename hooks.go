@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+
+	"linter/pkg/hooks"
+)
+
+type hooksCmd struct {
+	Install   *hookKinds `arg:"subcommand:install"   help:"write git hooks running the linter, chaining existing hooks"`
+	Uninstall *hookKinds `arg:"subcommand:uninstall" help:"remove the git hooks and restore the chained ones"`
+}
+
+type hookKinds struct {
+	PreCommit bool `arg:"--pre-commit" help:"the pre-commit hook, checking staged changes (default)"`
+	PrePush   bool `arg:"--pre-push"   help:"the pre-push hook, checking the branch against $LINTERDIFF_BASE_REF or origin/HEAD"`
+}
+
+func (k *hookKinds) kinds() []hooks.Kind {
+	var kinds []hooks.Kind
+	if k.PreCommit || !k.PrePush {
+		kinds = append(kinds, hooks.PreCommit)
+	}
+	if k.PrePush {
+		kinds = append(kinds, hooks.PrePush)
+	}
+	return kinds
+}
+
+func runHooks(cmd *hooksCmd, pwd string) error {
+	if cmd.Install == nil && cmd.Uninstall == nil {
+		return errors.New("hooks requires install or uninstall")
+	}
+
+	dir, err := hooks.Dir(pwd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Uninstall != nil {
+		for _, kind := range cmd.Uninstall.kinds() {
+			if err := hooks.Uninstall(dir, kind); err != nil {
+				return err
+			}
+			slog.Info("removed hook", "kind", string(kind))
+		}
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	for _, kind := range cmd.Install.kinds() {
+		if err := hooks.Install(dir, kind, executable); err != nil {
+			return err
+		}
+		slog.Info("installed hook", "kind", string(kind))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"linter/pkg/lock"
+	"linter/pkg/output"
+)
+
+// lockShared locks the files a run writes that other runs may share: the
+// golangci-lint report, the --out files, the history database and extra.
+// The lint cache is left out: it is shared by every run of the user and
+// written atomically. It waits for the runs holding them, up to --wait when
+// set, or with --no-wait fails at once. The returned function releases
+// them.
+func lockShared(ctx context.Context, extra ...string) (func(), error) {
+	shared := []string{args.JsonFile, args.History}
+	for _, spec := range args.Out {
+		if sink, err := output.ParseSink(spec); err == nil {
+			shared = append(shared, sink.Path)
+		}
+	}
+	return lockFiles(ctx, append(shared, extra...)...)
+}
+
+// lockFiles locks every one of files that is not empty, in the order of
+// their lock files so that runs never wait for each other in a circle.
+func lockFiles(ctx context.Context, files ...string) (func(), error) {
+	dir, err := lock.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	guarded := make(map[string]string)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		path, err := lock.Path(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		guarded[path] = file
+	}
+	paths := make([]string, 0, len(guarded))
+	for path := range guarded {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if args.Wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.Wait)
+		defer cancel()
+	}
+	var held []*lock.Lock
+	release := func() {
+		for _, l := range held {
+			if err := l.Release(); err != nil {
+				slog.Warn("releasing a lock", "error", err)
+			}
+		}
+	}
+	for _, path := range paths {
+		l, err := lock.TryAcquire(path)
+		if errors.Is(err, lock.ErrLocked) && !args.NoWait {
+			slog.Info("waiting for another run using a shared file", "file", guarded[path])
+			l, err = lock.Acquire(ctx, path)
+		}
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("%s is in use by another run: %w", guarded[path], err)
+		}
+		held = append(held, l)
+	}
+	return release, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"linter/pkg/config"
+	"linter/pkg/lock"
+)
+
+func TestWaitOptions(t *testing.T) {
+	tests := []struct {
+		argv    []string
+		wantErr bool
+	}{
+		{[]string{"--wait", "30s"}, false},
+		{[]string{"--no-wait"}, false},
+		{[]string{"--wait", "-1s"}, true},
+		{[]string{"--wait", "30s", "--no-wait"}, true},
+	}
+	for _, tt := range tests {
+		o := parseOptions(t, tt.argv...)
+		if err := o.applyConfig(&config.Config{}); (err != nil) != tt.wantErr {
+			t.Errorf("%v: applyConfig = %v, want an error %v", tt.argv, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLockShared(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.json")
+
+	args = options{JsonFile: report, Out: []string{"text", "sarif:" + filepath.Join(dir, "lint.sarif")}}
+	release, err := lockShared(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args = options{JsonFile: report, NoWait: true}
+	if _, err := lockShared(context.Background()); !errors.Is(err, lock.ErrLocked) || !strings.Contains(err.Error(), report) {
+		t.Errorf("--no-wait on a shared report = %v, want ErrLocked naming it", err)
+	}
+	args = options{JsonFile: filepath.Join(dir, "other.json"), NoWait: true}
+	other, err := lockShared(context.Background())
+	if err != nil {
+		t.Errorf("another report: %v", err)
+	} else {
+		other()
+	}
+
+	args = options{JsonFile: report, Wait: 50 * time.Millisecond}
+	if _, err := lockShared(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("--wait on a shared report = %v, want it to time out", err)
+	}
+
+	release()
+	args = options{JsonFile: report, NoWait: true}
+	again, err := lockShared(context.Background())
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	again()
+}
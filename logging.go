@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"linter/pkg/output"
+	"linter/pkg/progress"
+	"linter/pkg/trace"
+)
+
+// setupLogging sends log records to stderr: everything down to debug with
+// verbose, only errors with quiet, and from info up otherwise.
+func setupLogging(verbose, quiet bool) error {
+	if verbose && quiet {
+		return errors.New("--verbose and --quiet are mutually exclusive")
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case verbose:
+		level = slog.LevelDebug
+	case quiet:
+		level = slog.LevelError
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(toStderr{}, &slog.HandlerOptions{
+		Level: level,
+		// Timestamps only clutter the output of a short-lived command.
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+	return nil
+}
+
+// stderr is where log records and golangci-lint diagnostics go: standard
+// error, or the progress reporter drawing its spinner there.
+var stderr io.Writer = os.Stderr
+
+// toStderr writes to stderr as it is at the time of writing.
+type toStderr struct{}
+
+func (toStderr) Write(p []byte) (int, error) {
+	return stderr.Write(p)
+}
+
+// startProgress shows the steps of the run on stderr, with a spinner on a
+// terminal and otherwise a log line every so often, unless --no-progress
+// or --quiet. The returned function stops showing them.
+func startProgress(ctx context.Context) (context.Context, func()) {
+	if args.NoProgress || args.Quiet {
+		return ctx, func() {}
+	}
+	terminal := output.IsTerminal(os.Stderr) && os.Getenv("TERM") != "dumb"
+	reporter := progress.New(os.Stderr, terminal)
+	reporter.Start()
+	stderr = reporter
+	return progress.WithReporter(ctx, reporter), func() {
+		reporter.Stop()
+		stderr = os.Stderr
+	}
+}
+
+// phase logs at debug level how long the step name took once the returned
+// function is called, and adds it to the run summary. The step is traced
+// as a span under ctx and shown as progress, and the returned context
+// carries both.
+func phase(ctx context.Context, name string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := trace.Start(ctx, name)
+	ctx, step := progress.Begin(ctx, name)
+	return ctx, func() {
+		step.End()
+		span.End()
+		took := time.Since(start)
+		stats.Time(name, took)
+		slog.Debug("phase done", "phase", name, "took", took.Round(time.Millisecond))
+	}
+}
+
+// startTrace traces the run as a span named after its subcommands when the
+// OTEL_* environment variables name an OTLP endpoint. The returned function
+// ends the span and exports the trace.
+func startTrace(ctx context.Context, subcommands []string) (context.Context, func(code int, err error)) {
+	tracer, err := trace.FromEnv()
+	if err != nil {
+		slog.Warn("tracing disabled: " + err.Error())
+	}
+	if tracer == nil {
+		return ctx, func(int, error) {}
+	}
+	tracer.SetVersion(version)
+
+	if len(subcommands) == 0 {
+		subcommands = []string{"run"}
+	}
+	ctx, span := trace.Start(trace.WithTracer(ctx, tracer), "linter "+strings.Join(subcommands, " "))
+	return ctx, func(code int, err error) {
+		span.SetAttribute("exit.code", code)
+		span.SetError(err)
+		span.End()
+		// A trace is not worth failing the run over.
+		if err := tracer.Export(); err != nil {
+			slog.Warn("exporting the trace failed: " + err.Error())
+		}
+	}
+}
@@ -3,253 +3,1934 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
-	"regexp"
-	"strconv"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/alexflint/go-arg"
 	"github.com/golangci/golangci-lint/pkg/logutils"
-	"github.com/golangci/golangci-lint/pkg/printers"
 	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/baseline"
+	"linter/pkg/bitbucket"
+	"linter/pkg/command"
+	"linter/pkg/config"
+	"linter/pkg/coverage"
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/fix"
+	"linter/pkg/format"
+	"linter/pkg/gerrit"
+	"linter/pkg/github"
+	"linter/pkg/gitlab"
+	"linter/pkg/gitrepo"
+	"linter/pkg/lint"
+	"linter/pkg/metrics"
+	"linter/pkg/notify"
+	"linter/pkg/output"
+	"linter/pkg/pager"
+	"linter/pkg/permalink"
+	"linter/pkg/reports"
+	"linter/pkg/snippet"
+	"linter/pkg/summary"
+	"linter/pkg/suppress"
+	"linter/pkg/vuln"
 )
 
-var args struct {
-	Pwd        string `arg:"--pwd" default:"."                        help:"pwd to run linter"`
-	Cmd        string `arg:"-c"    default:"git diff"                 help:"command to find changes"`
-	JsonFile   string `arg:"-f"    default:"/tmp/golang_ci_lint.json" help:"json file output"`
-	InspectDes string `arg:"-d"    default:"./..."                    help:"path to inspect"`
+type options struct {
+	Config           string        `arg:"--config,env:LINTERDIFF_CONFIG"                           help:"config file, searched upward from pwd as .linterdiff.yml when empty"`
+	Pwd              string        `arg:"--pwd,env:LINTERDIFF_PWD"                                 help:"pwd to run linter [default: .]"`
+	Cmd              string        `arg:"-c,env:LINTERDIFF_CMD"                                    help:"command to find changes [default: git diff]"`
+	BaseRef          string        `arg:"--base-ref,env:LINTERDIFF_BASE_REF"                       help:"diff against the merge base of HEAD and this ref instead of -c"`
+	Staged           bool          `arg:"--staged,env:LINTERDIFF_STAGED"                           help:"check only staged changes, for pre-commit hooks"`
+	DiffFile         string        `arg:"--diff-file,env:LINTERDIFF_DIFF_FILE"                     help:"read the changes from a unified diff file instead of running -c"`
+	DiffStdin        bool          `arg:"--diff-stdin"                                             help:"read the changes as a unified diff from stdin"`
+	Range            string        `arg:"--range,env:LINTERDIFF_RANGE"                             help:"check the changes of the commits of a range, such as main..feature, merges left out"`
+	Commits          []string      `arg:"--commits,env:LINTERDIFF_COMMITS"                         help:"check the changes of these commits together, such as abc123,def456"`
+	JsonFile         string        `arg:"-f,env:LINTERDIFF_JSON_FILE"                              help:"json file output [default: /tmp/golang_ci_lint.json]"`
+	InspectDes       []string      `arg:"-d,env:LINTERDIFF_INSPECT"                                help:"paths to inspect [default: ./...]"`
+	Bin              string        `arg:"--bin,env:GOLANGCI_LINT_BIN"                              help:"golangci-lint binary, discovered when empty"`
+	LintVersion      string        `arg:"--lint-version,env:LINTERDIFF_LINT_VERSION"               help:"golangci-lint release to run, such as v1.59.1, downloaded into the cache directory unless the local binary is that version"`
+	Runner           string        `arg:"--runner,env:LINTERDIFF_RUNNER"                           help:"run golangci-lint as the local binary or in a Docker container: local or docker [default: local]"`
+	Image            string        `arg:"--image,env:LINTERDIFF_IMAGE"                             help:"golangci-lint image of --runner docker [default: golangci/golangci-lint of --lint-version, or v1.51.1]"`
+	Context          int           `arg:"--context,env:LINTERDIFF_CONTEXT"                         help:"with --out text, show this many source lines around each issue, changed lines marked +"`
+	Pager            string        `arg:"--pager,env:LINTERDIFF_PAGER"                             help:"show the text output through $PAGER, less when unset: auto, always or never; auto pages a terminal, and less only output longer than a screen [default: auto]"`
+	Color            string        `arg:"--color,env:LINTERDIFF_COLOR"                             help:"color the text output: auto, always or never; auto colors a terminal unless $NO_COLOR is set [default: auto]"`
+	Out              []string      `arg:"--out,separate"                                           help:"output format, or format:file to write a file; repeat to write several: text, sarif, github-actions, rdjson, rdjsonl, junit, markdown, json, jsonl, csv, tsv, gerrit, teamcity, template, annotated-diff, quickfix, vscode [default: text, or $LINTERDIFF_OUT as a comma-separated list]"`
+	TemplateFile     string        `arg:"--template-file,env:LINTERDIFF_TEMPLATE_FILE"             help:"Go text/template that --out template executes on the issues, the changed files and the run summary"`
+	LinkBase         string        `arg:"--link-base,env:LINTERDIFF_LINK_BASE"                     help:"web page of the repository, such as https://github.com/org/repo, to link each issue to its line at the checked commit in every output; auto derives it from the origin remote"`
+	ExcludePaths     []string      `arg:"--exclude-paths,env:LINTERDIFF_EXCLUDE_PATHS"             help:"globs of changed files to ignore, such as vendor/**,**/*_gen.go"`
+	IncludeGenerated bool          `arg:"--include-generated,env:LINTERDIFF_INCLUDE_GENERATED"     help:"also check changed Go files marked // Code generated ... DO NOT EDIT., skipped by default"`
+	IncludePaths     []string      `arg:"--include-paths,env:LINTERDIFF_INCLUDE_PATHS"             help:"globs of changed files to check, all of them when empty"`
+	ExcludeLinters   []string      `arg:"--exclude-linters,env:LINTERDIFF_EXCLUDE_LINTERS"         help:"linters whose issues are dropped, such as lll,godot"`
+	OnlyLinters      []string      `arg:"--only-linters,env:LINTERDIFF_ONLY_LINTERS"               help:"report only the issues of these linters"`
+	MergeResults     []string      `arg:"--merge-results,env:LINTERDIFF_MERGE_RESULTS"             help:"match the issues of these golangci-lint JSON, SARIF or checkstyle reports, written by a lint run elsewhere, instead of running golangci-lint"`
+	Vuln             bool          `arg:"--vuln,env:LINTERDIFF_VULN"                               help:"also run govulncheck, found on $PATH, on the changed packages and report the calls into vulnerable code on changed lines"`
+	FormatCheck      []string      `arg:"--format-check,env:LINTERDIFF_FORMAT_CHECK"               help:"also report the changed lines these formatters would rewrite: gofmt, gofumpt or goimports, found on $PATH"`
+	NoDedup          bool          `arg:"--no-dedup,env:LINTERDIFF_NO_DEDUP"                       help:"report every linter's issue even where another linter reported the same problem at the same position"`
+	SeverityMin      string        `arg:"--severity-min,env:LINTERDIFF_SEVERITY_MIN"               help:"report only issues at least this severe: info, warning or error"`
+	ContextLines     int           `arg:"--context-lines,env:LINTERDIFF_CONTEXT_LINES"             help:"also report issues up to this many lines away from a change"`
+	Deletions        bool          `arg:"--deletions,env:LINTERDIFF_DELETIONS"                     help:"also report issues next to removed lines, including in files that only lost lines"`
+	Scope            string        `arg:"--scope,env:LINTERDIFF_SCOPE"                             help:"report issues on changed lines, or anywhere in changed hunks, files or packages: line, hunk, file or package"`
+	Blame            bool          `arg:"--blame,env:LINTERDIFF_BLAME"                             help:"add the author of the line, from git blame, to every issue"`
+	Author           []string      `arg:"--author,env:LINTERDIFF_AUTHOR"                           help:"report only issues on lines last changed by these authors, by email or name"`
+	Owner            []string      `arg:"--owner,env:LINTERDIFF_OWNER"                             help:"report only issues in files these CODEOWNERS owners own, such as @org/team"`
+	GroupBy          string        `arg:"--group-by,env:LINTERDIFF_GROUP_BY"                       help:"print the issues in groups: by file, linter, package, or owner from CODEOWNERS"`
+	Sort             string        `arg:"--sort,env:LINTERDIFF_SORT"                               help:"order the issues by file, line, severity or linter instead of as the linters reported them"`
+	ChangedPackages  bool          `arg:"--changed-packages,env:LINTERDIFF_CHANGED_PACKAGES"       help:"lint only the packages containing changed files instead of -d"`
+	StepSummary      bool          `arg:"--github-step-summary"                                    help:"also append a Markdown summary to $GITHUB_STEP_SUMMARY"`
+	Baseline         string        `arg:"--baseline"                                               help:"baseline file, only issues missing from it are reported"`
+	Suppressions     string        `arg:"--suppressions,env:LINTERDIFF_SUPPRESSIONS"               help:"suppression file, searched upward from pwd as .linter-suppressions.yml when empty"`
+	Ratchet          string        `arg:"--ratchet,env:LINTERDIFF_RATCHET"                         help:"state file of the issue count per linter across the repository, failing when any count grows"`
+	Budgets          bool          `arg:"--budgets,env:LINTERDIFF_BUDGETS"                         help:"lint the whole repository and fail when the issues under a path of the budgets of the config file outnumber its budget"`
+	RatchetBranch    string        `arg:"--ratchet-branch,env:LINTERDIFF_RATCHET_BRANCH"           help:"branch whose runs record lower counts in --ratchet [default: main]"`
+	GitHubPR         string        `arg:"--github-pr,env:LINTERDIFF_GITHUB_PR"                     help:"also post issues as review comments on this pull request, as owner/repo#number"`
+	GitHubCheck      string        `arg:"--github-check,env:LINTERDIFF_GITHUB_CHECK"               help:"also report issues as a check run with annotations on the commit, in this repository as owner/repo"`
+	GitHubStatus     string        `arg:"--github-status,env:LINTERDIFF_GITHUB_STATUS"             help:"also set a commit status with the issue count on the commit, in this repository as owner/repo"`
+	GitLabMR         string        `arg:"--gitlab-mr,env:LINTERDIFF_GITLAB_MR"                     help:"also post issues as discussions on this merge request, as group/project!iid"`
+	GitLabStatus     string        `arg:"--gitlab-status,env:LINTERDIFF_GITLAB_STATUS"             help:"also set a commit status with the issue count on the commit, in this project as group/project or its ID"`
+	StatusContext    string        `arg:"--status-context,env:LINTERDIFF_STATUS_CONTEXT"           help:"context, or name, of the commit status [default: lint/changed-lines]"`
+	StatusURL        string        `arg:"--status-url,env:LINTERDIFF_STATUS_URL"                   help:"link of the commit status and the --notify-webhook summary, such as the uploaded report [default: the CI job]"`
+	NotifyWebhook    string        `arg:"--notify-webhook,env:LINTERDIFF_NOTIFY_WEBHOOK"           help:"post a summary with the top issues to this webhook, such as a Slack incoming webhook, when the run fails"`
+	NotifyFormat     string        `arg:"--notify-format,env:LINTERDIFF_NOTIFY_FORMAT"             help:"payload of --notify-webhook: slack or json [default: slack]"`
+	Bitbucket        string        `arg:"--bitbucket,env:LINTERDIFF_BITBUCKET"                     help:"also publish issues as a Code Insights report on the commit, in this repository as workspace/repo (project/repo on Bitbucket Server)"`
+	BitbucketURL     string        `arg:"--bitbucket-url,env:LINTERDIFF_BITBUCKET_URL"             help:"Bitbucket Server or Data Center to publish to, instead of Bitbucket Cloud"`
+	GerritChange     string        `arg:"--gerrit-change,env:LINTERDIFF_GERRIT_CHANGE"             help:"also post issues as robot comments on this Gerrit change, as its number or project~number"`
+	GerritURL        string        `arg:"--gerrit-url,env:LINTERDIFF_GERRIT_URL"                   help:"Gerrit server of --gerrit-change"`
+	GerritUser       string        `arg:"--gerrit-user,env:LINTERDIFF_GERRIT_USER"                 help:"Gerrit user posting with the HTTP password in --token-env"`
+	TokenEnv         string        `arg:"--token-env"                                              help:"environment variable holding the API token [default: GITHUB_TOKEN, GITLAB_TOKEN with --gitlab-mr, BITBUCKET_TOKEN with --bitbucket, GERRIT_HTTP_PASSWORD with --gerrit-change]"`
+	Fix              bool          `arg:"--fix"                                                    help:"apply the fixes golangci-lint suggests for issues lying entirely on changed lines"`
+	FixDryRun        bool          `arg:"--fix-dry-run"                                            help:"print the fixes --fix would apply as a patch instead of the issues"`
+	Stream           bool          `arg:"--stream,env:LINTERDIFF_STREAM"                           help:"with --out jsonl, print the issues of each linter, or module with --modules, as soon as it finishes instead of at the end of the run"`
+	LintConfig       string        `arg:"--lint-config,env:LINTERDIFF_LINT_CONFIG"                 help:"golangci-lint config file"`
+	BuildTags        []string      `arg:"--build-tags,env:LINTERDIFF_BUILD_TAGS"                   help:"build tags golangci-lint loads packages with, such as integration,e2e, so the changed files behind them are analyzed"`
+	Env              []string      `arg:"--env,separate"                                           help:"KEY=VALUE set for golangci-lint and the go command it runs, such as GOFLAGS=-mod=mod; repeat for several"`
+	IsolateEnv       bool          `arg:"--isolate-env,env:LINTERDIFF_ISOLATE_ENV"                 help:"run golangci-lint with only PATH, HOME and the temporary directory of the environment plus --env, so the GOFLAGS, GOPATH or GOCACHE of the caller do not apply"`
+	LintArgs         string        `arg:"--lint-args,env:LINTERDIFF_LINT_ARGS"                     help:"extra golangci-lint run flags, as one shell-quoted string"`
+	Verbose          bool          `arg:"-v,--verbose"                                             help:"log every command run and the time each step takes"`
+	Quiet            bool          `arg:"-q,--quiet"                                               help:"print only the issues, and errors"`
+	NoProgress       bool          `arg:"--no-progress,env:LINTERDIFF_NO_PROGRESS"                 help:"show no spinner on a terminal, nor a line of progress every 30s in logs"`
+	Timeout          time.Duration `arg:"--timeout,env:LINTERDIFF_TIMEOUT"                         help:"abort a run taking longer than this, such as 5m"`
+	Modules          bool          `arg:"--modules,env:LINTERDIFF_MODULES"                         help:"lint every Go module with changed files on its own, for repositories with several go.mod"`
+	FindRenames      int           `arg:"--find-renames,env:LINTERDIFF_FIND_RENAMES"               help:"similarity in percent at which git diff pairs a deleted and an added file as a rename"`
+	IgnoreWhitespace bool          `arg:"--ignore-whitespace,env:LINTERDIFF_IGNORE_WHITESPACE"     help:"leave out lines whose only change is whitespace or blank lines, such as gofmt churn"`
+	SkipMoved        bool          `arg:"--skip-moved,env:LINTERDIFF_SKIP_MOVED"                   help:"leave out blocks of lines moved verbatim from elsewhere in the diff, as git diff --color-moved finds them"`
+	VCS              string        `arg:"--vcs,env:LINTERDIFF_VCS"                                 help:"version control system reading the changes: git, hg or jj [default: git]"`
+	DiffEngine       string        `arg:"--diff-engine,env:LINTERDIFF_DIFF_ENGINE"                 help:"how git changes are read: exec runs git, native reads the repository in-process [default: exec]"`
+	FetchMissing     bool          `arg:"--fetch-missing,env:LINTERDIFF_FETCH_MISSING"             help:"fetch the history a shallow clone lacks for --base-ref, and diff with git when a partial clone lacks objects for --diff-engine native"`
+	Wait             time.Duration `arg:"--wait,env:LINTERDIFF_WAIT"                               help:"give up after waiting this long for other runs using the same report, outputs, cache or history [default: no limit]"`
+	NoWait           bool          `arg:"--no-wait,env:LINTERDIFF_NO_WAIT"                         help:"fail at once instead of waiting when another run uses the same report, outputs, cache or history"`
+	IncludeUntracked bool          `arg:"--include-untracked,env:LINTERDIFF_INCLUDE_UNTRACKED"     help:"also lint the Go files git neither tracks nor ignores, every line counting as changed"`
+	NoCache          bool          `arg:"--no-cache,env:LINTERDIFF_NO_CACHE"                       help:"lint every package again instead of reusing cached results"`
+	CacheDir         string        `arg:"--cache-dir,env:LINTERDIFF_CACHE_DIR"                     help:"directory of cached lint results [default: the user cache directory]"`
+	FailOn           []string      `arg:"--fail-on,env:LINTERDIFF_FAIL_ON"                         help:"count only the issues these rules pick against --max-issues: severity=<severity> for issues at least that severe, linter=<linter> for a linter's, such as severity=error,linter=gosec"`
+	MaxPerFile       int           `arg:"--max-per-file,env:LINTERDIFF_MAX_PER_FILE"               help:"print and comment at most this many issues of each file, ending the output with how many more there are; the summary and exit code still count them all"`
+	MaxTotal         int           `arg:"--max-total,env:LINTERDIFF_MAX_TOTAL"                     help:"print and comment at most this many issues in all, like --max-per-file"`
+	MaxIssues        int           `arg:"--max-issues,env:LINTERDIFF_MAX_ISSUES"                   help:"exit 1 only when more issues than this remain"`
+	SummaryJSON      string        `arg:"--summary-json,env:LINTERDIFF_SUMMARY_JSON"               help:"also write a JSON summary of the run to this file: issue counts, step durations and the exit decision"`
+	MetricsTextfile  string        `arg:"--metrics-textfile,env:LINTERDIFF_METRICS_TEXTFILE"       help:"also write Prometheus gauges of the run to this file, for the node_exporter textfile collector"`
+	Pushgateway      string        `arg:"--metrics-pushgateway,env:LINTERDIFF_METRICS_PUSHGATEWAY" help:"also push Prometheus gauges of the run to the Pushgateway at this URL"`
+	MetricsJob       string        `arg:"--metrics-job,env:LINTERDIFF_METRICS_JOB"                 help:"Pushgateway job the gauges are pushed under [default: linter]"`
+	History          string        `arg:"--history,env:LINTERDIFF_HISTORY"                         help:"also record the run in this SQLite database, for linter trends; needs the sqlite3 command"`
+
+	// ExtraLintArgs are the words after --, passed on to golangci-lint.
+	ExtraLintArgs []string `arg:"-"`
+	// lintArgs is the complete list of extra golangci-lint flags.
+	lintArgs []string
+	// lintEnv are the KEY=VALUE pairs of the config file and --env, set
+	// for golangci-lint in that order.
+	lintEnv []string
+	// vcs is the system selected with --vcs.
+	vcs diff.VCS
+	// tools are the linters other than golangci-lint of the config file.
+	tools []config.Tool
+	// profile is the coverage profile of the coverage subcommand.
+	profile coverage.Profile
+	// budgets map globs of paths to the most issues allowed under them,
+	// from the config file.
+	budgets map[string]int
+	// failOn are the parsed --fail-on rules.
+	failOn []filter.FailRule
+	// severities remap the severities of issues, from the config file.
+	severities []filter.SeverityRule
+	// excludes are the files and issues the golangci-lint config leaves
+	// out.
+	excludes *lint.Excludes
+	// stream prints the issues left of each linter with --stream.
+	stream func([]result.Issue) error
+
+	Run           *runCmd        `arg:"subcommand:run"        help:"lint the changes and print the issues on changed lines, the default"`
+	Report        *reportCmd     `arg:"subcommand:report"     help:"write the issues on changed lines to a report file"`
+	WriteBaseline *baselineCmd   `arg:"subcommand:baseline"   help:"write every current issue to a baseline file"`
+	Hooks         *hooksCmd      `arg:"subcommand:hooks"      help:"install or uninstall git pre-commit and pre-push hooks"`
+	VSCode        *vscodeCmd     `arg:"subcommand:vscode"     help:"set up a VS Code task listing the issues in the Problems panel"`
+	Cache         *cacheCmd      `arg:"subcommand:cache"      help:"manage the cache of lint results"`
+	Serve         *serveCmd      `arg:"subcommand:serve"      help:"keep running and publish issues to an editor"`
+	Compare       *compareCmd    `arg:"subcommand:compare"    help:"lint two refs and report the issues introduced between them"`
+	Coverage      *coverageCmd   `arg:"subcommand:coverage"   help:"lint the changes and also report the changed lines a coverage profile has as not run"`
+	Tui           *tuiCmd        `arg:"subcommand:tui"        help:"browse the issues on changed lines and triage them one by one"`
+	Suppress      *suppressCmd   `arg:"subcommand:suppress"   help:"add a nolint directive with a reason to the line of an issue on changed lines"`
+	Triage        *triageCmd     `arg:"subcommand:triage"     help:"write the issues on changed lines to a review file to decide on in bulk, or apply it"`
+	Trends        *trendsCmd     `arg:"subcommand:trends"     help:"show how the issues recorded in --history evolve"`
+	ShowVersion   *struct{}      `arg:"subcommand:version"    help:"print the version"`
+	Completion    *completionCmd `arg:"subcommand:completion" help:"print the shell completion script for bash, zsh, fish or powershell"`
 }
 
-func main() {
-	arg.MustParse(&args)
+// runCmd is the default subcommand; its flags are the global ones, which
+// report, compare, coverage, serve and tui share.
+type runCmd struct{}
 
-	pwd := args.Pwd
-	cmd := args.Cmd
-	jsonFile := args.JsonFile
-	inspectDes := args.InspectDes
+var args options
 
-	lint := NewGolangCILint().
-		SetPwd(pwd).
-		SetOutputJSON(jsonFile).
-		SetInspectDes(inspectDes)
-	_ = lint.Execute()
-	issues, err := lint.FindJSONIssues()
+// stats is the summary of the run written with --summary-json.
+var stats = summary.New()
+
+const (
+	exitOK     = 0
+	exitIssues = 1
+	exitError  = 2
+)
+
+// version is overridden at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+func (options) Version() string {
+	return "linter " + version
+}
+
+func (options) Description() string {
+	return "Runs golangci-lint and reports only the issues on changed lines.\n" +
+		"Without a subcommand, run is assumed. Arguments after -- are passed on\n" +
+		"to golangci-lint run."
+}
+
+func main() {
+	p, err := arg.NewParser(arg.Config{}, &args)
 	if err != nil {
-		log.Panicln(err)
+		slog.Error(err.Error())
+		os.Exit(exitError)
 	}
 
-	changes, err := findChanges(pwd, cmd)
-	if err != nil {
-		log.Panicln(err)
+	argv, extra := splitPassthrough(os.Args[1:])
+	args.ExtraLintArgs = extra
+
+	switch err := p.Parse(argv); {
+	case errors.Is(err, arg.ErrHelp):
+		p.WriteHelp(os.Stdout)
+		os.Exit(exitOK)
+	case errors.Is(err, arg.ErrVersion):
+		fmt.Println(args.Version())
+		os.Exit(exitOK)
+	case err == nil:
+		err = setupLogging(args.Verbose, args.Quiet)
+		if err == nil {
+			break
+		}
+		fallthrough
+	default:
+		p.WriteUsage(os.Stderr)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(exitError)
 	}
 
-	changesByFileName := getChangesByFileName(changes)
-	for _, issue := range issues.Issues {
-		if _, ok := changesByFileName[issue.FilePath()]; !ok {
-			continue
+	// Git hooks set GIT_DIR and the like relative to the directory they
+	// run in, while git runs in --pwd, submodules and worktrees.
+	if err := gitrepo.AbsEnv(); err != nil {
+		slog.Error(err.Error())
+		os.Exit(exitError)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, finishTrace := startTrace(ctx, p.SubcommandNames())
+	code, err := dispatch(ctx)
+	stop()
+	finishTrace(code, err)
+	if err != nil {
+		slog.Error(err.Error())
+	}
+	if saveErr := saveStats(code, err); saveErr != nil {
+		slog.Error(saveErr.Error())
+		code, err = exitError, errors.Join(err, saveErr)
+	}
+	if code != exitOK && args.NotifyWebhook != "" {
+		// ctx is done by now, while the commit and branch are still wanted.
+		if err := notifyFailure(context.Background(), err); err != nil {
+			slog.Warn("notifying of the failure: " + err.Error())
 		}
+	}
+	os.Exit(code)
+}
 
-		changes := changesByFileName[issue.FilePath()]
-		for _, change := range changes.Changes {
-			if change.Start <= issue.Pos.Line && issue.Pos.Line <= change.End {
-				printIssue(issue)
-			}
+// saveStats writes the summary of the run and its Prometheus gauges where
+// asked to, once dispatch returned code and err.
+func saveStats(code int, err error) error {
+	stats.SetExit(code, exitReason(code, err))
+	now := time.Now()
+	if args.SummaryJSON != "" {
+		if err := stats.Save(args.SummaryJSON); err != nil {
+			return fmt.Errorf("writing the summary: %w", err)
+		}
+	}
+	if args.MetricsTextfile != "" {
+		if err := metrics.WriteFile(args.MetricsTextfile, stats, now); err != nil {
+			return fmt.Errorf("writing the metrics: %w", err)
+		}
+	}
+	if args.Pushgateway != "" {
+		pusher := metrics.NewPusher(args.Pushgateway).SetJob(firstNonEmpty(args.MetricsJob, metrics.DefaultJob))
+		if err := pusher.Push(stats, now); err != nil {
+			return fmt.Errorf("pushing the metrics: %w", err)
 		}
 	}
+	return nil
+}
+
+// exitReason explains the exit code of dispatch for the run summary.
+func exitReason(code int, err error) string {
+	switch {
+	case err != nil:
+		return err.Error()
+	case code == exitIssues && len(args.failOn) > 0:
+		return fmt.Sprintf("%d issues reported, more than --max-issues %d of them picked by --fail-on", stats.Reported, args.MaxIssues)
+	case code == exitIssues:
+		return fmt.Sprintf("%d issues reported, more than --max-issues %d", stats.Reported, args.MaxIssues)
+	case len(args.failOn) > 0:
+		return fmt.Sprintf("%d issues reported, at most --max-issues %d of them picked by --fail-on", stats.Reported, args.MaxIssues)
+	default:
+		return fmt.Sprintf("%d issues reported, at most --max-issues %d", stats.Reported, args.MaxIssues)
+	}
 }
 
-type Changes struct {
-	Start, End int
+// dispatch runs the selected subcommand, or a lint pass, and returns the
+// exit code.
+func dispatch(ctx context.Context) (int, error) {
+	switch {
+	case args.ShowVersion != nil:
+		fmt.Println(args.Version())
+		return exitOK, nil
+	case args.Completion != nil:
+		if err := runCompletion(os.Stdout, args.Completion); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.WriteBaseline != nil:
+		if err := runBaseline(ctx, args.WriteBaseline); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Hooks != nil:
+		if err := runHooks(args.Hooks, firstNonEmpty(args.Pwd, ".")); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.VSCode != nil:
+		if err := runVSCode(args.VSCode, firstNonEmpty(args.Pwd, ".")); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Cache != nil:
+		if err := runCache(ctx, args.Cache); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Serve != nil:
+		if err := runServe(ctx, args.Serve); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Tui != nil:
+		if err := runTui(ctx, args.Tui); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Suppress != nil:
+		if err := runSuppress(ctx, args.Suppress); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Triage != nil:
+		if err := runTriage(ctx, args.Triage); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	case args.Trends != nil:
+		if err := runTrends(ctx, os.Stdout, args.Trends); err != nil {
+			return exitError, err
+		}
+		return exitOK, nil
+	}
+
+	ctx, stopProgress := startProgress(ctx)
+	defer stopProgress()
+
+	var (
+		found int
+		err   error
+	)
+	switch {
+	case args.Report != nil:
+		found, err = runReport(ctx, args.Report)
+	case args.Compare != nil:
+		found, err = runCompare(ctx, args.Compare)
+	case args.Coverage != nil:
+		found, err = runCoverage(ctx, args.Coverage)
+	default:
+		found, err = run(ctx)
+	}
+	if err != nil {
+		return exitError, err
+	}
+	if found > args.MaxIssues {
+		return exitIssues, nil
+	}
+	return exitOK, nil
 }
 
-type FileChange struct {
-	Changes []*Changes
-	Path    string
+// run executes one lint pass and returns how many issues were reported.
+func run(ctx context.Context) (int, error) {
+	if err := loadConfig(); err != nil {
+		return 0, err
+	}
+	release, err := lockShared(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	stdout, closePager := pagedStdout()
+	defer closePager()
+	printer, err := output.NewSinks(args.Out, stdout)
+	if err != nil {
+		return 0, err
+	}
+	var tmpl *template.Template
+	if args.TemplateFile != "" {
+		if tmpl, err = output.LoadTemplate(args.TemplateFile); err != nil {
+			return 0, err
+		}
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if args.Ratchet != "" {
+		grown, err := checkRatchet(ctx)
+		return grown, timedOut(err)
+	}
+	if args.Budgets {
+		over, err := checkBudgets(ctx)
+		return over, timedOut(err)
+	}
+
+	if args.LinkBase != "" {
+		link, err := issueLinks(ctx)
+		if err != nil {
+			return 0, err
+		}
+		printer.SetLink(link)
+	}
+
+	if args.Stream {
+		stream, err := printer.Stream()
+		if err != nil {
+			return 0, err
+		}
+		defer stream.Close()
+		args.stream = stream.Print
+	}
+
+	filtered, changes, err := check(ctx)
+	err = timedOut(err)
+	if err != nil {
+		return 0, err
+	}
+
+	if args.Fix || args.FixDryRun {
+		fixed, err := applyFixes(filtered, changes)
+		if err != nil {
+			return 0, err
+		}
+		if args.FixDryRun {
+			return failing(filtered), nil
+		}
+		filtered = fixed
+		stats.SetReported(filtered)
+	}
+
+	filtered = output.Sort(filtered, args.Sort)
+	// shown are the issues printed and commented, the counts and the exit
+	// code keep using all of them.
+	shown, hidden := filter.Limit(filtered, args.MaxPerFile, args.MaxTotal)
+	if hidden > 0 {
+		slog.Info("issues left out of the output", "count", hidden, "max-per-file", args.MaxPerFile, "max-total", args.MaxTotal)
+	}
+	printer.ConfigureTemplate(func(t *output.Template) {
+		t.SetTemplate(tmpl).SetChanges(changes).SetSummary(stats)
+	})
+	printer.ConfigureAnnotatedDiff(func(d *output.AnnotatedDiff) {
+		d.SetChanges(changes)
+	})
+	if args.Context > 0 {
+		changed := filter.NewIssueFilter(changes)
+		printer.ConfigureText(func(text *output.Text) {
+			text.SetSource(func(issue result.Issue) ([]snippet.Line, error) {
+				return snippet.Around(args.Pwd, issue, args.Context, func(line int) bool {
+					return changed.Changed(issue.FilePath(), line)
+				})
+			})
+		})
+	}
+
+	if args.GroupBy != "" {
+		// The footer goes after the last group rather than in each.
+		if err = printGroups(ctx, stdout, printer, shown); err == nil {
+			_, err = io.WriteString(stdout, output.Footer(hidden))
+		}
+	} else {
+		err = printer.SetHidden(hidden).Print(shown)
+	}
+	if err != nil {
+		return 0, err
+	}
+	closePager()
+
+	if args.StepSummary {
+		if err := output.AppendStepSummary(shown, hidden); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.History != "" {
+		if err := recordHistory(ctx, filtered); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.GitHubPR != "" {
+		if err := postReview(ctx, args.Pwd, shown); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.GitHubCheck != "" {
+		if err := publishCheck(ctx, args.Pwd, filtered, shown); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.GitHubStatus != "" || args.GitLabStatus != "" {
+		if err := setStatus(ctx, args.Pwd, filtered); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.GitLabMR != "" {
+		if err := postDiscussions(ctx, args.Pwd, shown); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.Bitbucket != "" {
+		if err := publishInsights(ctx, args.Pwd, filtered, shown); err != nil {
+			return 0, err
+		}
+	}
+
+	if args.GerritChange != "" {
+		if err := postRobotComments(ctx, args.Pwd, shown); err != nil {
+			return 0, err
+		}
+	}
+
+	return failing(filtered), nil
 }
 
-type GolangCILint struct {
-	binPath      string
-	pwdPath      string
-	outputFormat string
-	outputFile   string
-	checkingPath string
+// failing counts the issues that fail the run when more than --max-issues:
+// those --fail-on picks, or all of them.
+func failing(issues []result.Issue) int {
+	return len(filter.Failing(issues, args.failOn))
 }
 
-func NewGolangCILint() *GolangCILint {
-	return &GolangCILint{
-		binPath: "/home/shane/go/bin/golangci-lint",
-		pwdPath: ".",
+// pagedStdout returns where the output to stdout goes: through the pager
+// when the text output goes there and --pager allows, or else straight
+// out. closePager waits for the reader to quit the pager, so that what is
+// logged next does not land on the paged screen.
+func pagedStdout() (stdout io.Writer, closePager func()) {
+	command := pager.Command()
+	if args.Pager == "never" || command == nil || !args.printsText() {
+		return logutils.StdOut, func() {}
+	}
+	if args.Pager == "auto" && !output.IsTerminal(os.Stdout) {
+		return logutils.StdOut, func() {}
+	}
+	p := pager.New(command, os.Stdout)
+	return p, func() {
+		if err := p.Close(); err != nil {
+			slog.Warn("pager failed", "pager", strings.Join(command, " "), "error", err)
+		}
 	}
 }
 
-func (g *GolangCILint) SetBin(path string) *GolangCILint {
-	g.binPath = path
-	return g
+// printsText reports whether the text output goes to stdout.
+func (o *options) printsText() bool {
+	for _, spec := range o.Out {
+		if sink, err := output.ParseSink(spec); err == nil && sink.Format == "text" && sink.Path == "" {
+			return true
+		}
+	}
+	return false
 }
 
-func (g *GolangCILint) SetPwd(path string) *GolangCILint {
-	g.pwdPath = path
-	return g
+// loadConfig completes args from the config file and the defaults.
+func loadConfig() error {
+	cfg, err := config.LoadOrEmpty(args.Config, firstNonEmpty(args.Pwd, "."))
+	if err != nil {
+		return err
+	}
+	if err := args.applyConfig(cfg); err != nil {
+		return err
+	}
+	return output.SetColor(args.Color, os.Stdout)
 }
 
-func (g *GolangCILint) SetOutputJSON(filename string) *GolangCILint {
-	g.outputFormat = fmt.Sprintf("json:%s", filename)
-	g.outputFile = filename
-	return g
+// check lints the changes and returns the issues on changed lines that the
+// baseline does not already know about, together with the changes.
+func check(ctx context.Context) ([]result.Issue, []diff.FileChange, error) {
+	diffCtx, done := phase(ctx, "diff")
+	changes, err := findChanges(diffCtx, args.Pwd)
+	done()
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Debug("changes found", "files", len(changes))
+	stats.SetChanged(len(changes))
+	goChanges := filter.GoFiles(changes)
+	otherChanges := toolFiles(changes)
+	if len(args.MergeResults) > 0 {
+		// Merged reports may come from linters of any language.
+		otherChanges = filter.NonGoFiles(changes)
+	}
+	if len(goChanges) == 0 && len(otherChanges) == 0 {
+		slog.Info("no Go file changed, golangci-lint skipped", "files", len(changes))
+		return nil, nil, nil
+	}
+
+	checked := append(goChanges, otherChanges...)
+	keep, err := newKeeper(checked)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		found    int
+		filtered []result.Issue
+	)
+	// emit filters the issues of each linter as it finishes, streaming
+	// those left with --stream.
+	emit := func(issues []result.Issue) error {
+		found += len(issues)
+		filterCtx, done := phase(ctx, "filter")
+		kept, err := keep.filter(filterCtx, issues)
+		done()
+		if err != nil {
+			return err
+		}
+		filtered = append(filtered, kept...)
+		if args.stream != nil && len(kept) > 0 {
+			return args.stream(kept)
+		}
+		return nil
+	}
+
+	lintCtx, done := phase(ctx, "lint")
+	err = lintChanges(lintCtx, goChanges, emit)
+	for _, source := range []func() ([]result.Issue, error){
+		func() ([]result.Issue, error) { return toolIssues(lintCtx, changes) },
+		mergeResults,
+	} {
+		if err != nil {
+			break
+		}
+		var issues []result.Issue
+		if issues, err = source(); err == nil {
+			err = emit(issues)
+		}
+	}
+	done()
+	if err != nil {
+		return nil, nil, err
+	}
+	stats.SetFound(found)
+	stats.SetReported(filtered)
+	return filtered, checked, nil
 }
 
-func (g *GolangCILint) SetInspectDes(path string) *GolangCILint {
-	g.checkingPath = path
-	return g
+// keeper filters the issues found down to those reported, a batch at a
+// time, so the issues of each linter can be reported as it finishes.
+type keeper struct {
+	changed *filter.IssueFilter
+	known   *baseline.Matcher
+	dedup   *filter.Deduper
 }
 
-func (g *GolangCILint) Execute() error {
-	return exec.Command(
-		"sh", "-c",
-		fmt.Sprintf(
-			`cd %s; %s run --out-format %s %s`,
-			g.pwdPath, g.binPath, g.outputFormat, g.checkingPath,
-		),
-	).Run()
+func newKeeper(changes []diff.FileChange) (*keeper, error) {
+	k := &keeper{
+		changed: filter.NewIssueFilter(changes).
+			SetScope(args.Scope).
+			SetMargin(args.ContextLines).
+			SetDeletions(args.Deletions),
+	}
+	if !args.NoDedup {
+		k.dedup = filter.NewDeduper()
+	}
+	if args.Baseline != "" {
+		known, err := baseline.Load(args.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		k.known = known.Matcher()
+	}
+	return k, nil
 }
 
-func (g *GolangCILint) FindJSONIssues() (*printers.JSONResult, error) {
-	file, err := os.Open(g.outputFile)
+// filter keeps the issues on changed lines that the golangci-lint config,
+// the linter filters, the baseline and the suppression file let through,
+// once per problem several linters agree on, with their authors and owners
+// when asked for. Their severities are remapped first, for --severity-min
+// and every later use.
+func (k *keeper) filter(ctx context.Context, issues []result.Issue) ([]result.Issue, error) {
+	filtered := k.changed.Filter(issues)
+	filtered = args.excludes.Filter(filtered)
+	filtered = filter.Remap(filtered, args.severities)
+	filtered = filter.ByLinter(filtered, args.OnlyLinters, args.ExcludeLinters)
+	filtered = filter.BySeverity(filtered, args.SeverityMin)
+	if k.known != nil {
+		filtered = k.known.Filter(filtered)
+	}
+	filtered, err := suppressIssues(filtered)
 	if err != nil {
 		return nil, err
 	}
-
-	bytes, err := io.ReadAll(file)
+	if k.dedup != nil {
+		var dropped int
+		filtered, dropped = k.dedup.Filter(filtered)
+		if dropped > 0 {
+			slog.Info("duplicate issues merged", "count", dropped)
+		}
+	}
+	filtered, err = attribute(ctx, filtered)
 	if err != nil {
 		return nil, err
 	}
+	return filterOwners(ctx, filtered)
+}
 
-	var jsonResult printers.JSONResult
-	if err := json.Unmarshal(bytes, &jsonResult); err != nil {
+// formatIssues runs every --format-check formatter over files, given
+// relative to the repository root, reporting what it would rewrite as issues
+// with paths relative to --pwd like those of golangci-lint.
+func formatIssues(ctx context.Context, files []string) ([]result.Issue, error) {
+	if len(args.FormatCheck) == 0 {
+		return nil, nil
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
 		return nil, err
 	}
 
-	return &jsonResult, nil
-}
-
-func printIssue(issue result.Issue) {
-	p := printers.NewText(
-		true, true,
-		true, nil, logutils.StdOut,
-	)
-	if err := p.Print(context.Background(), []result.Issue{issue}); err != nil {
-		log.Fatal(err)
+	var issues []result.Issue
+	for _, tool := range args.FormatCheck {
+		bin, err := exec.LookPath(tool)
+		if err != nil {
+			return nil, fmt.Errorf("--format-check %s: %w", tool, err)
+		}
+		found, err := format.New(tool).
+			SetBin(bin).
+			SetContext(ctx).
+			SetPwd(root).
+			Run(files...)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
 	}
+	return relativeTo(pwd, root, issues)
 }
 
-func findChangesByHunkHeader(hunkHeader string) ([][]int, error) {
-	matches := regexp.
-		MustCompile(`[+](\d+),(\d+)`).
-		FindAllStringSubmatch(hunkHeader, -1)
+// vulnIssues runs govulncheck with --vuln on the packages of files, given
+// relative to the repository root, module by module, reporting the calls on
+// the way to vulnerable code with paths relative to --pwd.
+func vulnIssues(ctx context.Context, files []string) ([]result.Issue, error) {
+	if !args.Vuln {
+		return nil, nil
+	}
+	bin, err := exec.LookPath("govulncheck")
+	if err != nil {
+		return nil, fmt.Errorf("--vuln: %w", err)
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := lint.ChangedModules(root, files)
+	if err != nil {
+		return nil, err
+	}
 
-	ranges := make([][]int, 0, len(matches))
-	for _, match := range matches {
-		start, err := strconv.ParseInt(match[1], 10, 64)
+	var issues []result.Issue
+	for _, module := range modules {
+		packages, err := lint.ChangedPackages(ctx, module.Dir, root, module.Files)
 		if err != nil {
 			return nil, err
 		}
-
-		amount, err := strconv.ParseInt(match[2], 10, 64)
+		found, err := vuln.NewGovulncheck().
+			SetBin(bin).
+			SetContext(ctx).
+			SetDir(module.Dir).
+			SetPackages(packages...).
+			Run()
 		if err != nil {
 			return nil, err
 		}
-
-		ranges = append(ranges, []int{int(start), int(start + amount)})
+		found, err = relativeTo(pwd, module.Dir, found)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
 	}
+	return issues, nil
+}
 
-	return ranges, nil
+// lintChanges runs golangci-lint, unless --merge-results brings its issues,
+// and the formatters, govulncheck and the coverage profile asked for, on the
+// changed Go files, handing the issues of each to emit as it finishes.
+func lintChanges(ctx context.Context, changes []diff.FileChange, emit func([]result.Issue) error) error {
+	if len(changes) == 0 {
+		slog.Info("no Go file changed, golangci-lint skipped")
+		return nil
+	}
+	files := diff.Paths(changes)
+	switch {
+	case len(args.MergeResults) > 0:
+		// golangci-lint ran elsewhere; check merges its reports.
+	case args.Modules:
+		if err := lintModules(ctx, files, emit); err != nil {
+			return err
+		}
+	default:
+		rest, submodules, err := submoduleModules(ctx, files)
+		if err != nil {
+			return err
+		}
+		issues, err := lintPwd(ctx, rest)
+		if err != nil {
+			return err
+		}
+		if err := emit(issues); err != nil {
+			return err
+		}
+		if err := lintSubmodules(ctx, submodules, emit); err != nil {
+			return err
+		}
+	}
+	for _, source := range []func() ([]result.Issue, error){
+		func() ([]result.Issue, error) { return formatIssues(ctx, files) },
+		func() ([]result.Issue, error) { return vulnIssues(ctx, files) },
+		func() ([]result.Issue, error) { return coverageIssues(ctx, changes) },
+	} {
+		issues, err := source()
+		if err != nil {
+			return err
+		}
+		if err := emit(issues); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func listChangedFiles(pwd string, command string) ([]string, error) {
-	output, err := exec.Command(
-		"sh", "-c",
-		fmt.Sprintf(` cd %s; %s --no-commit-id --name-only `, pwd, command),
-	).Output()
+// mergeResults reads the issues of every --merge-results report, with paths
+// relative to --pwd like those of golangci-lint.
+func mergeResults() ([]result.Issue, error) {
+	if len(args.MergeResults) == 0 {
+		return nil, nil
+	}
+	var issues []result.Issue
+	for _, path := range args.MergeResults {
+		found, err := reports.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("report merged", "file", path, "issues", len(found))
+		issues = append(issues, found...)
+	}
+	pwd, err := filepath.Abs(args.Pwd)
 	if err != nil {
 		return nil, err
 	}
+	return relativeTo(pwd, pwd, issues)
+}
 
-	lines := strings.Split(string(output), "\n")
-	files := make([]string, 0, len(lines))
-	for _, line := range lines {
-		if strings.HasPrefix(line, "commit ") {
-			break
+// suppressIssues hides the issues listed in the suppression file, and
+// fails while any of its entries has expired.
+func suppressIssues(issues []result.Issue) ([]result.Issue, error) {
+	path := args.Suppressions
+	if path == "" {
+		found, err := config.FindFile(args.Pwd, suppress.FileName)
+		if err != nil {
+			return nil, err
 		}
-		files = append(files, line)
+		path = found
+	}
+	if path == "" {
+		return issues, nil
 	}
-	return files, nil
-}
 
-func findHunkHeadersOfFile(pwd string, cmd string, file string) ([]string, error) {
-	output, err := exec.Command(
-		"sh", "-c",
-		fmt.Sprintf(`cd %s; %s -- %s`, pwd, cmd, file),
-	).Output()
+	file, err := suppress.Load(path)
 	if err != nil {
 		return nil, err
 	}
+	kept, suppressed, expired := file.Apply(issues, time.Now())
+	if len(expired) > 0 {
+		names := make([]string, 0, len(expired))
+		for _, entry := range expired {
+			names = append(names, fmt.Sprintf("%s (expired %s)", entry, entry.Expires))
+		}
+		return nil, fmt.Errorf("%s: renew or remove the expired suppressions: %s", path, strings.Join(names, "; "))
+	}
+	if suppressed > 0 {
+		slog.Info("issues suppressed", "count", suppressed, "file", path)
+	}
+	return kept, nil
+}
+
+// applyFixes fixes the issues lying entirely on changed lines, or with
+// --fix-dry-run prints the patch doing so. It returns the issues left.
+func applyFixes(issues []result.Issue, changes []diff.FileChange) ([]result.Issue, error) {
+	covered := filter.NewIssueFilter(changes)
+	var (
+		candidates []result.Issue
+		fixable    []int
+	)
+	for i, issue := range issues {
+		if covered.Covers(issue) {
+			candidates = append(candidates, issue)
+			fixable = append(fixable, i)
+		}
+	}
 
-	hunkHeaders := regexp.
-		MustCompile(`(@@[ \-+\d,]+@@)`).
-		FindAllString(string(output), -1)
+	plan, err := fix.NewPlan(args.Pwd, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Skipped > 0 {
+		slog.Warn("overlapping fixes skipped, run again to apply them", "skipped", plan.Skipped)
+	}
+	if args.FixDryRun {
+		return issues, plan.Diff(os.Stdout)
+	}
+	if err := plan.Write(); err != nil {
+		return nil, err
+	}
 
-	return hunkHeaders, nil
+	fixed := make(map[int]bool)
+	for _, index := range plan.Fixed {
+		fixed[fixable[index]] = true
+	}
+	remaining := make([]result.Issue, 0, len(issues)-len(fixed))
+	for i, issue := range issues {
+		if !fixed[i] {
+			remaining = append(remaining, issue)
+		}
+	}
+	return remaining, nil
 }
 
-func findChanges(pwd, cmd string) ([]FileChange, error) {
-	files, err := listChangedFiles(pwd, cmd)
+// lintPwd lints --pwd like lintFiles, but takes the issues of packages
+// unchanged since an earlier run from the cache. Only when every package
+// of files is cached, or with --changed-packages, is linting skipped.
+func lintPwd(ctx context.Context, files []string) ([]result.Issue, error) {
+	if args.NoCache {
+		return lintFiles(ctx, files)
+	}
+	lookup, err := lookupCache(ctx, files)
+	if err != nil {
+		slog.Warn("lint cache unavailable", "error", err)
+		return lintFiles(ctx, files)
+	}
+	if len(lookup.missed) == 0 && lookup.hits > 0 {
+		slog.Info("lint results reused from the cache", "packages", lookup.hits)
+		return lookup.cached, nil
+	}
+
+	lintFilesOf := files
+	if args.ChangedPackages {
+		lintFilesOf = lookup.missedFiles
+	}
+	issues, err := lintFiles(ctx, lintFilesOf)
 	if err != nil {
 		return nil, err
 	}
+	if err := lookup.store(issues); err != nil {
+		slog.Warn("lint results not cached", "error", err)
+	}
+	if args.ChangedPackages {
+		issues = append(issues, lookup.cached...)
+	}
+	return issues, nil
+}
 
-	fileChanges := make([]FileChange, 0, len(files))
-	for _, file := range files {
-		hunkHeaders, err := findHunkHeadersOfFile(pwd, cmd, file)
+// lintFiles lints --pwd, only the packages of files with --changed-packages,
+// and only the go.work modules of files from a workspace outside of them.
+func lintFiles(ctx context.Context, files []string) ([]result.Issue, error) {
+	inspectDes := args.InspectDes
+	if args.ChangedPackages {
+		root, err := args.vcs.Root(ctx, args.Pwd)
 		if err != nil {
 			return nil, err
 		}
-
-		changes := make([]*Changes, 0)
-		for _, hunkHeader := range hunkHeaders {
-			changesPositions, err := findChangesByHunkHeader(hunkHeader)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, changesPosition := range changesPositions {
-				changes = append(changes, &Changes{
-					Start: changesPosition[0],
-					End:   changesPosition[1],
-				})
-			}
+		inspectDes, err = lint.ChangedPackages(ctx, args.Pwd, root, files)
+		if err != nil {
+			return nil, err
 		}
-
-		if len(changes) == 0 {
-			continue
+	} else if slices.Equal(inspectDes, []string{"./..."}) {
+		patterns, ok, err := workspacePackages(ctx, files)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			inspectDes = patterns
 		}
-
-		fileChanges = append(fileChanges, FileChange{
-			Path:    file,
-			Changes: changes,
-		})
 	}
-	return fileChanges, nil
+	return lintIssues(ctx, args.Pwd, args.JsonFile, inspectDes, false)
 }
 
-func getChangesByFileName(changes []FileChange) map[string]FileChange {
-	changesByFileName := make(map[string]FileChange)
-	for _, change := range changes {
-		changesByFileName[change.Path] = change
+// lintIssues runs golangci-lint in pwd on inspectDes, allowing other runs
+// at the same time when parallel is set. Nothing to inspect means no
+// issues.
+func lintIssues(ctx context.Context, pwd, jsonFile string, inspectDes []string, parallel bool) ([]result.Issue, error) {
+	if len(inspectDes) == 0 {
+		return nil, nil
+	}
+
+	runner := lint.NewGolangCILint()
+	if args.Runner == lint.RunnerDocker {
+		root, err := args.vcs.Root(ctx, pwd)
+		if err != nil {
+			return nil, err
+		}
+		runner.SetDocker(args.Image, root)
+	} else {
+		bin, err := golangciBin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		runner.SetBin(bin)
+	}
+
+	extraArgs := args.lintArgs
+	if parallel {
+		// golangci-lint otherwise gives up after waiting 5s for its lock.
+		extraArgs = append([]string{"--allow-parallel-runners"}, extraArgs...)
+	}
+
+	issues, err := runner.
+		SetContext(ctx).
+		SetPwd(pwd).
+		SetOutputJSON(jsonFile).
+		SetConfig(args.LintConfig).
+		SetExtraArgs(extraArgs...).
+		SetEnv(args.lintEnv...).
+		SetIsolatedEnv(args.IsolateEnv).
+		SetInspectDes(inspectDes...).
+		SetStderr(lintStderr()).
+		Run()
+	if err != nil {
+		return nil, err
+	}
+	if issues.Report != nil {
+		for _, warning := range issues.Report.Warnings {
+			slog.Warn("golangci-lint: "+warning.Text, "tag", warning.Tag)
+		}
+	}
+	return issues.Issues, nil
+}
+
+// golangciBin finds golangci-lint, or with --lint-version installs that
+// release into the cache directory unless the binary found is of it.
+func golangciBin(ctx context.Context) (string, error) {
+	bin, err := lint.FindBinary(args.Bin)
+	if args.LintVersion == "" {
+		return bin, err
+	}
+	if err == nil {
+		version, err := lint.BinaryVersion(ctx, bin)
+		if err == nil && version == strings.TrimPrefix(args.LintVersion, "v") {
+			return bin, nil
+		}
+		slog.Debug("golangci-lint is not of --lint-version", "bin", bin, "version", version, "err", err)
+	}
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return lint.NewInstaller(filepath.Join(dir, "tools", "golangci-lint")).Install(ctx, args.LintVersion)
+}
+
+// postReview comments on --github-pr for every issue. GitHub wants paths
+// from the repository root, while golangci-lint reports them from pwd.
+func postReview(ctx context.Context, pwd string, issues []result.Issue) error {
+	pr, err := github.ParsePullRequest(args.GitHubPR)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(args.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("--github-pr needs a token in $%s", args.TokenEnv)
+	}
+
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	issues, err = relativeTo(root, pwd, issues)
+	if err != nil {
+		return err
+	}
+
+	client := github.NewClient(token).
+		SetBaseURL(firstNonEmpty(os.Getenv("GITHUB_API_URL"), github.DefaultBaseURL))
+	created, updated, err := client.Review(pr, issues)
+	if err != nil {
+		return err
+	}
+	slog.Info("review comments posted", "pr", pr.String(), "created", created, "updated", updated)
+	return nil
+}
+
+// publishCheck reports the issues as a check run on the commit being
+// checked, annotating those shown, which fails when more than --max-issues
+// remain. In a pull_request
+// workflow that is the head of the pull request rather than $GITHUB_SHA, the
+// merge commit GitHub does not show checks for.
+func publishCheck(ctx context.Context, pwd string, issues, shown []result.Issue) error {
+	repo, err := github.ParseRepository(args.GitHubCheck)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(args.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("--github-check needs a token in $%s", args.TokenEnv)
+	}
+	commit, err := checkedCommit(ctx, pwd)
+	if err != nil {
+		return err
+	}
+
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	passed := failing(issues) <= args.MaxIssues
+	shown, err = relativeTo(root, pwd, shown)
+	if err != nil {
+		return err
+	}
+	summary, err := checkSummary(shown)
+	if err != nil {
+		return err
+	}
+
+	client := github.NewClient(token).
+		SetBaseURL(firstNonEmpty(os.Getenv("GITHUB_API_URL"), github.DefaultBaseURL))
+	id, err := client.StartCheck(repo, commit)
+	if err != nil {
+		return err
+	}
+	if err := client.CompleteCheck(repo, id, shown, len(issues), summary, passed); err != nil {
+		return err
+	}
+	slog.Info("check run published", "repo", repo.String(), "commit", commit, "annotations", len(shown))
+	return nil
+}
+
+// checkedCommit is the head of the pull request in $GITHUB_EVENT_PATH, or
+// else $GITHUB_SHA, or else HEAD.
+func checkedCommit(ctx context.Context, pwd string) (string, error) {
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		var event struct {
+			PullRequest struct {
+				Head struct {
+					SHA string `json:"sha"`
+				} `json:"head"`
+			} `json:"pull_request"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		if sha := event.PullRequest.Head.SHA; sha != "" {
+			return sha, nil
+		}
+	}
+	if sha := os.Getenv("GITHUB_SHA"); sha != "" {
+		return sha, nil
+	}
+	return diff.ResolveCommit(ctx, pwd, "HEAD")
+}
+
+// checkSummary is the Markdown report of issues shown on a check run.
+func checkSummary(issues []result.Issue) (string, error) {
+	var b strings.Builder
+	if err := output.NewMarkdown(&b).Print(issues); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// postDiscussions starts a discussion on --gitlab-mr for every issue, and
+// resolves those of earlier runs whose issue is gone.
+func postDiscussions(ctx context.Context, pwd string, issues []result.Issue) error {
+	mr, err := gitlab.ParseMergeRequest(args.GitLabMR)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(args.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("--gitlab-mr needs a token in $%s", args.TokenEnv)
+	}
+
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	issues, err = relativeTo(root, pwd, issues)
+	if err != nil {
+		return err
+	}
+
+	client := gitlab.NewClient(token).
+		SetBaseURL(firstNonEmpty(os.Getenv("CI_API_V4_URL"), gitlab.DefaultBaseURL))
+	res, err := client.Discuss(mr, issues)
+	if err != nil {
+		return err
+	}
+	slog.Info("merge request discussions posted", "mr", mr.String(),
+		"created", res.Created, "updated", res.Updated, "resolved", res.Resolved)
+	return nil
+}
+
+// publishInsights replaces the Code Insights report of --bitbucket on the
+// commit being checked, $BITBUCKET_COMMIT in Pipelines or else HEAD, with
+// the issues counted and those shown annotated.
+func publishInsights(ctx context.Context, pwd string, issues, shown []result.Issue) error {
+	repo, err := bitbucket.ParseRepository(args.Bitbucket)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(args.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("--bitbucket needs a token in $%s", args.TokenEnv)
+	}
+	commit := os.Getenv("BITBUCKET_COMMIT")
+	if commit == "" {
+		if commit, err = diff.ResolveCommit(ctx, pwd, "HEAD"); err != nil {
+			return err
+		}
+	}
+
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	passed := failing(issues) <= args.MaxIssues
+	shown, err = relativeTo(root, pwd, shown)
+	if err != nil {
+		return err
+	}
+
+	client := bitbucket.NewClient(token)
+	if args.BitbucketURL != "" {
+		client.SetServerURL(args.BitbucketURL)
+	}
+	annotated, err := client.Publish(repo, commit, shown, len(issues), passed)
+	if err != nil {
+		return err
+	}
+	slog.Info("code insights report published", "repo", repo.String(), "commit", commit, "annotations", annotated)
+	return nil
+}
+
+// postRobotComments reviews --gerrit-change with a robot comment per issue,
+// on the patch set being checked, $GERRIT_PATCHSET_REVISION when triggered
+// by Gerrit or else the current one.
+func postRobotComments(ctx context.Context, pwd string, issues []result.Issue) error {
+	password := os.Getenv(args.TokenEnv)
+	if password == "" {
+		return fmt.Errorf("--gerrit-change needs an HTTP password in $%s", args.TokenEnv)
+	}
+
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	issues, err = relativeTo(root, pwd, issues)
+	if err != nil {
+		return err
+	}
+
+	revision := firstNonEmpty(os.Getenv("GERRIT_PATCHSET_REVISION"), "current")
+	client := gerrit.NewClient(args.GerritURL, args.GerritUser, password)
+	if err := client.Review(args.GerritChange, revision, gerrit.NewReviewInput(issues)); err != nil {
+		return err
+	}
+	slog.Info("robot comments posted", "change", args.GerritChange, "revision", revision, "comments", len(issues))
+	return nil
+}
+
+// issueLinks returns the permalink of the line of an issue at the checked
+// commit under --link-base, or under the page of the origin remote for auto.
+func issueLinks(ctx context.Context) (func(result.Issue) string, error) {
+	base := args.LinkBase
+	if base == "auto" {
+		remote, err := diff.RemoteURL(ctx, args.Pwd, "origin")
+		if err != nil {
+			return nil, err
+		}
+		if base, err = permalink.FromRemote(remote); err != nil {
+			return nil, fmt.Errorf("--link-base auto: %w", err)
+		}
+	}
+	commit, err := checkedCommit(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	linker, err := permalink.New(base, commit)
+	if err != nil {
+		return nil, err
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	return func(issue result.Issue) string {
+		fromRoot, err := relativeTo(root, args.Pwd, []result.Issue{issue})
+		if err != nil || strings.HasPrefix(fromRoot[0].FilePath(), "../") {
+			// Files outside the repository have no page.
+			return ""
+		}
+		return linker.Link(fromRoot[0].FilePath(), issue.Line(), 0)
+	}, nil
+}
+
+// relativeTo rewrites the paths of issues, given relative to dir, to be
+// relative to base.
+func relativeTo(base, dir string, issues []result.Issue) ([]result.Issue, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	rewritten := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		path := issue.FilePath()
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(absDir, path)
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return nil, err
+		}
+		issue.Pos.Filename = filepath.ToSlash(rel)
+		rewritten = append(rewritten, issue)
+	}
+	return rewritten, nil
+}
+
+func findChanges(ctx context.Context, pwd string) ([]diff.FileChange, error) {
+	engine := args.DiffEngine
+	changes, err := diffChanges(ctx, pwd, engine)
+	if errors.Is(err, gitrepo.ErrSparseIndex) && engine == diff.EngineNative {
+		slog.Info("sparse index, diffing with git")
+		engine = diff.EngineExec
+		changes, err = diffChanges(ctx, pwd, engine)
+	}
+	if errors.Is(err, gitrepo.ErrMissingObject) && engine == diff.EngineNative {
+		if !args.FetchMissing {
+			return nil, fmt.Errorf("%w: a partial clone may lack it, pass --fetch-missing or --diff-engine exec", err)
+		}
+		slog.Warn("objects missing from the clone, diffing with git, which fetches them", "error", err)
+		engine = diff.EngineExec
+		changes, err = diffChanges(ctx, pwd, engine)
+	}
+	if (errors.Is(err, diff.ErrShallowHistory) || errors.Is(err, diff.ErrMissingRef)) && !args.FetchMissing {
+		return nil, fmt.Errorf("%w, or pass --fetch-missing", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Change paths are relative to the root of the repository, or to pwd
+	// for a diff outside of one.
+	root, err := args.vcs.Root(ctx, pwd)
+	if err != nil {
+		root = pwd
+	}
+	if args.vcs.Name == diff.Git.Name && !args.DiffStdin && args.DiffFile == "" && engine != diff.EngineNative {
+		if changes, err = findSubmoduleChanges(ctx, root, changes); err != nil {
+			return nil, err
+		}
+	}
+	if args.IncludeUntracked {
+		untracked, err := diff.Untracked(ctx, pwd)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, untracked...)
+	}
+	if args.SkipMoved {
+		changes = diff.SkipMoved(changes)
+	}
+	if !args.Deletions {
+		changes = diff.Added(changes)
+	}
+	changes = filter.IncludePaths(changes, args.IncludePaths)
+	changes = filter.ExcludePaths(changes, args.ExcludePaths)
+
+	if changes, err = skipExcluded(root, changes); err != nil {
+		return nil, err
+	}
+	warnMissing(root, changes)
+	if args.IncludeGenerated {
+		return changes, nil
+	}
+	return skipGenerated(root, changes)
+}
+
+// diffChanges reads the changes the options select, finding those of the
+// working tree with engine.
+func diffChanges(ctx context.Context, pwd, engine string) ([]diff.FileChange, error) {
+	usesBase := args.BaseRef != "" && !args.DiffStdin && args.DiffFile == "" && !args.Staged &&
+		(engine == diff.EngineNative || args.Range == "" && len(args.Commits) == 0)
+	if usesBase && args.FetchMissing && args.vcs.Name == diff.Git.Name {
+		if err := diff.FetchHistory(ctx, pwd, args.BaseRef); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case args.DiffStdin:
+		return diff.Parse(os.Stdin)
+	case args.DiffFile != "":
+		return parseDiffFile(args.DiffFile)
+	case engine == diff.EngineNative && args.Staged:
+		return diff.FindStagedNative(pwd)
+	case engine == diff.EngineNative:
+		return diff.FindNative(pwd, args.BaseRef)
+	case args.Staged:
+		return diff.FindStaged(ctx, pwd, args.diffArgs()...)
+	case args.Range != "":
+		commits, err := diff.CommitRange(ctx, pwd, args.Range)
+		if err != nil {
+			return nil, err
+		}
+		return diff.FindCommits(ctx, pwd, commits, args.diffArgs()...)
+	case len(args.Commits) > 0:
+		return diff.FindCommits(ctx, pwd, args.Commits, args.diffArgs()...)
+	case args.BaseRef != "":
+		cmd, err := args.vcs.SinceCommand(ctx, pwd, args.BaseRef)
+		if err != nil {
+			return nil, err
+		}
+		return diff.Find(ctx, pwd, strings.Join(append([]string{cmd}, args.diffArgs()...), " "))
+	default:
+		return diff.Find(ctx, pwd, args.Cmd)
+	}
+}
+
+// warnMissing warns about the changed files, with lines to lint, that are
+// not in the working tree, such as those outside the cone of a sparse
+// checkout: golangci-lint cannot lint them.
+func warnMissing(root string, changes []diff.FileChange) {
+	var missing []string
+	for _, change := range changes {
+		if len(change.Changes) == 0 {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(change.Path))); errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, change.Path)
+		}
+	}
+	if len(missing) > 0 {
+		slog.Warn("changed files missing from the working tree are not linted, check them out to lint them",
+			"count", len(missing), "files", missing)
+	}
+}
+
+// skipExcluded drops the changed files .gitignore ignores and those the
+// golangci-lint config skips, for the checks golangci-lint does not run.
+func skipExcluded(root string, changes []diff.FileChange) ([]diff.FileChange, error) {
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		files = append(files, change.Path)
+	}
+	ignore, err := filter.LoadGitignore(root, files)
+	if err != nil {
+		return nil, err
+	}
+	kept := filter.SkipPaths(changes, ignore.Ignored)
+	kept = filter.SkipPaths(kept, args.excludes.Skipped)
+	if len(kept) < len(changes) {
+		slog.Info("ignored files skipped", "count", len(changes)-len(kept))
+	}
+	return kept, nil
+}
+
+// skipGenerated drops the generated files of changes, read from root.
+func skipGenerated(root string, changes []diff.FileChange) ([]diff.FileChange, error) {
+	changes, skipped, err := filter.SkipGenerated(changes, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		slog.Info("generated files skipped", "count", len(skipped), "files", skipped)
+	}
+	return changes, nil
+}
+
+func parseDiffFile(path string) ([]diff.FileChange, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return diff.Parse(file)
+}
+
+// applyConfig fills every option left unset by flags and environment
+// variables from the config file, then from the built-in defaults.
+// The diff source is chosen as a whole: any of --staged, -c, --base-ref,
+// --range, --commits, --diff-file or --diff-stdin given on the command line
+// or in the environment hides both settings of the file.
+func (o *options) applyConfig(cfg *config.Config) error {
+	if o.diffSources() > 1 {
+		return errors.New("only one of -c, --base-ref, --staged, --range, --commits, --diff-file and --diff-stdin may be given")
+	}
+	if o.diffSources() == 0 {
+		if cfg.DiffCommand != "" && cfg.BaseRef != "" {
+			return errors.New("config sets both diff-command and base-ref")
+		}
+		o.Cmd = cfg.DiffCommand
+		o.BaseRef = cfg.BaseRef
+	}
+	vcs, err := diff.LookupVCS(firstNonEmpty(o.VCS, cfg.VCS, diff.Git.Name))
+	if err != nil {
+		return err
+	}
+	o.vcs, o.VCS = vcs, vcs.Name
+	if vcs.Name != diff.Git.Name && o.Staged {
+		return errors.New("--staged needs git, other systems have no staging area")
+	}
+	if vcs.Name != diff.Git.Name && (o.Range != "" || len(o.Commits) > 0) {
+		return errors.New("--range and --commits need git")
+	}
+	o.Commits = splitList(o.Commits)
+	if vcs.Name != diff.Git.Name && (o.Blame || len(o.Author) > 0) {
+		return errors.New("--blame and --author need git")
+	}
+	if vcs.Name != diff.Git.Name && o.FindRenames > 0 {
+		return errors.New("--find-renames needs git, other systems record renames themselves")
+	}
+	if o.FindRenames < 0 || o.FindRenames > 100 {
+		return fmt.Errorf("--find-renames %d is not a percentage", o.FindRenames)
+	}
+	if o.FindRenames > 0 && (o.Cmd != "" || o.DiffFile != "" || o.DiffStdin) {
+		return errors.New("--find-renames only applies to the git diff the linter runs, add it to your own diff instead")
+	}
+	if o.IgnoreWhitespace && vcs.Name != diff.Git.Name {
+		return errors.New("--ignore-whitespace needs git")
+	}
+	if o.IgnoreWhitespace && (o.Cmd != "" || o.DiffFile != "" || o.DiffStdin) {
+		return errors.New("--ignore-whitespace only applies to the git diff the linter runs, add -w to your own diff instead")
+	}
+	o.DiffEngine = firstNonEmpty(o.DiffEngine, cfg.DiffEngine, diff.EngineExec)
+	if !diff.ValidEngine(o.DiffEngine) {
+		return fmt.Errorf("--diff-engine %q is not one of %s", o.DiffEngine, strings.Join(diff.Engines, ", "))
+	}
+	if o.DiffEngine == diff.EngineNative {
+		switch {
+		case vcs.Name != diff.Git.Name:
+			return errors.New("--diff-engine native reads git repositories only")
+		case o.Cmd != "":
+			return errors.New("--diff-engine native reads the changes itself and runs no diff command")
+		case o.FindRenames > 0:
+			return errors.New("--diff-engine native only detects renames of unchanged files, --find-renames needs exec")
+		case o.Range != "" || len(o.Commits) > 0:
+			return errors.New("--range and --commits need --diff-engine exec")
+		case o.IgnoreWhitespace:
+			return errors.New("--ignore-whitespace needs --diff-engine exec")
+		}
+		o.vcs = diff.NativeGit
+	}
+	if o.IncludeUntracked {
+		switch {
+		case vcs.Name != diff.Git.Name:
+			return errors.New("--include-untracked needs git")
+		case o.Staged || o.Range != "" || len(o.Commits) > 0 || o.DiffFile != "" || o.DiffStdin:
+			return errors.New("--include-untracked only applies to the working tree, not to --staged, --range, --commits, --diff-file or --diff-stdin")
+		case o.DiffEngine == diff.EngineNative:
+			return errors.New("--include-untracked needs --diff-engine exec to tell ignored files apart")
+		}
+	}
+	if o.diffSources() == 0 {
+		o.Cmd = strings.Join(append([]string{vcs.DiffCommand}, o.diffArgs()...), " ")
+	}
+
+	o.Pwd = firstNonEmpty(o.Pwd, cfg.Pwd, ".")
+	o.JsonFile = firstNonEmpty(o.JsonFile, cfg.JSONFile, "/tmp/golang_ci_lint.json")
+	o.Bin = firstNonEmpty(o.Bin, cfg.Bin)
+	o.Runner = firstNonEmpty(o.Runner, cfg.Runner, lint.RunnerLocal)
+	if !lint.ValidRunner(o.Runner) {
+		return fmt.Errorf("--runner %q is not one of %s", o.Runner, strings.Join(lint.Runners, ", "))
+	}
+	o.LintVersion = firstNonEmpty(o.LintVersion, cfg.LintVersion)
+	if o.LintVersion != "" && !lint.ValidVersion(o.LintVersion) {
+		return fmt.Errorf("--lint-version %q is not a release such as v1.59.1", o.LintVersion)
+	}
+	image := lint.DefaultImage
+	if o.LintVersion != "" {
+		image = lint.Image(o.LintVersion)
+	}
+	o.Image = firstNonEmpty(o.Image, cfg.Image, image)
+	// Repeated --out flags add up, which go-arg cannot combine with an
+	// environment variable, so $LINTERDIFF_OUT is read here.
+	if len(o.Out) == 0 {
+		o.Out = splitList([]string{firstNonEmpty(os.Getenv("LINTERDIFF_OUT"), cfg.Output, "text")})
+	}
+	if o.outputs("template") != (o.TemplateFile != "") {
+		return errors.New("--out template and --template-file go together")
+	}
+	if o.Stream {
+		if o.Fix || o.FixDryRun {
+			return errors.New("--stream prints the issues before --fix or --fix-dry-run could fix them")
+		}
+		if !o.outputs(output.StreamFormat) {
+			return fmt.Errorf("--stream needs --out %s", output.StreamFormat)
+		}
+	}
+	// The flags of one forge combine, as they share the token.
+	forges := 0
+	for _, targets := range [][]string{
+		{o.GitHubPR, o.GitHubCheck, o.GitHubStatus},
+		{o.GitLabMR, o.GitLabStatus},
+		{o.Bitbucket},
+		{o.GerritChange},
+	} {
+		if firstNonEmpty(targets...) != "" {
+			forges++
+		}
+	}
+	if forges > 1 {
+		return errors.New("issues can be reported to only one of GitHub, GitLab, Bitbucket and Gerrit")
+	}
+	if o.GerritChange != "" && (o.GerritURL == "" || o.GerritUser == "") {
+		return errors.New("--gerrit-change needs --gerrit-url and --gerrit-user")
+	}
+	switch {
+	case o.GitLabMR != "" || o.GitLabStatus != "":
+		o.TokenEnv = firstNonEmpty(o.TokenEnv, "GITLAB_TOKEN")
+	case o.Bitbucket != "":
+		o.TokenEnv = firstNonEmpty(o.TokenEnv, "BITBUCKET_TOKEN")
+	case o.GerritChange != "":
+		o.TokenEnv = firstNonEmpty(o.TokenEnv, "GERRIT_HTTP_PASSWORD")
+	}
+	o.TokenEnv = firstNonEmpty(o.TokenEnv, "GITHUB_TOKEN")
+	o.RatchetBranch = firstNonEmpty(o.RatchetBranch, "main")
+	o.StatusContext = firstNonEmpty(o.StatusContext, "lint/changed-lines")
+	o.LintConfig = firstNonEmpty(o.LintConfig, cfg.LintConfig)
+	o.Suppressions = firstNonEmpty(o.Suppressions, cfg.Suppressions)
+	if err := o.loadExcludes(); err != nil {
+		return err
+	}
+
+	lintArgs, err := command.Split(o.LintArgs)
+	if err != nil {
+		return fmt.Errorf("--lint-args: %w", err)
+	}
+	o.lintArgs = append(lintArgs, o.ExtraLintArgs...)
+	if len(o.lintArgs) == 0 {
+		o.lintArgs = cfg.LintArgs
+	}
+	if len(o.BuildTags) == 0 {
+		o.BuildTags = cfg.BuildTags
+	}
+	o.BuildTags = splitList(o.BuildTags)
+	if len(o.BuildTags) > 0 {
+		for _, arg := range o.lintArgs {
+			if arg == "--build-tags" || strings.HasPrefix(arg, "--build-tags=") {
+				return errors.New("give build tags with either --build-tags or the golangci-lint flags, not both")
+			}
+		}
+		o.lintArgs = append(o.lintArgs[:len(o.lintArgs):len(o.lintArgs)], "--build-tags", strings.Join(o.BuildTags, ","))
+	}
+	o.lintEnv = nil
+	keys := make([]string, 0, len(cfg.Env))
+	for key := range cfg.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		o.lintEnv = append(o.lintEnv, key+"="+cfg.Env[key])
+	}
+	for _, pair := range o.Env {
+		if key, _, ok := strings.Cut(pair, "="); !ok || key == "" {
+			return fmt.Errorf("--env %q is not KEY=VALUE", pair)
+		}
+		o.lintEnv = append(o.lintEnv, pair)
+	}
+
+	if len(o.InspectDes) == 0 {
+		o.InspectDes = cfg.InspectPaths
+	}
+	if len(o.InspectDes) == 0 {
+		o.InspectDes = []string{"./..."}
+	}
+	if len(o.ExcludePaths) == 0 {
+		o.ExcludePaths = cfg.ExcludePaths
+	}
+	if len(o.IncludePaths) == 0 {
+		o.IncludePaths = cfg.IncludePaths
+	}
+	o.ExcludePaths = splitList(o.ExcludePaths)
+	o.IncludePaths = splitList(o.IncludePaths)
+	if len(o.ExcludeLinters) == 0 {
+		o.ExcludeLinters = cfg.ExcludeLinters
+	}
+	if len(o.OnlyLinters) == 0 {
+		o.OnlyLinters = cfg.OnlyLinters
+	}
+	o.ExcludeLinters = splitList(o.ExcludeLinters)
+	o.OnlyLinters = splitList(o.OnlyLinters)
+	if len(o.FailOn) == 0 {
+		o.FailOn = cfg.FailOn
+	}
+	o.failOn = nil
+	for _, spec := range splitList(o.FailOn) {
+		rule, err := filter.ParseFailRule(spec)
+		if err != nil {
+			return err
+		}
+		o.failOn = append(o.failOn, rule)
+	}
+	o.severities = nil
+	for _, severity := range cfg.Severities {
+		rule := filter.SeverityRule{Linter: severity.Linter, Rule: severity.Rule, Severity: severity.Severity}
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		o.severities = append(o.severities, rule)
+	}
+	if len(o.FormatCheck) == 0 {
+		o.FormatCheck = cfg.FormatCheck
+	}
+	if err := validateTools(cfg.Tools); err != nil {
+		return err
+	}
+	o.tools = cfg.Tools
+	for paths, max := range cfg.Budgets {
+		if max < 0 {
+			return fmt.Errorf("the budget of %s cannot be negative", paths)
+		}
+	}
+	o.budgets = cfg.Budgets
+	if o.Budgets && len(o.budgets) == 0 {
+		return errors.New("--budgets needs budgets in the config file")
+	}
+	if o.Budgets && o.Ratchet != "" {
+		return errors.New("--budgets and --ratchet each lint the whole repository, run them one at a time")
+	}
+	o.MergeResults = splitList(o.MergeResults)
+	o.FormatCheck = splitList(o.FormatCheck)
+	for _, tool := range o.FormatCheck {
+		if !format.ValidTool(tool) {
+			return fmt.Errorf("--format-check %q is not one of %s", tool, strings.Join(format.Tools, ", "))
+		}
+	}
+	o.Author = splitList(o.Author)
+	o.Owner = splitList(o.Owner)
+	if err := o.checkGroupBy(); err != nil {
+		return err
+	}
+	if o.Sort != "" && !output.ValidSort(o.Sort) {
+		return fmt.Errorf("--sort %q is not one of %s", o.Sort, strings.Join(output.SortKeys, ", "))
+	}
+	o.SeverityMin = firstNonEmpty(o.SeverityMin, cfg.SeverityMin)
+	if o.SeverityMin != "" && !filter.ValidSeverity(o.SeverityMin) {
+		return fmt.Errorf("--severity-min %q is not one of %s", o.SeverityMin, strings.Join(filter.Severities, ", "))
+	}
+	o.Scope = firstNonEmpty(o.Scope, cfg.Scope, filter.ScopeLine)
+	if !filter.ValidScope(o.Scope) {
+		return fmt.Errorf("--scope %q is not one of %s", o.Scope, strings.Join(filter.Scopes, ", "))
+	}
+	if o.Context < 0 {
+		return errors.New("--context cannot be negative")
+	}
+	if o.ContextLines < 0 {
+		return errors.New("--context-lines cannot be negative")
+	}
+	if o.MaxPerFile < 0 || o.MaxTotal < 0 {
+		return errors.New("--max-per-file and --max-total cannot be negative")
+	}
+	if o.Wait < 0 {
+		return errors.New("--wait cannot be negative")
+	}
+	if o.Wait > 0 && o.NoWait {
+		return errors.New("--wait and --no-wait cannot be combined")
+	}
+	o.Color = firstNonEmpty(o.Color, "auto")
+	o.Pager = firstNonEmpty(o.Pager, "auto")
+	if !pager.ValidMode(o.Pager) {
+		return fmt.Errorf("--pager %q is not one of %s", o.Pager, strings.Join(pager.Modes, ", "))
+	}
+	o.NotifyFormat = firstNonEmpty(o.NotifyFormat, notify.FormatSlack)
+	if !notify.ValidFormat(o.NotifyFormat) {
+		return fmt.Errorf("--notify-format %q is not one of %s", o.NotifyFormat, strings.Join(notify.Formats, ", "))
+	}
+	return nil
+}
+
+// loadExcludes reads what the golangci-lint config excludes, from
+// --lint-config or the config golangci-lint finds from pwd.
+func (o *options) loadExcludes() error {
+	o.excludes = nil
+	path := o.LintConfig
+	for _, name := range golangciConfigNames {
+		if path != "" {
+			break
+		}
+		found, err := config.FindFile(firstNonEmpty(o.Pwd, "."), name)
+		if err != nil {
+			return err
+		}
+		path = found
+	}
+	if path == "" {
+		return nil
+	}
+	excludes, err := lint.LoadExcludes(path)
+	if err != nil {
+		return err
+	}
+	o.excludes = excludes
+	return nil
+}
+
+// diffArgs are the extra git diff options for --find-renames and
+// --ignore-whitespace.
+func (o *options) diffArgs() []string {
+	var args []string
+	if o.FindRenames > 0 {
+		args = append(args, diff.FindRenames(o.FindRenames))
+	}
+	if o.IgnoreWhitespace {
+		args = append(args, diff.IgnoreWhitespace...)
+	}
+	return args
+}
+
+// outputs tells whether one of --out is in format.
+func (o *options) outputs(format string) bool {
+	for _, spec := range o.Out {
+		if sink, err := output.ParseSink(spec); err == nil && sink.Format == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *options) diffSources() int {
+	count := 0
+	for _, set := range []bool{o.Cmd != "", o.BaseRef != "", o.Staged, o.Range != "", len(o.Commits) > 0, o.DiffFile != "", o.DiffStdin} {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+// withTimeout bounds ctx by --timeout when one is set.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if args.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, args.Timeout)
+}
+
+// timedOut names the --timeout in err when the run was cut short by it.
+func timedOut(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", args.Timeout, err)
+	}
+	return err
+}
+
+// lintStderr is where golangci-lint diagnostics go; --quiet drops them.
+func lintStderr() io.Writer {
+	if args.Quiet {
+		return nil
+	}
+	return stderr
+}
+
+// splitPassthrough separates the words after the first -- from the
+// linter's own arguments.
+func splitPassthrough(argv []string) ([]string, []string) {
+	for i, arg := range argv {
+		if arg == "--" {
+			return argv[:i], argv[i+1:]
+		}
+	}
+	return argv, nil
+}
+
+// splitList also splits the values of a list option at commas, so that
+// "--exclude-linters lll,godot" and "--exclude-linters lll godot" agree.
+func splitList(values []string) []string {
+	var split []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				split = append(split, item)
+			}
+		}
+	}
+	return split
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
 	}
-	return changesByFileName
+	return ""
 }
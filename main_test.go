@@ -0,0 +1,1534 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/alexflint/go-arg"
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/config"
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/pager"
+	"linter/pkg/progress"
+	"linter/pkg/summary"
+	"linter/pkg/suppress"
+)
+
+func parseOptions(t *testing.T, argv ...string) options {
+	t.Helper()
+	var o options
+	p, err := arg.NewParser(arg.Config{}, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(argv); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestOptionPrecedence(t *testing.T) {
+	cfg := &config.Config{
+		DiffCommand:  "git diff from-file",
+		Output:       "sarif",
+		JSONFile:     "file.json",
+		InspectPaths: []string{"./file/..."},
+	}
+
+	t.Setenv("LINTERDIFF_CMD", "git diff from-env")
+	t.Setenv("LINTERDIFF_OUT", "github-actions")
+
+	o := parseOptions(t, "--out", "text")
+	if err := o.applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag beats env", o.Out, []string{"text"}},
+		{"env beats file", o.Cmd, "git diff from-env"},
+		{"file beats default", o.JsonFile, "file.json"},
+		{"file beats default for lists", o.InspectDes, []string{"./file/..."}},
+		{"default when unset", o.Pwd, "."},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestOptionDefaults(t *testing.T) {
+	o := parseOptions(t)
+	if err := o.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Cmd != "git diff" || !reflect.DeepEqual(o.Out, []string{"text"}) || !reflect.DeepEqual(o.InspectDes, []string{"./..."}) {
+		t.Errorf("defaults = %+v", o)
+	}
+}
+
+func TestTokenEnvDefault(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want string
+	}{
+		{nil, "GITHUB_TOKEN"},
+		{[]string{"--github-pr", "o/r#1"}, "GITHUB_TOKEN"},
+		{[]string{"--github-pr", "o/r#1", "--github-check", "o/r"}, "GITHUB_TOKEN"},
+		{[]string{"--gitlab-mr", "g/p!1"}, "GITLAB_TOKEN"},
+		{[]string{"--gitlab-mr", "g/p!1", "--token-env", "MR_TOKEN"}, "MR_TOKEN"},
+		{[]string{"--gitlab-status", "g/p"}, "GITLAB_TOKEN"},
+		{[]string{"--github-status", "o/r"}, "GITHUB_TOKEN"},
+		{[]string{"--bitbucket", "ws/repo"}, "BITBUCKET_TOKEN"},
+		{[]string{"--gerrit-change", "42", "--gerrit-url", "https://review.example.com", "--gerrit-user", "bot"}, "GERRIT_HTTP_PASSWORD"},
+	}
+	for _, tt := range tests {
+		o := parseOptions(t, tt.argv...)
+		if err := o.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		if o.TokenEnv != tt.want {
+			t.Errorf("%v: --token-env = %q, want %q", tt.argv, o.TokenEnv, tt.want)
+		}
+	}
+
+	o := parseOptions(t, "--github-pr", "o/r#1", "--bitbucket", "ws/repo")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--github-pr with --bitbucket expected an error")
+	}
+	o = parseOptions(t, "--github-check", "o/r", "--gitlab-mr", "g/p!1")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--github-check with --gitlab-mr expected an error")
+	}
+	o = parseOptions(t, "--github-status", "o/r", "--gitlab-status", "g/p")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--github-status with --gitlab-status expected an error")
+	}
+	o = parseOptions(t, "--gerrit-change", "42")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--gerrit-change without --gerrit-url expected an error")
+	}
+}
+
+func TestDiffSourcePrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		argv        []string
+		cfg         config.Config
+		wantCmd     string
+		wantBaseRef string
+		wantErr     bool
+	}{
+		{
+			name:        "file base-ref when nothing explicit",
+			cfg:         config.Config{BaseRef: "origin/main"},
+			wantBaseRef: "origin/main",
+		},
+		{
+			name:    "explicit -c hides file base-ref",
+			argv:    []string{"-c", "git show HEAD"},
+			cfg:     config.Config{BaseRef: "origin/main"},
+			wantCmd: "git show HEAD",
+		},
+		{
+			name:        "explicit --base-ref hides file diff-command",
+			argv:        []string{"--base-ref", "origin/dev"},
+			cfg:         config.Config{DiffCommand: "git diff HEAD~1"},
+			wantBaseRef: "origin/dev",
+		},
+		{
+			name:    "explicit -c and --base-ref conflict",
+			argv:    []string{"-c", "git diff", "--base-ref", "origin/main"},
+			wantErr: true,
+		},
+		{
+			name: "staged hides file diff source",
+			argv: []string{"--staged"},
+			cfg:  config.Config{BaseRef: "origin/main"},
+		},
+		{
+			name:    "staged with -c conflicts",
+			argv:    []string{"--staged", "-c", "git show HEAD"},
+			wantErr: true,
+		},
+		{
+			name:    "staged with --base-ref conflicts",
+			argv:    []string{"--staged", "--base-ref", "origin/main"},
+			wantErr: true,
+		},
+		{
+			name: "diff file hides file diff source",
+			argv: []string{"--diff-file", "change.patch"},
+			cfg:  config.Config{DiffCommand: "git diff HEAD~1"},
+		},
+		{
+			name:    "diff file with --staged conflicts",
+			argv:    []string{"--diff-file", "change.patch", "--staged"},
+			wantErr: true,
+		},
+		{
+			name:    "rename threshold joins the default diff",
+			argv:    []string{"--find-renames", "70"},
+			wantCmd: "git diff --find-renames=70%",
+		},
+		{
+			name:        "rename threshold with base ref",
+			argv:        []string{"--find-renames", "70", "--base-ref", "origin/main"},
+			wantBaseRef: "origin/main",
+		},
+		{
+			name:    "rename threshold with -c conflicts",
+			argv:    []string{"--find-renames", "70", "-c", "git diff"},
+			wantErr: true,
+		},
+		{
+			name:    "rename threshold with file diff-command conflicts",
+			argv:    []string{"--find-renames", "70"},
+			cfg:     config.Config{DiffCommand: "git diff HEAD~1"},
+			wantErr: true,
+		},
+		{
+			name:    "ignoring whitespace joins the default diff",
+			argv:    []string{"--ignore-whitespace", "--find-renames", "70"},
+			wantCmd: "git diff --find-renames=70% --ignore-all-space --ignore-blank-lines",
+		},
+		{
+			name:    "ignoring whitespace with -c conflicts",
+			argv:    []string{"--ignore-whitespace", "-c", "git diff"},
+			wantErr: true,
+		},
+		{
+			name:    "ignoring whitespace needs git",
+			argv:    []string{"--ignore-whitespace", "--vcs", "jj"},
+			wantErr: true,
+		},
+		{
+			name:    "ignoring whitespace with the native engine",
+			argv:    []string{"--ignore-whitespace", "--diff-engine", "native"},
+			wantErr: true,
+		},
+		{
+			name:    "rename threshold above 100",
+			argv:    []string{"--find-renames", "101"},
+			wantErr: true,
+		},
+		{
+			name:    "hg diffs the working copy",
+			argv:    []string{"--vcs", "hg"},
+			wantCmd: "hg diff --git",
+		},
+		{
+			name:    "vcs from the file",
+			cfg:     config.Config{VCS: "jj"},
+			wantCmd: "jj diff --git",
+		},
+		{
+			name:    "unknown vcs",
+			argv:    []string{"--vcs", "svn"},
+			wantErr: true,
+		},
+		{
+			name:    "staged needs git",
+			argv:    []string{"--vcs", "jj", "--staged"},
+			wantErr: true,
+		},
+		{
+			name:    "find-renames needs git",
+			argv:    []string{"--vcs", "hg", "--find-renames", "50"},
+			wantErr: true,
+		},
+		{
+			name: "range hides file diff source",
+			argv: []string{"--range", "main..feature"},
+			cfg:  config.Config{BaseRef: "origin/main"},
+		},
+		{
+			name: "commits with rename threshold",
+			argv: []string{"--commits", "abc123,def456", "--find-renames", "70"},
+		},
+		{
+			name:    "range with --commits conflicts",
+			argv:    []string{"--range", "main..feature", "--commits", "abc123"},
+			wantErr: true,
+		},
+		{
+			name:    "commits need git",
+			argv:    []string{"--vcs", "hg", "--commits", "abc123"},
+			wantErr: true,
+		},
+		{
+			name:    "range with the native engine",
+			argv:    []string{"--range", "main..feature", "--diff-engine", "native"},
+			wantErr: true,
+		},
+		{
+			name:    "file with both conflicts",
+			cfg:     config.Config{DiffCommand: "git diff", BaseRef: "origin/main"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOptions(t, tt.argv...)
+			err := o.applyConfig(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if o.Cmd != tt.wantCmd || o.BaseRef != tt.wantBaseRef {
+				t.Errorf("Cmd = %q, BaseRef = %q, want %q, %q", o.Cmd, o.BaseRef, tt.wantCmd, tt.wantBaseRef)
+			}
+		})
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	root := t.TempDir()
+	pwd := filepath.Join(root, "svc")
+	issues := []result.Issue{
+		{Pos: token.Position{Filename: "pkg/a.go"}},
+		{Pos: token.Position{Filename: filepath.Join(root, "b.go")}},
+	}
+
+	got, err := relativeTo(root, pwd, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].FilePath() != "svc/pkg/a.go" || got[1].FilePath() != "b.go" {
+		t.Errorf("paths = %q, %q", got[0].FilePath(), got[1].FilePath())
+	}
+	if issues[0].FilePath() != "pkg/a.go" {
+		t.Error("relativeTo modified its input")
+	}
+}
+
+func TestCheckedCommit(t *testing.T) {
+	dir := t.TempDir()
+	pullRequest := filepath.Join(dir, "pull_request.json")
+	if err := os.WriteFile(pullRequest, []byte(`{"pull_request":{"head":{"sha":"head123"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	push := filepath.Join(dir, "push.json")
+	if err := os.WriteFile(push, []byte(`{"ref":"refs/heads/main"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		eventPath string
+		sha       string
+		want      string
+	}{
+		{name: "pull request head", eventPath: pullRequest, sha: "merge123", want: "head123"},
+		{name: "push", eventPath: push, sha: "push123", want: "push123"},
+		{name: "no event", sha: "sha123", want: "sha123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_EVENT_PATH", tt.eventPath)
+			t.Setenv("GITHUB_SHA", tt.sha)
+			got, err := checkedCommit(context.Background(), ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("checkedCommit = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintArgs(t *testing.T) {
+	cfg := &config.Config{LintArgs: []string{"--timeout", "1m"}}
+
+	tests := []struct {
+		name  string
+		argv  []string
+		extra []string
+		want  []string
+	}{
+		{name: "config when unset", want: []string{"--timeout", "1m"}},
+		{
+			name: "flag hides config",
+			argv: []string{`--lint-args=--build-tags "a b"`},
+			want: []string{"--build-tags", "a b"},
+		},
+		{
+			name:  "words after -- follow the flag",
+			argv:  []string{"--lint-args=--fast"},
+			extra: []string{"-E", "gosec"},
+			want:  []string{"--fast", "-E", "gosec"},
+		},
+		{
+			name: "build tags follow",
+			argv: []string{"--build-tags", "integration,e2e"},
+			want: []string{"--timeout", "1m", "--build-tags", "integration,e2e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOptions(t, tt.argv...)
+			o.ExtraLintArgs = tt.extra
+			if err := o.applyConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(o.lintArgs, tt.want) {
+				t.Errorf("lintArgs = %q, want %q", o.lintArgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintEnvOptions(t *testing.T) {
+	cfg := &config.Config{
+		BuildTags: []string{"e2e"},
+		Env:       map[string]string{"GOFLAGS": "-mod=mod", "GOCACHE": "/cache"},
+	}
+	o := parseOptions(t, "--env", "GOFLAGS=-mod=vendor", "--env", "GOPATH=")
+	if err := o.applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GOCACHE=/cache", "GOFLAGS=-mod=mod", "GOFLAGS=-mod=vendor", "GOPATH="}; !reflect.DeepEqual(o.lintEnv, want) {
+		t.Errorf("lintEnv = %q, want %q", o.lintEnv, want)
+	}
+	if want := []string{"--build-tags", "e2e"}; !reflect.DeepEqual(o.lintArgs, want) {
+		t.Errorf("lintArgs = %q, want %q", o.lintArgs, want)
+	}
+
+	for _, argv := range [][]string{
+		{"--env", "GOFLAGS"},
+		{"--env", "=x"},
+		{"--build-tags", "a", "--lint-args=--build-tags=b"},
+	} {
+		o := parseOptions(t, argv...)
+		if err := o.applyConfig(&config.Config{}); err == nil {
+			t.Errorf("%q expected an error", argv)
+		}
+	}
+}
+
+func TestLinterFilterOptions(t *testing.T) {
+	cfg := &config.Config{ExcludeLinters: []string{"lll"}, SeverityMin: "warning"}
+
+	o := parseOptions(t, "--exclude-linters", "godot,lll", "misspell", "--only-linters", "govet, errcheck")
+	if err := o.applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"godot", "lll", "misspell"}; !reflect.DeepEqual(o.ExcludeLinters, want) {
+		t.Errorf("ExcludeLinters = %q, want %q", o.ExcludeLinters, want)
+	}
+	if want := []string{"govet", "errcheck"}; !reflect.DeepEqual(o.OnlyLinters, want) {
+		t.Errorf("OnlyLinters = %q, want %q", o.OnlyLinters, want)
+	}
+	if o.SeverityMin != "warning" {
+		t.Errorf("SeverityMin = %q, want the config value", o.SeverityMin)
+	}
+
+	o = parseOptions(t, "--severity-min", "fatal")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("unknown --severity-min expected an error")
+	}
+}
+
+func TestScopeOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		cfg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "line"},
+		{name: "config", cfg: "hunk", want: "hunk"},
+		{name: "flag over config", argv: []string{"--scope", "package"}, cfg: "file", want: "package"},
+		{name: "unknown", argv: []string{"--scope", "module"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOptions(t, tt.argv...)
+			err := o.applyConfig(&config.Config{Scope: tt.cfg})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if o.Scope != tt.want {
+				t.Errorf("Scope = %q, want %q", o.Scope, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutOption(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+		env  string
+		cfg  string
+		want []string
+	}{
+		{name: "repeated flags", argv: []string{"--out", "text", "--out", "sarif:report.sarif", "--out", "json:issues.json"}, env: "junit", want: []string{"text", "sarif:report.sarif", "json:issues.json"}},
+		{name: "env list", env: "text, sarif:report.sarif", cfg: "junit", want: []string{"text", "sarif:report.sarif"}},
+		{name: "config", cfg: "markdown", want: []string{"markdown"}},
+		{name: "default", want: []string{"text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LINTERDIFF_OUT", tt.env)
+			o := parseOptions(t, tt.argv...)
+			if err := o.applyConfig(&config.Config{Output: tt.cfg}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(o.Out, tt.want) {
+				t.Errorf("--out = %q, want %q", o.Out, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextOption(t *testing.T) {
+	o := parseOptions(t, "--context", "-1")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("negative --context expected an error")
+	}
+	o = parseOptions(t, "--context-lines", "-1")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("negative --context-lines expected an error")
+	}
+}
+
+func TestOutputLimitOptions(t *testing.T) {
+	for _, flag := range []string{"--max-per-file", "--max-total"} {
+		o := parseOptions(t, flag, "-1")
+		if err := o.applyConfig(&config.Config{}); err == nil {
+			t.Errorf("negative %s expected an error", flag)
+		}
+		o = parseOptions(t, flag, "5")
+		if err := o.applyConfig(&config.Config{}); err != nil {
+			t.Errorf("%s 5: %v", flag, err)
+		}
+	}
+}
+
+func TestColorOption(t *testing.T) {
+	o := parseOptions(t)
+	if err := o.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if o.Color != "auto" {
+		t.Errorf("--color defaults to %q, want auto", o.Color)
+	}
+
+	t.Setenv("LINTERDIFF_COLOR", "never")
+	if o := parseOptions(t); o.Color != "never" {
+		t.Errorf("LINTERDIFF_COLOR gave --color %q", o.Color)
+	}
+}
+
+func TestRunnerOption(t *testing.T) {
+	o := parseOptions(t)
+	if err := o.applyConfig(&config.Config{Image: "golangci/golangci-lint:v1.59"}); err != nil {
+		t.Fatal(err)
+	}
+	if o.Runner != "local" || o.Image != "golangci/golangci-lint:v1.59" {
+		t.Errorf("--runner %q --image %q, want local and the image of the config", o.Runner, o.Image)
+	}
+	o = parseOptions(t, "--lint-version", "v1.59.1")
+	if err := o.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if o.Image != "golangci/golangci-lint:v1.59.1" {
+		t.Errorf("--image %q, want the image of --lint-version", o.Image)
+	}
+	o = parseOptions(t, "--lint-version", "latest")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--lint-version latest expected an error")
+	}
+	o = parseOptions(t, "--runner", "podman")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--runner podman expected an error")
+	}
+}
+
+func TestDiffEngineOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		cfg     config.Config
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "exec"},
+		{name: "from the config", cfg: config.Config{DiffEngine: "native"}, want: "native"},
+		{name: "with a base ref", argv: []string{"--diff-engine", "native", "--base-ref", "main"}, want: "native"},
+		{name: "unknown", argv: []string{"--diff-engine", "libgit2"}, wantErr: true},
+		{name: "other system", argv: []string{"--diff-engine", "native", "--vcs", "hg"}, wantErr: true},
+		{name: "diff command", argv: []string{"--diff-engine", "native", "-c", "git diff HEAD"}, wantErr: true},
+		{name: "config diff command", argv: []string{"--diff-engine", "native"}, cfg: config.Config{DiffCommand: "git diff HEAD"}, wantErr: true},
+		{name: "rename detection", argv: []string{"--diff-engine", "native", "--find-renames", "50"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOptions(t, tt.argv...)
+			err := o.applyConfig(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConfig error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && o.DiffEngine != tt.want {
+				t.Errorf("--diff-engine %q, want %q", o.DiffEngine, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeUntrackedOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		wantErr bool
+	}{
+		{name: "working tree", argv: []string{"--include-untracked"}},
+		{name: "base ref", argv: []string{"--include-untracked", "--base-ref", "main"}},
+		{name: "staged", argv: []string{"--include-untracked", "--staged"}, wantErr: true},
+		{name: "diff file", argv: []string{"--include-untracked", "--diff-file", "change.patch"}, wantErr: true},
+		{name: "other system", argv: []string{"--include-untracked", "--vcs", "jj"}, wantErr: true},
+		{name: "native engine", argv: []string{"--include-untracked", "--diff-engine", "native"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOptions(t, tt.argv...)
+			if err := o.applyConfig(&config.Config{}); (err != nil) != tt.wantErr {
+				t.Errorf("applyConfig error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSkipMovedOption(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	patch := filepath.Join(t.TempDir(), "move.patch")
+	err := os.WriteFile(patch, []byte(`--- a/a.go
++++ b/a.go
+@@ -1,4 +1,1 @@
+ package a
+-func helper(values []int) int {
+-	return len(values)
+-}
+--- a/b.go
++++ b/b.go
+@@ -1,1 +1,5 @@
+ package a
++func helper(values []int) int {
++	return len(values)
++}
++var added = 1
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, skip := range []bool{false, true} {
+		args = parseOptions(t, "--diff-file", patch)
+		args.SkipMoved = skip
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		changes, err := findChanges(context.Background(), ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*diff.Change{{Start: 2, End: 5}}
+		if skip {
+			want = []*diff.Change{{Start: 5, End: 5}}
+		}
+		if len(changes) != 1 || !reflect.DeepEqual(changes[0].Changes, want) {
+			t.Errorf("--skip-moved=%v: changes = %+v, want b.go lines %v", skip, changes, want)
+		}
+	}
+}
+
+func TestIncludeGeneratedOption(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":   "package a\n\nvar x = 1\n",
+		"gen.go": "// Code generated by stringer. DO NOT EDIT.\n\npackage a\n",
+		"change.patch": `--- a/a.go
++++ b/a.go
+@@ -0,0 +1,3 @@
++package a
++
++var x = 1
+--- a/gen.go
++++ b/gen.go
+@@ -0,0 +1,3 @@
++// Code generated by stringer. DO NOT EDIT.
++
++package a
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, include := range []bool{false, true} {
+		args = parseOptions(t, "--diff-file", filepath.Join(dir, "change.patch"))
+		args.IncludeGenerated = include
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		changes, err := findChanges(context.Background(), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, change := range changes {
+			paths = append(paths, change.Path)
+		}
+		want := []string{"a.go"}
+		if include {
+			want = []string{"a.go", "gen.go"}
+		}
+		if !reflect.DeepEqual(paths, want) {
+			t.Errorf("--include-generated=%v: changed files = %v, want %v", include, paths, want)
+		}
+	}
+}
+
+func TestProjectExcludes(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	var patch strings.Builder
+	for _, file := range []string{"a.go", "build/a.go", "legacy/a.go", "vendor/x/a.go"} {
+		fmt.Fprintf(&patch, "--- a/%s\n+++ b/%s\n@@ -0,0 +1,1 @@\n+package a\n", file, file)
+	}
+	files := map[string]string{
+		".gitignore":    "build/\n",
+		".golangci.yml": "run:\n  skip-dirs: [legacy]\nissues:\n  exclude-rules:\n    - linters: [lll]\n",
+		"change.patch":  patch.String(),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args = parseOptions(t, "--pwd", dir, "--diff-file", filepath.Join(dir, "change.patch"))
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := findChanges(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "a.go" {
+		t.Fatalf("changes = %+v, want only a.go", changes)
+	}
+
+	k, err := newKeeper(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := k.filter(context.Background(), []result.Issue{
+		{FromLinter: "lll", Text: "line too long", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].FromLinter != "errcheck" {
+		t.Errorf("kept = %+v, want only the errcheck issue", kept)
+	}
+
+	args = parseOptions(t, "--lint-config", filepath.Join(dir, "missing.yml"))
+	if err := args.applyConfig(&config.Config{}); err == nil {
+		t.Error("a missing --lint-config expected an error")
+	}
+}
+
+func TestFormatCheckOption(t *testing.T) {
+	o := parseOptions(t, "--format-check", "gofumpt,goimports")
+	if err := o.applyConfig(&config.Config{FormatCheck: []string{"gofmt"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.FormatCheck, []string{"gofumpt", "goimports"}) {
+		t.Errorf("--format-check %q, want gofumpt and goimports", o.FormatCheck)
+	}
+	o = parseOptions(t)
+	if err := o.applyConfig(&config.Config{FormatCheck: []string{"gofmt"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.FormatCheck, []string{"gofmt"}) {
+		t.Errorf("--format-check %q, want gofmt from the config", o.FormatCheck)
+	}
+	o = parseOptions(t, "--format-check", "prettier")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--format-check prettier expected an error")
+	}
+}
+
+func TestFormatIssues(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt not found")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.Mkdir(filepath.Join(repo, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(filepath.Join(repo, "sub", "a.go"), []byte("package a\n\nvar x=1\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args = parseOptions(t, "--pwd", filepath.Join(repo, "sub"), "--format-check", "gofmt")
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := formatIssues(context.Background(), []string{"sub/a.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].FilePath() != "a.go" || issues[0].Line() != 3 || issues[0].FromLinter != "gofmt" {
+		t.Errorf("issues = %+v, want gofmt on a.go:3", issues)
+	}
+}
+
+func TestVulnIssues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake govulncheck is a shell script")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	files := map[string]string{
+		"svc/go.mod":   "module example.com/svc\n\ngo 1.21\n",
+		"svc/pkg/a.go": "package pkg\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo "$*" > ` + filepath.Join(bin, "args.txt") + `
+echo '{"finding":{"osv":"GO-2023-1571","trace":[{"module":"stdlib","package":"net/http","function":"Get"},{"module":"example.com/svc","package":"example.com/svc/pkg","function":"F","position":{"filename":"pkg/a.go","line":3,"column":2}}]}}'
+`
+	if err := os.WriteFile(filepath.Join(bin, "govulncheck"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	args = parseOptions(t, "--pwd", repo, "--vuln")
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	issues, err := vulnIssues(context.Background(), []string{"svc/pkg/a.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].FilePath() != "svc/pkg/a.go" || issues[0].Line() != 3 || issues[0].FromLinter != "govulncheck" {
+		t.Errorf("issues = %+v, want govulncheck on svc/pkg/a.go:3", issues)
+	}
+	recorded, err := os.ReadFile(filepath.Join(bin, "args.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(recorded)) != "-json ./pkg" {
+		t.Errorf("govulncheck ran with %q, want the changed package only", recorded)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := vulnIssues(context.Background(), []string{"svc/pkg/a.go"}); err == nil {
+		t.Error("--vuln without govulncheck expected an error")
+	}
+}
+
+func TestGolangciBin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	fake := func(t *testing.T, path, version string) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		script := "#!/bin/sh\necho 'golangci-lint has version " + version + " built with go1.22.3'\n"
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	cache := t.TempDir()
+	installed := fake(t, filepath.Join(cache, "tools", "golangci-lint", "v1.59.1", "golangci-lint"), "1.59.1")
+	local := fake(t, filepath.Join(t.TempDir(), "golangci-lint"), "1.59.1")
+	older := fake(t, filepath.Join(t.TempDir(), "golangci-lint"), "1.51.1")
+
+	tests := []struct {
+		name    string
+		bin     string
+		version string
+		want    string
+	}{
+		{name: "unpinned", bin: older, want: older},
+		{name: "local matches", bin: local, version: "v1.59.1", want: local},
+		{name: "local mismatches", bin: older, version: "v1.59.1", want: installed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := args
+			t.Cleanup(func() { args = saved })
+			args = options{Bin: tt.bin, LintVersion: tt.version, CacheDir: cache}
+			got, err := golangciBin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("golangciBin = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyFormatOption(t *testing.T) {
+	o := parseOptions(t)
+	if err := o.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if o.NotifyFormat != "slack" {
+		t.Errorf("--notify-format defaults to %q, want slack", o.NotifyFormat)
+	}
+	o = parseOptions(t, "--notify-format", "xml")
+	if err := o.applyConfig(&config.Config{}); err == nil {
+		t.Error("--notify-format xml expected an error")
+	}
+}
+
+func TestPathOptions(t *testing.T) {
+	cfg := &config.Config{ExcludePaths: []string{"vendor"}, IncludePaths: []string{"api/**"}}
+	t.Setenv("LINTERDIFF_EXCLUDE_PATHS", "vendor/**,**/*_gen.go")
+
+	o := parseOptions(t)
+	if err := o.applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vendor/**", "**/*_gen.go"}; !reflect.DeepEqual(o.ExcludePaths, want) {
+		t.Errorf("ExcludePaths = %q, want %q", o.ExcludePaths, want)
+	}
+	if want := []string{"api/**"}; !reflect.DeepEqual(o.IncludePaths, want) {
+		t.Errorf("IncludePaths = %q, want %q", o.IncludePaths, want)
+	}
+}
+
+func TestSplitPassthrough(t *testing.T) {
+	argv, extra := splitPassthrough([]string{"--staged", "--", "-E", "gosec", "--"})
+	if !reflect.DeepEqual(argv, []string{"--staged"}) || !reflect.DeepEqual(extra, []string{"-E", "gosec", "--"}) {
+		t.Errorf("splitPassthrough = %q, %q", argv, extra)
+	}
+
+	argv, extra = splitPassthrough([]string{"--staged"})
+	if len(argv) != 1 || extra != nil {
+		t.Errorf("splitPassthrough without -- = %q, %q", argv, extra)
+	}
+}
+
+func TestExitReason(t *testing.T) {
+	args = options{MaxIssues: 2}
+	stats = summary.New()
+	stats.SetReported(make([]result.Issue, 3))
+
+	tests := []struct {
+		code int
+		err  error
+		want string
+	}{
+		{code: exitOK, want: "3 issues reported, at most --max-issues 2"},
+		{code: exitIssues, want: "3 issues reported, more than --max-issues 2"},
+		{code: exitError, err: errors.New("golangci-lint failed"), want: "golangci-lint failed"},
+	}
+	for _, tt := range tests {
+		if got := exitReason(tt.code, tt.err); got != tt.want {
+			t.Errorf("exitReason(%d, %v) = %q, want %q", tt.code, tt.err, got, tt.want)
+		}
+	}
+
+	args.failOn = []filter.FailRule{{Severity: "error"}}
+	if got, want := exitReason(exitOK, nil), "3 issues reported, at most --max-issues 2 of them picked by --fail-on"; got != want {
+		t.Errorf("exitReason with --fail-on = %q, want %q", got, want)
+	}
+}
+
+func TestFailOnOption(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	args = parseOptions(t, "--fail-on", "severity=error,linter=gosec")
+	if err := args.applyConfig(&config.Config{FailOn: []string{"linter=lll"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []filter.FailRule{{Severity: "error"}, {Linter: "gosec"}}; !reflect.DeepEqual(args.failOn, want) {
+		t.Errorf("rules = %+v, want %+v", args.failOn, want)
+	}
+	issues := []result.Issue{{FromLinter: "gosec"}, {FromLinter: "godot", Severity: "info"}, {FromLinter: "govet", Severity: "error"}}
+	if got := failing(issues); got != 2 {
+		t.Errorf("failing = %d, want the gosec and error issues", got)
+	}
+
+	args = parseOptions(t)
+	if err := args.applyConfig(&config.Config{FailOn: []string{"linter=lll"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []filter.FailRule{{Linter: "lll"}}; !reflect.DeepEqual(args.failOn, want) {
+		t.Errorf("rules from the config file = %+v, want %+v", args.failOn, want)
+	}
+	if got := failing(issues); got != 0 {
+		t.Errorf("failing = %d, want none", got)
+	}
+
+	args = parseOptions(t, "--fail-on", "severity=fatal")
+	if err := args.applyConfig(&config.Config{}); err == nil {
+		t.Error("--fail-on severity=fatal expected an error")
+	}
+}
+
+func TestSeveritiesConfig(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	args = parseOptions(t, "--pwd", t.TempDir(), "--severity-min", "warning")
+	cfg := &config.Config{Severities: []config.Severity{
+		{Linter: "errcheck", Severity: "error"},
+		{Linter: "godox", Severity: "info"},
+	}}
+	if err := args.applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	k, err := newKeeper([]diff.FileChange{{Path: "a.go", Changes: []*diff.Change{{Start: 1, End: 9}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "godox", Text: "TODO", Severity: "warning", Pos: token.Position{Filename: "a.go", Line: 2}},
+	}
+	kept, err := k.filter(context.Background(), issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].FromLinter != "errcheck" || kept[0].Severity != "error" {
+		t.Errorf("kept = %+v, want only the errcheck issue as an error", kept)
+	}
+
+	args = parseOptions(t)
+	if err := args.applyConfig(&config.Config{Severities: []config.Severity{{Linter: "godox", Severity: "minor"}}}); err == nil {
+		t.Error("an unknown severity expected an error")
+	}
+}
+
+func TestSaveStats(t *testing.T) {
+	dir := t.TempDir()
+	args = options{
+		SummaryJSON:     filepath.Join(dir, "summary.json"),
+		MetricsTextfile: filepath.Join(dir, "linter.prom"),
+	}
+	stats = summary.New()
+
+	if err := saveStats(exitError, errors.New("golangci-lint failed")); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(args.SummaryJSON)
+	if err != nil || !strings.Contains(string(content), `"exit_reason": "golangci-lint failed"`) {
+		t.Errorf("summary = %s, %v", content, err)
+	}
+	content, err = os.ReadFile(args.MetricsTextfile)
+	if err != nil || !strings.Contains(string(content), "\nlinter_exit_code 2\n") {
+		t.Errorf("metrics = %s, %v", content, err)
+	}
+}
+
+func TestSetupLoggingRejectsVerboseAndQuiet(t *testing.T) {
+	if err := setupLogging(true, true); err == nil {
+		t.Error("setupLogging(verbose, quiet) expected an error")
+	}
+}
+
+func TestStartProgress(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	for _, flags := range [][]string{{"--no-progress"}, {"--quiet"}} {
+		args = parseOptions(t, flags...)
+		ctx, stop := startProgress(context.Background())
+		lintCtx, done := phase(ctx, "lint")
+		if progress.FromContext(lintCtx) != nil || stderr != os.Stderr {
+			t.Errorf("%s still shows progress", flags[0])
+		}
+		done()
+		stop()
+	}
+
+	args = parseOptions(t)
+	ctx, stop := startProgress(context.Background())
+	if stderr == os.Stderr {
+		t.Error("logs do not go through the progress reporter")
+	}
+	lintCtx, done := phase(ctx, "lint")
+	if progress.FromContext(lintCtx) == nil {
+		t.Error("the lint phase is not shown as progress")
+	}
+	done()
+	stop()
+	if stderr != os.Stderr {
+		t.Error("stopping the progress left logs going through it")
+	}
+}
+
+func TestModuleReport(t *testing.T) {
+	defer func(saved string) { args.JsonFile = saved }(args.JsonFile)
+
+	args.JsonFile = "/tmp/report.json"
+	if got := moduleReport("/repo", 2); got != "/tmp/report.2.json" {
+		t.Errorf("moduleReport = %q", got)
+	}
+	args.JsonFile = "out/report"
+	if got := moduleReport("/repo", 0); got != filepath.Join("/repo", "out", "report.0") {
+		t.Errorf("moduleReport = %q", got)
+	}
+}
+
+func TestSuppressIssues(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, suppress.FileName), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args = options{Pwd: filepath.Join(dir, "svc")}
+	issues := []result.Issue{
+		{FromLinter: "lll", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "govet", Pos: token.Position{Filename: "a.go", Line: 2}},
+	}
+
+	write("suppressions:\n  - linter: lll\n    reason: long URLs\n")
+	got, err := suppressIssues(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].FromLinter != "govet" {
+		t.Errorf("suppressIssues kept %v, want the govet issue", got)
+	}
+
+	write("suppressions:\n  - linter: lll\n    expires: 2001-01-01\n    reason: long URLs\n")
+	if _, err := suppressIssues(issues); err == nil || !strings.Contains(err.Error(), "linter lll (expired 2001-01-01)") {
+		t.Errorf("suppressIssues error = %v, want the expired entry", err)
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"change.patch": `--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@
++	f()
++	g()
+--- a/deploy.sh
++++ b/deploy.sh
+@@ -1,0 +2,1 @@
++rm -rf $DIR
+`,
+		"lint.json": `{"Issues":[
+			{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"a.go","Line":4}},
+			{"FromLinter":"errcheck","Text":"old","Pos":{"Filename":"a.go","Line":1}}]}`,
+		"shellcheck.sarif": `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"shellcheck"}},"results":[
+			{"ruleId":"SC2086","level":"warning","message":{"text":"Double quote to prevent globbing."},
+			 "locations":[{"physicalLocation":{"artifactLocation":{"uri":"deploy.sh"},"region":{"startLine":2}}}]}]}]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args = parseOptions(t, "--pwd", dir, "--diff-file", filepath.Join(dir, "change.patch"),
+		"--merge-results", filepath.Join(dir, "lint.json")+","+filepath.Join(dir, "shellcheck.sarif"))
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	issues, _, err := check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d %s", issue.FilePath(), issue.Line(), issue.FromLinter))
+	}
+	want := []string{"a.go:4 errcheck", "deploy.sh:2 shellcheck"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %q, want %q", got, want)
+	}
+}
+
+func TestStreamOption(t *testing.T) {
+	tests := []struct {
+		flags   []string
+		wantErr string
+	}{
+		{[]string{"--stream", "--out", "jsonl"}, ""},
+		{[]string{"--stream", "--out", "text", "--out", "jsonl:out/issues.jsonl"}, ""},
+		{[]string{"--stream"}, "--stream needs --out jsonl"},
+		{[]string{"--stream", "--out", "json"}, "--stream needs --out jsonl"},
+		{[]string{"--stream", "--out", "jsonl", "--fix"}, "--stream prints the issues before --fix"},
+	}
+	for _, tt := range tests {
+		o := parseOptions(t, tt.flags...)
+		err := o.applyConfig(&config.Config{})
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tt.flags, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%q: error = %v, want %q", tt.flags, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckStreamsKeptIssues(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"change.patch": `--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@
++	f()
++	g()
+`,
+		"lint.json": `{"Issues":[
+			{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"a.go","Line":4}},
+			{"FromLinter":"errcheck","Text":"old","Pos":{"Filename":"a.go","Line":1}},
+			{"FromLinter":"lll","Text":"line is 130 characters","Pos":{"Filename":"a.go","Line":5}}]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args = parseOptions(t, "--pwd", dir, "--diff-file", filepath.Join(dir, "change.patch"),
+		"--merge-results", filepath.Join(dir, "lint.json"), "--exclude-linters", "lll", "--stream", "--out", "jsonl")
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	var streamed [][]string
+	args.stream = func(issues []result.Issue) error {
+		var batch []string
+		for _, issue := range issues {
+			batch = append(batch, fmt.Sprintf("%s:%d %s", issue.FilePath(), issue.Line(), issue.FromLinter))
+		}
+		streamed = append(streamed, batch)
+		return nil
+	}
+	issues, _, err := check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a.go:4 errcheck"}}; !reflect.DeepEqual(streamed, want) {
+		t.Errorf("streamed %q, want %q", streamed, want)
+	}
+	if len(issues) != 1 {
+		t.Errorf("check returned %d issues, want the streamed one", len(issues))
+	}
+
+	args.stream = func([]result.Issue) error { return errors.New("stdout closed") }
+	if _, _, err := check(context.Background()); err == nil || err.Error() != "stdout closed" {
+		t.Errorf("check error = %v, want the stream failure", err)
+	}
+}
+
+func TestCheckMergesDuplicates(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"change.patch": `--- a/a.go
++++ b/a.go
+@@ -3,0 +4,1 @@
++	s := fmt.Sprintf("%d", name)
+`,
+		"lint.json": `{"Issues":[
+			{"FromLinter":"govet","Text":"printf: fmt.Sprintf format %d has arg name of wrong type string","Pos":{"Filename":"a.go","Line":4,"Column":7}},
+			{"FromLinter":"staticcheck","Text":"SA5009: Printf format %d has arg #1 of wrong type string","Pos":{"Filename":"a.go","Line":4,"Column":7}}]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		flag string
+		want []string
+	}{
+		{"", []string{"govet: printf: fmt.Sprintf format %d has arg name of wrong type string (also staticcheck)"}},
+		{"--no-dedup", []string{
+			"govet: printf: fmt.Sprintf format %d has arg name of wrong type string",
+			"staticcheck: SA5009: Printf format %d has arg #1 of wrong type string",
+		}},
+	} {
+		flags := []string{"--pwd", dir, "--diff-file", filepath.Join(dir, "change.patch"), "--merge-results", filepath.Join(dir, "lint.json")}
+		if tt.flag != "" {
+			flags = append(flags, tt.flag)
+		}
+		args = parseOptions(t, flags...)
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		issues, _, err := check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, issue := range issues {
+			got = append(got, issue.FromLinter+": "+issue.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: issues = %q, want %q", tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateOption(t *testing.T) {
+	tests := []struct {
+		flags   []string
+		wantErr bool
+	}{
+		{[]string{"--out", "template", "--template-file", "issues.tmpl"}, false},
+		{[]string{"--out", "text", "--out", "template:issues.csv", "--template-file", "csv.tmpl"}, false},
+		{[]string{"--out", "template"}, true},
+		{[]string{"--template-file", "issues.tmpl"}, true},
+	}
+	for _, tt := range tests {
+		o := parseOptions(t, tt.flags...)
+		if err := o.applyConfig(&config.Config{}); (err != nil) != tt.wantErr {
+			t.Errorf("%q: error = %v, want error %v", tt.flags, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIssueLinks(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	t.Setenv("GITHUB_SHA", "abc123")
+
+	repo := t.TempDir()
+	for _, git := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "remote", "add", "origin", "git@github.com:metailurini/linter.git"},
+	} {
+		if output, err := exec.Command("git", git...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", git[0], err, output)
+		}
+	}
+	sub := filepath.Join(repo, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"auto", "https://github.com/metailurini/linter/blob/abc123/sub/a.go#L4"},
+		{"https://gitlab.com/group/linter", "https://gitlab.com/group/linter/-/blob/abc123/sub/a.go#L4"},
+	}
+	for _, tt := range tests {
+		args = parseOptions(t, "--pwd", sub, "--link-base", tt.base)
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		link, err := issueLinks(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.base, err)
+		}
+		if got := link(result.Issue{Pos: token.Position{Filename: "a.go", Line: 4}}); got != tt.want {
+			t.Errorf("%s: link = %q, want %q", tt.base, got, tt.want)
+		}
+		if got := link(result.Issue{Pos: token.Position{Filename: "../../elsewhere/b.go", Line: 1}}); got != "" {
+			t.Errorf("%s: link outside the repository = %q, want none", tt.base, got)
+		}
+	}
+
+	args = parseOptions(t, "--pwd", sub, "--link-base", "github.com/org/repo")
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issueLinks(context.Background()); err == nil {
+		t.Error("--link-base without a scheme expected an error")
+	}
+}
+
+func TestPagerOption(t *testing.T) {
+	t.Setenv("PAGER", "less")
+	tests := []struct {
+		flags     []string
+		wantErr   bool
+		wantPaged bool
+	}{
+		{flags: []string{"--pager", "always"}, wantPaged: true},
+		{flags: []string{"--pager", "always", "--out", "json", "--out", "text:lint.txt"}},
+		{flags: []string{"--pager", "never"}},
+		// Tests do not run on a terminal.
+		{flags: nil},
+		{flags: []string{"--pager", "sometimes"}, wantErr: true},
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+	for _, tt := range tests {
+		args = parseOptions(t, tt.flags...)
+		err := args.applyConfig(&config.Config{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error = %v, want error %v", tt.flags, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		stdout, closePager := pagedStdout()
+		if _, paged := stdout.(*pager.Pager); paged != tt.wantPaged {
+			t.Errorf("%q: paged = %v, want %v", tt.flags, paged, tt.wantPaged)
+		}
+		closePager()
+	}
+}
+
+func TestFetchMissingHint(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	commit := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+	commit.Dir = repo
+	if output, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, output)
+	}
+
+	for _, engine := range []string{diff.EngineExec, diff.EngineNative} {
+		args = parseOptions(t, "--pwd", repo, "--base-ref", "origin/main", "--diff-engine", engine)
+		if err := args.applyConfig(&config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := findChanges(context.Background(), repo)
+		if !errors.Is(err, diff.ErrMissingRef) || !strings.Contains(err.Error(), "--fetch-missing") {
+			t.Errorf("%s engine: findChanges = %v, want a missing ref pointing at --fetch-missing", engine, err)
+		}
+	}
+}
+
+func TestFindChangesSparseIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	git := func(argv ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, argv...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("sub/a.go", "package sub\n")
+	write("other/b.go", "package other\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("sparse-checkout", "set", "--cone", "--sparse-index", "sub")
+	write("sub/a.go", "package sub\n\nvar a = 1\n")
+
+	args = parseOptions(t, "--pwd", repo, "--diff-engine", diff.EngineNative)
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := findChanges(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diff.Paths(changes); !reflect.DeepEqual(got, []string{"sub/a.go"}) {
+		t.Errorf("findChanges = %v, want sub/a.go", got)
+	}
+}
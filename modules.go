@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/lint"
+	"linter/pkg/progress"
+)
+
+// lintModules runs golangci-lint in every module containing one of files,
+// several at a time, with paths made relative to --pwd like those of a
+// single run. The issues of each module go to emit in module order, as soon
+// as it and the modules before it are linted.
+func lintModules(ctx context.Context, files []string, emit func([]result.Issue) error) error {
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return err
+	}
+	modules, err := lint.ChangedModules(root, files)
+	if err != nil {
+		return err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return err
+	}
+
+	step := progress.FromContext(ctx)
+	step.SetTotal(len(modules), "modules")
+
+	var (
+		wg      sync.WaitGroup
+		slots   = make(chan struct{}, runtime.GOMAXPROCS(0))
+		results = make([][]result.Issue, len(modules))
+		errs    = make([]error, len(modules))
+		mu      sync.Mutex
+		linted  = make([]bool, len(modules))
+		next    int
+		emitErr error
+	)
+	for i, module := range modules {
+		wg.Add(1)
+		go func(i int, module lint.Module) {
+			defer wg.Done()
+			slots <- struct{}{}
+			name, _ := filepath.Rel(root, module.Dir)
+			results[i], errs[i] = lintModule(ctx, root, pwd, module, moduleReport(pwd, i), len(modules) > 1)
+			<-slots
+			step.Add(1)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("module %s: %w", name, errs[i])
+			} else {
+				slog.Info("module linted", "module", filepath.ToSlash(name), "issues", len(results[i]))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			linted[i] = true
+			for next < len(modules) && linted[next] && errs[next] == nil && emitErr == nil {
+				emitErr = emit(results[next])
+				next++
+			}
+		}(i, module)
+	}
+	wg.Wait()
+
+	for i := range modules {
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	return emitErr
+}
+
+// workspacePackages gives the patterns of the modules of the go.work
+// holding files, to lint in place of ./... when --pwd is in a workspace but
+// in none of its modules, where ./... matches no packages. ok is false
+// otherwise.
+func workspacePackages(ctx context.Context, files []string) (patterns []string, ok bool, err error) {
+	workspace, err := lint.FindWorkspace(args.Pwd)
+	if err != nil || workspace == nil {
+		return nil, false, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return nil, false, err
+	}
+	if workspace.ModuleOf(pwd) != "" {
+		return nil, false, nil
+	}
+
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, false, err
+	}
+	modules, err := workspace.ChangedModules(root, files)
+	if err != nil {
+		return nil, false, err
+	}
+	patterns = make([]string, 0, len(modules))
+	for _, module := range modules {
+		rel, err := filepath.Rel(pwd, module.Dir)
+		if err != nil {
+			return nil, false, err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		patterns = append(patterns, rel+"/...")
+	}
+	slog.Info("linting the changed modules of the workspace", "workspace", workspace.Dir, "modules", len(patterns))
+	return patterns, true, nil
+}
+
+func lintModule(ctx context.Context, root, pwd string, module lint.Module, report string, parallel bool) ([]result.Issue, error) {
+	inspectDes := args.InspectDes
+	if args.ChangedPackages {
+		var err error
+		inspectDes, err = lint.ChangedPackages(ctx, module.Dir, root, module.Files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	issues, err := lintIssues(ctx, module.Dir, report, inspectDes, parallel)
+	if err != nil {
+		return nil, err
+	}
+	return relativeTo(pwd, module.Dir, issues)
+}
+
+// moduleReport gives the run of module i a report file of its own next to
+// the configured one.
+func moduleReport(pwd string, i int) string {
+	path := args.JsonFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pwd, path)
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + strconv.Itoa(i) + ext
+}
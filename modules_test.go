@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"linter/pkg/diff"
+)
+
+func TestWorkspacePackages(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+	t.Setenv("GOWORK", "")
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	files := map[string]string{
+		"go.work":        "go 1.21\n\nuse (\n\t./api\n\t./lib\n)\n",
+		"api/go.mod":     "module example.com/api\n",
+		"lib/go.mod":     "module example.com/lib\n",
+		"tools/tools.go": "package tools\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changed := []string{"api/handler.go", "lib/lib.go", "tools/tools.go"}
+
+	tests := []struct {
+		name   string
+		pwd    string
+		want   []string
+		wantOK bool
+	}{
+		{"workspace root", repo, []string{"./api/...", "./lib/..."}, true},
+		{"outside of every module", filepath.Join(repo, "tools"), []string{"../api/...", "../lib/..."}, true},
+		{"inside a module", filepath.Join(repo, "api"), nil, false},
+		{"no workspace", t.TempDir(), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args = options{Pwd: tt.pwd, vcs: diff.Git}
+			got, ok, err := workspacePackages(context.Background(), changed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workspacePackages = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"linter/pkg/diff"
+	"linter/pkg/notify"
+)
+
+// notifyFailure posts the failed run to --notify-webhook, with the error
+// that stopped it or else the issues it reported, linking to --status-url
+// or the CI job. A commit or branch that cannot be found is left out.
+func notifyFailure(ctx context.Context, runErr error) error {
+	run := notify.Run{Issues: stats.Issues(), URL: statusURL()}
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	var err error
+	if run.Commit, err = diff.ResolveCommit(ctx, firstNonEmpty(args.Pwd, "."), "HEAD"); err != nil {
+		slog.Debug("no commit to notify of", "err", err)
+	}
+	if run.Branch, err = currentBranch(ctx); err != nil {
+		slog.Debug("no branch to notify of", "err", err)
+	}
+	return notify.New(args.NotifyWebhook).SetFormat(args.NotifyFormat).Notify(run)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/summary"
+)
+
+func TestNotifyFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantError string
+		wantCount float64
+	}{
+		{name: "issues", wantCount: 1},
+		{name: "error", err: errors.New("golangci-lint: exit status 3"), wantError: "golangci-lint: exit status 3", wantCount: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+			}))
+			defer server.Close()
+			t.Setenv("GITHUB_RUN_ID", "")
+			t.Setenv("CI_JOB_URL", "")
+
+			savedArgs, savedStats := args, stats
+			t.Cleanup(func() { args, stats = savedArgs, savedStats })
+			args = options{
+				Pwd:           t.TempDir(),
+				NotifyWebhook: server.URL,
+				NotifyFormat:  "json",
+				StatusURL:     "https://ci.example.com/1",
+			}
+			stats = summary.New()
+			stats.SetReported([]result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}})
+
+			if err := notifyFailure(context.Background(), tt.err); err != nil {
+				t.Fatal(err)
+			}
+			if posted["url"] != "https://ci.example.com/1" || posted["issue_count"] != tt.wantCount {
+				t.Errorf("posted %v", posted)
+			}
+			if got, _ := posted["error"].(string); got != tt.wantError {
+				t.Errorf("error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/codeowners"
+	"linter/pkg/output"
+)
+
+// unowned is the group of issues in files no CODEOWNERS rule assigns.
+const unowned = "(unowned)"
+
+// issueOwners returns the owners of the file of every issue, read from the
+// CODEOWNERS file of the repository.
+func issueOwners(ctx context.Context, issues []result.Issue) ([][]string, error) {
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	path, err := codeowners.Find(root)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no CODEOWNERS file in %s", strings.Join(codeowners.Locations, ", "))
+	}
+	owners, err := codeowners.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// CODEOWNERS paths are relative to the root, issue paths to --pwd.
+	fromRoot, err := relativeTo(root, args.Pwd, issues)
+	if err != nil {
+		return nil, err
+	}
+	byIssue := make([][]string, len(issues))
+	for i, issue := range fromRoot {
+		byIssue[i] = owners.Of(issue.FilePath())
+	}
+	return byIssue, nil
+}
+
+// filterOwners keeps the issues of files owned by one of --owner.
+func filterOwners(ctx context.Context, issues []result.Issue) ([]result.Issue, error) {
+	if len(args.Owner) == 0 {
+		return issues, nil
+	}
+	owners, err := issueOwners(ctx, issues)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]result.Issue, 0, len(issues))
+	for i, issue := range issues {
+		if ownedByAny(owners[i], args.Owner) {
+			kept = append(kept, issue)
+		}
+	}
+	return kept, nil
+}
+
+func ownedByAny(owners, wanted []string) bool {
+	for _, owner := range owners {
+		for _, want := range wanted {
+			if strings.EqualFold(owner, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ownerGroups groups the issues by owner, owners in alphabetical order and
+// unowned files last. Issues of files with several owners are in the group
+// of each of them.
+func ownerGroups(ctx context.Context, issues []result.Issue) ([]output.Group, error) {
+	owners, err := issueOwners(ctx, issues)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]result.Issue)
+	for i, issue := range issues {
+		if len(owners[i]) == 0 {
+			groups[unowned] = append(groups[unowned], issue)
+		}
+		for _, owner := range owners[i] {
+			groups[owner] = append(groups[owner], issue)
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != unowned {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[unowned]; ok {
+		names = append(names, unowned)
+	}
+
+	list := make([]output.Group, 0, len(names))
+	for _, name := range names {
+		list = append(list, output.Group{Name: name, Issues: groups[name]})
+	}
+	return list, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/output"
+)
+
+func TestOwners(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	codeowners := "* @org/core\n/api/ @org/platform-team\n"
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte(codeowners), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pwd := filepath.Join(dir, "api")
+	if err := os.Mkdir(pwd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "handler.go", Line: 3}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "../main.go", Line: 5}},
+	}
+
+	args = options{Pwd: pwd, vcs: diff.Git, Owner: []string{"@ORG/platform-team"}}
+	got, err := filterOwners(context.Background(), issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].FilePath() != "handler.go" {
+		t.Errorf("--owner kept %v, want handler.go only", got)
+	}
+
+	args = options{Pwd: pwd, vcs: diff.Git, Out: []string{"text"}, GroupBy: "owner"}
+	var out strings.Builder
+	if err := printGroups(context.Background(), &out, output.NewText(&out), issues); err != nil {
+		t.Fatal(err)
+	}
+	core := strings.Index(out.String(), "@org/core: 1 issue(s)")
+	platform := strings.Index(out.String(), "@org/platform-team: 1 issue(s)")
+	if core < 0 || platform < core {
+		t.Errorf("--group-by owner printed\n%s", out.String())
+	}
+}
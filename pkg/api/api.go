@@ -0,0 +1,93 @@
+// Package api serves lint runs over HTTP, so one long-running server with
+// warm caches can check many pull requests.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/output"
+)
+
+// Request asks for the issues headRef introduces on the lines it changed
+// since its merge base with baseRef.
+type Request struct {
+	// Repo is the path of the repository on the server; empty means the
+	// one the server was started in.
+	Repo    string `json:"repo"`
+	BaseRef string `json:"baseRef"`
+	// HeadRef defaults to HEAD.
+	HeadRef string `json:"headRef"`
+}
+
+// LintFunc returns the issues on the lines changed as req describes, with
+// paths relative to the repository.
+type LintFunc func(ctx context.Context, req Request) ([]result.Issue, error)
+
+// Server answers POST /lint with the issues of a LintFunc, in the format of
+// --out json, and GET /healthz with 200 once it is up.
+type Server struct {
+	lint LintFunc
+	mux  *http.ServeMux
+}
+
+// NewServer returns a server running lint for each request.
+func NewServer(lint LintFunc) *Server {
+	s := &Server{lint: lint, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/lint", s.handleLint)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	return s
+}
+
+// Handle serves pattern with handler as well, such as a webhook.
+func (s *Server) Handle(pattern string, handler http.Handler) *Server {
+	s.mux.Handle(pattern, handler)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleLint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s not allowed, use POST", r.Method))
+		return
+	}
+	var req Request
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("malformed request: %w", err))
+		return
+	}
+	if req.BaseRef == "" {
+		writeError(w, http.StatusBadRequest, errors.New("baseRef is required"))
+		return
+	}
+
+	issues, err := s.lint(r.Context(), req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = output.NewJSON(w).Print(issues)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
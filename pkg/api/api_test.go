@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestServer(t *testing.T) {
+	var got Request
+	server := httptest.NewServer(NewServer(func(ctx context.Context, req Request) ([]result.Issue, error) {
+		if req.BaseRef == "broken" {
+			return nil, errors.New("golangci-lint failed")
+		}
+		got = req
+		return []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}}, nil
+	}).Handle("/hook", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "lint", method: http.MethodPost, path: "/lint",
+			body:       `{"repo":"/srv/repo","baseRef":"origin/main","headRef":"pr-1"}`,
+			wantStatus: http.StatusOK, wantBody: `"linter": "errcheck"`,
+		},
+		{name: "wrong method", method: http.MethodGet, path: "/lint", wantStatus: http.StatusMethodNotAllowed},
+		{name: "malformed", method: http.MethodPost, path: "/lint", body: `{"base":"main"}`, wantStatus: http.StatusBadRequest},
+		{name: "no base", method: http.MethodPost, path: "/lint", body: `{"repo":"/srv/repo"}`, wantStatus: http.StatusBadRequest, wantBody: "baseRef is required"},
+		{name: "lint fails", method: http.MethodPost, path: "/lint", body: `{"baseRef":"broken"}`, wantStatus: http.StatusInternalServerError, wantBody: `{"error":"golangci-lint failed"}`},
+		{name: "health", method: http.MethodGet, path: "/healthz", wantStatus: http.StatusOK, wantBody: "ok"},
+		{name: "unknown", method: http.MethodGet, path: "/nowhere", wantStatus: http.StatusNotFound},
+		{name: "extra handler", method: http.MethodPost, path: "/hook", wantStatus: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d with %q", tt.method, tt.path, resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	if want := (Request{Repo: "/srv/repo", BaseRef: "origin/main", HeadRef: "pr-1"}); got != want {
+		t.Errorf("lint got %+v, want %+v", got, want)
+	}
+}
+
+func TestServerRespondsWithJSONReport(t *testing.T) {
+	handler := NewServer(func(ctx context.Context, req Request) ([]result.Issue, error) {
+		return nil, nil
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/lint", strings.NewReader(`{"baseRef":"main"}`)))
+
+	var report struct {
+		Issues []json.RawMessage `json:"issues"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || report.Issues == nil || len(report.Issues) != 0 {
+		t.Errorf("response %s, want an empty issue list: %v", rec.Body.String(), err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
@@ -0,0 +1,95 @@
+// Package baseline records known issues so later runs only report new ones.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+// version changes with the fingerprint scheme, since older fingerprints
+// would never match again.
+const version = 2
+
+type Baseline struct {
+	Version      int            `json:"version"`
+	Fingerprints map[string]int `json:"fingerprints"`
+}
+
+func New(issues []result.Issue) *Baseline {
+	b := &Baseline{
+		Version:      version,
+		Fingerprints: make(map[string]int, len(issues)),
+	}
+	for _, issue := range issues {
+		b.Fingerprints[fingerprint.Of(issue)]++
+	}
+	return b
+}
+
+func Load(path string) (*Baseline, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(bytes, &b); err != nil {
+		return nil, err
+	}
+	if b.Version != version {
+		return nil, fmt.Errorf("baseline %s has version %d, recreate it with linter baseline", path, b.Version)
+	}
+	if b.Fingerprints == nil {
+		b.Fingerprints = make(map[string]int)
+	}
+	return &b, nil
+}
+
+func (b *Baseline) Save(path string) error {
+	bytes, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bytes, '\n'), 0o644)
+}
+
+// Filter returns the issues that are not covered by the baseline. Each
+// baseline entry absorbs as many issues as were recorded for it, so a second
+// copy of a known issue is still reported.
+func (b *Baseline) Filter(issues []result.Issue) []result.Issue {
+	return b.Matcher().Filter(issues)
+}
+
+// Matcher filters issues arriving in batches, an entry absorbing no more
+// issues over all batches than Filter would in one.
+type Matcher struct {
+	remaining map[string]int
+}
+
+func (b *Baseline) Matcher() *Matcher {
+	remaining := make(map[string]int, len(b.Fingerprints))
+	for hash, count := range b.Fingerprints {
+		remaining[hash] = count
+	}
+	return &Matcher{remaining: remaining}
+}
+
+// Filter returns the issues of a batch left once the baseline entries not
+// used up by earlier batches absorbed theirs.
+func (m *Matcher) Filter(issues []result.Issue) []result.Issue {
+	fresh := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		hash := fingerprint.Of(issue)
+		if m.remaining[hash] > 0 {
+			m.remaining[hash]--
+			continue
+		}
+		fresh = append(fresh, issue)
+	}
+	return fresh
+}
@@ -0,0 +1,79 @@
+package baseline
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func issue(file string, line int, text string) result.Issue {
+	return result.Issue{
+		FromLinter: "govet",
+		Text:       text,
+		Pos:        token.Position{Filename: file, Line: line},
+	}
+}
+
+func TestFilterToleratesLineShifts(t *testing.T) {
+	known := New([]result.Issue{issue("a.go", 10, "x declared at line 10 is unused")})
+
+	moved := issue("a.go", 14, "x declared at line 14 is unused")
+	fresh := issue("a.go", 20, "y is unused")
+
+	got := known.Filter([]result.Issue{moved, fresh})
+	if len(got) != 1 || got[0].Text != fresh.Text {
+		t.Errorf("Filter = %v, want only %q", got, fresh.Text)
+	}
+}
+
+func TestFilterCountsDuplicates(t *testing.T) {
+	known := New([]result.Issue{issue("a.go", 1, "unused")})
+
+	got := known.Filter([]result.Issue{issue("a.go", 1, "unused"), issue("a.go", 5, "unused")})
+	if len(got) != 1 {
+		t.Errorf("Filter kept %d issues, want the second copy only", len(got))
+	}
+}
+
+func TestMatcherCountsAcrossBatches(t *testing.T) {
+	known := New([]result.Issue{issue("a.go", 1, "unused")})
+
+	matcher := known.Matcher()
+	if got := matcher.Filter([]result.Issue{issue("a.go", 1, "unused")}); len(got) != 0 {
+		t.Errorf("first batch kept %v, want the known issue absorbed", got)
+	}
+	if got := matcher.Filter([]result.Issue{issue("a.go", 5, "unused")}); len(got) != 1 {
+		t.Errorf("second batch kept %d issues, want the second copy", len(got))
+	}
+	if got := known.Matcher().Filter([]result.Issue{issue("a.go", 5, "unused")}); len(got) != 0 {
+		t.Error("a new matcher starts from the baseline counts")
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := New([]result.Issue{issue("a.go", 1, "unused")}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Filter([]result.Issue{issue("a.go", 3, "unused")})) != 0 {
+		t.Error("loaded baseline did not absorb the recorded issue")
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "fingerprints": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load of a version 1 baseline expected an error")
+	}
+}
@@ -0,0 +1,204 @@
+// Package bitbucket publishes lint issues as a Code Insights report with
+// inline annotations on a commit, on Bitbucket Cloud or Bitbucket Server.
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/forge"
+)
+
+// DefaultBaseURL is the REST endpoint of Bitbucket Cloud.
+const DefaultBaseURL = "https://api.bitbucket.org/2.0"
+
+const (
+	// ReportID identifies our report among those of other tools.
+	ReportID = "linterdiff"
+	// MaxAnnotations is how many annotations Bitbucket keeps per report.
+	MaxAnnotations = 1000
+)
+
+var repositoryPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)$`)
+
+// Repository names a repository as workspace/repo on Bitbucket Cloud, or
+// project/repo on Bitbucket Server.
+type Repository struct {
+	Owner string
+	Slug  string
+}
+
+// ParseRepository reads "workspace/repo".
+func ParseRepository(s string) (Repository, error) {
+	match := repositoryPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Repository{}, fmt.Errorf("malformed repository %q, expected workspace/repo", s)
+	}
+	return Repository{Owner: match[1], Slug: match[2]}, nil
+}
+
+func (r Repository) String() string {
+	return r.Owner + "/" + r.Slug
+}
+
+// Client talks to the Code Insights API of Bitbucket Cloud, or of a
+// Bitbucket Server once SetServerURL is called.
+type Client struct {
+	baseURL string
+	server  bool
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for Bitbucket Cloud authenticating with token.
+func NewClient(token string) *Client {
+	return &Client{
+		baseURL: DefaultBaseURL,
+		token:   token,
+		http:    http.DefaultClient,
+	}
+}
+
+// SetBaseURL points the client at another Bitbucket Cloud API endpoint.
+func (c *Client) SetBaseURL(url string) *Client {
+	c.baseURL = strings.TrimRight(url, "/")
+	c.server = false
+	return c
+}
+
+// SetServerURL switches the client to the Bitbucket Server or Data Center
+// instance at url, whose API differs from the Cloud one.
+func (c *Client) SetServerURL(url string) *Client {
+	c.baseURL = strings.TrimRight(url, "/") + "/rest/insights/1.0"
+	c.server = true
+	return c
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (c *Client) SetHTTPClient(client *http.Client) *Client {
+	c.http = client
+	return c
+}
+
+// Publish replaces our report on commit with one annotation per issue,
+// whose paths must be relative to the repository root. total counts the
+// issues found, which issues may be the first of. The report fails unless
+// passed. Only the first MaxAnnotations issues are annotated; it returns
+// how many were.
+func (c *Client) Publish(repo Repository, commit string, issues []result.Issue, total int, passed bool) (int, error) {
+	// Replacing a report keeps its annotations, so start afresh.
+	if err := c.do(http.MethodDelete, c.reportPath(repo, commit), nil); err != nil && !errors.Is(err, forge.ErrNotFound) {
+		return 0, err
+	}
+
+	if len(issues) > MaxAnnotations {
+		issues = issues[:MaxAnnotations]
+	}
+	details := fmt.Sprintf("%d issue(s) on changed lines", total)
+	if len(issues) < total {
+		details += fmt.Sprintf(", the first %d annotated", len(issues))
+	}
+	if err := c.do(http.MethodPut, c.reportPath(repo, commit), c.report(details, total, passed)); err != nil {
+		return 0, err
+	}
+	if len(issues) == 0 {
+		return 0, nil
+	}
+
+	annotations := c.annotations(issues)
+	// Bitbucket Cloud takes at most 100 annotations per request.
+	batch := len(annotations)
+	if !c.server {
+		batch = 100
+	}
+	for start := 0; start < len(annotations); start += batch {
+		end := start + batch
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		var body interface{} = annotations[start:end]
+		if c.server {
+			body = map[string]interface{}{"annotations": annotations[start:end]}
+		}
+		if err := c.do(http.MethodPost, c.reportPath(repo, commit)+"/annotations", body); err != nil {
+			return start, err
+		}
+	}
+	return len(issues), nil
+}
+
+func (c *Client) report(details string, count int, passed bool) map[string]interface{} {
+	data := []map[string]interface{}{{"title": "Issues", "type": "NUMBER", "value": count}}
+	if c.server {
+		status := "FAIL"
+		if passed {
+			status = "PASS"
+		}
+		return map[string]interface{}{
+			"title": "Lint", "details": details, "reporter": ReportID, "result": status, "data": data,
+		}
+	}
+	status := "FAILED"
+	if passed {
+		status = "PASSED"
+	}
+	return map[string]interface{}{
+		"title": "Lint", "details": details, "reporter": ReportID, "report_type": "BUG", "result": status, "data": data,
+	}
+}
+
+func (c *Client) annotations(issues []result.Issue) []map[string]interface{} {
+	annotations := make([]map[string]interface{}, 0, len(issues))
+	occurrences := fingerprint.Occurrences(issues)
+	for i, issue := range issues {
+		id := fmt.Sprintf("%s-%d", occurrences[i].Hash, occurrences[i].Index)
+
+		message := issue.FromLinter + ": " + issue.Text
+		path := filepath.ToSlash(issue.FilePath())
+		if c.server {
+			annotations = append(annotations, map[string]interface{}{
+				"externalId": id, "path": path, "line": issue.Line(),
+				"message": message, "severity": severity(issue), "type": "CODE_SMELL",
+			})
+			continue
+		}
+		annotations = append(annotations, map[string]interface{}{
+			"external_id": id, "path": path, "line": issue.Line(),
+			"summary": message, "severity": severity(issue), "annotation_type": "CODE_SMELL",
+		})
+	}
+	return annotations
+}
+
+// severity maps golangci-lint severities onto those of Code Insights.
+func severity(issue result.Issue) string {
+	switch strings.ToLower(issue.Severity) {
+	case "error":
+		return "HIGH"
+	case "info", "note":
+		return "LOW"
+	default:
+		return "MEDIUM"
+	}
+}
+
+func (c *Client) reportPath(repo Repository, commit string) string {
+	if c.server {
+		return fmt.Sprintf("/projects/%s/repos/%s/commits/%s/reports/%s", repo.Owner, repo.Slug, commit, ReportID)
+	}
+	return fmt.Sprintf("/repositories/%s/%s/commit/%s/reports/%s", repo.Owner, repo.Slug, commit, ReportID)
+}
+
+func (c *Client) do(method, path string, in interface{}) error {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Authorization", "Bearer "+c.token)
+	return forge.Do(c.http, method, c.baseURL, path, header, in, nil)
+}
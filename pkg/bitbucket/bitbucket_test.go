@@ -0,0 +1,157 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestParseRepository(t *testing.T) {
+	repo, err := ParseRepository("metailurini/linter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo != (Repository{Owner: "metailurini", Slug: "linter"}) || repo.String() != "metailurini/linter" {
+		t.Errorf("ParseRepository = %+v", repo)
+	}
+
+	for _, bad := range []string{"", "linter", "a/b/c", "a/b#1"} {
+		if _, err := ParseRepository(bad); err == nil {
+			t.Errorf("ParseRepository(%q) expected an error", bad)
+		}
+	}
+}
+
+// fakeBitbucket records the requests made to one report.
+type fakeBitbucket struct {
+	mu          sync.Mutex
+	report      map[string]interface{}
+	annotations []map[string]interface{}
+	requests    []string
+}
+
+func (f *fakeBitbucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+		return
+	}
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	var body interface{}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case r.Method == http.MethodDelete && !strings.HasSuffix(r.URL.Path, "/annotations"):
+		if f.report == nil {
+			http.NotFound(w, r)
+			return
+		}
+		f.report, f.annotations = nil, nil
+	case r.Method == http.MethodPut:
+		f.report = body.(map[string]interface{})
+	case r.Method == http.MethodPost:
+		if wrapped, ok := body.(map[string]interface{}); ok {
+			body = wrapped["annotations"]
+		}
+		for _, a := range body.([]interface{}) {
+			f.annotations = append(f.annotations, a.(map[string]interface{}))
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func issues(n int) []result.Issue {
+	list := make([]result.Issue, n)
+	for i := range list {
+		list[i] = result.Issue{
+			FromLinter: "errcheck",
+			Text:       fmt.Sprintf("unchecked error %c", 'a'+i%26),
+			Severity:   "error",
+			Pos:        token.Position{Filename: "pkg/a.go", Line: i + 1},
+		}
+	}
+	return list
+}
+
+func TestPublishCloud(t *testing.T) {
+	fake := &fakeBitbucket{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient("secret").SetBaseURL(server.URL)
+	repo := Repository{Owner: "ws", Slug: "repo"}
+
+	annotated, err := client.Publish(repo, "abc123", issues(150), 150, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotated != 150 || len(fake.annotations) != 150 {
+		t.Fatalf("annotated %d, server has %d; want 150", annotated, len(fake.annotations))
+	}
+	report := "/repositories/ws/repo/commit/abc123/reports/linterdiff"
+	want := []string{"DELETE " + report, "PUT " + report, "POST " + report + "/annotations", "POST " + report + "/annotations"}
+	if strings.Join(fake.requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(fake.requests, "\n"), strings.Join(want, "\n"))
+	}
+	if fake.report["result"] != "FAILED" || fake.report["report_type"] != "BUG" {
+		t.Errorf("report = %v", fake.report)
+	}
+	a := fake.annotations[0]
+	if a["path"] != "pkg/a.go" || a["line"] != 1.0 || a["severity"] != "HIGH" || a["summary"] != "errcheck: unchecked error a" {
+		t.Errorf("annotation = %v", a)
+	}
+
+	// A re-run replaces the report and its annotations.
+	if _, err := client.Publish(repo, "abc123", nil, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.annotations) != 0 || fake.report["result"] != "PASSED" {
+		t.Errorf("re-run left %d annotations, report %v", len(fake.annotations), fake.report)
+	}
+}
+
+func TestPublishServer(t *testing.T) {
+	fake := &fakeBitbucket{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient("secret").SetServerURL(server.URL)
+	annotated, err := client.Publish(Repository{Owner: "PROJ", Slug: "repo"}, "abc123", issues(MaxAnnotations+5), MaxAnnotations+5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotated != MaxAnnotations || len(fake.annotations) != MaxAnnotations {
+		t.Errorf("annotated %d, server has %d; want %d", annotated, len(fake.annotations), MaxAnnotations)
+	}
+	report := "/rest/insights/1.0/projects/PROJ/repos/repo/commits/abc123/reports/linterdiff"
+	if fake.requests[1] != "PUT "+report || fake.report["result"] != "FAIL" {
+		t.Errorf("requests %v, report %v", fake.requests, fake.report)
+	}
+	if !strings.Contains(fake.report["details"].(string), "1005 issue(s) on changed lines, the first 1000 annotated") {
+		t.Errorf("details = %v", fake.report["details"])
+	}
+	if a := fake.annotations[0]; a["message"] != "errcheck: unchecked error a" || a["externalId"] == nil {
+		t.Errorf("annotation = %v", a)
+	}
+}
+
+func TestPublishReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeBitbucket{})
+	defer server.Close()
+
+	_, err := NewClient("wrong").SetBaseURL(server.URL).Publish(Repository{Owner: "ws", Slug: "repo"}, "abc123", nil, 0, true)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Publish error = %v, want a 401", err)
+	}
+}
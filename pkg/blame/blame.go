@@ -0,0 +1,159 @@
+// Package blame finds who last changed lines of a file, with git blame.
+package blame
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// uncommitted is the commit git blame gives lines changed in the working
+// tree only.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// annotationPattern matches what Annotate appends to a message.
+var annotationPattern = regexp.MustCompile(` \[blame: [^\]]*\]$`)
+
+// Author is who last changed a line.
+type Author struct {
+	Name  string
+	Email string
+}
+
+func (a Author) String() string {
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// Matches reports whether who is the email or the name of a, ignoring case.
+func (a Author) Matches(who string) bool {
+	return strings.EqualFold(who, a.Email) || strings.EqualFold(who, a.Name)
+}
+
+// Annotate appends author to an issue message.
+func Annotate(text string, author Author) string {
+	return text + " [blame: " + author.String() + "]"
+}
+
+// StripAnnotation removes what Annotate appended to text, if anything.
+func StripAnnotation(text string) string {
+	return annotationPattern.ReplaceAllString(text, "")
+}
+
+// Lines returns the authors of lines of file, relative to dir, as it is in
+// the working tree. Lines not committed yet belong to the user configured
+// in git, who is about to commit them.
+func Lines(ctx context.Context, dir, file string, lines []int) (map[int]Author, error) {
+	unique := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		if line > 0 {
+			unique[line] = true
+		}
+	}
+	if len(unique) == 0 {
+		return map[int]Author{}, nil
+	}
+	sorted := make([]int, 0, len(unique))
+	for line := range unique {
+		sorted = append(sorted, line)
+	}
+	sort.Ints(sorted)
+
+	cmd := command.New("git", "blame", "--line-porcelain").SetContext(ctx).SetDir(dir)
+	for _, line := range sorted {
+		cmd.AppendArgs("-L", fmt.Sprintf("%d,%d", line, line))
+	}
+	output, err := cmd.AppendArgs("--", file).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", file, err)
+	}
+
+	authors, err := parsePorcelain(output)
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", file, err)
+	}
+	for line, author := range authors {
+		if author.Email != uncommitted {
+			continue
+		}
+		user, err := CurrentUser(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		authors[line] = user
+	}
+	return authors, nil
+}
+
+// CurrentUser returns the author git records for new commits in dir.
+func CurrentUser(ctx context.Context, dir string) (Author, error) {
+	var user Author
+	for key, field := range map[string]*string{"user.name": &user.Name, "user.email": &user.Email} {
+		// git config exits with 1 when the key is not set.
+		output, _ := command.New("git", "config", "--get", key).SetContext(ctx).SetDir(dir).Output()
+		*field = strings.TrimSpace(string(output))
+	}
+	if ctx.Err() != nil {
+		return Author{}, ctx.Err()
+	}
+	return user, nil
+}
+
+// parsePorcelain reads git blame --line-porcelain, where every line starts
+// with "<commit> <original line> <final line>" and its full commit details.
+// Uncommitted lines are returned with the email uncommitted.
+func parsePorcelain(output []byte) (map[int]Author, error) {
+	authors := make(map[int]Author)
+	var (
+		commit string
+		line   int
+		author Author
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// The content closes the entry of a line.
+			if commit == uncommitted {
+				author = Author{Email: uncommitted}
+			}
+			authors[line] = author
+		case strings.HasPrefix(text, "author "):
+			author.Name = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-mail "):
+			author.Email = strings.Trim(strings.TrimPrefix(text, "author-mail "), "<>")
+		default:
+			fields := strings.Fields(text)
+			if len(fields) < 3 || !isCommit(fields[0]) {
+				continue
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed line %q", text)
+			}
+			commit, line, author = fields[0], n, Author{}
+		}
+	}
+	return authors, scanner.Err()
+}
+
+// isCommit reports whether s is a full SHA-1 or SHA-256 commit hash.
+func isCommit(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package blame
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func TestLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	git(t, dir, "config", "commit.gpgsign", "false")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("package a\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "-c", "user.name=Ada", "-c", "user.email=ada@example.com", "commit", "-q", "-m", "first")
+	write("package a\n\nvar X = 1\n")
+	git(t, dir, "-c", "user.name=Bob", "-c", "user.email=bob@example.com", "commit", "-q", "-am", "second")
+	write("package a\n\nvar X = 1\nvar Y = 2\n")
+	git(t, dir, "config", "user.name", "Me")
+	git(t, dir, "config", "user.email", "me@example.com")
+
+	authors, err := Lines(context.Background(), dir, "a.go", []int{4, 1, 3, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]Author{
+		1: {Name: "Ada", Email: "ada@example.com"},
+		3: {Name: "Bob", Email: "bob@example.com"},
+		4: {Name: "Me", Email: "me@example.com"},
+	}
+	for line, author := range want {
+		if authors[line] != author {
+			t.Errorf("line %d: author = %v, want %v", line, authors[line], author)
+		}
+	}
+	if len(authors) != len(want) {
+		t.Errorf("authors = %v, want only lines 1, 3 and 4", authors)
+	}
+}
+
+func TestAuthorMatches(t *testing.T) {
+	a := Author{Name: "Ada Lovelace", Email: "ada@example.com"}
+	for who, want := range map[string]bool{
+		"ada@example.com": true,
+		"ADA@example.com": true,
+		"ada lovelace":    true,
+		"ada":             false,
+	} {
+		if got := a.Matches(who); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", who, got, want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	text := Annotate("x is unused", Author{Name: "Ada", Email: "ada@example.com"})
+	if text != "x is unused [blame: Ada <ada@example.com>]" {
+		t.Errorf("Annotate = %q", text)
+	}
+	if got := StripAnnotation(text); got != "x is unused" {
+		t.Errorf("StripAnnotation = %q", got)
+	}
+	if got := StripAnnotation("index [blame: x] out of range"); got != "index [blame: x] out of range" {
+		t.Errorf("StripAnnotation changed a message without annotation: %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+// Package budget holds areas of the repository to a number of issues, so
+// that messy code is kept from getting worse while it waits for a cleanup.
+package budget
+
+import (
+	"sort"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/filter"
+)
+
+// Usage is how many issues a budget allows under Paths and how many there
+// are.
+type Usage struct {
+	Paths string
+	Max   int
+	Count int
+}
+
+// Over reports whether there are more issues than the budget allows.
+func (u Usage) Over() bool {
+	return u.Count > u.Max
+}
+
+// Check counts the issues under each of the budgets, which map a glob of
+// paths to the most issues allowed there, with the rules of
+// filter.ExcludePaths. An issue counts against every budget it falls
+// under, so a budget nested in another does not lift the outer one. The
+// usages are sorted by paths.
+func Check(budgets map[string]int, issues []result.Issue) []Usage {
+	usages := make([]Usage, 0, len(budgets))
+	for paths, max := range budgets {
+		usage := Usage{Paths: paths, Max: max}
+		for _, issue := range issues {
+			if filter.MatchPath(issue.FilePath(), paths) {
+				usage.Count++
+			}
+		}
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Paths < usages[j].Paths })
+	return usages
+}
@@ -0,0 +1,45 @@
+package budget
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestCheck(t *testing.T) {
+	var issues []result.Issue
+	for _, file := range []string{
+		"internal/legacy/a.go", "internal/legacy/a.go", "internal/legacy/old/b.go",
+		"internal/api/c.go", "cmd/main.go",
+	} {
+		issues = append(issues, result.Issue{Pos: token.Position{Filename: file, Line: 1}})
+	}
+	budgets := map[string]int{
+		"internal/legacy/**": 3,
+		"internal":           3,
+		"cmd/*.go":           0,
+		"tools/**":           5,
+	}
+
+	got := Check(budgets, issues)
+	want := []Usage{
+		{Paths: "cmd/*.go", Max: 0, Count: 1},
+		{Paths: "internal", Max: 3, Count: 4},
+		{Paths: "internal/legacy/**", Max: 3, Count: 3},
+		{Paths: "tools/**", Max: 5, Count: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %+v, want %+v", got, want)
+	}
+	var over []string
+	for _, usage := range got {
+		if usage.Over() {
+			over = append(over, usage.Paths)
+		}
+	}
+	if want := []string{"cmd/*.go", "internal"}; !reflect.DeepEqual(over, want) {
+		t.Errorf("over budget: %q, want %q", over, want)
+	}
+}
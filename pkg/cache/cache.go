@@ -0,0 +1,152 @@
+// Package cache keeps the issues of earlier lint runs per package
+// directory, keyed by the content of its Go files, those of its
+// dependencies and the lint settings.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Cache is a directory of cached lint results.
+type Cache struct {
+	dir string
+}
+
+// DefaultDir is the linter directory under the user cache directory, such
+// as ~/.cache/linter.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "linter"), nil
+}
+
+// New returns the cache kept in dir, created on the first Put.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Settings hashes everything besides the package itself that the issues
+// depend on, such as the golangci-lint version, its configuration and flags.
+func Settings(values ...string) string {
+	h := sha256.New()
+	for _, value := range values {
+		io.WriteString(h, value)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PackageKey hashes settings with the names and contents of the Go files in
+// dir and in the directories of the packages it depends on, so that editing,
+// adding or removing any of them changes the key.
+func PackageKey(settings, dir string, deps ...string) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, settings)
+	if err := hashGoFiles(h, dir); err != nil {
+		return "", err
+	}
+	for _, dep := range deps {
+		io.WriteString(h, "\x00"+dep)
+		if err := hashGoFiles(h, dep); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashGoFiles writes the names and hashed contents of the Go files in dir
+// to h, in the order of their names.
+func hashGoFiles(h hash.Hash, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		io.WriteString(h, "\x00"+name+"\x00")
+		h.Write(sum[:])
+	}
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the issues stored under key. Unreadable entries are misses.
+func (c *Cache) Get(key string) ([]result.Issue, bool) {
+	content, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var issues []result.Issue
+	if err := json.Unmarshal(content, &issues); err != nil {
+		return nil, false
+	}
+	return issues, true
+}
+
+// Put stores issues under key. The entry is written to a temporary file
+// first, so a concurrent Get never reads half of it.
+func (c *Cache) Put(key string, issues []result.Issue) error {
+	if issues == nil {
+		issues = []result.Issue{}
+	}
+	content, err := json.Marshal(issues)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Clean removes every cached result.
+func (c *Cache) Clean() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
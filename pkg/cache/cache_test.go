@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestPackageKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := func(settings string) string {
+		t.Helper()
+		k, err := PackageKey(settings, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	write("a.go", "package a\n")
+	write("README.md", "docs\n")
+	settings := Settings("golangci-lint 1.55", "--fast")
+	first := key(settings)
+
+	write("README.md", "more docs\n")
+	if key(settings) != first {
+		t.Error("a non-Go file changed the key")
+	}
+	if key(Settings("golangci-lint 1.56", "--fast")) == first {
+		t.Error("other settings kept the key")
+	}
+	write("b.go", "package a\n")
+	second := key(settings)
+	if second == first {
+		t.Error("a new file kept the key")
+	}
+	write("b.go", "package a\n\nvar X int\n")
+	third := key(settings)
+	if third == second {
+		t.Error("an edit kept the key")
+	}
+
+	dep := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dep, "c.go"), []byte("package c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withDep, err := PackageKey(settings, dir, dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withDep == third {
+		t.Error("a dependency kept the key")
+	}
+	if err := os.WriteFile(filepath.Join(dep, "c.go"), []byte("package c\n\nvar Y int\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if edited, err := PackageKey(settings, dir, dep); err != nil || edited == withDep {
+		t.Errorf("an edit of a dependency kept the key: %v", err)
+	}
+}
+
+func TestGetPutClean(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "linter"))
+	key := Settings("key")
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get hit an empty cache")
+	}
+
+	issues := []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}}
+	if err := c.Put(key, issues); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.Get(key)
+	if !ok || len(got) != 1 || got[0].FromLinter != "errcheck" || got[0].Line() != 3 {
+		t.Errorf("Get = %v, %v", got, ok)
+	}
+
+	if err := c.Put(Settings("clean"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Get(Settings("clean")); !ok || len(got) != 0 {
+		t.Errorf("Get of a package without issues = %v, %v", got, ok)
+	}
+
+	if err := c.Clean(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(key); ok {
+		t.Error("Get hit after Clean")
+	}
+}
@@ -0,0 +1,129 @@
+// Package codeowners reads the owners of repository paths from a CODEOWNERS
+// file, as GitHub and GitLab do.
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"linter/pkg/filter"
+)
+
+// Locations are the places a CODEOWNERS file is looked for, relative to
+// the repository root, in the order GitHub uses.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type rule struct {
+	pattern string
+	// children is set for patterns ending in "/*", which match the files
+	// right inside a directory but not those deeper down.
+	children bool
+	owners   []string
+}
+
+// Owners maps paths to their owners.
+type Owners struct {
+	rules []rule
+}
+
+// Find returns the CODEOWNERS file of the repository at root, or an empty
+// string when there is none.
+func Find(root string) (string, error) {
+	for _, location := range Locations {
+		path := filepath.Join(root, filepath.FromSlash(location))
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func Load(path string) (*Owners, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	owners, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return owners, nil
+}
+
+// Parse reads CODEOWNERS rules: a pattern followed by its owners on every
+// line, with # starting a comment. GitLab [Section] headers are skipped.
+func Parse(r io.Reader) (*Owners, error) {
+	var owners Owners
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		var ruleOwners []string
+		if len(fields) > 1 {
+			ruleOwners = fields[1:]
+		}
+		owners.rules = append(owners.rules, newRule(fields[0], ruleOwners))
+	}
+	return &owners, scanner.Err()
+}
+
+// newRule turns a gitignore-style pattern into a glob from the root: a
+// pattern without a slash but at its end matches at any depth, and one
+// naming a directory matches everything below it.
+func newRule(pattern string, owners []string) rule {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if !anchored && !strings.HasPrefix(pattern, "**") {
+		pattern = "**/" + pattern
+	}
+	return rule{
+		pattern:  pattern,
+		children: strings.HasSuffix(pattern, "/*"),
+		owners:   owners,
+	}
+}
+
+func (r rule) matches(file string) bool {
+	if filter.MatchGlob(file, r.pattern) {
+		return true
+	}
+	if r.children {
+		return false
+	}
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if filter.MatchGlob(dir, r.pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Of returns the owners of file, given relative to the repository root.
+// The last matching rule wins, and may leave the file without owners.
+func (o *Owners) Of(file string) []string {
+	file = filepath.ToSlash(file)
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if o.rules[i].matches(file) {
+			return o.rules[i].owners
+		}
+	}
+	return nil
+}
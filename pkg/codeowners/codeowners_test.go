@@ -0,0 +1,68 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `
+# Default owners
+*                     @org/everyone
+*.go                  @org/gophers
+/build/               @org/release
+docs/*                @org/docs
+apps/                 @org/apps
+/internal/**/db/*.sql @org/dba
+[Platform]
+/platform/            @org/platform @alice # with a comment
+/platform/vendor/
+`
+
+func TestOf(t *testing.T) {
+	owners, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"README.md":                    {"@org/everyone"},
+		"cmd/main.go":                  {"@org/gophers"},
+		"build/ci/pipeline.go":         {"@org/release"},
+		"x/build/tool.go":              {"@org/gophers"},
+		"docs/intro.md":                {"@org/docs"},
+		"docs/guide/deep.md":           {"@org/everyone"},
+		"services/apps/web/page.go":    {"@org/apps"},
+		"internal/store/db/schema.sql": {"@org/dba"},
+		"internal/db/schema.sql":       {"@org/dba"},
+		"platform/api/server.go":       {"@org/platform", "@alice"},
+		"platform/vendor/lib/x.go":     nil,
+	}
+	for file, want := range tests {
+		if got := owners.Of(file); !reflect.DeepEqual(got, want) {
+			t.Errorf("Of(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	if got, err := Find(root); err != nil || got != "" {
+		t.Errorf("Find without a file = %q, %v", got, err)
+	}
+
+	for _, location := range []string{"docs/CODEOWNERS", ".github/CODEOWNERS"} {
+		path := filepath.Join(root, location)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("* @org/everyone\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := Find(root); err != nil || got != filepath.Join(root, ".github", "CODEOWNERS") {
+		t.Errorf("Find = %q, %v, want the .github one", got, err)
+	}
+}
@@ -0,0 +1,259 @@
+// Package command runs external programs without going through a shell.
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// waitDelay is how long a cancelled command gets to exit after being
+// interrupted.
+const waitDelay = 5 * time.Second
+
+// Command is an external program with its arguments and working directory.
+type Command struct {
+	ctx  context.Context
+	name string
+	args []string
+	dir  string
+	env  []string
+	// inherit names the variables of the current environment the command
+	// gets when isolated, instead of all of them.
+	inherit  []string
+	isolated bool
+	// unset names the variables of the current environment the command
+	// goes without.
+	unset  []string
+	stdin  io.Reader
+	stderr io.Writer
+}
+
+// New returns a command running name with args in the current directory.
+func New(name string, args ...string) *Command {
+	return &Command{
+		ctx:  context.Background(),
+		name: name,
+		args: args,
+	}
+}
+
+// Parse splits a command line into its program and arguments the way
+// a POSIX shell would for simple words and quotes, without invoking a shell.
+// Backslashes are kept literally on Windows so native paths survive.
+func Parse(line string) (*Command, error) {
+	words, err := Split(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return New(words[0], words[1:]...), nil
+}
+
+// Split breaks line into words with the quoting rules of Parse.
+func Split(line string) ([]string, error) {
+	return splitWords(line, runtime.GOOS == "windows")
+}
+
+// SetContext makes the command stop when ctx is done: it is interrupted
+// first, so it can clean up its own children, and killed if it does not
+// exit within waitDelay.
+func (c *Command) SetContext(ctx context.Context) *Command {
+	c.ctx = ctx
+	return c
+}
+
+// SetDir sets the working directory of the command.
+func (c *Command) SetDir(dir string) *Command {
+	c.dir = dir
+	return c
+}
+
+// SetEnv adds the key=value pairs env to the environment of the command.
+// Unlike arguments, they are left out of logs and errors, so they suit
+// secrets.
+func (c *Command) SetEnv(env ...string) *Command {
+	c.env = append(c.env, env...)
+	return c
+}
+
+// SetIsolated starts the environment of the command from the variables
+// named inherit alone, those of the current environment that are set,
+// before the pairs of SetEnv.
+func (c *Command) SetIsolated(inherit ...string) *Command {
+	c.inherit = inherit
+	c.isolated = true
+	return c
+}
+
+// UnsetEnv removes the variables named keys from the environment the
+// command inherits. Pairs of SetEnv are kept.
+func (c *Command) UnsetEnv(keys ...string) *Command {
+	c.unset = append(c.unset, keys...)
+	return c
+}
+
+// SetStdin feeds r to the command as its standard input.
+func (c *Command) SetStdin(r io.Reader) *Command {
+	c.stdin = r
+	return c
+}
+
+// SetStderr also copies what the command writes to stderr into w, on top of
+// keeping it for the error returned by Output.
+func (c *Command) SetStderr(w io.Writer) *Command {
+	c.stderr = w
+	return c
+}
+
+// AppendArgs adds args after the existing arguments.
+func (c *Command) AppendArgs(args ...string) *Command {
+	c.args = append(c.args, args...)
+	return c
+}
+
+// String renders the command line for messages.
+func (c *Command) String() string {
+	return strings.Join(append([]string{c.name}, c.args...), " ")
+}
+
+// Output runs the command and returns its stdout. A failure is reported as
+// an *Error carrying the command line and stderr.
+func (c *Command) Output() ([]byte, error) {
+	cmd := exec.CommandContext(c.ctx, c.name, c.args...)
+	cmd.Dir = c.dir
+	cmd.Stdin = c.stdin
+	switch {
+	case c.isolated:
+		// A nil Env would inherit everything.
+		env := []string{}
+		for _, key := range c.inherit {
+			if value, ok := os.LookupEnv(key); ok {
+				env = append(env, key+"="+value)
+			}
+		}
+		cmd.Env = append(env, c.env...)
+	case len(c.env) > 0 || len(c.unset) > 0:
+		env := []string{}
+		for _, pair := range os.Environ() {
+			key, _, _ := strings.Cut(pair, "=")
+			if !slices.Contains(c.unset, key) {
+				env = append(env, pair)
+			}
+		}
+		cmd.Env = append(env, c.env...)
+	}
+	cmd.Cancel = func() error {
+		return interrupt(cmd.Process)
+	}
+	cmd.WaitDelay = waitDelay
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if c.stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, c.stderr)
+	}
+	start := time.Now()
+	output, err := cmd.Output()
+	slog.Debug("command finished", "cmd", c.String(), "dir", c.dir,
+		"took", time.Since(start).Round(time.Millisecond), "err", err)
+	if ctxErr := c.ctx.Err(); ctxErr != nil && err != nil {
+		return output, &Error{Command: c.String(), Err: ctxErr}
+	}
+	if err != nil {
+		return output, &Error{
+			Command: c.String(),
+			Stderr:  strings.TrimSpace(stderr.String()),
+			Err:     err,
+		}
+	}
+	return output, nil
+}
+
+// Error reports a failed command together with what it wrote to stderr.
+type Error struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("%s: %v", e.Command, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: %s", e.Command, e.Err, e.Stderr)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// splitWords follows POSIX quoting: single quotes keep everything literally,
+// and inside double quotes a backslash only escapes $, `, ", \ and newline.
+// With literalBackslash set, as on Windows, backslashes never escape.
+func splitWords(line string, literalBackslash bool) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", r) {
+				word.WriteRune('\\')
+			}
+			if r != '\n' {
+				word.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\' && quote != '\'' && !literalBackslash:
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name             string
+		line             string
+		literalBackslash bool
+		want             []string
+	}{
+		{name: "plain words", line: "git diff  HEAD", want: []string{"git", "diff", "HEAD"}},
+		{name: "empty line", line: "   ", want: nil},
+		{name: "single quotes", line: `git show 'a b'`, want: []string{"git", "show", "a b"}},
+		{name: "double quotes", line: `git show "a b"`, want: []string{"git", "show", "a b"}},
+		{name: "adjacent quoted parts", line: `a'b'"c"d`, want: []string{"abcd"}},
+		{name: "empty single quoted word", line: `git diff ''`, want: []string{"git", "diff", ""}},
+		{name: "empty double quoted word", line: `git diff ""`, want: []string{"git", "diff", ""}},
+		{name: "escaped space", line: `cat a\ b`, want: []string{"cat", "a b"}},
+		{name: "escaped quote", line: `echo \"x`, want: []string{"echo", `"x`}},
+		{name: "backslash literal in single quotes", line: `echo '\n'`, want: []string{"echo", `\n`}},
+		{name: "double quotes escape special", line: `echo "\" \\ \$ \x"`, want: []string{"echo", `" \ $ \x`}},
+		{name: "line continuation", line: "git \\\ndiff", want: []string{"git", "diff"}},
+		{
+			name:             "windows backslash path",
+			line:             `git diff -- C:\repo\main.go "C:\Program Files\x"`,
+			literalBackslash: true,
+			want:             []string{"git", "diff", "--", `C:\repo\main.go`, `C:\Program Files\x`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitWords(tt.line, tt.literalBackslash)
+			if err != nil {
+				t.Fatalf("splitWords(%q) error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWords(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWordsErrors(t *testing.T) {
+	for _, line := range []string{`git 'diff`, `git "diff`, `git diff\`} {
+		if _, err := splitWords(line, false); err == nil {
+			t.Errorf("splitWords(%q) expected an error", line)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if _, err := Parse("  "); err == nil {
+		t.Error("Parse of a blank line expected an error")
+	}
+}
+
+func TestOutputIncludesStderr(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	_, err := New("go", "no-such-subcommand").Output()
+	var cmdErr *Error
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("Output error = %v, want *Error", err)
+	}
+	if cmdErr.Command != "go no-such-subcommand" {
+		t.Errorf("Command = %q", cmdErr.Command)
+	}
+	if !strings.Contains(err.Error(), "no-such-subcommand") || cmdErr.Stderr == "" {
+		t.Errorf("error %q does not carry stderr", err)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("error does not unwrap to *exec.ExitError")
+	}
+}
+
+func TestOutputReadsStdin(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat binary not available")
+	}
+
+	output, err := New("cat").SetStdin(strings.NewReader("fed in")).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "fed in" {
+		t.Errorf("Output = %q, want the input back", output)
+	}
+}
+
+func TestOutputSetsEnv(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh binary not available")
+	}
+
+	cmd := New("sh", "-c", `printf %s "$SECRET"`).SetEnv("SECRET=hunter2")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hunter2" || strings.Contains(cmd.String(), "hunter2") {
+		t.Errorf("Output = %q, command %q", output, cmd.String())
+	}
+}
+
+func TestOutputIsolatesEnv(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh binary not available")
+	}
+	t.Setenv("GOFLAGS", "-tags=leaked")
+	t.Setenv("KEPT", "yes")
+
+	output, err := New("/bin/sh", "-c", `printf '%s|%s|%s' "$GOFLAGS" "$KEPT" "$GOCACHE"`).
+		SetEnv("GOCACHE=/tmp/cache").
+		SetIsolated("KEPT", "UNSET").
+		Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "|yes|/tmp/cache" {
+		t.Errorf("Output = %q, want only KEPT and GOCACHE set", output)
+	}
+}
+
+func TestOutputUnsetsEnv(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh binary not available")
+	}
+	t.Setenv("GIT_DIR", "/elsewhere/.git")
+	t.Setenv("KEPT", "yes")
+
+	output, err := New("/bin/sh", "-c", `printf '%s|%s|%s' "${GIT_DIR-unset}" "$KEPT" "$GOCACHE"`).
+		UnsetEnv("GIT_DIR").
+		SetEnv("GOCACHE=/tmp/cache").
+		Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "unset|yes|/tmp/cache" {
+		t.Errorf("Output = %q, want GIT_DIR unset and the rest kept", output)
+	}
+}
+
+func TestOutputStopsWhenContextIsDone(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep binary not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New("sleep", "10").SetContext(ctx).Output()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Output error = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Output returned after %s", elapsed)
+	}
+}
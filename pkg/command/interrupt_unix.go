@@ -0,0 +1,9 @@
+//go:build !windows
+
+package command
+
+import "os"
+
+func interrupt(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
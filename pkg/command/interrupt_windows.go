@@ -0,0 +1,8 @@
+package command
+
+import "os"
+
+// Windows cannot deliver an interrupt to another process, so it is killed.
+func interrupt(p *os.Process) error {
+	return p.Kill()
+}
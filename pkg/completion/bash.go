@@ -0,0 +1,54 @@
+package completion
+
+import "text/template"
+
+var bashTemplate = template.Must(template.New("bash").Funcs(funcs).Parse(`# bash completion for {{.Name}}
+# Load it with: source <({{.Name}} completion bash)
+
+{{funcName .Name}}() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local cmd={{shQuote .Name}} i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		[[ ${COMP_WORDS[i]} == -- ]] && return
+		case "$cmd ${COMP_WORDS[i]}" in
+{{- range .Commands}}{{if ne .Path $.Name}}
+		{{shQuote .Path}}) cmd={{shQuote .Path}} ;;
+{{- end}}{{end}}
+		esac
+	done
+
+	case "$prev" in
+{{- range .Values}}
+	{{.Name}}) COMPREPLY=($(compgen -W {{shQuote (join .Values " ")}} -- "$cur")); return ;;
+{{- end}}
+{{- if .Files}}
+	{{join .Files "|"}}) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+{{- end}}
+{{- if .Other}}
+	{{join .Other "|"}}) return ;;
+{{- end}}
+	esac
+
+	case "$cmd" in
+{{- range .Commands}}
+	{{shQuote .Path}})
+		if [[ $cur == -* ]]; then
+			COMPREPLY=($(compgen -W {{shQuote (flagWords .)}} -- "$cur"))
+{{- if .Args}}
+		else
+			COMPREPLY=($(compgen -W {{shQuote (join .Args " ")}} -- "$cur"))
+{{- else if .FileArgs}}
+		else
+			COMPREPLY=($(compgen -f -- "$cur"))
+{{- else if .Subcommands}}
+		else
+			COMPREPLY=($(compgen -W {{shQuote (subcommandWords .)}} -- "$cur"))
+{{- end}}
+		fi
+		;;
+{{- end}}
+	esac
+}
+
+complete -o default -F {{funcName .Name}} {{.Name}}
+`))
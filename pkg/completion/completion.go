@@ -0,0 +1,257 @@
+// Package completion writes shell completion scripts for a command line
+// declared with go-arg struct tags.
+package completion
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Shells are the shells Write has a script for.
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// Command is a command or subcommand with what may follow it.
+type Command struct {
+	Name        string
+	Help        string
+	Flags       []Flag
+	Subcommands []Command
+	// Args are the values a positional argument takes; with Files set and
+	// no Args, it takes a file.
+	Args  []string
+	Files bool
+}
+
+// Flag is an option of a command. Flags of a command are also accepted
+// after its subcommands, as go-arg does.
+type Flag struct {
+	// Long and Short include their dashes, such as --out and -c.
+	Long, Short string
+	Help        string
+	// TakesValue is unset for booleans. A value is one of Values, or a
+	// file when Files is set, as it is for text without Values.
+	TakesValue bool
+	Values     []string
+	Files      bool
+}
+
+// FromStruct reads the flags and subcommands of the go-arg destination
+// dest, a pointer to a struct. values gives the accepted values of flags by
+// their long name, and of the positional argument of a subcommand by its
+// name. The --help flag go-arg adds is listed too, and --version on the
+// top command when versioned is set.
+func FromStruct(name string, dest any, values map[string][]string, versioned bool) Command {
+	cmd := fromType(name, "", reflect.TypeOf(dest).Elem(), values)
+	if versioned {
+		cmd.Flags = append(cmd.Flags, Flag{Long: "--version", Help: "display version and exit"})
+	}
+	return cmd
+}
+
+func fromType(name, help string, t reflect.Type, values map[string][]string) Command {
+	cmd := Command{Name: name, Help: help}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("arg")
+		if tag == "-" {
+			continue
+		}
+
+		flag := Flag{
+			Long:       "--" + strings.ToLower(field.Name),
+			Help:       field.Tag.Get("help"),
+			TakesValue: field.Type.Kind() != reflect.Bool,
+		}
+		positional := false
+		for _, part := range strings.Split(tag, ",") {
+			key, value, _ := strings.Cut(part, ":")
+			switch {
+			case key == "subcommand":
+				sub := fromType(value, flag.Help, field.Type.Elem(), values)
+				cmd.Subcommands = append(cmd.Subcommands, sub)
+			case key == "positional":
+				positional = true
+			case strings.HasPrefix(key, "--"):
+				flag.Long = key
+			case strings.HasPrefix(key, "-"):
+				flag.Short = key
+			}
+		}
+		switch {
+		case strings.HasPrefix(tag, "subcommand:"):
+		case positional:
+			cmd.Args = values[name]
+			cmd.Files = len(cmd.Args) == 0
+		default:
+			if flag.TakesValue {
+				flag.Values = values[flag.Long]
+				flag.Files = len(flag.Values) == 0 && isText(field.Type)
+			}
+			cmd.Flags = append(cmd.Flags, flag)
+		}
+	}
+	cmd.Flags = append(cmd.Flags, Flag{Long: "--help", Short: "-h", Help: "display this help and exit"})
+	return cmd
+}
+
+// isText reports whether a flag of type t takes free text, as opposed to a
+// number or a duration.
+func isText(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// Write writes the completion script of root for shell, one of Shells.
+func Write(w io.Writer, shell string, root Command) error {
+	scripts := map[string]*template.Template{
+		"bash":       bashTemplate,
+		"zsh":        zshTemplate,
+		"fish":       fishTemplate,
+		"powershell": powershellTemplate,
+	}
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("no completion for shell %q, expected one of: %s", shell, strings.Join(Shells, ", "))
+	}
+	return script.Execute(w, newScript(root))
+}
+
+// script is what the templates see: every command path with the words
+// that may follow it, and the flags taking values.
+type script struct {
+	Name     string
+	Commands []scriptCommand
+	// Values, Files and Other list the flags taking one of some values, a
+	// file, or something else such as a number, under both their names.
+	Values []valueFlag
+	Files  []string
+	Other  []string
+}
+
+type scriptCommand struct {
+	// Path is the command with its parent commands, space separated.
+	Path        string
+	Subcommands []Command
+	Flags       []Flag
+	Args        []string
+	FileArgs    bool
+}
+
+type valueFlag struct {
+	Name   string
+	Values []string
+}
+
+func newScript(root Command) script {
+	s := script{Name: root.Name}
+	valued := make(map[string][]string)
+	files := make(map[string]bool)
+	other := make(map[string]bool)
+
+	var walk func(cmd Command, path string, inherited []Flag)
+	walk = func(cmd Command, path string, inherited []Flag) {
+		flags := append(append([]Flag(nil), cmd.Flags...), inherited...)
+		flags = uniqueFlags(flags)
+		s.Commands = append(s.Commands, scriptCommand{
+			Path:        path,
+			Subcommands: cmd.Subcommands,
+			Flags:       flags,
+			Args:        cmd.Args,
+			FileArgs:    cmd.Files,
+		})
+		for _, flag := range cmd.Flags {
+			if !flag.TakesValue {
+				continue
+			}
+			for _, name := range []string{flag.Long, flag.Short} {
+				switch {
+				case name == "":
+				case len(flag.Values) > 0:
+					valued[name] = flag.Values
+				case flag.Files:
+					files[name] = true
+				default:
+					other[name] = true
+				}
+			}
+		}
+		for _, sub := range cmd.Subcommands {
+			walk(sub, path+" "+sub.Name, flags)
+		}
+	}
+	walk(root, root.Name, nil)
+
+	for name, values := range valued {
+		s.Values = append(s.Values, valueFlag{Name: name, Values: values})
+	}
+	sort.Slice(s.Values, func(i, j int) bool { return s.Values[i].Name < s.Values[j].Name })
+	s.Files = sortedKeys(files)
+	s.Other = sortedKeys(other)
+	return s
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// uniqueFlags drops the inherited flags a command declares again.
+func uniqueFlags(flags []Flag) []Flag {
+	seen := make(map[string]bool, len(flags))
+	unique := flags[:0]
+	for _, flag := range flags {
+		if seen[flag.Long] {
+			continue
+		}
+		seen[flag.Long] = true
+		unique = append(unique, flag)
+	}
+	return unique
+}
+
+// subcommandWords lists the subcommands that may follow a command, and
+// flagWords the names of its flags.
+func (c scriptCommand) subcommandWords() string {
+	words := make([]string, 0, len(c.Subcommands))
+	for _, sub := range c.Subcommands {
+		words = append(words, sub.Name)
+	}
+	return strings.Join(words, " ")
+}
+
+func (c scriptCommand) flagWords() string {
+	words := make([]string, 0, 2*len(c.Flags))
+	for _, flag := range c.Flags {
+		words = append(words, flag.Long)
+		if flag.Short != "" {
+			words = append(words, flag.Short)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var funcs = template.FuncMap{
+	"join":            strings.Join,
+	"subcommandWords": scriptCommand.subcommandWords,
+	"flagWords":       scriptCommand.flagWords,
+	// shQuote quotes for bash and zsh, fishQuote for fish and psQuote for
+	// PowerShell, all in single quotes.
+	"shQuote":   func(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" },
+	"fishQuote": func(s string) string { return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'" },
+	"psQuote":   func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" },
+	"trim":      func(s string) string { return strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-") },
+	"funcName":  func(s string) string { return "_" + strings.NewReplacer("-", "_", " ", "_").Replace(s) },
+}
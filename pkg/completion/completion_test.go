@@ -0,0 +1,132 @@
+package completion
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOptions struct {
+	Pwd     string        `arg:"--pwd" help:"directory to run in"`
+	Cmd     string        `arg:"-c"    help:"diff command"`
+	Out     []string      `arg:"--out" help:"output format"`
+	Verbose bool          `arg:"-v,--verbose" help:"log more"`
+	Timeout time.Duration `arg:"--timeout" help:"give up after this"`
+	Extra   []string      `arg:"-"`
+	hidden  string
+
+	Report *struct {
+		HTML string `arg:"--html" help:"page to write"`
+	} `arg:"subcommand:report" help:"write a report"`
+	Completion *struct {
+		Shell string `arg:"positional"`
+	} `arg:"subcommand:completion" help:"print a completion script"`
+}
+
+func testCommand() Command {
+	values := map[string][]string{"--out": {"text", "json"}, "completion": Shells}
+	return FromStruct("tool", &testOptions{}, values, true)
+}
+
+func TestFromStruct(t *testing.T) {
+	cmd := testCommand()
+
+	var flags []string
+	for _, flag := range cmd.Flags {
+		flags = append(flags, strings.TrimSpace(flag.Long+" "+flag.Short))
+	}
+	want := []string{"--pwd", "--cmd -c", "--out", "--verbose -v", "--timeout", "--help -h", "--version"}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("flags = %q, want %q", flags, want)
+	}
+	if out := cmd.Flags[2]; !reflect.DeepEqual(out.Values, []string{"text", "json"}) || out.Files {
+		t.Errorf("--out = %+v, want its values", out)
+	}
+	if pwd, timeout := cmd.Flags[0], cmd.Flags[4]; !pwd.Files || timeout.Files || !timeout.TakesValue {
+		t.Errorf("--pwd = %+v, --timeout = %+v", pwd, timeout)
+	}
+	if cmd.Flags[3].TakesValue {
+		t.Error("boolean --verbose takes a value")
+	}
+
+	if len(cmd.Subcommands) != 2 {
+		t.Fatalf("subcommands = %+v", cmd.Subcommands)
+	}
+	report, completion := cmd.Subcommands[0], cmd.Subcommands[1]
+	if report.Name != "report" || report.Help != "write a report" || report.Flags[0].Long != "--html" {
+		t.Errorf("report = %+v", report)
+	}
+	if !reflect.DeepEqual(completion.Args, Shells) || completion.Files {
+		t.Errorf("completion args = %+v", completion)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{shell: "bash", want: []string{"complete -o default -F _tool tool", "'tool report') cmd='tool report' ;;", "--out) COMPREPLY=($(compgen -W 'text json'"}},
+		{shell: "zsh", want: []string{"#compdef tool", "compdef _tool tool", "'report:write a report'", "--out) compadd -- 'text' 'json'"}},
+		{shell: "fish", want: []string{"complete -c tool -n '__tool_path \\'tool report\\'' -l html -r -F -d 'page to write'", "-l out -x -a 'text json'", "-l timeout -x -d"}},
+		{shell: "powershell", want: []string{"Register-ArgumentCompleter -Native -CommandName 'tool'", "'tool' = @('report', 'completion')", "'--out' = @('text', 'json')"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.shell, testCommand()); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("script does not contain %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	if err := Write(&bytes.Buffer{}, "tcsh", testCommand()); err == nil {
+		t.Error("unknown shell expected an error")
+	}
+}
+
+func TestBashCompletes(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	var script bytes.Buffer
+	if err := Write(&script, "bash", testCommand()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{words: []string{"tool", ""}, want: "report completion"},
+		{words: []string{"tool", "--o"}, want: "--out"},
+		{words: []string{"tool", "--out", "j"}, want: "json"},
+		{words: []string{"tool", "report", "--h"}, want: "--html --help"},
+		{words: []string{"tool", "--pwd", "x", "report", "--v"}, want: "--verbose --version"},
+		{words: []string{"tool", "completion", "f"}, want: "fish"},
+	}
+	for _, tt := range tests {
+		words := make([]string, len(tt.words))
+		for i, word := range tt.words {
+			words[i] = "'" + word + "'"
+		}
+		test := script.String() + "\nCOMP_WORDS=(" + strings.Join(words, " ") + ")\n" +
+			"COMP_CWORD=$((${#COMP_WORDS[@]} - 1))\n_tool\necho \"${COMPREPLY[*]}\"\n"
+		output, err := exec.Command(bash, "-c", test).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", tt.words, err, output)
+		}
+		if got := strings.TrimSpace(string(output)); got != tt.want {
+			t.Errorf("%v completes to %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
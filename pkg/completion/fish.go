@@ -0,0 +1,38 @@
+package completion
+
+import "text/template"
+
+var fishTemplate = template.Must(template.New("fish").Funcs(funcs).Parse(`# fish completion for {{.Name}}
+# Load it with: {{.Name}} completion fish | source
+
+function __{{.Name}}_path
+	set -l path {{fishQuote .Name}}
+	for word in (commandline -opc)[2..-1]
+		test "$word" = -- && break
+		switch "$path $word"
+{{- range .Commands}}{{if ne .Path $.Name}}
+			case {{fishQuote .Path}}
+				set path {{fishQuote .Path}}
+{{- end}}{{end}}
+		end
+	end
+	test "$path" = "$argv[1]"
+end
+
+complete -c {{.Name}} -f
+{{- range $cmd := .Commands}}
+{{- $when := print "__" $.Name "_path " (fishQuote .Path)}}
+{{- range .Subcommands}}
+complete -c {{$.Name}} -n {{fishQuote $when}} -a {{fishQuote .Name}} -d {{fishQuote .Help}}
+{{- end}}
+{{- range .Flags}}
+complete -c {{$.Name}} -n {{fishQuote $when}} -l {{trim .Long}}{{if .Short}} -s {{trim .Short}}{{end}}
+{{- if .Values}} -x -a {{fishQuote (join .Values " ")}}{{else if .Files}} -r -F{{else if .TakesValue}} -x{{end}} -d {{fishQuote .Help}}
+{{- end}}
+{{- if .Args}}
+complete -c {{$.Name}} -n {{fishQuote $when}} -a {{fishQuote (join .Args " ")}}
+{{- else if .FileArgs}}
+complete -c {{$.Name}} -n {{fishQuote $when}} -F
+{{- end}}
+{{- end}}
+`))
@@ -0,0 +1,60 @@
+package completion
+
+import "text/template"
+
+var powershellTemplate = template.Must(template.New("powershell").Funcs(funcs).Parse(`# PowerShell completion for {{.Name}}
+# Load it with: {{.Name}} completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName {{psQuote .Name}} -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$subcommands = @{
+{{- range .Commands}}
+		{{psQuote .Path}} = @({{range $i, $sub := .Subcommands}}{{if $i}}, {{end}}{{psQuote $sub.Name}}{{end}})
+{{- end}}
+	}
+	$flags = @{
+{{- range .Commands}}
+		{{psQuote .Path}} = [ordered]@{ {{- range $i, $flag := .Flags}}{{if $i}};{{end}} {{psQuote $flag.Long}} = {{psQuote $flag.Help}}{{if $flag.Short}}; {{psQuote $flag.Short}} = {{psQuote $flag.Help}}{{end}}{{end}} }
+{{- end}}
+	}
+	$positional = @{
+{{- range .Commands}}{{if .Args}}
+		{{psQuote .Path}} = @({{range $i, $arg := .Args}}{{if $i}}, {{end}}{{psQuote $arg}}{{end}})
+{{- end}}{{end}}
+	}
+	$values = @{
+{{- range .Values}}
+		{{psQuote .Name}} = @({{range $i, $value := .Values}}{{if $i}}, {{end}}{{psQuote $value}}{{end}})
+{{- end}}
+	}
+	$files = @({{range $i, $name := .Files}}{{if $i}}, {{end}}{{psQuote $name}}{{end}})
+
+	# The words before the one being completed.
+	$previous = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+	$cmd = {{psQuote .Name}}
+	foreach ($word in ($previous | Select-Object -Skip 1)) {
+		if ($word -eq '--') { return }
+		if ($subcommands[$cmd] -contains $word) { $cmd = "$cmd $word" }
+	}
+
+	$last = if ($previous.Count -gt 1) { $previous[-1] } else { '' }
+	if ($values.ContainsKey($last)) {
+		$values[$last] | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+			[System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+		}
+		return
+	}
+	# Returning nothing falls back to completing paths.
+	if ($files -contains $last) { return }
+
+	if ($wordToComplete -notlike '-*') {
+		@($subcommands[$cmd]) + @($positional[$cmd]) | Where-Object { $_ -and $_ -like "$wordToComplete*" } | ForEach-Object {
+			[System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+		}
+	}
+	$flags[$cmd].GetEnumerator() | Where-Object { $_.Key -like "$wordToComplete*" } | ForEach-Object {
+		[System.Management.Automation.CompletionResult]::new($_.Key, $_.Key, 'ParameterName', $_.Value)
+	}
+}
+`))
@@ -0,0 +1,71 @@
+package completion
+
+import "text/template"
+
+var zshTemplate = template.Must(template.New("zsh").Funcs(funcs).Parse(`#compdef {{.Name}}
+# zsh completion for {{.Name}}
+# Load it with: source <({{.Name}} completion zsh)
+
+{{funcName .Name}}() {
+	local cmd={{shQuote .Name}} word
+	local -a items
+	for word in "${(@)words[2,CURRENT-1]}"; do
+		[[ $word == -- ]] && return
+		case "$cmd $word" in
+{{- range .Commands}}{{if ne .Path $.Name}}
+		{{shQuote .Path}}) cmd={{shQuote .Path}} ;;
+{{- end}}{{end}}
+		esac
+	done
+
+	case "${words[CURRENT-1]}" in
+{{- range .Values}}
+	{{.Name}}) compadd -- {{range .Values}}{{shQuote .}} {{end}}; return ;;
+{{- end}}
+{{- if .Files}}
+	{{join .Files "|"}}) _files; return ;;
+{{- end}}
+{{- if .Other}}
+	{{join .Other "|"}}) return ;;
+{{- end}}
+	esac
+
+	case "$cmd" in
+{{- range .Commands}}
+	{{shQuote .Path}})
+		if [[ $PREFIX == -* ]]; then
+			items=(
+{{- range .Flags}}
+				{{shQuote (print .Long ":" .Help)}}
+{{- if .Short}}
+				{{shQuote (print .Short ":" .Help)}}
+{{- end}}
+{{- end}}
+			)
+		else
+{{- if .Args}}
+			compadd -- {{range .Args}}{{shQuote .}} {{end}}
+			return
+{{- else if .FileArgs}}
+			_files
+			return
+{{- else}}
+			items=(
+{{- range .Subcommands}}
+				{{shQuote (print .Name ":" .Help)}}
+{{- end}}
+			)
+{{- end}}
+		fi
+		;;
+{{- end}}
+	esac
+	_describe {{shQuote .Name}} items
+}
+
+if [[ $funcstack[1] == {{funcName .Name}} ]]; then
+	{{funcName .Name}} "$@"
+else
+	compdef {{funcName .Name}} {{.Name}}
+fi
+`))
@@ -0,0 +1,147 @@
+// Package config loads the project-level .linterdiff.yml settings file.
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = ".linterdiff.yml"
+
+type Config struct {
+	Pwd            string   `yaml:"pwd"`
+	VCS            string   `yaml:"vcs"`
+	DiffEngine     string   `yaml:"diff-engine"`
+	DiffCommand    string   `yaml:"diff-command"`
+	BaseRef        string   `yaml:"base-ref"`
+	JSONFile       string   `yaml:"json-file"`
+	InspectPaths   []string `yaml:"inspect-paths"`
+	Output         string   `yaml:"output"`
+	ExcludePaths   []string `yaml:"exclude-paths"`
+	IncludePaths   []string `yaml:"include-paths"`
+	ExcludeLinters []string `yaml:"exclude-linters"`
+	OnlyLinters    []string `yaml:"only-linters"`
+	FailOn         []string `yaml:"fail-on"`
+	FormatCheck    []string `yaml:"format-check"`
+	SeverityMin    string   `yaml:"severity-min"`
+	Scope          string   `yaml:"scope"`
+	Bin            string   `yaml:"bin"`
+	LintVersion    string   `yaml:"lint-version"`
+	Runner         string   `yaml:"runner"`
+	Image          string   `yaml:"image"`
+	LintConfig     string   `yaml:"lint-config"`
+	LintArgs       []string `yaml:"lint-args"`
+	BuildTags      []string `yaml:"build-tags"`
+	Suppressions   string   `yaml:"suppressions"`
+	Tools          []Tool   `yaml:"tools"`
+	// Budgets map globs of paths to the most issues allowed under them
+	// across the whole repository, checked with --budgets.
+	Budgets map[string]int `yaml:"budgets"`
+	// Severities override the severities reported for linters or rules.
+	Severities []Severity `yaml:"severities"`
+	// Env is set for golangci-lint, before --env.
+	Env map[string]string `yaml:"env"`
+}
+
+// Severity sets the severity of the issues of Linter, or only those of its
+// Rule when set.
+type Severity struct {
+	Linter   string `yaml:"linter"`
+	Rule     string `yaml:"rule"`
+	Severity string `yaml:"severity"`
+}
+
+// Tool is a linter other than golangci-lint, run on the changed files
+// matching Files, whose JSON report Issues and Fields map to issues.
+type Tool struct {
+	Name string `yaml:"name"`
+	// Command is the command line, where the word {files} stands for the
+	// files to lint.
+	Command string     `yaml:"command"`
+	Files   []string   `yaml:"files"`
+	Issues  string     `yaml:"issues"`
+	Fields  ToolFields `yaml:"fields"`
+}
+
+// ToolFields are the paths to the fields of an issue, from the issue.
+type ToolFields struct {
+	File     string `yaml:"file"`
+	Line     string `yaml:"line"`
+	EndLine  string `yaml:"end-line"`
+	Column   string `yaml:"column"`
+	Message  string `yaml:"message"`
+	Severity string `yaml:"severity"`
+	Rule     string `yaml:"rule"`
+}
+
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(bytes, &cfg); err != nil {
+		return nil, err
+	}
+
+	// Relative paths in the file are meant relative to the file itself.
+	for _, p := range []*string{&cfg.Pwd, &cfg.LintConfig, &cfg.Suppressions} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(filepath.Dir(path), *p)
+		}
+	}
+	return &cfg, nil
+}
+
+// Find walks up from dir looking for FileName and returns its path, or an
+// empty string when no config file exists in dir or any of its parents.
+func Find(dir string) (string, error) {
+	return FindFile(dir, FileName)
+}
+
+// FindFile walks up from dir looking for a file called name, like Find.
+func FindFile(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, name)
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadOrEmpty loads the explicit path when given, otherwise the first config
+// file found above dir, and falls back to an empty Config.
+func LoadOrEmpty(explicit, dir string) (*Config, error) {
+	path := explicit
+	if path == "" {
+		found, err := Find(dir)
+		if err != nil {
+			return nil, err
+		}
+		path = found
+	}
+
+	if path == "" {
+		return &Config{}, nil
+	}
+	return Load(path)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindWalksUp(t *testing.T) {
+	root := t.TempDir()
+	path := writeConfig(t, root, "output: sarif\n")
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Find(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != path {
+		t.Errorf("Find = %q, want %q", got, path)
+	}
+}
+
+func TestLoadOrEmptyWithoutFile(t *testing.T) {
+	cfg, err := LoadOrEmpty("", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("LoadOrEmpty = %+v, want an empty config", cfg)
+	}
+}
+
+func TestLoadResolvesPathsAgainstFile(t *testing.T) {
+	root := t.TempDir()
+	path := writeConfig(t, root, `
+pwd: service
+diff-command: git diff origin/main
+inspect-paths: [./internal/...]
+exclude-paths: [vendor]
+lint-config: .golangci.yml
+lint-args: [--timeout, 5m]
+runner: docker
+image: golangci/golangci-lint:v1.59
+`)
+
+	cfg, err := LoadOrEmpty(path, "elsewhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Config{
+		Pwd:          filepath.Join(root, "service"),
+		DiffCommand:  "git diff origin/main",
+		InspectPaths: []string{"./internal/..."},
+		ExcludePaths: []string{"vendor"},
+		LintConfig:   filepath.Join(root, ".golangci.yml"),
+		LintArgs:     []string{"--timeout", "5m"},
+		Runner:       "docker",
+		Image:        "golangci/golangci-lint:v1.59",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadOrEmpty = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadTools(t *testing.T) {
+	cfg, err := Load(writeConfig(t, t.TempDir(), `
+tools:
+  - name: eslint
+    command: npx eslint --format json {files}
+    files: ["web/**/*.js"]
+    issues: $[*].messages[*]
+    fields: {file: ^.filePath, line: line, end-line: endLine, message: message, rule: ruleId}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Tool{{
+		Name:    "eslint",
+		Command: "npx eslint --format json {files}",
+		Files:   []string{"web/**/*.js"},
+		Issues:  "$[*].messages[*]",
+		Fields:  ToolFields{File: "^.filePath", Line: "line", EndLine: "endLine", Message: "message", Rule: "ruleId"},
+	}}
+	if !reflect.DeepEqual(cfg.Tools, want) {
+		t.Errorf("Tools = %+v, want %+v", cfg.Tools, want)
+	}
+}
+
+func TestLoadKeepsAbsolutePwd(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "abs")
+	cfg, err := Load(writeConfig(t, t.TempDir(), "pwd: "+abs+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Pwd != abs {
+		t.Errorf("Pwd = %q, want %q", cfg.Pwd, abs)
+	}
+}
@@ -0,0 +1,156 @@
+// Package coverage reads Go coverage profiles, as written by go test
+// -coverprofile, and finds the lines no test runs.
+package coverage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is a run of statements of a file and how often tests ran it.
+type Block struct {
+	StartLine, EndLine int
+	Statements         int
+	Count              int
+}
+
+// Profile holds the blocks of every file, by the name the profile gives
+// it: the import path of its package followed by the file name.
+type Profile map[string][]Block
+
+// Load reads the profile at path.
+func Load(path string) (Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	profile, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Parse reads a coverage profile. Profiles concatenated from several runs
+// may repeat the mode line and blocks; a block is covered when any run
+// covered it.
+func Parse(r io.Reader) (Profile, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	profile := make(Profile)
+	number := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		number++
+		if line == "" || strings.HasPrefix(line, "mode: ") {
+			continue
+		}
+		name, block, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		profile[name] = append(profile[name], block)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if number == 0 {
+		return nil, errors.New("empty coverage profile")
+	}
+	return profile, nil
+}
+
+// parseBlock reads "name.go:line.col,line.col statements count".
+func parseBlock(line string) (string, Block, error) {
+	colon := strings.LastIndexByte(line, ':')
+	if colon < 0 {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	name := line[:colon]
+	span := strings.Fields(line[colon+1:])
+	if len(span) != 3 {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	start, end, ok := strings.Cut(span[0], ",")
+	if !ok {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	var (
+		block Block
+		err   error
+	)
+	if block.StartLine, err = lineOf(start); err != nil {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if block.EndLine, err = lineOf(end); err != nil {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if block.Statements, err = strconv.Atoi(span[1]); err != nil {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if block.Count, err = strconv.Atoi(span[2]); err != nil {
+		return "", Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	return name, block, nil
+}
+
+// lineOf reads the line of a "line.col" position.
+func lineOf(position string) (int, error) {
+	line, _, _ := strings.Cut(position, ".")
+	return strconv.Atoi(line)
+}
+
+// Uncovered returns the lines of the file called name holding statements
+// that no test ran, in order. A line shared by a block that ran, such as
+// "} else {", counts as covered. Files the profile does not know have no
+// uncovered lines.
+func (p Profile) Uncovered(name string) []int {
+	covered := make(map[int]bool)
+	missed := make(map[int]bool)
+	for _, block := range p[name] {
+		if block.Statements == 0 {
+			continue
+		}
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if block.Count > 0 {
+				covered[line] = true
+			} else {
+				missed[line] = true
+			}
+		}
+	}
+	lines := make([]int, 0, len(missed))
+	for line := range missed {
+		if !covered[line] {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// ModulePath reads the path go.mod in dir declares for its module.
+func ModulePath(dir string) (string, error) {
+	path := filepath.Join(dir, "go.mod")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`+"`"), nil
+		}
+	}
+	return "", fmt.Errorf("%s: no module directive", path)
+}
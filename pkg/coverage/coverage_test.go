@@ -0,0 +1,89 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const profile = `mode: set
+example.com/m/a.go:3.13,5.2 1 1
+example.com/m/a.go:7.13,8.9 1 1
+example.com/m/a.go:8.9,10.3 1 0
+example.com/m/a.go:11.2,11.10 1 1
+example.com/m/b.go:3.13,6.2 2 0
+example.com/m/b.go:8.13,8.14 0 0
+mode: set
+example.com/m/b.go:3.13,6.2 2 1
+example.com/m/c.go:3.13,6.2 2 0
+`
+
+func TestUncovered(t *testing.T) {
+	p, err := Parse(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want []int
+	}{
+		// Line 8 starts the block that never ran but ends one that did.
+		{"example.com/m/a.go", []int{9, 10}},
+		// The second run covered what the first did not.
+		{"example.com/m/b.go", []int{}},
+		{"example.com/m/c.go", []int{3, 4, 5, 6}},
+		{"example.com/m/unknown.go", []int{}},
+	}
+	for _, tt := range tests {
+		if got := p.Uncovered(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Uncovered(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+	}{
+		{"empty", ""},
+		{"no position", "mode: set\nexample.com/m/a.go 1 1\n"},
+		{"no end", "mode: set\nexample.com/m/a.go:3.13 1 1\n"},
+		{"bad count", "mode: set\nexample.com/m/a.go:3.13,5.2 1 x\n"},
+		{"missing count", "mode: set\nexample.com/m/a.go:3.13,5.2 1\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(strings.NewReader(tt.profile)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		gomod string
+		want  string
+	}{
+		{"module example.com/m\n\ngo 1.21\n", "example.com/m"},
+		{"// Deprecated: use v2.\nmodule \"example.com/quoted\" // comment\n", "example.com/quoted"},
+		{"go 1.21\n", ""},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.gomod), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ModulePath(dir)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ModulePath(%q) = %q, want an error", tt.gomod, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ModulePath(%q) = %q, %v, want %q", tt.gomod, got, err, tt.want)
+		}
+	}
+}
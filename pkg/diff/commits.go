@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// CommitCommand prints the changes a commit made to its first parent, or
+// to nothing for a root commit, without context.
+const CommitCommand = "git diff-tree -p -U0 -M --root -m --first-parent --no-commit-id"
+
+// CommitRange lists the commits of rng, such as main..feature, oldest
+// first. Merges are left out, as they bring in changes made elsewhere.
+func CommitRange(ctx context.Context, pwd, rng string) ([]string, error) {
+	from, to, ok := strings.Cut(rng, "..")
+	to = strings.TrimPrefix(to, ".")
+	if !ok || strings.HasPrefix(from, "-") || to == "" || strings.HasPrefix(to, "-") {
+		return nil, fmt.Errorf("invalid range %q, want from..to", rng)
+	}
+	output, err := command.New("git", "rev-list", "--reverse", "--no-merges", rng, "--").
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list %s: %w", rng, err)
+	}
+	commits := strings.Fields(string(output))
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("range %q has no commits", rng)
+	}
+	return commits, nil
+}
+
+// FindCommits returns the lines commits changed, together, with line
+// numbers translated to the working tree golangci-lint sees. Lines a commit
+// changed that were rewritten since are dropped, as with FindStaged, as are
+// files removed since. diffArgs are added to CommitCommand.
+func FindCommits(ctx context.Context, pwd string, commits []string, diffArgs ...string) ([]FileChange, error) {
+	var all []FileChange
+	for _, commit := range commits {
+		hash, err := ResolveCommit(ctx, pwd, commit)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := Find(ctx, pwd, strings.Join(append([]string{CommitCommand}, append(diffArgs, hash)...), " "))
+		if err != nil {
+			return nil, err
+		}
+		since, renamed, err := hunksSince(ctx, pwd, hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range translateStaged(changes, since) {
+			if path, ok := renamed[change.Path]; ok {
+				if path == devNull {
+					continue
+				}
+				change.Path = path
+			}
+			all = append(all, change)
+		}
+	}
+	return union(all), nil
+}
+
+// hunksSince reads the edits made to the working tree since commit, by
+// path in commit, and where files were renamed to, devNull for those
+// removed.
+func hunksSince(ctx context.Context, pwd, commit string) (map[string][]Hunk, map[string]string, error) {
+	output, err := command.New("git", "diff", "-U0", "-M", commit).
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return nil, nil, err
+	}
+	patches, err := ParsePatches(bytes.NewReader(output))
+	if err != nil {
+		return nil, nil, err
+	}
+	hunks := make(map[string][]Hunk, len(patches))
+	renamed := make(map[string]string)
+	for _, patch := range patches {
+		hunks[patch.OldPath] = append(hunks[patch.OldPath], patch.Hunks...)
+		if patch.Renamed || patch.Deleted() {
+			renamed[patch.OldPath] = patch.NewPath
+		}
+	}
+	return hunks, renamed, nil
+}
+
+// union merges the changes of several diffs of the same files, by path.
+func union(changes []FileChange) []FileChange {
+	lines := make(map[string]map[int]bool)
+	deletions := make(map[string]map[int]bool)
+	moved := make(map[string]map[int]bool)
+	for _, change := range changes {
+		if lines[change.Path] == nil {
+			lines[change.Path], deletions[change.Path], moved[change.Path] = make(map[int]bool), make(map[int]bool), make(map[int]bool)
+		}
+		for _, c := range change.Changes {
+			for line := c.Start; line <= c.End; line++ {
+				lines[change.Path][line] = true
+			}
+		}
+		for _, line := range change.Deletions {
+			deletions[change.Path][line] = true
+		}
+		for _, c := range change.Moved {
+			for line := c.Start; line <= c.End; line++ {
+				moved[change.Path][line] = true
+			}
+		}
+	}
+
+	merged := make([]FileChange, 0, len(lines))
+	for path := range lines {
+		change := FileChange{Path: path}
+		for _, line := range sortedLines(lines[path]) {
+			change.Changes = appendLine(change.Changes, line)
+			change.Hunks = appendLine(change.Hunks, line)
+		}
+		change.Deletions = sortedLines(deletions[path])
+		for _, line := range sortedLines(moved[path]) {
+			change.Moved = appendLine(change.Moved, line)
+		}
+		merged = append(merged, change)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Path < merged[j].Path })
+	return merged
+}
+
+func sortedLines(set map[int]bool) []int {
+	if len(set) == 0 {
+		return nil
+	}
+	lines := make([]int, 0, len(set))
+	for line := range set {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
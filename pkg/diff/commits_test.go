@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindCommits(t *testing.T) {
+	dir := gitRepo(t)
+	commit := func(message string) string {
+		git(t, dir, "add", "-A")
+		git(t, dir, "commit", "-q", "-m", message)
+		return git(t, dir, "rev-parse", "HEAD")
+	}
+	writeFile(t, dir, "b.go", "package a\n\nvar x = 1\nvar y = 2\n")
+	first := commit("b")
+	writeFile(t, dir, "c.go", "package a\n\nvar c = 1\n")
+	second := commit("c")
+	writeFile(t, dir, "b.go", "package a\n\nvar x = 10\nvar y = 2\n")
+	third := commit("x")
+	writeFile(t, dir, "gone.go", "package a\n")
+	fourth := commit("gone")
+
+	// Since then b.go gained a line on top, c.go moved and gone.go went.
+	writeFile(t, dir, "b.go", "// top\npackage a\n\nvar x = 10\nvar y = 2\n")
+	git(t, dir, "mv", "c.go", "moved.go")
+	if err := os.Remove(filepath.Join(dir, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := FileChange{Path: "b.go", Changes: []*Change{{Start: 2, End: 5}}, Hunks: []*Change{{Start: 2, End: 5}}}
+	moved := FileChange{Path: "moved.go", Changes: []*Change{{Start: 1, End: 3}}, Hunks: []*Change{{Start: 1, End: 3}}}
+	tests := []struct {
+		name    string
+		commits []string
+		want    []FileChange
+	}{
+		{
+			name:    "rewritten lines are dropped",
+			commits: []string{first},
+			want:    []FileChange{{Path: "b.go", Changes: []*Change{{Start: 2, End: 3}, {Start: 5, End: 5}}, Hunks: []*Change{{Start: 2, End: 3}, {Start: 5, End: 5}}}},
+		},
+		{name: "union", commits: []string{third, first}, want: []FileChange{b}},
+		{name: "renamed since", commits: []string{second}, want: []FileChange{moved}},
+		{name: "removed since", commits: []string{fourth}, want: []FileChange{}},
+		{name: "by ref", commits: []string{"HEAD~3", "HEAD~2", "HEAD~1", "HEAD"}, want: []FileChange{b, moved}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindCommits(context.Background(), dir, tt.commits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCommits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FindCommits(context.Background(), dir, []string{"--all"}); err == nil {
+		t.Error("FindCommits of an option expected an error")
+	}
+}
+
+func TestCommitRange(t *testing.T) {
+	dir := gitRepo(t)
+	base := git(t, dir, "rev-parse", "HEAD")
+	var want []string
+	for _, name := range []string{"b.go", "c.go"} {
+		writeFile(t, dir, name, "package a\n")
+		git(t, dir, "add", name)
+		git(t, dir, "commit", "-q", "-m", name)
+		want = append(want, git(t, dir, "rev-parse", "HEAD"))
+	}
+
+	for _, rng := range []string{base + "..HEAD", base[:7] + "...HEAD"} {
+		got, err := CommitRange(context.Background(), dir, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("CommitRange(%q) = %v, want %v", rng, got, want)
+		}
+	}
+	for _, rng := range []string{"HEAD", "HEAD..", "--all..HEAD", base + "..--all", "HEAD..HEAD"} {
+		if _, err := CommitRange(context.Background(), dir, rng); err == nil {
+			t.Errorf("CommitRange(%q) expected an error", rng)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package diff finds the lines touched by a change set.
+package diff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"linter/pkg/command"
+)
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Change is an inclusive range of added lines in the new version of a file.
+type Change struct {
+	Start, End int
+}
+
+// FileChange lists the added line ranges of one file, by its repository path.
+type FileChange struct {
+	Changes []*Change
+	// Hunks are the lines of the new file each hunk spans, context
+	// included.
+	Hunks []*Change
+	// Deletions are the lines of the new file right after which lines
+	// were removed, 0 for the top of the file.
+	Deletions []int
+	// Moved are the added lines the same diff removed elsewhere, in
+	// blocks moved rather than written.
+	Moved []*Change
+	Path  string
+	// OldPath is the path the file was renamed or copied from, if any.
+	OldPath string
+	// Patch is the hunks of the diff the change was read from, with their
+	// body lines; empty when the change merges several diffs.
+	Patch []Hunk
+}
+
+// Overlaps reports whether any line from from to to, inclusive, falls
+// inside a changed range.
+func (f FileChange) Overlaps(from, to int) bool {
+	return overlaps(f.Changes, from, to)
+}
+
+// HunkOverlaps reports whether any line from from to to, inclusive, falls
+// inside a hunk, on a changed line or on the context around it.
+func (f FileChange) HunkOverlaps(from, to int) bool {
+	return overlaps(f.Hunks, from, to)
+}
+
+// DeletionOverlaps reports whether any line from from to to, inclusive, is
+// right before or right after lines that were removed.
+func (f FileChange) DeletionOverlaps(from, to int) bool {
+	for _, line := range f.Deletions {
+		if line <= to && from <= line+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(ranges []*Change, from, to int) bool {
+	for _, r := range ranges {
+		if r.Start <= to && from <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether line falls inside any of the changed ranges.
+func (f FileChange) Contains(line int) bool {
+	for _, change := range f.Changes {
+		if change.Start <= line && line <= change.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Find runs the diff command cmd in pwd and collects the lines it adds to
+// and removes from every file. Context lines are not changes of the new
+// file.
+func Find(ctx context.Context, pwd, cmd string) ([]FileChange, error) {
+	patches, err := runPatches(ctx, pwd, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return fileChanges(patches), nil
+}
+
+func runPatches(ctx context.Context, pwd, line string) ([]Patch, error) {
+	cmd, err := command.Parse(line)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.SetContext(ctx).SetDir(pwd).Output()
+	if err != nil {
+		return nil, err
+	}
+	return ParsePatches(bytes.NewReader(output))
+}
+
+// FindRenames is the git diff option treating files at least threshold
+// percent alike as renamed, rather than as deleted and added in full.
+func FindRenames(threshold int) string {
+	return fmt.Sprintf("--find-renames=%d%%", threshold)
+}
+
+// IgnoreWhitespace are the git diff options leaving out lines whose only
+// change is whitespace, and changes that only add or remove blank lines.
+var IgnoreWhitespace = []string{"--ignore-all-space", "--ignore-blank-lines"}
+
+// Paths returns the file paths of changes, in order.
+func Paths(changes []FileChange) []string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// Added keeps the files that gained lines, dropping those that only lost
+// some.
+func Added(changes []FileChange) []FileChange {
+	added := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		if len(change.Changes) > 0 {
+			added = append(added, change)
+		}
+	}
+	return added
+}
+
+// ByFileName indexes changes by their file path.
+func ByFileName(changes []FileChange) map[string]FileChange {
+	changesByFileName := make(map[string]FileChange)
+	for _, change := range changes {
+		changesByFileName[change.Path] = change
+	}
+	return changesByFileName
+}
+
+// parseHunkHeader reads "@@ -l,s +l,s @@" where an omitted count means 1.
+func parseHunkHeader(header string) (Hunk, error) {
+	match := hunkHeaderPattern.FindStringSubmatch(header)
+	if match == nil {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q", header)
+	}
+
+	var h Hunk
+	for i, field := range []*int{&h.OldStart, &h.OldCount, &h.NewStart, &h.NewCount} {
+		value := match[i+1]
+		if value == "" {
+			*field = 1
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Hunk{}, err
+		}
+		*field = n
+	}
+	return h, nil
+}
@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileChangeContains(t *testing.T) {
+	change := FileChange{
+		Path:    "a.go",
+		Changes: []*Change{{Start: 3, End: 5}, {Start: 10, End: 10}},
+	}
+
+	tests := map[int]bool{1: false, 3: true, 4: true, 5: true, 6: false, 10: true, 11: false}
+	for line, want := range tests {
+		if got := change.Contains(line); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestFileChangeOverlaps(t *testing.T) {
+	change := FileChange{
+		Path:    "a.go",
+		Changes: []*Change{{Start: 3, End: 5}, {Start: 10, End: 10}},
+	}
+
+	tests := []struct {
+		from, to int
+		want     bool
+	}{
+		{1, 2, false},
+		{1, 3, true},
+		{4, 4, true},
+		{5, 9, true},
+		{6, 9, false},
+		{1, 20, true},
+		{11, 12, false},
+	}
+	for _, tt := range tests {
+		if got := change.Overlaps(tt.from, tt.to); got != tt.want {
+			t.Errorf("Overlaps(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	change.Deletions = []int{20}
+	if !change.DeletionOverlaps(21, 21) || !change.DeletionOverlaps(15, 20) || change.DeletionOverlaps(22, 30) {
+		t.Error("DeletionOverlaps does not match the lines around line 20")
+	}
+
+	change.Hunks = []*Change{{Start: 1, End: 8}}
+	if !change.HunkOverlaps(7, 7) || change.HunkOverlaps(9, 12) {
+		t.Error("HunkOverlaps does not match the hunk around lines 3 to 5")
+	}
+}
+
+func TestPathsAndByFileName(t *testing.T) {
+	changes := []FileChange{{Path: "a.go"}, {Path: "dir/b.go"}}
+
+	if got, want := Paths(changes), []string{"a.go", "dir/b.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths = %v, want %v", got, want)
+	}
+
+	byName := ByFileName(changes)
+	if len(byName) != 2 || byName["dir/b.go"].Path != "dir/b.go" {
+		t.Errorf("ByFileName = %v", byName)
+	}
+}
+
+func TestFindRecordsAddedAndRemovedLines(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "b.go", "package a\n\nvar (\n\tx = 1\n\ty = 2\n\tz = 3\n)\n")
+	writeFile(t, dir, "c.go", "package a\n\nvar w = 0\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "second")
+
+	writeFile(t, dir, "b.go", "package a\n\nvar (\n\tx = 1\n\ty = 20\n\tz = 3\n)\n")
+	writeFile(t, dir, "c.go", "package a\n")
+
+	changes, err := Find(context.Background(), dir, "git diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{
+		{Path: "b.go", Changes: []*Change{{Start: 5, End: 5}}, Hunks: []*Change{{Start: 2, End: 7}}, Patch: []Hunk{{
+			OldStart: 2, OldCount: 6, NewStart: 2, NewCount: 6,
+			Lines: []string{" ", " var (", " \tx = 1", "-\ty = 2", "+\ty = 20", " \tz = 3", " )"},
+		}}},
+		{Path: "c.go", Hunks: []*Change{{Start: 1, End: 1}}, Deletions: []int{1}, Patch: []Hunk{{
+			OldStart: 1, OldCount: 3, NewStart: 1, NewCount: 1, Lines: []string{" package a", "-", "-var w = 0"},
+		}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Find = %+v, want %+v", changes, want)
+	}
+	if got := Added(changes); len(got) != 1 || got[0].Path != "b.go" {
+		t.Errorf("Added = %+v, want only b.go", got)
+	}
+}
+
+func TestFindWithRenameDetection(t *testing.T) {
+	dir := gitRepo(t)
+	body := "package a\n\nfunc one() {}\n\nfunc two() {}\n\nfunc three() {}\n"
+	writeFile(t, dir, "old.go", body)
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "old")
+
+	git(t, dir, "mv", "old.go", "new.go")
+	writeFile(t, dir, "new.go", strings.Replace(body, "func two() {}", "func two() { _ = 2 }", 1))
+	git(t, dir, "add", ".")
+
+	changes, err := Find(context.Background(), dir, "git diff --cached --no-renames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Changes[0].Start != 1 || changes[0].Changes[0].End != 7 {
+		t.Fatalf("without rename detection the whole file should count, got %+v", changes)
+	}
+
+	changes, err = Find(context.Background(), dir, "git diff --cached "+FindRenames(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{{Path: "new.go", OldPath: "old.go", Changes: []*Change{{Start: 5, End: 5}}, Hunks: []*Change{{Start: 2, End: 7}}, Patch: []Hunk{{
+		OldStart: 2, OldCount: 6, NewStart: 2, NewCount: 6,
+		Lines: []string{" ", " func one() {}", " ", "-func two() {}", "+func two() { _ = 2 }", " ", " func three() {}"},
+	}}}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Find = %+v, want %+v", changes, want)
+	}
+}
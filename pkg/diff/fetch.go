@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// ErrShallowHistory is returned when HEAD and a ref share no commit in the
+// history a shallow clone fetched, which may just not reach back far enough.
+var ErrShallowHistory = errors.New("no common commit in the history of this shallow clone, fetch more of it, e.g. with git fetch --deepen or fetch-depth: 0 on actions/checkout")
+
+// ErrMissingRef is returned when the ref to compare against is unknown,
+// which in CI usually means the clone did not fetch it.
+var ErrMissingRef = errors.New("a shallow or single-branch clone may lack it, fetch it first, e.g. with git fetch origin main")
+
+// deepenStep and deepenTries bound FetchHistory: history is deepened by
+// deepenStep commits deepenTries times before the rest is fetched at once.
+const (
+	deepenStep  = 50
+	deepenTries = 4
+)
+
+// IsShallow reports whether the repository containing pwd is a shallow
+// clone.
+func IsShallow(ctx context.Context, pwd string) (bool, error) {
+	output, err := command.New("git", "rev-parse", "--is-shallow-repository").
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
+
+// FetchHistory fetches what a shallow or single-branch clone lacks for the
+// merge base of HEAD and ref: ref itself when it is a missing
+// remote-tracking branch, then more history, deepenStep commits at a time,
+// until the two share a commit, and at last all of it.
+func FetchHistory(ctx context.Context, pwd, ref string) error {
+	remote := ""
+	if _, err := ResolveCommit(ctx, pwd, ref); err != nil {
+		name, branch, ok := strings.Cut(ref, "/")
+		if !ok || branch == "" {
+			return err
+		}
+		if _, urlErr := RemoteURL(ctx, pwd, name); urlErr != nil {
+			return err
+		}
+		remote = name
+		refspec := "+refs/heads/" + branch + ":refs/remotes/" + remote + "/" + branch
+		if err := fetch(ctx, pwd, "--no-tags", "--depth="+strconv.Itoa(deepenStep), remote, refspec); err != nil {
+			return err
+		}
+	}
+
+	for try := 0; ; try++ {
+		_, err := MergeBase(ctx, pwd, ref)
+		if !errors.Is(err, ErrShallowHistory) {
+			return err
+		}
+		deepen := "--deepen=" + strconv.Itoa(deepenStep)
+		if try == deepenTries {
+			deepen = "--unshallow"
+		}
+		argv := []string{"--no-tags", deepen}
+		if remote != "" {
+			argv = append(argv, remote)
+		}
+		if err := fetch(ctx, pwd, argv...); err != nil {
+			return err
+		}
+		if try == deepenTries {
+			_, err := MergeBase(ctx, pwd, ref)
+			return err
+		}
+	}
+}
+
+func fetch(ctx context.Context, pwd string, argv ...string) error {
+	if _, err := command.New("git", append([]string{"fetch", "--quiet"}, argv...)...).
+		SetContext(ctx).
+		SetDir(pwd).
+		Output(); err != nil {
+		return fmt.Errorf("git fetch: %w", err)
+	}
+	return nil
+}
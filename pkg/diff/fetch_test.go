@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// shallowClone clones the feature branch of a repository whose main branch
+// moved on since feature forked from it, one commit deep, and returns the
+// clone with the fork point.
+func shallowClone(t *testing.T) (string, string) {
+	t.Helper()
+	upstream := gitRepo(t)
+	git(t, upstream, "branch", "-M", "main")
+	fork := git(t, upstream, "rev-parse", "HEAD")
+	for _, name := range []string{"b.go", "c.go"} {
+		writeFile(t, upstream, name, "package a\n")
+		git(t, upstream, "add", ".")
+		git(t, upstream, "commit", "-q", "-m", name)
+	}
+	git(t, upstream, "checkout", "-q", "-b", "feature", fork)
+	for _, name := range []string{"d.go", "e.go"} {
+		writeFile(t, upstream, name, "package a\n")
+		git(t, upstream, "add", ".")
+		git(t, upstream, "commit", "-q", "-m", name)
+	}
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(t, upstream, "clone", "-q", "--depth=1", "--single-branch", "--branch", "feature", "file://"+upstream, clone)
+	return clone, fork
+}
+
+func TestFetchHistoryMissingRef(t *testing.T) {
+	clone, fork := shallowClone(t)
+	ctx := context.Background()
+
+	if _, err := MergeBase(ctx, clone, "origin/main"); !errors.Is(err, ErrMissingRef) {
+		t.Fatalf("MergeBase of an unfetched branch = %v, want ErrMissingRef", err)
+	}
+	if err := FetchHistory(ctx, clone, "origin/main"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := MergeBase(ctx, clone, "origin/main"); err != nil || got != fork {
+		t.Errorf("MergeBase after FetchHistory = %q, %v, want %s", got, err, fork)
+	}
+}
+
+func TestFetchHistoryShallow(t *testing.T) {
+	clone, fork := shallowClone(t)
+	ctx := context.Background()
+	git(t, clone, "fetch", "-q", "--depth=1", "origin", "+refs/heads/main:refs/remotes/origin/main")
+
+	if shallow, err := IsShallow(ctx, clone); err != nil || !shallow {
+		t.Fatalf("IsShallow = %v, %v, want true", shallow, err)
+	}
+	if _, err := MergeBase(ctx, clone, "origin/main"); !errors.Is(err, ErrShallowHistory) {
+		t.Fatalf("MergeBase beyond the fetched history = %v, want ErrShallowHistory", err)
+	}
+	if _, err := FindNative(clone, "origin/main"); !errors.Is(err, ErrShallowHistory) {
+		t.Errorf("FindNative beyond the fetched history = %v, want ErrShallowHistory", err)
+	}
+
+	if err := FetchHistory(ctx, clone, "origin/main"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := MergeBase(ctx, clone, "origin/main"); err != nil || got != fork {
+		t.Errorf("MergeBase after FetchHistory = %q, %v, want %s", got, err, fork)
+	}
+}
@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// MergeBase returns the best common ancestor of HEAD and ref, the commit a
+// pull request based on ref should be compared against. An unknown ref
+// wraps ErrMissingRef, and no merge base in a shallow clone
+// ErrShallowHistory.
+func MergeBase(ctx context.Context, pwd, ref string) (string, error) {
+	commit, err := ResolveCommit(ctx, pwd, ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", err, ErrMissingRef)
+	}
+
+	output, err := command.New("git", "merge-base", "HEAD", commit).
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		if shallow, _ := IsShallow(ctx, pwd); shallow {
+			return "", fmt.Errorf("git merge-base HEAD %s: %w", ref, ErrShallowHistory)
+		}
+		return "", fmt.Errorf("git merge-base HEAD %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResolveCommit turns ref into a commit hash. Refs starting with a dash are
+// rejected so they can never be taken for an option of a later git command.
+func ResolveCommit(ctx context.Context, pwd, ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid ref %q", ref)
+	}
+
+	output, err := command.New("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}").
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %q: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CurrentBranch returns the branch checked out in pwd, or an empty string
+// when HEAD is detached, as it often is in CI.
+func CurrentBranch(ctx context.Context, pwd string) (string, error) {
+	output, err := command.New("git", "branch", "--show-current").
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RemoteURL returns the URL of the remote called name.
+func RemoteURL(ctx context.Context, pwd, name string) (string, error) {
+	output, err := command.New("git", "remote", "get-url", name).
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Untracked returns the Go files of the working tree containing pwd that git
+// neither tracks nor ignores, each changed on every line as if added by a
+// diff.
+func Untracked(ctx context.Context, pwd string) ([]FileChange, error) {
+	root, err := Git.Root(ctx, pwd)
+	if err != nil {
+		return nil, err
+	}
+	output, err := command.New("git", "ls-files", "-z", "--others", "--exclude-standard", "--", ":(top)*.go").
+		SetContext(ctx).
+		SetDir(root).
+		Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, err
+		}
+		lines := splitLines(content)
+		if len(lines) == 0 {
+			continue
+		}
+		hunk := Hunk{NewStart: 1, NewCount: len(lines)}
+		for _, line := range lines {
+			hunk.Lines = appendBody(hunk.Lines, '+', line)
+		}
+		changes = append(changes, FileChange{
+			Path:    path,
+			Changes: []*Change{{Start: 1, End: len(lines)}},
+			Hunks:   []*Change{{Start: 1, End: len(lines)}},
+			Patch:   []Hunk{hunk},
+		})
+	}
+	return changes, nil
+}
@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository with a single commit and returns its path.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	writeFile(t, dir, "a.go", "package a\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+	}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveCommitRejectsOptions(t *testing.T) {
+	for _, ref := range []string{"", "-h", "--output=/tmp/x"} {
+		if _, err := ResolveCommit(context.Background(), ".", ref); err == nil {
+			t.Errorf("ResolveCommit(%q) expected an error", ref)
+		}
+	}
+}
+
+func TestMergeBase(t *testing.T) {
+	dir := gitRepo(t)
+	base := git(t, dir, "rev-parse", "HEAD")
+	git(t, dir, "branch", "main-copy")
+
+	writeFile(t, dir, "b.go", "package a\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "second")
+
+	got, err := MergeBase(context.Background(), dir, "main-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != base {
+		t.Errorf("MergeBase = %q, want %q", got, base)
+	}
+
+	if _, err := MergeBase(context.Background(), dir, "no-such-ref"); err == nil {
+		t.Error("MergeBase of an unknown ref expected an error")
+	}
+}
+
+func TestCurrentBranch(t *testing.T) {
+	dir := gitRepo(t)
+	git(t, dir, "checkout", "-q", "-b", "feature")
+
+	got, err := CurrentBranch(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "feature" {
+		t.Errorf("CurrentBranch = %q, want feature", got)
+	}
+
+	git(t, dir, "checkout", "-q", "--detach")
+	if got, err := CurrentBranch(context.Background(), dir); err != nil || got != "" {
+		t.Errorf("CurrentBranch on a detached HEAD = %q, %v", got, err)
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	dir := gitRepo(t)
+	git(t, dir, "remote", "add", "origin", "git@github.com:metailurini/linter.git")
+
+	got, err := RemoteURL(context.Background(), dir, "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "git@github.com:metailurini/linter.git" {
+		t.Errorf("RemoteURL = %q", got)
+	}
+	if _, err := RemoteURL(context.Background(), dir, "upstream"); err == nil {
+		t.Error("RemoteURL of a missing remote expected an error")
+	}
+}
+
+func TestUntracked(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, ".gitignore", "gen/\n")
+	writeFile(t, dir, "new.go", "package a\n\nvar n = 1")
+	writeFile(t, dir, "sub/new.go", "package sub\n")
+	writeFile(t, dir, "sub/empty.go", "")
+	writeFile(t, dir, "gen/skip.go", "package gen\n")
+	writeFile(t, dir, "notes.txt", "not Go\n")
+	writeFile(t, dir, "a.go", "package a\n\nvar a = 1\n")
+
+	got, err := Untracked(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{
+		{Path: "new.go", Changes: []*Change{{Start: 1, End: 3}}, Hunks: []*Change{{Start: 1, End: 3}}, Patch: []Hunk{{
+			NewStart: 1, NewCount: 3, Lines: []string{"+package a", "+", "+var n = 1", `\ No newline at end of file`},
+		}}},
+		{Path: "sub/new.go", Changes: []*Change{{Start: 1, End: 1}}, Hunks: []*Change{{Start: 1, End: 1}}, Patch: []Hunk{{
+			NewStart: 1, NewCount: 1, Lines: []string{"+package sub"},
+		}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Untracked = %+v, want %+v", got, want)
+	}
+}
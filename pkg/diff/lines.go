@@ -0,0 +1,725 @@
+package diff
+
+import "bytes"
+
+// lineDiff marks the lines of two versions of a file removed from the old
+// one and added to the new one the way git diff does: by Myers' algorithm
+// as git's xdiff runs it, with each run of changed lines then slid to where
+// git's indent heuristic puts it.
+type lineDiff struct {
+	old, new       [][]byte
+	oldIDs, newIDs []int
+	removed, added []bool
+}
+
+// splitLines cuts content after every newline; a last line without one is
+// a line too.
+func splitLines(content []byte) [][]byte {
+	var lines [][]byte
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, content)
+			break
+		}
+		lines = append(lines, content[:i+1])
+		content = content[i+1:]
+	}
+	return lines
+}
+
+func newLineDiff(old, new []byte) *lineDiff {
+	d := &lineDiff{old: splitLines(old), new: splitLines(new)}
+	ids := make(map[string]int)
+	intern := func(lines [][]byte) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[string(line)]
+			if !ok {
+				id = len(ids)
+				ids[string(line)] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	d.oldIDs, d.newIDs = intern(d.old), intern(d.new)
+	// One extra entry on each side stands for the lines past the end, so
+	// runs of changes can be walked without bounds checks.
+	d.removed = make([]bool, len(d.old)+1)
+	d.added = make([]bool, len(d.new)+1)
+	d.compare()
+	compact(d.oldIDs, d.old, d.removed, d.added)
+	compact(d.newIDs, d.new, d.added, d.removed)
+	return d
+}
+
+// The limits of xdiff, the diff library of git, whose choices among equally
+// short diffs are followed so that the hunks match those of git diff.
+const (
+	maxEqualLimit   = 1024
+	simScanWindow   = 100
+	keepDiscardRun  = 4
+	maxCostMin      = 256
+	heuristicCost   = 256
+	snakeCount      = 20
+	heuristicFactor = 4
+)
+
+// compare marks the changed lines. Like git it first sets aside the ends
+// both versions share and the lines without a match on the other side,
+// then diffs what is left.
+func (d *lineDiff) compare() {
+	n1, n2 := len(d.oldIDs), len(d.newIDs)
+	start := 0
+	for start < n1 && start < n2 && d.oldIDs[start] == d.newIDs[start] {
+		start++
+	}
+	end := 0
+	for end < n1-start && end < n2-start && d.oldIDs[n1-1-end] == d.newIDs[n2-1-end] {
+		end++
+	}
+
+	count1, count2 := make(map[int]int), make(map[int]int)
+	for _, id := range d.oldIDs {
+		count1[id]++
+	}
+	for _, id := range d.newIDs {
+		count2[id]++
+	}
+	old := cleanup(d.oldIDs, start, n1-1-end, count2, d.removed)
+	new := cleanup(d.newIDs, start, n2-1-end, count1, d.added)
+
+	m := &myers{old: old, new: new}
+	diags := len(old.ids) + len(new.ids) + 3
+	m.offset = len(new.ids) + 1
+	m.forward, m.backward = make([]int, diags), make([]int, diags)
+	m.maxCost = max(bogoSqrt(diags), maxCostMin)
+	m.compare(0, len(old.ids), 0, len(new.ids), false)
+}
+
+// records are the lines of one side left to diff, by ID, with their index
+// in the file.
+type records struct {
+	ids   []int
+	index []int
+	c     changes
+}
+
+// cleanup returns the lines from start to end, inclusive, still worth
+// diffing: lines the other side lacks are changed, and so are lines found
+// many times over there amid runs of such lines.
+func cleanup(ids []int, start, end int, other map[int]int, c changes) records {
+	limit := min(bogoSqrt(len(ids)), maxEqualLimit)
+	discard := make([]byte, len(ids))
+	for i := start; i <= end; i++ {
+		switch n := other[ids[i]]; {
+		case n == 0:
+			discard[i] = 0
+		case n >= limit:
+			discard[i] = 2
+		default:
+			discard[i] = 1
+		}
+	}
+	r := records{c: c}
+	for i := start; i <= end; i++ {
+		if discard[i] == 1 || discard[i] == 2 && !discardMultiMatch(discard, i, start, end) {
+			r.ids = append(r.ids, ids[i])
+			r.index = append(r.index, i)
+		} else {
+			c[i] = true
+		}
+	}
+	return r
+}
+
+// discardMultiMatch reports whether line i, matched many times, sits in a
+// run of unmatched lines and should be set aside with them.
+func discardMultiMatch(discard []byte, i, start, end int) bool {
+	start = max(start, i-simScanWindow)
+	end = min(end, i+simScanWindow)
+	before, beforeMulti := 0, 1
+	for r := 1; i-r >= start; r++ {
+		if discard[i-r] == 0 {
+			before++
+		} else if discard[i-r] == 2 {
+			beforeMulti++
+		} else {
+			break
+		}
+	}
+	if before == 0 {
+		return false
+	}
+	after, afterMulti := 0, 1
+	for r := 1; i+r <= end; r++ {
+		if discard[i+r] == 0 {
+			after++
+		} else if discard[i+r] == 2 {
+			afterMulti++
+		} else {
+			break
+		}
+	}
+	if after == 0 {
+		return false
+	}
+	multi := beforeMulti + afterMulti
+	return multi*keepDiscardRun < multi+before+after
+}
+
+// bogoSqrt approximates the square root of n from above, as xdiff does.
+func bogoSqrt(n int) int {
+	i := 1
+	for ; n > 0; n >>= 2 {
+		i <<= 1
+	}
+	return i
+}
+
+// myers finds the changes between two sides with xdiff's variant of Myers'
+// algorithm, walking from both ends towards the middle of the shortest
+// edit script and splitting there. It gives up on the shortest script for
+// a good enough one when the edit cost grows large.
+type myers struct {
+	old, new          records
+	forward, backward []int
+	offset            int
+	maxCost           int
+}
+
+func (m *myers) compare(off1, lim1, off2, lim2 int, needMin bool) {
+	ha1, ha2 := m.old.ids, m.new.ids
+	for off1 < lim1 && off2 < lim2 && ha1[off1] == ha2[off2] {
+		off1++
+		off2++
+	}
+	for off1 < lim1 && off2 < lim2 && ha1[lim1-1] == ha2[lim2-1] {
+		lim1--
+		lim2--
+	}
+	switch {
+	case off1 == lim1:
+		for ; off2 < lim2; off2++ {
+			m.new.c[m.new.index[off2]] = true
+		}
+	case off2 == lim2:
+		for ; off1 < lim1; off1++ {
+			m.old.c[m.old.index[off1]] = true
+		}
+	default:
+		i1, i2, minLo, minHi := m.split(off1, lim1, off2, lim2, needMin)
+		m.compare(off1, i1, off2, i2, minLo)
+		m.compare(i1, lim1, i2, lim2, minHi)
+	}
+}
+
+// split returns where to divide the box from (off1, off2) to (lim1, lim2),
+// and whether each half needs its shortest script.
+func (m *myers) split(off1, lim1, off2, lim2 int, needMin bool) (int, int, bool, bool) {
+	ha1, ha2 := m.old.ids, m.new.ids
+	kf := func(d int) *int { return &m.forward[d+m.offset] }
+	kb := func(d int) *int { return &m.backward[d+m.offset] }
+	const lineMax = int(^uint(0) >> 1)
+
+	dmin, dmax := off1-lim2, lim1-off2
+	fmid, bmid := off1-off2, lim1-lim2
+	odd := (fmid-bmid)&1 != 0
+	fmin, fmax := fmid, fmid
+	bmin, bmax := bmid, bmid
+	*kf(fmid) = off1
+	*kb(bmid) = lim1
+
+	for ec := 1; ; ec++ {
+		gotSnake := false
+
+		if fmin > dmin {
+			fmin--
+			*kf(fmin - 1) = -1
+		} else {
+			fmin++
+		}
+		if fmax < dmax {
+			fmax++
+			*kf(fmax + 1) = -1
+		} else {
+			fmax--
+		}
+		for d := fmax; d >= fmin; d -= 2 {
+			var i1 int
+			if *kf(d - 1) >= *kf(d + 1) {
+				i1 = *kf(d - 1) + 1
+			} else {
+				i1 = *kf(d + 1)
+			}
+			prev := i1
+			i2 := i1 - d
+			for i1 < lim1 && i2 < lim2 && ha1[i1] == ha2[i2] {
+				i1++
+				i2++
+			}
+			if i1-prev > snakeCount {
+				gotSnake = true
+			}
+			*kf(d) = i1
+			if odd && bmin <= d && d <= bmax && *kb(d) <= i1 {
+				return i1, i2, true, true
+			}
+		}
+
+		if bmin > dmin {
+			bmin--
+			*kb(bmin - 1) = lineMax
+		} else {
+			bmin++
+		}
+		if bmax < dmax {
+			bmax++
+			*kb(bmax + 1) = lineMax
+		} else {
+			bmax--
+		}
+		for d := bmax; d >= bmin; d -= 2 {
+			var i1 int
+			if *kb(d - 1) < *kb(d + 1) {
+				i1 = *kb(d - 1)
+			} else {
+				i1 = *kb(d + 1) - 1
+			}
+			prev := i1
+			i2 := i1 - d
+			for i1 > off1 && i2 > off2 && ha1[i1-1] == ha2[i2-1] {
+				i1--
+				i2--
+			}
+			if prev-i1 > snakeCount {
+				gotSnake = true
+			}
+			*kb(d) = i1
+			if !odd && fmin <= d && d <= fmax && i1 <= *kf(d) {
+				return i1, i2, true, true
+			}
+		}
+
+		if needMin {
+			continue
+		}
+
+		// Past a cost, a long enough snake reaching far along its
+		// diagonal is taken as the split.
+		if gotSnake && ec > heuristicCost {
+			best, s1, s2 := 0, 0, 0
+			for d := fmax; d >= fmin; d -= 2 {
+				dd := abs(d - fmid)
+				i1 := *kf(d)
+				i2 := i1 - d
+				v := (i1 - off1) + (i2 - off2) - dd
+				if v > heuristicFactor*ec && v > best &&
+					off1+snakeCount <= i1 && i1 < lim1 && off2+snakeCount <= i2 && i2 < lim2 {
+					for k := 1; ha1[i1-k] == ha2[i2-k]; k++ {
+						if k == snakeCount {
+							best, s1, s2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return s1, s2, true, false
+			}
+			for d := bmax; d >= bmin; d -= 2 {
+				dd := abs(d - bmid)
+				i1 := *kb(d)
+				i2 := i1 - d
+				v := (lim1 - i1) + (lim2 - i2) - dd
+				if v > heuristicFactor*ec && v > best &&
+					off1 < i1 && i1 <= lim1-snakeCount && off2 < i2 && i2 <= lim2-snakeCount {
+					for k := 0; ha1[i1+k] == ha2[i2+k]; k++ {
+						if k == snakeCount-1 {
+							best, s1, s2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return s1, s2, false, true
+			}
+		}
+
+		// Enough is enough: split where the furthest reaching path got.
+		if ec >= m.maxCost {
+			fbest, fbest1 := -1, -1
+			for d := fmax; d >= fmin; d -= 2 {
+				i1 := min(*kf(d), lim1)
+				i2 := i1 - d
+				if lim2 < i2 {
+					i1, i2 = lim2+d, lim2
+				}
+				if fbest < i1+i2 {
+					fbest, fbest1 = i1+i2, i1
+				}
+			}
+			bbest, bbest1 := lineMax, lineMax
+			for d := bmax; d >= bmin; d -= 2 {
+				i1 := max(off1, *kb(d))
+				i2 := i1 - d
+				if i2 < off2 {
+					i1, i2 = off2+d, off2
+				}
+				if i1+i2 < bbest {
+					bbest, bbest1 = i1+i2, i1
+				}
+			}
+			if (lim1+lim2)-bbest < fbest-(off1+off2) {
+				return fbest1, fbest - fbest1, true, false
+			}
+			return bbest1, bbest - bbest1, false, true
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// The weights of git's indent heuristic, which picks where among the places
+// a run of changes could slide to it reads best.
+const (
+	maxIndent  = 200
+	maxBlanks  = 20
+	maxSliding = 100
+
+	startOfFilePenalty              = 1
+	endOfFilePenalty                = 21
+	totalBlankWeight                = -30
+	postBlankWeight                 = 6
+	relativeIndentPenalty           = -4
+	relativeIndentWithBlankPenalty  = 10
+	relativeOutdentPenalty          = 24
+	relativeOutdentWithBlankPenalty = 17
+	relativeDedentPenalty           = 23
+	relativeDedentWithBlankPenalty  = 17
+	indentWeight                    = 60
+)
+
+// group is a run of changed lines, start inclusive and end exclusive; an
+// empty one sits between two unchanged lines.
+type group struct{ start, end int }
+
+// changes tells the changed lines of one side apart, answering false
+// outside of it.
+type changes []bool
+
+func (c changes) at(i int) bool { return i >= 0 && i < len(c) && c[i] }
+
+func (c changes) first() group {
+	g := group{}
+	for c.at(g.end) {
+		g.end++
+	}
+	return g
+}
+
+func (c changes) next(g *group) bool {
+	if g.end >= len(c)-1 {
+		return false
+	}
+	g.start = g.end + 1
+	g.end = g.start
+	for c.at(g.end) {
+		g.end++
+	}
+	return true
+}
+
+func (c changes) previous(g *group) bool {
+	if g.start == 0 {
+		return false
+	}
+	g.end = g.start - 1
+	g.start = g.end
+	for c.at(g.start - 1) {
+		g.start--
+	}
+	return true
+}
+
+// slideDown moves g one line down when the line after it equals its first,
+// merging it with the next group if they meet.
+func (c changes) slideDown(ids []int, g *group) bool {
+	if g.end >= len(ids) || ids[g.start] != ids[g.end] {
+		return false
+	}
+	c[g.start], c[g.end] = false, true
+	g.start++
+	g.end++
+	for c.at(g.end) {
+		g.end++
+	}
+	return true
+}
+
+func (c changes) slideUp(ids []int, g *group) bool {
+	if g.start == 0 || ids[g.start-1] != ids[g.end-1] {
+		return false
+	}
+	g.start--
+	g.end--
+	c[g.start], c[g.end] = true, false
+	for c.at(g.start - 1) {
+		g.start--
+	}
+	return true
+}
+
+// compact slides each group of changes of one side, ids and lines with
+// changed lines c, to where git diff shows it: next to a group of the other
+// side, other, if it can, else where the indent heuristic scores best.
+func compact(ids []int, lines [][]byte, c, other changes) {
+	g, og := c.first(), other.first()
+	for {
+		if g.end != g.start {
+			var size, earliestEnd, endMatchingOther int
+			for {
+				size = g.end - g.start
+				// The last end aligning the group with changes of the
+				// other side, if any.
+				endMatchingOther = -1
+				for c.slideUp(ids, &g) {
+					other.previous(&og)
+				}
+				earliestEnd = g.end
+				if og.end > og.start {
+					endMatchingOther = g.end
+				}
+				for c.slideDown(ids, &g) {
+					other.next(&og)
+					if og.end > og.start {
+						endMatchingOther = g.end
+					}
+				}
+				if size == g.end-g.start {
+					break
+				}
+			}
+
+			switch {
+			case g.end == earliestEnd:
+			case endMatchingOther != -1:
+				for og.end == og.start {
+					c.slideUp(ids, &g)
+					other.previous(&og)
+				}
+			default:
+				shift := earliestEnd
+				if g.end-size-1 > shift {
+					shift = g.end - size - 1
+				}
+				if g.end-maxSliding > shift {
+					shift = g.end - maxSliding
+				}
+				bestShift := -1
+				var best splitScore
+				for ; shift <= g.end; shift++ {
+					var score splitScore
+					score.add(measureSplit(lines, shift))
+					score.add(measureSplit(lines, shift-size))
+					if bestShift == -1 || score.compare(best) <= 0 {
+						best, bestShift = score, shift
+					}
+				}
+				for g.end > bestShift {
+					c.slideUp(ids, &g)
+					other.previous(&og)
+				}
+			}
+		}
+		if !c.next(&g) {
+			return
+		}
+		other.next(&og)
+	}
+}
+
+// indent is the width of the leading whitespace of line, tabs to multiples
+// of 8, or -1 for a blank line.
+func indent(line []byte) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 8 - n%8
+		case '\n', '\r', '\v', '\f':
+		default:
+			return n
+		}
+		if n >= maxIndent {
+			return maxIndent
+		}
+	}
+	return -1
+}
+
+// splitMeasure describes the lines around a place to split a file at.
+type splitMeasure struct {
+	endOfFile             bool
+	indent                int
+	preBlank, preIndent   int
+	postBlank, postIndent int
+}
+
+func measureSplit(lines [][]byte, split int) splitMeasure {
+	m := splitMeasure{indent: -1, preIndent: -1, postIndent: -1}
+	if split >= len(lines) {
+		m.endOfFile = true
+	} else {
+		m.indent = indent(lines[split])
+	}
+	for i := split - 1; i >= 0; i-- {
+		if m.preIndent = indent(lines[i]); m.preIndent != -1 {
+			break
+		}
+		if m.preBlank++; m.preBlank == maxBlanks {
+			m.preIndent = 0
+			break
+		}
+	}
+	for i := split + 1; i < len(lines); i++ {
+		if m.postIndent = indent(lines[i]); m.postIndent != -1 {
+			break
+		}
+		if m.postBlank++; m.postBlank == maxBlanks {
+			m.postIndent = 0
+			break
+		}
+	}
+	return m
+}
+
+type splitScore struct{ effectiveIndent, penalty int }
+
+func (s *splitScore) add(m splitMeasure) {
+	if m.preIndent == -1 && m.preBlank == 0 {
+		s.penalty += startOfFilePenalty
+	}
+	if m.endOfFile {
+		s.penalty += endOfFilePenalty
+	}
+	postBlank := 0
+	if m.indent == -1 {
+		postBlank = 1 + m.postBlank
+	}
+	totalBlank := m.preBlank + postBlank
+	s.penalty += totalBlankWeight*totalBlank + postBlankWeight*postBlank
+
+	indent := m.indent
+	if indent == -1 {
+		indent = m.postIndent
+	}
+	anyBlanks := totalBlank != 0
+	s.effectiveIndent += indent
+	switch {
+	case indent == -1 || m.preIndent == -1 || indent == m.preIndent:
+	case indent > m.preIndent:
+		s.penalty += pick(anyBlanks, relativeIndentWithBlankPenalty, relativeIndentPenalty)
+	case m.postIndent != -1 && m.postIndent > indent:
+		s.penalty += pick(anyBlanks, relativeOutdentWithBlankPenalty, relativeOutdentPenalty)
+	default:
+		s.penalty += pick(anyBlanks, relativeDedentWithBlankPenalty, relativeDedentPenalty)
+	}
+}
+
+func (s splitScore) compare(o splitScore) int {
+	cmp := 0
+	switch {
+	case s.effectiveIndent > o.effectiveIndent:
+		cmp = 1
+	case s.effectiveIndent < o.effectiveIndent:
+		cmp = -1
+	}
+	return indentWeight*cmp + s.penalty - o.penalty
+}
+
+func pick(cond bool, yes, no int) int {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// hunks groups the changes into hunks with up to context unchanged lines
+// around them, merging hunks that would overlap, as in a unified diff.
+func (d *lineDiff) hunks(context int) []Hunk {
+	var hunks []Hunk
+	i, j := 0, 0
+	for i < len(d.old) || j < len(d.new) {
+		if !d.removed[i] && !d.added[j] {
+			i++
+			j++
+			continue
+		}
+		// A change starts at old line i and new line j; take in the
+		// context before it, then the changes and the unchanged lines
+		// between them while those are few enough to keep in one hunk.
+		before := min(context, i, j)
+		h := Hunk{OldStart: i - before, NewStart: j - before}
+		for k := before; k > 0; k-- {
+			h.Lines = appendBody(h.Lines, ' ', d.old[i-k])
+		}
+		for {
+			for ; d.removed[i]; i++ {
+				h.Lines = appendBody(h.Lines, '-', d.old[i])
+			}
+			for ; d.added[j]; j++ {
+				h.Lines = appendBody(h.Lines, '+', d.new[j])
+			}
+			same := 0
+			for i+same < len(d.old) && j+same < len(d.new) && !d.removed[i+same] && !d.added[j+same] {
+				same++
+			}
+			end := i+same == len(d.old) && j+same == len(d.new)
+			if end || same > 2*context {
+				for k := 0; k < min(same, context); k++ {
+					h.Lines = appendBody(h.Lines, ' ', d.old[i+k])
+				}
+				i += min(same, context)
+				j += min(same, context)
+				break
+			}
+			for k := 0; k < same; k++ {
+				h.Lines = appendBody(h.Lines, ' ', d.old[i+k])
+			}
+			i += same
+			j += same
+		}
+		h.OldCount, h.NewCount = i-h.OldStart, j-h.NewStart
+		// Hunks count lines from 1, and an empty side starts at the line
+		// before it.
+		if h.OldCount > 0 {
+			h.OldStart++
+		}
+		if h.NewCount > 0 {
+			h.NewStart++
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+// appendBody adds line to a hunk behind its prefix, without its newline,
+// saying so if it has none.
+func appendBody(lines []string, prefix byte, line []byte) []string {
+	text, ok := bytes.CutSuffix(line, []byte("\n"))
+	lines = append(lines, string(prefix)+string(text))
+	if !ok {
+		lines = append(lines, `\ No newline at end of file`)
+	}
+	return lines
+}
@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minMovedAlnum is how many letters and digits a block of lines needs to
+// count as moved, as with git diff --color-moved, so that a stray "}" is
+// never taken for moved code.
+const minMovedAlnum = 20
+
+// removedLine is a line a diff removes, in a run of removed lines.
+type removedLine struct {
+	run, at int
+}
+
+// movedLines finds, for each patch, the lines of the new file that were
+// removed elsewhere in the same diff: blocks of added lines that continue,
+// line after line, a block of removed lines, the way git diff --color-moved
+// finds them.
+func movedLines(patches []Patch) [][]int {
+	var runs [][]string
+	index := make(map[string][]removedLine)
+	for _, patch := range patches {
+		for _, h := range patch.Hunks {
+			var run []string
+			flush := func() {
+				if len(run) > 0 {
+					runs = append(runs, run)
+					run = nil
+				}
+			}
+			for _, body := range h.Lines {
+				if text, ok := strings.CutPrefix(body, "-"); ok {
+					index[text] = append(index[text], removedLine{run: len(runs), at: len(run)})
+					run = append(run, text)
+				} else if !strings.HasPrefix(body, `\`) {
+					flush()
+				}
+			}
+			flush()
+		}
+	}
+
+	moved := make([][]int, len(patches))
+	for i, patch := range patches {
+		for _, h := range patch.Hunks {
+			line := h.NewStart
+			if h.NewCount == 0 {
+				line++
+			}
+			var (
+				candidates []removedLine
+				block      []int
+				alnum      int
+			)
+			endBlock := func() {
+				if alnum >= minMovedAlnum {
+					moved[i] = append(moved[i], block...)
+				}
+				candidates, block, alnum = nil, nil, 0
+			}
+			for _, body := range h.Lines {
+				text, added := strings.CutPrefix(body, "+")
+				switch {
+				case added:
+					// Follow the blocks being moved to this line; when
+					// none goes on, a new block may start here.
+					var next []removedLine
+					for _, c := range candidates {
+						if c.at+1 < len(runs[c.run]) && runs[c.run][c.at+1] == text {
+							next = append(next, removedLine{run: c.run, at: c.at + 1})
+						}
+					}
+					if len(next) == 0 {
+						endBlock()
+						next = index[text]
+					}
+					if candidates = next; len(candidates) > 0 {
+						block = append(block, line)
+						alnum += countAlnum(text)
+					}
+					line++
+				case strings.HasPrefix(body, `\`):
+				default:
+					endBlock()
+					if !strings.HasPrefix(body, "-") {
+						line++
+					}
+				}
+			}
+			endBlock()
+		}
+	}
+	return moved
+}
+
+func countAlnum(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// SkipMoved leaves out of changes the lines that were moved rather than
+// written, so code moved verbatim does not bring its old issues along.
+// Files left without changed lines keep their deletions.
+func SkipMoved(changes []FileChange) []FileChange {
+	kept := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		if len(change.Moved) > 0 {
+			var remaining []*Change
+			for _, c := range change.Changes {
+				for line := c.Start; line <= c.End; line++ {
+					if !overlaps(change.Moved, line, line) {
+						remaining = appendLine(remaining, line)
+					}
+				}
+			}
+			change.Changes = remaining
+		}
+		if len(change.Changes) == 0 && len(change.Deletions) == 0 {
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept
+}
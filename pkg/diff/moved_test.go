@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMovedLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  map[string][]*Change
+	}{
+		{
+			name: "function moved to another file",
+			patch: `--- a/a.go
++++ b/a.go
+@@ -1,7 +1,2 @@
+ package a
+ 
+-func helper(values []int) int {
+-	return len(values)
+-}
+-
+-var keep = 1
+--- a/b.go
++++ b/b.go
+@@ -1,2 +1,6 @@
+ package a
+ 
++func helper(values []int) int {
++	return len(values)
++}
++
++var added = 2
+`,
+			want: map[string][]*Change{"b.go": {{Start: 3, End: 6}}},
+		},
+		{
+			name: "edited while moving",
+			patch: `--- a/a.go
++++ b/a.go
+@@ -1,6 +1,6 @@
+-func helper(values []int) int {
+-	return len(values)
+-}
+ package a
+ 
++func helper(values []int) int {
++	return cap(values)
++}
+ var x = 1
+`,
+			want: map[string][]*Change{"a.go": {{Start: 3, End: 3}}},
+		},
+		{
+			name: "too short to be a move",
+			patch: `--- a/a.go
++++ b/a.go
+@@ -1,4 +1,4 @@
+ package a
+-}
+ var x = 1
++}
+ var y = 2
+`,
+			want: map[string][]*Change{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Parse(strings.NewReader(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string][]*Change)
+			for _, change := range changes {
+				if len(change.Moved) > 0 {
+					got[change.Path] = change.Moved
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moved = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipMoved(t *testing.T) {
+	changes := []FileChange{
+		{Path: "a.go", Changes: []*Change{{Start: 3, End: 8}}, Moved: []*Change{{Start: 3, End: 5}, {Start: 7, End: 7}}},
+		{Path: "b.go", Changes: []*Change{{Start: 1, End: 2}}, Moved: []*Change{{Start: 1, End: 2}}},
+		{Path: "c.go", Changes: []*Change{{Start: 1, End: 2}}, Moved: []*Change{{Start: 1, End: 2}}, Deletions: []int{4}},
+	}
+	want := []FileChange{
+		{Path: "a.go", Changes: []*Change{{Start: 6, End: 6}, {Start: 8, End: 8}}, Moved: []*Change{{Start: 3, End: 5}, {Start: 7, End: 7}}},
+		{Path: "c.go", Moved: []*Change{{Start: 1, End: 2}}, Deletions: []int{4}},
+	}
+	if got := SkipMoved(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("SkipMoved = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,292 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"linter/pkg/gitrepo"
+)
+
+// Engines lists the ways git changes can be read: by running git, or
+// in-process.
+var Engines = []string{EngineExec, EngineNative}
+
+const (
+	EngineExec   = "exec"
+	EngineNative = "native"
+)
+
+// ValidEngine reports whether engine is one of Engines.
+func ValidEngine(engine string) bool {
+	for _, known := range Engines {
+		if engine == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NativeGit is git read in-process with pkg/gitrepo, so finding the root
+// needs no git binary; FindNative and FindStagedNative read the changes.
+var NativeGit = VCS{
+	Name:         Git.Name,
+	DiffCommand:  Git.DiffCommand,
+	sinceCommand: Git.sinceCommand,
+	root: func(pwd string) (string, error) {
+		repo, err := gitrepo.Open(pwd)
+		if err != nil {
+			return "", err
+		}
+		return repo.Root(), nil
+	},
+}
+
+// contextLines is how many unchanged lines git diff shows around changes.
+const contextLines = 3
+
+// binaryPeek is how far git looks for a NUL byte to call a file binary.
+const binaryPeek = 8000
+
+// file is a version of a file being compared. Working tree files carry
+// the content read to hash them; other versions are read from the
+// repository by hash.
+type file struct {
+	gitrepo.Entry
+	content []byte
+}
+
+func (f file) read(repo *gitrepo.Repo) ([]byte, error) {
+	if f.content != nil {
+		return f.content, nil
+	}
+	return repo.Blob(f.Hash)
+}
+
+// FindNative compares the working tree of the git repository containing
+// pwd with the point where HEAD forked from base, or with the index when
+// base is empty, as Git's SinceCommand or DiffCommand would, without running
+// git. Only renames of unchanged files are detected.
+func FindNative(pwd, base string) ([]FileChange, error) {
+	repo, err := gitrepo.Open(pwd)
+	if err != nil {
+		return nil, err
+	}
+	index, indexTime, err := repo.Index()
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := worktreeFiles(repo, index, indexTime)
+	if err != nil {
+		return nil, err
+	}
+
+	old := indexFiles(index)
+	if base != "" {
+		head, err := repo.Resolve("HEAD")
+		if err != nil {
+			return nil, err
+		}
+		ref, err := repo.Resolve(base)
+		if errors.Is(err, gitrepo.ErrUnknownRevision) {
+			return nil, fmt.Errorf("%w: %w", err, ErrMissingRef)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fork, err := repo.MergeBase(head, ref)
+		if errors.Is(err, gitrepo.ErrNoMergeBase) && repo.Shallow() {
+			return nil, fmt.Errorf("merge base of HEAD and %s: %w", base, ErrShallowHistory)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if old, err = commitFiles(repo, fork); err != nil {
+			return nil, err
+		}
+	}
+	patches, err := comparePatches(repo, old, worktree, contextLines)
+	if err != nil {
+		return nil, err
+	}
+	return fileChanges(patches), nil
+}
+
+// FindStagedNative is FindStaged without running git.
+func FindStagedNative(pwd string) ([]FileChange, error) {
+	repo, err := gitrepo.Open(pwd)
+	if err != nil {
+		return nil, err
+	}
+	index, indexTime, err := repo.Index()
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := worktreeFiles(repo, index, indexTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Before the first commit everything staged is new.
+	head := map[string]file{}
+	if hash, err := repo.Resolve("HEAD"); err == nil {
+		if head, err = commitFiles(repo, hash); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, gitrepo.ErrUnknownRevision) {
+		return nil, err
+	}
+
+	staged, err := comparePatches(repo, head, indexFiles(index), 0)
+	if err != nil {
+		return nil, err
+	}
+	unstagedPatches, err := comparePatches(repo, indexFiles(index), worktree, 0)
+	if err != nil {
+		return nil, err
+	}
+	unstaged := make(map[string][]Hunk, len(unstagedPatches))
+	for _, patch := range unstagedPatches {
+		unstaged[patch.OldPath] = append(unstaged[patch.OldPath], patch.Hunks...)
+	}
+	return translateStaged(fileChanges(staged), unstaged), nil
+}
+
+func commitFiles(repo *gitrepo.Repo, commit gitrepo.Hash) (map[string]file, error) {
+	entries, err := repo.Files(commit)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]file, len(entries))
+	for path, entry := range entries {
+		files[path] = file{Entry: entry}
+	}
+	return files, nil
+}
+
+// indexFiles lists the content staged in index; files only intended to
+// be added have none yet.
+func indexFiles(index map[string]gitrepo.IndexEntry) map[string]file {
+	files := make(map[string]file, len(index))
+	for path, entry := range index {
+		if !entry.IntentToAdd {
+			files[path] = file{Entry: entry.Entry}
+		}
+	}
+	return files
+}
+
+// worktreeFiles lists the files of the working tree git tracks, those of
+// index, taking those outside a sparse checkout as staged. Like git, it trusts a file whose size and time of modification are
+// those recorded in the index to be unchanged, unless the index was written
+// in the same instant, and only reads and hashes the others.
+func worktreeFiles(repo *gitrepo.Repo, index map[string]gitrepo.IndexEntry, indexTime time.Time) (map[string]file, error) {
+	files := make(map[string]file, len(index))
+	for path, entry := range index {
+		if entry.SkipWorktree {
+			files[path] = file{Entry: entry.Entry}
+			continue
+		}
+		name := filepath.Join(repo.Root(), filepath.FromSlash(path))
+		info, err := os.Lstat(name)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case entry.Mode == gitrepo.ModeSubmodule:
+			files[path] = file{Entry: entry.Entry}
+		case info.Mode()&fs.ModeSymlink != 0:
+			files[path] = file{Entry: gitrepo.Entry{Mode: gitrepo.ModeSymlink}}
+		case !info.Mode().IsRegular():
+		case !entry.IntentToAdd && int64(entry.Size) == info.Size()&0xffffffff &&
+			entry.ModTime.Equal(info.ModTime()) && info.ModTime().Before(indexTime):
+			files[path] = file{Entry: entry.Entry}
+		default:
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			mode := uint32(gitrepo.ModeRegular)
+			if info.Mode()&0o111 != 0 {
+				mode = gitrepo.ModeExecutable
+			}
+			files[path] = file{Entry: gitrepo.Entry{Mode: mode, Hash: gitrepo.HashBlob(content)}, content: content}
+		}
+	}
+	return files, nil
+}
+
+// comparePatches diffs the text files of new that differ from old, with
+// context lines around each change, in the order of their paths. Files
+// only in old were removed and have no lines left to lint, and a file
+// added to new with the content of a removed one is a rename without
+// changes. Binary files, symbolic links and submodules have no lines to
+// compare.
+func comparePatches(repo *gitrepo.Repo, old, new map[string]file, context int) ([]Patch, error) {
+	removed := make(map[gitrepo.Hash]int)
+	for path, f := range old {
+		if _, ok := new[path]; !ok {
+			removed[f.Hash]++
+		}
+	}
+	paths := make([]string, 0, len(new))
+	for path, f := range new {
+		if _, ok := old[path]; !ok && removed[f.Hash] > 0 {
+			removed[f.Hash]--
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var patches []Patch
+	for _, path := range paths {
+		oldFile, inOld := old[path]
+		newFile := new[path]
+		if inOld && oldFile.Hash == newFile.Hash || !isText(newFile) || inOld && !isText(oldFile) {
+			continue
+		}
+
+		patch := Patch{OldPath: devNull, NewPath: path}
+		var oldContent []byte
+		if inOld {
+			patch.OldPath = path
+			var err error
+			if oldContent, err = oldFile.read(repo); err != nil {
+				return nil, err
+			}
+		}
+		newContent, err := newFile.read(repo)
+		if err != nil {
+			return nil, err
+		}
+		if isBinary(oldContent) || isBinary(newContent) {
+			continue
+		}
+		patch.Hunks = newLineDiff(oldContent, newContent).hunks(context)
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// isText reports whether f is a regular file.
+func isText(f file) bool {
+	return f.Mode == gitrepo.ModeRegular || f.Mode == gitrepo.ModeExecutable
+}
+
+func isBinary(content []byte) bool {
+	if len(content) > binaryPeek {
+		content = content[:binaryPeek]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
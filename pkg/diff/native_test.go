@@ -0,0 +1,254 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"linter/pkg/gitrepo"
+)
+
+// gitHunks diffs old and new with git diff --no-index.
+func gitHunks(t *testing.T, dir, old, new string) []Hunk {
+	t.Helper()
+	writeFile(t, dir, "old", old)
+	writeFile(t, dir, "new", new)
+	cmd := exec.Command("git", "diff", "--no-index", "old", "new")
+	cmd.Dir = dir
+	// git diff --no-index exits with 1 when the files differ.
+	output, _ := cmd.Output()
+	patches, err := ParsePatches(bytes.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+	return patches[0].Hunks
+}
+
+func TestLineDiffMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		old, new string
+	}{
+		{name: "identical", old: "a\nb\n", new: "a\nb\n"},
+		{name: "new file", new: "package a\n\nvar a = 1\n"},
+		{name: "emptied", old: "package a\n\nvar a = 1\n"},
+		{name: "missing newline", old: "a\nb", new: "a\nb\n"},
+		{
+			name: "function added after a similar one",
+			old:  "package a\n\nfunc one() {\n\treturn\n}\n\nfunc three() {\n\treturn\n}\n",
+			new:  "package a\n\nfunc one() {\n\treturn\n}\n\nfunc two() {\n\treturn\n}\n\nfunc three() {\n\treturn\n}\n",
+		},
+		{
+			name: "distant changes",
+			old:  strings.Repeat("x\n", 5) + "a\n" + strings.Repeat("x\n", 7) + "b\n" + strings.Repeat("x\n", 5),
+			new:  strings.Repeat("x\n", 5) + "A\n" + strings.Repeat("x\n", 7) + "B\n" + strings.Repeat("x\n", 5),
+		},
+		{
+			name: "nearby changes",
+			old:  strings.Repeat("x\n", 5) + "a\n" + strings.Repeat("x\n", 6) + "b\n" + strings.Repeat("x\n", 5),
+			new:  strings.Repeat("x\n", 5) + "A\n" + strings.Repeat("x\n", 6) + "B\n" + strings.Repeat("x\n", 5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newLineDiff([]byte(tt.old), []byte(tt.new)).hunks(contextLines)
+			if want := gitHunks(t, dir, tt.old, tt.new); !reflect.DeepEqual(got, want) {
+				t.Errorf("hunks = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	// Random edits of code-like files with many repeated lines, where git
+	// has the most equally short diffs to choose from.
+	pool := []string{"}", "", "\treturn nil", "func f() {", "\tx := 1", "\tif err != nil {", "\t\treturn err", "\t}", "// c"}
+	for i := 0; i < 50; i++ {
+		pool = append(pool, fmt.Sprintf("\tv%d := %d", i, i%7))
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		lines := make([]string, r.Intn(300))
+		for j := range lines {
+			lines[j] = pool[r.Intn(len(pool))] + "\n"
+		}
+		old := strings.Join(lines, "")
+		for edits := r.Intn(30) + 1; edits > 0; edits-- {
+			j := r.Intn(len(lines) + 1)
+			switch {
+			case r.Intn(3) == 0 || j == len(lines):
+				lines = append(lines[:j], append([]string{pool[r.Intn(len(pool))] + "\n"}, lines[j:]...)...)
+			case r.Intn(2) == 0:
+				lines = append(lines[:j], lines[j+1:]...)
+			default:
+				lines[j] = pool[r.Intn(len(pool))] + "\n"
+			}
+		}
+		new := strings.Join(lines, "")
+		got := newLineDiff([]byte(old), []byte(new)).hunks(contextLines)
+		if want := gitHunks(t, dir, old, new); !reflect.DeepEqual(got, want) {
+			t.Fatalf("case %d: hunks = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestFindNativeMatchesGit(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "b.go", "package a\n\nvar (\n\tx = 1\n\ty = 2\n\tz = 3\n)\n")
+	writeFile(t, dir, "c.go", "package a\n\nvar w = 0\n")
+	writeFile(t, dir, "moved.go", "package a\n\nvar m = 0\n")
+	writeFile(t, dir, "gone.go", "package a\n")
+	writeFile(t, dir, "blob.bin", "\x00\x01")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "second")
+	git(t, dir, "branch", "base")
+	writeFile(t, dir, "sub/d.go", "package sub\n\nfunc d() {}\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "third")
+
+	writeFile(t, dir, "b.go", "package a\n\nvar (\n\tx = 1\n\ty = 20\n\tz = 3\n)\n")
+	writeFile(t, dir, "c.go", "package a\n")
+	writeFile(t, dir, "sub/d.go", "package sub\n\nfunc d() {\n\tprintln()\n}\n")
+	writeFile(t, dir, "blob.bin", "\x00\x02")
+	writeFile(t, dir, "new.go", "package a\n\nvar n = 1\n")
+	writeFile(t, dir, "untracked.go", "package a\n")
+	git(t, dir, "add", "--intent-to-add", "new.go")
+	git(t, dir, "mv", "moved.go", "renamed.go")
+	if err := os.Remove(filepath.Join(dir, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, base := range []string{"", "base", "HEAD~1"} {
+		t.Run("base "+base, func(t *testing.T) {
+			cmd := "git diff"
+			if base != "" {
+				cmd += " " + git(t, dir, "merge-base", "HEAD", base)
+			}
+			want, err := Find(context.Background(), dir, cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := FindNative(filepath.Join(dir, "sub"), base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 || !reflect.DeepEqual(got, want) {
+				t.Errorf("FindNative = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFindNativeSparseCheckout(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "sub/d.go", "package sub\n")
+	writeFile(t, dir, "other/e.go", "package other\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "second")
+	git(t, dir, "branch", "base")
+	writeFile(t, dir, "other/e.go", "package other\n\nvar e = 1\n")
+	git(t, dir, "commit", "-q", "-am", "third")
+	git(t, dir, "sparse-checkout", "set", "--cone", "--no-sparse-index", "sub")
+	writeFile(t, dir, "sub/d.go", "package sub\n\nvar d = 1\n")
+
+	for _, base := range []string{"", "base"} {
+		t.Run("base "+base, func(t *testing.T) {
+			cmd := "git diff"
+			if base != "" {
+				cmd += " " + git(t, dir, "merge-base", "HEAD", base)
+			}
+			want, err := Find(context.Background(), dir, cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := FindNative(dir, base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 || !reflect.DeepEqual(got, want) {
+				t.Errorf("FindNative = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	git(t, dir, "sparse-checkout", "set", "--cone", "--sparse-index", "sub")
+	if _, err := FindNative(dir, ""); !errors.Is(err, gitrepo.ErrSparseIndex) {
+		t.Errorf("FindNative with a sparse index = %v, want ErrSparseIndex", err)
+	}
+}
+
+func TestFindStagedNativeMatchesGit(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 1\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "b")
+
+	writeFile(t, dir, "a.go", "package a\n\nvar a = 1\n")
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 2\n")
+	writeFile(t, dir, "c.go", "package a\n\nvar c = 3\n")
+	git(t, dir, "add", ".")
+	writeFile(t, dir, "a.go", "package a\n\n// doc\nvar a = 1\n")
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 3\n")
+
+	want, err := FindStaged(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FindStagedNative(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStagedNative = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindStagedNativeBeforeFirstCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	writeFile(t, dir, "a.go", "package a\n")
+	git(t, dir, "add", ".")
+
+	got, err := FindStagedNative(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{{Path: "a.go", Changes: []*Change{{Start: 1, End: 1}}, Hunks: []*Change{{Start: 1, End: 1}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStagedNative = %+v, want %+v", got, want)
+	}
+}
+
+func TestNativeGitRoot(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "sub/c.go", "package sub\n")
+
+	want, err := Git.Root(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NativeGit.Root(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Root = %q, want %q", got, want)
+	}
+}
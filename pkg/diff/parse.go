@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const devNull = "/dev/null"
+
+// Patch is the part of a unified diff describing one file.
+type Patch struct {
+	OldPath string
+	NewPath string
+	// Renamed and Copied tell how git related NewPath to OldPath.
+	Renamed bool
+	Copied  bool
+	Hunks   []Hunk
+}
+
+// Hunk is one "@@" section of a patch with its body lines, each still
+// carrying its ' ', '+' or '-' prefix.
+type Hunk struct {
+	OldStart, OldCount int
+	NewStart, NewCount int
+	Lines              []string
+}
+
+// Deleted reports whether the patch removes the file.
+func (p Patch) Deleted() bool {
+	return p.NewPath == devNull
+}
+
+// FileChange collects the lines the patch adds to the new file and where it
+// removes lines. Context lines are walked past but not recorded, so an issue
+// on an untouched line next to an edit is not attributed to the change.
+func (p Patch) FileChange() FileChange {
+	var (
+		changes, hunks []*Change
+		deletions      []int
+	)
+	for _, h := range p.Hunks {
+		if h.NewCount > 0 {
+			hunks = append(hunks, &Change{Start: h.NewStart, End: h.NewStart + h.NewCount - 1})
+		}
+		line := h.NewStart
+		if h.NewCount == 0 {
+			// An empty new side starts at the line before it.
+			line++
+		}
+		// Removed lines replaced by added ones are covered by the added
+		// lines; only those removed outright are recorded.
+		removed := false
+		for _, body := range h.Lines {
+			switch {
+			case strings.HasPrefix(body, "+"):
+				changes = appendLine(changes, line)
+				line++
+				removed = false
+			case strings.HasPrefix(body, "-"):
+				removed = true
+			case strings.HasPrefix(body, " "), body == "":
+				if removed {
+					deletions = append(deletions, line-1)
+					removed = false
+				}
+				line++
+			}
+		}
+		if removed {
+			deletions = append(deletions, line-1)
+		}
+	}
+	change := FileChange{Path: p.NewPath, Changes: changes, Hunks: hunks, Deletions: deletions, Patch: p.Hunks}
+	if p.Renamed || p.Copied {
+		change.OldPath = p.OldPath
+	}
+	return change
+}
+
+// Parse reads a unified diff, as written by git diff, git show, diff -u or
+// most code review tools, and returns the changes of each file that still
+// exists afterwards.
+func Parse(r io.Reader) ([]FileChange, error) {
+	patches, err := ParsePatches(r)
+	if err != nil {
+		return nil, err
+	}
+	return fileChanges(patches), nil
+}
+
+func fileChanges(patches []Patch) []FileChange {
+	moved := movedLines(patches)
+	changes := make([]FileChange, 0, len(patches))
+	for i, patch := range patches {
+		if patch.Deleted() {
+			continue
+		}
+		change := patch.FileChange()
+		for _, line := range moved[i] {
+			change.Moved = appendLine(change.Moved, line)
+		}
+		if len(change.Changes) == 0 && len(change.Deletions) == 0 {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// ParsePatches splits a unified diff into per-file patches. Text outside of
+// file sections, such as commit messages, is skipped.
+func ParsePatches(r io.Reader) ([]Patch, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		patches []Patch
+		current *Patch
+		hunk    *Hunk
+		// remainingOld and remainingNew count the body lines still expected
+		// in the current hunk, so "--- " inside a hunk is a removed line.
+		remainingOld, remainingNew int
+		pendingOld                 string
+		number                     int
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		number++
+
+		if hunk != nil && (remainingOld > 0 || remainingNew > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				remainingNew--
+			case strings.HasPrefix(line, "-"):
+				remainingOld--
+			case strings.HasPrefix(line, " "), line == "":
+				remainingOld--
+				remainingNew--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file" belongs to the previous line.
+			default:
+				return nil, fmt.Errorf("line %d: unexpected %q inside hunk", number, line)
+			}
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+		if hunk != nil && strings.HasPrefix(line, `\`) {
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+		hunk = nil
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			patches = append(patches, Patch{})
+			current = &patches[len(patches)-1]
+			current.OldPath, current.NewPath = gitHeaderPaths(line)
+		case current != nil && strings.HasPrefix(line, "rename from "):
+			current.OldPath = headerPath(strings.TrimPrefix(line, "rename from "), "")
+			current.Renamed = true
+		case current != nil && strings.HasPrefix(line, "rename to "):
+			current.NewPath = headerPath(strings.TrimPrefix(line, "rename to "), "")
+		case current != nil && strings.HasPrefix(line, "copy from "):
+			current.OldPath = headerPath(strings.TrimPrefix(line, "copy from "), "")
+			current.Copied = true
+		case current != nil && strings.HasPrefix(line, "copy to "):
+			current.NewPath = headerPath(strings.TrimPrefix(line, "copy to "), "")
+		case strings.HasPrefix(line, "--- "):
+			pendingOld = line
+		case strings.HasPrefix(line, "+++ ") && pendingOld != "":
+			oldPath := headerPath(strings.TrimPrefix(pendingOld, "--- "), "a/")
+			newPath := headerPath(strings.TrimPrefix(line, "+++ "), "b/")
+			pendingOld = ""
+			// Plain diff -u output has no "diff --git" line to open a file.
+			if current == nil || len(current.Hunks) > 0 {
+				patches = append(patches, Patch{})
+				current = &patches[len(patches)-1]
+			}
+			current.OldPath, current.NewPath = oldPath, newPath
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk outside of a file", number)
+			}
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			current.Hunks = append(current.Hunks, h)
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			remainingOld, remainingNew = h.OldCount, h.NewCount
+		default:
+			pendingOld = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if hunk != nil && (remainingOld > 0 || remainingNew > 0) {
+		return nil, fmt.Errorf("line %d: truncated hunk", number)
+	}
+	return patches, nil
+}
+
+// gitHeaderPaths reads "diff --git a/x b/y". The paths are only a fallback
+// for patches without ---/+++ lines, such as pure renames or mode changes.
+func gitHeaderPaths(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasPrefix(rest, `"`) {
+		oldPath, tail, ok := cutQuoted(rest)
+		if !ok {
+			return "", ""
+		}
+		return headerPath(oldPath, "a/"), headerPath(strings.TrimSpace(tail), "b/")
+	}
+
+	// Unquoted paths may contain spaces, so split where " b/" starts the
+	// second half of a symmetric header.
+	if i := strings.Index(rest, " b/"); i >= 0 {
+		return headerPath(rest[:i], "a/"), headerPath(rest[i+1:], "b/")
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 2 {
+		return "", ""
+	}
+	return headerPath(fields[0], "a/"), headerPath(fields[1], "b/")
+}
+
+// headerPath cleans a path from a ---/+++ line: drops a trailing timestamp,
+// unquotes C-style quoting and strips the a/ or b/ prefix.
+func headerPath(raw, prefix string) string {
+	if i := strings.IndexByte(raw, '\t'); i >= 0 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimRight(raw, " ")
+	if strings.HasPrefix(raw, `"`) {
+		if unquoted, _, ok := cutQuoted(raw); ok {
+			raw = unquoted
+		}
+	}
+	if raw == devNull {
+		return raw
+	}
+	return strings.TrimPrefix(raw, prefix)
+}
+
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			unquoted, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			return unquoted, s[i+1:], true
+		}
+	}
+	return "", "", false
+}
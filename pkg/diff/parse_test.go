@@ -0,0 +1,209 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const gitShowOutput = `commit 0123456789abcdef
+Author: Someone <someone@example.com>
+
+    Rework parsing
+
+    --- not a file header
++++ neither
+
+diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1,5 +1,5 @@
+ package a
+-
+--- removed line that looks like a header
++import "fmt"
++
+ func A() {}
+ 
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package a
++var x = 1
+\ No newline at end of file
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package a
+diff --git "a/with space.go" "b/with space.go"
+--- "a/with space.go"
++++ "b/with space.go"
+@@ -3 +3 @@ func B() {
+-	return
++	return nil
+`
+
+func TestParsePatches(t *testing.T) {
+	patches, err := ParsePatches(strings.NewReader(gitShowOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, patch := range patches {
+		paths = append(paths, patch.OldPath+" -> "+patch.NewPath)
+	}
+	want := []string{
+		"pkg/a.go -> pkg/a.go",
+		"/dev/null -> new.go",
+		"gone.go -> /dev/null",
+		"with space.go -> with space.go",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+
+	first := patches[0].Hunks[0]
+	if first.OldStart != 1 || first.OldCount != 5 || first.NewStart != 1 || first.NewCount != 5 || len(first.Lines) != 7 {
+		t.Errorf("first hunk = %+v", first)
+	}
+	if lines := patches[1].Hunks[0].Lines; lines[len(lines)-1] != `\ No newline at end of file` {
+		t.Errorf("no-newline marker not kept: %q", lines)
+	}
+}
+
+func TestParse(t *testing.T) {
+	changes, err := Parse(strings.NewReader(gitShowOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FileChange{
+		{Path: "pkg/a.go", Changes: []*Change{{Start: 2, End: 3}}, Hunks: []*Change{{Start: 1, End: 5}}, Patch: []Hunk{{
+			OldStart: 1, OldCount: 5, NewStart: 1, NewCount: 5,
+			Lines: []string{" package a", "-", "--- removed line that looks like a header", `+import "fmt"`, "+", " func A() {}", " "},
+		}}},
+		{Path: "new.go", Changes: []*Change{{Start: 1, End: 2}}, Hunks: []*Change{{Start: 1, End: 2}}, Patch: []Hunk{{
+			NewStart: 1, NewCount: 2,
+			Lines: []string{"+package a", "+var x = 1", `\ No newline at end of file`},
+		}}},
+		{Path: "with space.go", Changes: []*Change{{Start: 3, End: 3}}, Hunks: []*Change{{Start: 3, End: 3}}, Patch: []Hunk{{
+			OldStart: 3, OldCount: 1, NewStart: 3, NewCount: 1,
+			Lines: []string{"-\treturn", "+\treturn nil"},
+		}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Parse = %+v, want %+v", changes, want)
+	}
+}
+
+func TestParsePlainUnifiedDiff(t *testing.T) {
+	input := `--- old/a.go	2024-01-01 10:00:00.000000000 +0000
++++ new/a.go	2024-01-02 10:00:00.000000000 +0000
+@@ -2,0 +3,1 @@
++var y = 2
+--- old/b.go
++++ new/b.go
+@@ -1 +1 @@
+-a
++b
+`
+	changes, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FileChange{
+		{Path: "new/a.go", Changes: []*Change{{Start: 3, End: 3}}, Hunks: []*Change{{Start: 3, End: 3}}, Patch: []Hunk{{
+			OldStart: 2, NewStart: 3, NewCount: 1, Lines: []string{"+var y = 2"},
+		}}},
+		{Path: "new/b.go", Changes: []*Change{{Start: 1, End: 1}}, Hunks: []*Change{{Start: 1, End: 1}}, Patch: []Hunk{{
+			OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1, Lines: []string{"-a", "+b"},
+		}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Parse = %+v, want %+v", changes, want)
+	}
+}
+
+func TestParseDeletions(t *testing.T) {
+	input := `--- a/a.go
++++ b/a.go
+@@ -1,2 +0,0 @@
+-// Package a
+-// is documented.
+@@ -10,2 +7,0 @@
+-	x := 1
+-	_ = x
+@@ -20,3 +17,2 @@
+-	old()
++	updated()
+ 	keep()
+-	gone()
+`
+	changes, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want 1", len(changes))
+	}
+	// The replaced old() is covered by the added line instead.
+	if want := []int{0, 7, 18}; !reflect.DeepEqual(changes[0].Deletions, want) {
+		t.Errorf("Deletions = %v, want %v", changes[0].Deletions, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	inputs := map[string]string{
+		"hunk without file": "@@ -1 +1 @@\n-a\n+b\n",
+		"truncated hunk":    "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n",
+		"garbage in hunk":   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n?\n",
+	}
+	for name, input := range inputs {
+		if _, err := ParsePatches(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseRenamesAndCopies(t *testing.T) {
+	input := `diff --git a/old name.go b/new name.go
+similarity index 100%
+rename from old name.go
+rename to new name.go
+diff --git a/a.go b/b.go
+similarity index 90%
+copy from a.go
+copy to b.go
+--- a/a.go
++++ b/b.go
+@@ -1,2 +1,2 @@
+ package a
+-var a = 1
++var b = 1
+`
+	patches, err := ParsePatches(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("got %d patches, want 2", len(patches))
+	}
+	if p := patches[0]; p.OldPath != "old name.go" || p.NewPath != "new name.go" || !p.Renamed || len(p.Hunks) != 0 {
+		t.Errorf("rename = %+v", p)
+	}
+
+	changes := fileChanges(patches)
+	want := []FileChange{{Path: "b.go", OldPath: "a.go", Changes: []*Change{{Start: 2, End: 2}}, Hunks: []*Change{{Start: 1, End: 2}}, Patch: []Hunk{{
+		OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2, Lines: []string{" package a", "-var a = 1", "+var b = 1"},
+	}}}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
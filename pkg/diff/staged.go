@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// StagedCommand lists the staged lines only; context is left out as it
+// would never count as changed anyway.
+const StagedCommand = "git diff --cached -U0"
+
+// FindStaged returns the staged changes with line numbers translated from
+// the index to the working tree golangci-lint sees. Staged lines that were
+// edited again without being staged are dropped, since their current content
+// is not what is about to be committed. diffArgs are added to StagedCommand.
+func FindStaged(ctx context.Context, pwd string, diffArgs ...string) ([]FileChange, error) {
+	staged, err := Find(ctx, pwd, strings.Join(append([]string{StagedCommand}, diffArgs...), " "))
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := unstagedHunks(ctx, pwd)
+	if err != nil {
+		return nil, err
+	}
+	return translateStaged(staged, unstaged), nil
+}
+
+// translateStaged maps the lines of staged from the index to the working
+// tree through the unstaged hunks of each file, by its path in the index.
+func translateStaged(staged []FileChange, unstaged map[string][]Hunk) []FileChange {
+	translated := make([]FileChange, 0, len(staged))
+	for _, fileChange := range staged {
+		changes := make([]*Change, 0, len(fileChange.Changes))
+		for _, change := range fileChange.Changes {
+			for line := change.Start; line <= change.End; line++ {
+				worktreeLine, ok := translateLine(unstaged[fileChange.Path], line)
+				if !ok {
+					continue
+				}
+				changes = appendLine(changes, worktreeLine)
+			}
+		}
+
+		var moved []*Change
+		for _, change := range fileChange.Moved {
+			for line := change.Start; line <= change.End; line++ {
+				if worktreeLine, ok := translateLine(unstaged[fileChange.Path], line); ok {
+					moved = appendLine(moved, worktreeLine)
+				}
+			}
+		}
+
+		var deletions []int
+		for _, line := range fileChange.Deletions {
+			if line == 0 {
+				deletions = append(deletions, 0)
+				continue
+			}
+			if worktreeLine, ok := translateLine(unstaged[fileChange.Path], line); ok {
+				deletions = append(deletions, worktreeLine)
+			}
+		}
+
+		if len(changes) == 0 && len(deletions) == 0 {
+			continue
+		}
+		// Without context, every hunk is a run of changed lines.
+		translated = append(translated, FileChange{
+			Path:      fileChange.Path,
+			Changes:   changes,
+			Hunks:     changes,
+			Deletions: deletions,
+			Moved:     moved,
+		})
+	}
+	return translated
+}
+
+// unstagedHunks reads the edits made since staging with a single git diff,
+// indexed by their path in the index.
+func unstagedHunks(ctx context.Context, pwd string) (map[string][]Hunk, error) {
+	output, err := command.New("git", "diff", "-U0").
+		SetContext(ctx).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err := ParsePatches(bytes.NewReader(output))
+	if err != nil {
+		return nil, err
+	}
+	hunks := make(map[string][]Hunk, len(patches))
+	for _, patch := range patches {
+		hunks[patch.OldPath] = append(hunks[patch.OldPath], patch.Hunks...)
+	}
+	return hunks, nil
+}
+
+// translateLine maps a line of the old side of hunks to the new side,
+// reporting false when the line itself was rewritten or removed.
+func translateLine(hunks []Hunk, line int) (int, bool) {
+	offset := 0
+	for _, h := range hunks {
+		if h.OldCount == 0 {
+			// A pure insertion sits after line oldStart.
+			if line > h.OldStart {
+				offset += h.NewCount
+				continue
+			}
+			break
+		}
+		if line < h.OldStart {
+			break
+		}
+		if line < h.OldStart+h.OldCount {
+			return 0, false
+		}
+		offset += h.NewCount - h.OldCount
+	}
+	return line + offset, true
+}
+
+func appendLine(changes []*Change, line int) []*Change {
+	if n := len(changes); n > 0 && changes[n-1].End+1 == line {
+		changes[n-1].End = line
+		return changes
+	}
+	return append(changes, &Change{Start: line, End: line})
+}
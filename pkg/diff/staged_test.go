@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Hunk
+	}{
+		{"@@ -1,3 +1,4 @@", Hunk{OldStart: 1, OldCount: 3, NewStart: 1, NewCount: 4}},
+		{"@@ -5 +5 @@ func main() {", Hunk{OldStart: 5, OldCount: 1, NewStart: 5, NewCount: 1}},
+		{"@@ -7,0 +8,2 @@", Hunk{OldStart: 7, OldCount: 0, NewStart: 8, NewCount: 2}},
+		{"@@ -3,2 +2,0 @@", Hunk{OldStart: 3, OldCount: 2, NewStart: 2, NewCount: 0}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHunkHeader(tt.header)
+		if err != nil {
+			t.Errorf("parseHunkHeader(%q) error: %v", tt.header, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHunkHeader(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+
+	if _, err := parseHunkHeader("@@ bogus @@"); err == nil {
+		t.Error("parseHunkHeader of a malformed header expected an error")
+	}
+}
+
+func TestTranslateLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		hunks  []Hunk
+		line   int
+		want   int
+		wantOK bool
+	}{
+		{name: "no hunks", line: 7, want: 7, wantOK: true},
+		{name: "before pure insertion", hunks: []Hunk{{OldStart: 10, OldCount: 0, NewStart: 11, NewCount: 2}}, line: 10, want: 10, wantOK: true},
+		{name: "after pure insertion", hunks: []Hunk{{OldStart: 10, OldCount: 0, NewStart: 11, NewCount: 2}}, line: 11, want: 13, wantOK: true},
+		{name: "pure deletion removes the line", hunks: []Hunk{{OldStart: 4, OldCount: 2, NewStart: 3, NewCount: 0}}, line: 5, wantOK: false},
+		{name: "after pure deletion", hunks: []Hunk{{OldStart: 4, OldCount: 2, NewStart: 3, NewCount: 0}}, line: 6, want: 4, wantOK: true},
+		{name: "replaced line is dropped", hunks: []Hunk{{OldStart: 4, OldCount: 1, NewStart: 4, NewCount: 3}}, line: 4, wantOK: false},
+		{name: "after replacement", hunks: []Hunk{{OldStart: 4, OldCount: 1, NewStart: 4, NewCount: 3}}, line: 5, want: 7, wantOK: true},
+		{
+			name:   "after several hunks",
+			hunks:  []Hunk{{OldStart: 2, OldCount: 0, NewStart: 3, NewCount: 1}, {OldStart: 5, OldCount: 2, NewStart: 6, NewCount: 0}, {OldStart: 9, OldCount: 1, NewStart: 8, NewCount: 4}},
+			line:   12,
+			want:   12 + 1 - 2 + 3,
+			wantOK: true,
+		},
+		{
+			name:   "between hunks",
+			hunks:  []Hunk{{OldStart: 2, OldCount: 0, NewStart: 3, NewCount: 1}, {OldStart: 5, OldCount: 2, NewStart: 6, NewCount: 0}, {OldStart: 9, OldCount: 1, NewStart: 8, NewCount: 4}},
+			line:   8,
+			want:   8 + 1 - 2,
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := translateLine(tt.hunks, tt.line)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("translateLine(%d) = %d, %v, want %d, %v", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFindStagedIgnoresContextAndUnstagedEdits(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "a.go", "package a\n\nfunc one() {}\n\nfunc two() {}\n\nfunc three() {}\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "functions")
+
+	// Stage a new line after two(), then insert two unstaged lines at the top.
+	writeFile(t, dir, "a.go", "package a\n\nfunc one() {}\n\nfunc two() {}\nvar staged = 1\n\nfunc three() {}\n")
+	git(t, dir, "add", "a.go")
+	writeFile(t, dir, "a.go", "package a\n\n// unstaged\n// unstaged\nfunc one() {}\n\nfunc two() {}\nvar staged = 1\n\nfunc three() {}\n")
+
+	got, err := FindStaged(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FileChange{{Path: "a.go", Changes: []*Change{{Start: 8, End: 8}}, Hunks: []*Change{{Start: 8, End: 8}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStaged = %+v, want only line 8 of a.go", got)
+	}
+}
+
+func TestFindStagedTranslatesEachFileOnItsOwn(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 1\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "b")
+
+	writeFile(t, dir, "a.go", "package a\n\nvar a = 1\n")
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 2\n")
+	git(t, dir, "add", ".")
+	// Only a.go moves on in the working tree.
+	writeFile(t, dir, "a.go", "package a\n\n// doc\nvar a = 1\n")
+
+	got, err := FindStaged(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FileChange{
+		{Path: "a.go", Changes: []*Change{{Start: 2, End: 2}, {Start: 4, End: 4}}, Hunks: []*Change{{Start: 2, End: 2}, {Start: 4, End: 4}}},
+		{Path: "b.go", Changes: []*Change{{Start: 3, End: 3}}, Hunks: []*Change{{Start: 3, End: 3}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStaged = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindStagedIgnoringWhitespace(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "b.go", "package a\n\nfunc b() {\n  x := 1\n  _ = x\n}\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "b")
+
+	// gofmt reindents the body while one line really changes.
+	writeFile(t, dir, "b.go", "package a\n\nfunc b() {\n\tx := 2\n\t_ = x\n}\n")
+	git(t, dir, "add", ".")
+	// Blank lines added since staging still move the lines.
+	writeFile(t, dir, "b.go", "package a\n\n\nfunc b() {\n\tx := 2\n\t_ = x\n}\n")
+
+	got, err := FindStaged(context.Background(), dir, IgnoreWhitespace...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{{Path: "b.go", Changes: []*Change{{Start: 5, End: 5}}, Hunks: []*Change{{Start: 5, End: 5}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStaged = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"linter/pkg/command"
+	"linter/pkg/gitrepo"
+)
+
+// ErrNotCheckedOut is returned by FindSubmodule for a submodule without a
+// working tree of its own, where git would read the superproject instead.
+var ErrNotCheckedOut = errors.New("submodule not checked out")
+
+// emptyTree is the hash of the tree without files, which new submodules
+// are compared against.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+const subprojectCommit = "Subproject commit "
+
+// Submodule is a submodule whose commit, or working tree, a diff changed.
+type Submodule struct {
+	// Path is the directory of the submodule, relative to the root of the
+	// repository like the paths of changes.
+	Path string
+	// From is the commit of the submodule before, empty for a submodule
+	// the diff adds. To is the commit after, empty when the diff reaches
+	// the working tree of a submodule with uncommitted changes.
+	From, To string
+}
+
+// SplitSubmodules takes the submodules out of changes: git shows them as
+// files whose patch compares "Subproject commit" lines. Removed submodules
+// are dropped. Changes merging several diffs have no patch to tell by and
+// are kept as files.
+func SplitSubmodules(changes []FileChange) ([]FileChange, []Submodule) {
+	files := make([]FileChange, 0, len(changes))
+	var submodules []Submodule
+	for _, change := range changes {
+		submodule, ok := submoduleOf(change)
+		switch {
+		case !ok:
+			files = append(files, change)
+		case submodule.To != "" || submodule.From != "":
+			submodules = append(submodules, submodule)
+		}
+	}
+	return files, submodules
+}
+
+func submoduleOf(change FileChange) (Submodule, bool) {
+	if len(change.Patch) != 1 {
+		return Submodule{}, false
+	}
+	submodule := Submodule{Path: change.Path}
+	added := false
+	for _, line := range change.Patch[0].Lines {
+		if len(line) == 0 || !strings.HasPrefix(line[1:], subprojectCommit) {
+			return Submodule{}, false
+		}
+		commit := strings.TrimPrefix(line[1:], subprojectCommit)
+		switch line[0] {
+		case '-':
+			submodule.From = commit
+		case '+':
+			added = true
+			submodule.To = commit
+		default:
+			return Submodule{}, false
+		}
+	}
+	if !added {
+		// Removed, with no files left to lint.
+		return Submodule{}, true
+	}
+	if strings.HasSuffix(submodule.To, "-dirty") {
+		submodule.To = ""
+	} else if submodule.To == "" {
+		return Submodule{}, false
+	}
+	if submodule.From == "" {
+		submodule.From = emptyTree
+	}
+	return submodule, true
+}
+
+// FindSubmodule diffs submodule in its own repository, under root, with
+// extra git diff options, and returns the changed files with paths relative
+// to root. Submodules of the submodule that changed are followed in turn.
+// The git variables of gitrepo.Env are dropped, so that git hooks do not
+// point the diff at the superproject.
+func FindSubmodule(ctx context.Context, root string, submodule Submodule, extra ...string) ([]FileChange, error) {
+	dir := filepath.Join(root, filepath.FromSlash(submodule.Path))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil, fmt.Errorf("%s: %w", submodule.Path, ErrNotCheckedOut)
+	}
+
+	argv := append([]string{"diff"}, extra...)
+	argv = append(argv, submodule.From)
+	if submodule.To != "" {
+		argv = append(argv, submodule.To)
+	}
+	output, err := command.New("git", argv...).
+		SetContext(ctx).
+		SetDir(dir).
+		UnsetEnv(gitrepo.Env...).
+		Output()
+	if err != nil {
+		return nil, fmt.Errorf("submodule %s: %w", submodule.Path, err)
+	}
+	patches, err := ParsePatches(bytes.NewReader(output))
+	if err != nil {
+		return nil, fmt.Errorf("submodule %s: %w", submodule.Path, err)
+	}
+
+	files, nested := SplitSubmodules(fileChanges(patches))
+	for i := range files {
+		files[i].Path = path.Join(submodule.Path, files[i].Path)
+		if files[i].OldPath != "" {
+			files[i].OldPath = path.Join(submodule.Path, files[i].OldPath)
+		}
+	}
+	for _, inner := range nested {
+		inner.Path = path.Join(submodule.Path, inner.Path)
+		found, err := FindSubmodule(ctx, root, inner, extra...)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitSubmodules(t *testing.T) {
+	const a, b = "4973dda989ea715929b1f09287aa462348de2bbf", "1bdf1e4f5acae854a345aefebe6b3419e6453dbd"
+	patch := func(lines ...string) []Hunk {
+		return []Hunk{{Lines: lines}}
+	}
+	changes := []FileChange{
+		{Path: "a.go", Patch: patch("+package a")},
+		{Path: "moved", Patch: patch("-Subproject commit "+a, "+Subproject commit "+b)},
+		{Path: "dirty", Patch: patch("-Subproject commit "+a, "+Subproject commit "+a+"-dirty")},
+		{Path: "added", Patch: patch("+Subproject commit " + b)},
+		{Path: "removed", Patch: patch("-Subproject commit " + a)},
+		{Path: "merged.go"},
+	}
+
+	files, submodules := SplitSubmodules(changes)
+	if got := Paths(files); !reflect.DeepEqual(got, []string{"a.go", "merged.go"}) {
+		t.Errorf("files = %v, want a.go and merged.go", got)
+	}
+	want := []Submodule{
+		{Path: "moved", From: a, To: b},
+		{Path: "dirty", From: a},
+		{Path: "added", From: emptyTree, To: b},
+	}
+	if !reflect.DeepEqual(submodules, want) {
+		t.Errorf("submodules = %+v, want %+v", submodules, want)
+	}
+}
+
+func TestFindSubmodule(t *testing.T) {
+	sub := gitRepo(t)
+	root := gitRepo(t)
+	git(t, root, "-c", "protocol.file.allow=always", "submodule", "add", "-q", sub, "lib")
+	git(t, root, "commit", "-q", "-m", "add lib")
+	dir := filepath.Join(root, "lib")
+	writeFile(t, dir, "b.go", "package a\n\nvar b = 1\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "b")
+	writeFile(t, dir, "a.go", "package a\n\nvar a = 1\n")
+
+	// Hooks point git at the superproject, which the submodule diff must
+	// not follow.
+	t.Setenv("GIT_DIR", filepath.Join(root, ".git"))
+	t.Setenv("GIT_WORK_TREE", root)
+
+	changes, err := Find(context.Background(), root, "git diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, submodules := SplitSubmodules(changes)
+	if len(files) != 0 || len(submodules) != 1 || submodules[0].Path != "lib" || submodules[0].To != "" {
+		t.Fatalf("SplitSubmodules = %v, %+v, want the dirty lib alone", files, submodules)
+	}
+
+	found, err := FindSubmodule(context.Background(), root, submodules[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Paths(found); !reflect.DeepEqual(got, []string{"lib/a.go", "lib/b.go"}) {
+		t.Errorf("FindSubmodule = %v, want lib/a.go and lib/b.go", got)
+	}
+	if !found[0].Overlaps(3, 3) || found[0].Overlaps(1, 1) {
+		t.Errorf("lib/a.go changes = %v, want line 3 alone", found[0].Changes)
+	}
+
+	_, err = FindSubmodule(context.Background(), root, Submodule{Path: "missing", From: emptyTree})
+	if !errors.Is(err, ErrNotCheckedOut) {
+		t.Errorf("FindSubmodule of a missing submodule = %v, want ErrNotCheckedOut", err)
+	}
+}
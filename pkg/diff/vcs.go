@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// VCS is a version control system the changes can be read from. Every one
+// of them is asked for git-style unified diffs with paths relative to the
+// root of the repository.
+type VCS struct {
+	// Name selects the system with --vcs.
+	Name string
+	// DiffCommand lists the uncommitted changes of the working copy.
+	DiffCommand string
+	rootCommand string
+	// root finds the root in-process instead of running rootCommand.
+	root func(pwd string) (string, error)
+	// sinceCommand returns the command comparing the working copy with the
+	// point where it forked from ref.
+	sinceCommand func(ctx context.Context, pwd, ref string) (string, error)
+}
+
+var (
+	Git = VCS{
+		Name:        "git",
+		DiffCommand: "git diff",
+		rootCommand: "git rev-parse --show-toplevel",
+		sinceCommand: func(ctx context.Context, pwd, ref string) (string, error) {
+			base, err := MergeBase(ctx, pwd, ref)
+			if err != nil {
+				return "", err
+			}
+			return "git diff " + base, nil
+		},
+	}
+	Mercurial = VCS{
+		Name:        "hg",
+		DiffCommand: "hg diff --git",
+		rootCommand: "hg root",
+		sinceCommand: func(_ context.Context, _, ref string) (string, error) {
+			return revsetCommand("hg diff --git -r 'ancestor(., %s)'", ref)
+		},
+	}
+	Jujutsu = VCS{
+		Name:        "jj",
+		DiffCommand: "jj diff --git",
+		rootCommand: "jj root",
+		sinceCommand: func(_ context.Context, _, ref string) (string, error) {
+			return revsetCommand("jj diff --git --from 'heads(::@ & ::%s)'", ref)
+		},
+	}
+)
+
+// systems are the version control systems --vcs accepts.
+var systems = []VCS{Git, Mercurial, Jujutsu}
+
+// VCSNames lists the names LookupVCS accepts.
+func VCSNames() []string {
+	names := make([]string, 0, len(systems))
+	for _, vcs := range systems {
+		names = append(names, vcs.Name)
+	}
+	return names
+}
+
+// LookupVCS returns the system called name.
+func LookupVCS(name string) (VCS, error) {
+	for _, vcs := range systems {
+		if vcs.Name == name {
+			return vcs, nil
+		}
+	}
+	return VCS{}, fmt.Errorf("unknown version control system %q, want git, hg or jj", name)
+}
+
+// Root returns the root of the working copy containing pwd, the directory
+// diff paths are relative to.
+func (v VCS) Root(ctx context.Context, pwd string) (string, error) {
+	if v.root != nil {
+		return v.root(pwd)
+	}
+	cmd, err := command.Parse(v.rootCommand)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.SetContext(ctx).SetDir(pwd).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SinceCommand returns the diff command comparing the working copy with
+// the point where it forked from ref, as a pull request against ref would.
+func (v VCS) SinceCommand(ctx context.Context, pwd, ref string) (string, error) {
+	return v.sinceCommand(ctx, pwd, ref)
+}
+
+// revsetCommand puts ref into the quoted revset of format. Refs that could
+// close the quote or pass for an option are rejected.
+func revsetCommand(format, ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") || strings.ContainsAny(ref, `'\`) {
+		return "", fmt.Errorf("invalid ref %q", ref)
+	}
+	return fmt.Sprintf(format, ref), nil
+}
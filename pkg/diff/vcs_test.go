@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupVCS(t *testing.T) {
+	for _, name := range []string{"git", "hg", "jj"} {
+		vcs, err := LookupVCS(name)
+		if err != nil || vcs.Name != name {
+			t.Errorf("LookupVCS(%q) = %q, %v", name, vcs.Name, err)
+		}
+	}
+	if _, err := LookupVCS("svn"); err == nil {
+		t.Error("LookupVCS(svn) expected an error")
+	}
+}
+
+func TestSinceCommand(t *testing.T) {
+	tests := []struct {
+		vcs     VCS
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{vcs: Mercurial, ref: "default", want: "hg diff --git -r 'ancestor(., default)'"},
+		{vcs: Jujutsu, ref: "main@origin", want: "jj diff --git --from 'heads(::@ & ::main@origin)'"},
+		{vcs: Mercurial, ref: "-r0", wantErr: true},
+		{vcs: Jujutsu, ref: "x')", wantErr: true},
+		{vcs: Jujutsu, ref: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.vcs.SinceCommand(context.Background(), ".", tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s SinceCommand(%q) error = %v, wantErr %v", tt.vcs.Name, tt.ref, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s SinceCommand(%q) = %q, want %q", tt.vcs.Name, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestGitSinceCommandUsesMergeBase(t *testing.T) {
+	dir := gitRepo(t)
+	base := git(t, dir, "rev-parse", "HEAD")
+	git(t, dir, "branch", "main-copy")
+	writeFile(t, dir, "b.go", "package a\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "second")
+
+	got, err := Git.SinceCommand(context.Background(), dir, "main-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "git diff " + base; got != want {
+		t.Errorf("SinceCommand = %q, want %q", got, want)
+	}
+}
+
+func TestGitRoot(t *testing.T) {
+	dir := gitRepo(t)
+	writeFile(t, dir, "sub/c.go", "package sub\n")
+
+	got, err := Git.Root(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Root = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,282 @@
+// Package external runs linters other than golangci-lint, configured by a
+// command template and a mapping of the JSON they print to issues, so the
+// issues of any linter can be matched to the changed lines.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/command"
+)
+
+// FilesPlaceholder is the word of a command template standing for the
+// files to lint. Templates without it get the files appended.
+const FilesPlaceholder = "{files}"
+
+// Mapping tells where the issues and their fields are in the JSON report,
+// as paths such as $.comments[*] and ^.filePath. Issues, File, Line and
+// Message are required.
+type Mapping struct {
+	Issues   string
+	File     string
+	Line     string
+	EndLine  string
+	Column   string
+	Message  string
+	Severity string
+	Rule     string
+}
+
+// fields are the parsed paths of a Mapping.
+type fields struct {
+	issues, file, line, endLine, column, message, severity, rule jsonPath
+	hasEndLine, hasColumn, hasSeverity, hasRule                  bool
+}
+
+func (m Mapping) parse() (fields, error) {
+	var f fields
+	for _, required := range []struct {
+		name, path string
+		into       *jsonPath
+	}{
+		{"issues", m.Issues, &f.issues},
+		{"file", m.File, &f.file},
+		{"line", m.Line, &f.line},
+		{"message", m.Message, &f.message},
+	} {
+		if required.path == "" {
+			return fields{}, fmt.Errorf("no %s path", required.name)
+		}
+		path, err := parsePath(required.path)
+		if err != nil {
+			return fields{}, err
+		}
+		*required.into = path
+	}
+	for _, optional := range []struct {
+		path string
+		into *jsonPath
+		set  *bool
+	}{
+		{m.EndLine, &f.endLine, &f.hasEndLine},
+		{m.Column, &f.column, &f.hasColumn},
+		{m.Severity, &f.severity, &f.hasSeverity},
+		{m.Rule, &f.rule, &f.hasRule},
+	} {
+		if optional.path == "" {
+			continue
+		}
+		path, err := parsePath(optional.path)
+		if err != nil {
+			return fields{}, err
+		}
+		*optional.into, *optional.set = path, true
+	}
+	return f, nil
+}
+
+// Validate reports what is missing or malformed in the mapping.
+func (m Mapping) Validate() error {
+	_, err := m.parse()
+	return err
+}
+
+// Tool is one configured linter.
+type Tool struct {
+	ctx     context.Context
+	name    string
+	command string
+	mapping Mapping
+	dir     string
+}
+
+// New returns the linter called name, run with the command template in
+// the current directory.
+func New(name, command string) *Tool {
+	return &Tool{
+		ctx:     context.Background(),
+		name:    name,
+		command: command,
+	}
+}
+
+// SetMapping sets how the report of the linter maps to issues.
+func (t *Tool) SetMapping(mapping Mapping) *Tool {
+	t.mapping = mapping
+	return t
+}
+
+// SetContext sets the context the linter runs under.
+func (t *Tool) SetContext(ctx context.Context) *Tool {
+	t.ctx = ctx
+	return t
+}
+
+// SetDir sets the directory the linter runs in. Relative paths of files and
+// of issues are taken from it.
+func (t *Tool) SetDir(dir string) *Tool {
+	t.dir = dir
+	return t
+}
+
+// Run lints files and returns the issues of the report, reported as from
+// the linter name. No files means no issues. Linters commonly exit with an
+// error when they find issues, so that only counts as a failure when they
+// printed no JSON.
+func (t *Tool) Run(files ...string) ([]result.Issue, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	fields, err := t.mapping.parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.name, err)
+	}
+	cmd, err := t.expand(files)
+	if err != nil {
+		return nil, err
+	}
+	output, runErr := cmd.SetContext(t.ctx).SetDir(t.dir).Output()
+	if t.ctx.Err() != nil {
+		return nil, runErr
+	}
+
+	var report any
+	if len(bytes.TrimSpace(output)) == 0 {
+		if runErr != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, runErr)
+		}
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+	if err := decoder.Decode(&report); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, runErr)
+		}
+		return nil, fmt.Errorf("%s: report is not JSON: %w", t.name, err)
+	}
+
+	var issues []result.Issue
+	for i, m := range fields.issues.selectAll(report) {
+		issue, err := t.issue(fields, m)
+		if err != nil {
+			return nil, fmt.Errorf("%s: issue %d: %w", t.name, i+1, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// expand fills the command template in with files.
+func (t *Tool) expand(files []string) (*command.Command, error) {
+	words, err := command.Split(t.command)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s: empty command", t.name)
+	}
+	var argv []string
+	expanded := false
+	for _, word := range words[1:] {
+		if word == FilesPlaceholder {
+			argv = append(argv, files...)
+			expanded = true
+			continue
+		}
+		argv = append(argv, word)
+	}
+	if !expanded {
+		argv = append(argv, files...)
+	}
+	return command.New(words[0], argv...), nil
+}
+
+func (t *Tool) issue(f fields, m match) (result.Issue, error) {
+	file, err := stringField(f.file, m, "file")
+	if err != nil {
+		return result.Issue{}, err
+	}
+	line, err := intField(f.line, m, "line")
+	if err != nil {
+		return result.Issue{}, err
+	}
+	message, err := stringField(f.message, m, "message")
+	if err != nil {
+		return result.Issue{}, err
+	}
+	issue := result.Issue{
+		FromLinter: t.name,
+		Text:       message,
+		Pos:        token.Position{Filename: file, Line: line},
+	}
+	if f.hasColumn {
+		if column, err := intField(f.column, m, "column"); err == nil {
+			issue.Pos.Column = column
+		}
+	}
+	if f.hasEndLine {
+		if end, err := intField(f.endLine, m, "end line"); err == nil && end > line {
+			issue.LineRange = &result.Range{From: line, To: end}
+		}
+	}
+	if f.hasSeverity {
+		if severity, err := stringField(f.severity, m, "severity"); err == nil {
+			issue.Severity = strings.ToLower(severity)
+		}
+	}
+	if f.hasRule {
+		if rule, err := stringField(f.rule, m, "rule"); err == nil && rule != "" {
+			issue.Text = rule + ": " + issue.Text
+		}
+	}
+	return issue, nil
+}
+
+var errMissing = errors.New("missing")
+
+func stringField(p jsonPath, m match, name string) (string, error) {
+	value, ok := p.lookup(m)
+	if !ok || value == nil {
+		return "", fmt.Errorf("%s: %w", name, errMissing)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("%s: %v is not a string", name, value)
+	}
+}
+
+func intField(p jsonPath, m match, name string) (int, error) {
+	value, ok := p.lookup(m)
+	if !ok || value == nil {
+		return 0, fmt.Errorf("%s: %w", name, errMissing)
+	}
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	default:
+		return 0, fmt.Errorf("%s: %v is not a number", name, value)
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 || n > math.MaxInt32 || n != math.Trunc(n) {
+		return 0, fmt.Errorf("%s: %q is not a line or column number", name, text)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,157 @@
+package external
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTool writes a linter recording its arguments into args.txt of the
+// directory it runs in, printing report and exiting with code.
+func fakeTool(t *testing.T, report string, code int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+
+	script := "#!/bin/sh\necho \"$*\" > args.txt\ncat <<'EOF'\n" + report + "\nEOF\nexit " + fmt.Sprint(code) + "\n"
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		report   string
+		code     int
+		mapping  Mapping
+		args     string
+		want     []string
+	}{
+		{
+			name:     "shellcheck",
+			template: "{bin} -f json {files}",
+			report:   `[{"file":"a.sh","line":3,"endLine":4,"column":7,"level":"warning","code":2086,"message":"Double quote to prevent globbing."}]`,
+			code:     1,
+			mapping:  Mapping{Issues: "$[*]", File: "file", Line: "line", EndLine: "endLine", Column: "column", Message: "message", Severity: "level", Rule: "code"},
+			args:     "-f json a.sh b.sh",
+			want:     []string{"a.sh:3-4:7 warning 2086: Double quote to prevent globbing."},
+		},
+		{
+			name:     "eslint",
+			template: "{bin} --format json",
+			report: `[
+				{"filePath":"web/a.js","messages":[
+					{"ruleId":"no-unused-vars","severity":2,"message":"'x' is unused.","line":1,"column":5},
+					{"ruleId":"semi","severity":1,"message":"Missing semicolon.","line":2,"column":9}]},
+				{"filePath":"web/b.js","messages":[]}]`,
+			mapping: Mapping{Issues: "$[*].messages[*]", File: "^.filePath", Line: "line", Column: "column", Message: "message", Rule: "ruleId"},
+			args:    "--format json a.sh b.sh",
+			want:    []string{"web/a.js:1:5  no-unused-vars: 'x' is unused.", "web/a.js:2:9  semi: Missing semicolon."},
+		},
+		{
+			name:     "nested report",
+			template: `{bin} lint --output "json" {files} --strict`,
+			report:   `{"results":{"lint":[{"location":{"path":"api/a.proto","start":{"line":"12"}},"text":"Field name should be lower_snake_case."}]}}`,
+			mapping:  Mapping{Issues: `$.results["lint"][*]`, File: "location.path", Line: "location.start.line", Message: "text", Severity: "level"},
+			args:     "lint --output json a.sh b.sh --strict",
+			want:     []string{"api/a.proto:12:0  Field name should be lower_snake_case."},
+		},
+		{
+			name:     "no issues",
+			template: "{bin} {files}",
+			report:   "",
+			mapping:  Mapping{Issues: "$[*]", File: "file", Line: "line", Message: "message"},
+			args:     "a.sh b.sh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			template := strings.Replace(tt.template, "{bin}", fakeTool(t, tt.report, tt.code), 1)
+			issues, err := New("custom", template).SetMapping(tt.mapping).SetDir(dir).Run("a.sh", "b.sh")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, issue := range issues {
+				if issue.FromLinter != "custom" {
+					t.Errorf("issue from %q, want custom", issue.FromLinter)
+				}
+				where := fmt.Sprintf("%s:%d", issue.FilePath(), issue.Line())
+				if lines := issue.GetLineRange(); lines.To != lines.From {
+					where += fmt.Sprintf("-%d", lines.To)
+				}
+				got = append(got, fmt.Sprintf("%s:%d %s %s", where, issue.Column(), issue.Severity, issue.Text))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			recorded, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.TrimSpace(string(recorded)) != tt.args {
+				t.Errorf("ran with %q, want %q", strings.TrimSpace(string(recorded)), tt.args)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	mapping := Mapping{Issues: "$[*]", File: "file", Line: "line", Message: "message"}
+	tests := []struct {
+		name    string
+		report  string
+		code    int
+		mapping Mapping
+	}{
+		{"failure without report", "", 2, mapping},
+		{"not JSON", "warning: a.sh is odd", 0, mapping},
+		{"missing line", `[{"file":"a.sh","message":"odd"}]`, 1, mapping},
+		{"line not a number", `[{"file":"a.sh","line":"three","message":"odd"}]`, 1, mapping},
+		{"no message path", `[]`, 0, Mapping{Issues: "$[*]", File: "file", Line: "line"}},
+	}
+	for _, tt := range tests {
+		_, err := New("custom", fakeTool(t, tt.report, tt.code)).SetMapping(tt.mapping).SetDir(t.TempDir()).Run("a.sh")
+		if err == nil || !strings.HasPrefix(err.Error(), "custom: ") {
+			t.Errorf("%s: error = %v, want a custom failure", tt.name, err)
+		}
+	}
+}
+
+func TestRunNoFiles(t *testing.T) {
+	issues, err := New("custom", "/nonexistent {files}").Run()
+	if err != nil || issues != nil {
+		t.Errorf("Run() = %v, %v, want nothing", issues, err)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"$[*].messages[*]", false},
+		{"^^.a.b[0]", false},
+		{`$["key with.dots"][*]`, false},
+		{"$.a[x]", true},
+		{"$.a[1", true},
+		{`$["a`, true},
+		{"$..a", true},
+	}
+	for _, tt := range tests {
+		_, err := parsePath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePath(%q) error = %v, want error %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
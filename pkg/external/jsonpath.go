@@ -0,0 +1,138 @@
+package external
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// step is one element of a path: an object key, an array index, or "[*]"
+// for every element of an array.
+type step struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonPath is the subset of JSONPath the mappings use: $ for the document,
+// .key or ["key"] for a member, [n] for an element, [*] for every element.
+// Leading ^ steps, as in ^.filePath, start from the element an enclosing
+// [*] matched instead of the issue itself.
+type jsonPath struct {
+	up    int
+	steps []step
+}
+
+func parsePath(s string) (jsonPath, error) {
+	var p jsonPath
+	rest := strings.TrimSpace(s)
+	for strings.HasPrefix(rest, "^") {
+		p.up++
+		rest = rest[1:]
+	}
+	rest = strings.TrimPrefix(rest, "$")
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "[*]"):
+			p.steps = append(p.steps, step{wildcard: true})
+			rest = rest[3:]
+		case strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest, `"]`)
+			if end < 0 {
+				return jsonPath{}, fmt.Errorf("path %q: unterminated [\"", s)
+			}
+			p.steps = append(p.steps, step{key: rest[2:end]})
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return jsonPath{}, fmt.Errorf("path %q: unterminated [", s)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return jsonPath{}, fmt.Errorf("path %q: %q is no index", s, rest[1:end])
+			}
+			p.steps = append(p.steps, step{index: index, isIndex: true})
+			rest = rest[end+1:]
+		default:
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return jsonPath{}, fmt.Errorf("path %q: empty key", s)
+			}
+			p.steps = append(p.steps, step{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	return p, nil
+}
+
+// match is a value a path selected, with the elements each [*] on the way
+// matched, outermost first.
+type match struct {
+	value   any
+	parents []any
+}
+
+// selectAll returns every value p selects from value. Missing members and
+// elements select nothing.
+func (p jsonPath) selectAll(value any) []match {
+	matches := []match{{value: value}}
+	for _, s := range p.steps {
+		var next []match
+		for _, m := range matches {
+			switch v := m.value.(type) {
+			case map[string]any:
+				switch {
+				case s.wildcard:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, match{value: v[key], parents: append(append([]any{}, m.parents...), v[key])})
+					}
+				case !s.isIndex:
+					if child, ok := v[s.key]; ok {
+						next = append(next, match{value: child, parents: m.parents})
+					}
+				}
+			case []any:
+				switch {
+				case s.wildcard:
+					for _, child := range v {
+						next = append(next, match{value: child, parents: append(append([]any{}, m.parents...), child)})
+					}
+				case s.isIndex && s.index < len(v):
+					next = append(next, match{value: v[s.index], parents: m.parents})
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// lookup evaluates p, a field of an issue, against the issue match m, the
+// last element of whose parents is the issue itself.
+func (p jsonPath) lookup(m match) (any, bool) {
+	from := m.value
+	if p.up > 0 {
+		at := len(m.parents) - 1 - p.up
+		if at < 0 {
+			return nil, false
+		}
+		from = m.parents[at]
+	}
+	values := jsonPath{steps: p.steps}.selectAll(from)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0].value, true
+}
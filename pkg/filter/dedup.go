@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// similarity is the share of words two messages need in common, out of
+// all the words of both, to be taken for the same problem.
+const similarity = 0.5
+
+// Deduper drops the issues that another linter already reported at the
+// same file, line and column with a similar message, such as govet and
+// staticcheck both finding a bad printf verb. It remembers the issues it
+// kept, so issues filtered in batches are compared with the earlier ones.
+type Deduper struct {
+	kept map[position][]seen
+}
+
+type position struct {
+	file         string
+	line, column int
+}
+
+type seen struct {
+	linter string
+	words  map[string]bool
+	// index is the place of the issue in the batch being filtered, or -1
+	// once that batch is done.
+	index int
+}
+
+func NewDeduper() *Deduper {
+	return &Deduper{kept: make(map[position][]seen)}
+}
+
+// Filter returns the issues not reported already, the message of each
+// naming the linters that agreed with it in this batch, such as
+// "printf: wrong verb (also staticcheck)". It also returns how many
+// issues were dropped.
+func (d *Deduper) Filter(issues []result.Issue) ([]result.Issue, int) {
+	kept := make([]result.Issue, 0, len(issues))
+	agreed := make(map[int][]string)
+	var touched []position
+	dropped := 0
+	for _, issue := range issues {
+		pos := position{file: issue.FilePath(), line: issue.Line(), column: issue.Column()}
+		words := messageWords(issue.Text)
+		if match := d.match(pos, issue.FromLinter, words); match != nil {
+			if match.index >= 0 && !slices.Contains(agreed[match.index], issue.FromLinter) {
+				agreed[match.index] = append(agreed[match.index], issue.FromLinter)
+			}
+			dropped++
+			continue
+		}
+		d.kept[pos] = append(d.kept[pos], seen{linter: issue.FromLinter, words: words, index: len(kept)})
+		touched = append(touched, pos)
+		kept = append(kept, issue)
+	}
+
+	for index, linters := range agreed {
+		kept[index].Text += " (also " + strings.Join(linters, ", ") + ")"
+	}
+	for _, pos := range touched {
+		for i := range d.kept[pos] {
+			d.kept[pos][i].index = -1
+		}
+	}
+	return kept, dropped
+}
+
+// match returns the issue kept at pos that another linter reported with
+// a message similar to words, or nil.
+func (d *Deduper) match(pos position, linter string, words map[string]bool) *seen {
+	for i := range d.kept[pos] {
+		other := &d.kept[pos][i]
+		if other.linter != linter && similar(other.words, words) {
+			return other
+		}
+	}
+	return nil
+}
+
+// messageWords splits a message into its lowercase words and numbers.
+func messageWords(message string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+func similar(a, b map[string]bool) bool {
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	all := len(a) + len(b) - shared
+	return all > 0 && float64(shared) >= similarity*float64(all)
+}
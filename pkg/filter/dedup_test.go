@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func reported(linter string, line, column int, text string) result.Issue {
+	return result.Issue{FromLinter: linter, Text: text, Pos: token.Position{Filename: "a.go", Line: line, Column: column}}
+}
+
+func describeIssues(issues []result.Issue) []string {
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("%d:%d %s: %s", issue.Line(), issue.Column(), issue.FromLinter, issue.Text))
+	}
+	return lines
+}
+
+func TestDeduper(t *testing.T) {
+	printf := "printf: fmt.Sprintf format %d has arg s of wrong type string"
+	tests := []struct {
+		name        string
+		issues      []result.Issue
+		want        []string
+		wantDropped int
+	}{
+		{
+			name: "same problem",
+			issues: []result.Issue{
+				reported("govet", 4, 9, printf),
+				reported("staticcheck", 4, 9, "SA5009: Printf format %d has arg #1 of wrong type string"),
+				reported("gocritic", 4, 9, "badCall: fmt.Sprintf format %d has arg s of wrong type string"),
+			},
+			want:        []string{"4:9 govet: " + printf + " (also staticcheck, gocritic)"},
+			wantDropped: 2,
+		},
+		{
+			name: "other column",
+			issues: []result.Issue{
+				reported("govet", 4, 9, printf),
+				reported("staticcheck", 4, 2, "SA5009: Printf format %d has arg #1 of wrong type string"),
+			},
+			want: []string{"4:9 govet: " + printf, "4:2 staticcheck: SA5009: Printf format %d has arg #1 of wrong type string"},
+		},
+		{
+			name: "other problem",
+			issues: []result.Issue{
+				reported("errcheck", 7, 2, "Error return value of `f.Close` is not checked"),
+				reported("gosec", 7, 2, "G104: Errors unhandled."),
+			},
+			want: []string{"7:2 errcheck: Error return value of `f.Close` is not checked", "7:2 gosec: G104: Errors unhandled."},
+		},
+		{
+			name: "same linter twice",
+			issues: []result.Issue{
+				reported("govet", 4, 9, printf),
+				reported("govet", 4, 9, printf),
+			},
+			want: []string{"4:9 govet: " + printf, "4:9 govet: " + printf},
+		},
+	}
+	for _, tt := range tests {
+		got, dropped := NewDeduper().Filter(tt.issues)
+		if !reflect.DeepEqual(describeIssues(got), tt.want) || dropped != tt.wantDropped {
+			t.Errorf("%s: kept %q, dropped %d; want %q, dropped %d", tt.name, describeIssues(got), dropped, tt.want, tt.wantDropped)
+		}
+	}
+}
+
+func TestDeduperAcrossBatches(t *testing.T) {
+	d := NewDeduper()
+	first, _ := d.Filter([]result.Issue{reported("govet", 4, 9, "unreachable code")})
+	second, dropped := d.Filter([]result.Issue{reported("staticcheck", 4, 9, "SA4000: unreachable code"), reported("staticcheck", 5, 1, "unreachable code")})
+
+	if want := []string{"4:9 govet: unreachable code"}; !reflect.DeepEqual(describeIssues(first), want) {
+		t.Errorf("first batch kept %q, want %q", describeIssues(first), want)
+	}
+	if want := []string{"5:1 staticcheck: unreachable code"}; !reflect.DeepEqual(describeIssues(second), want) || dropped != 1 {
+		t.Errorf("second batch kept %q, dropped %d; want %q, dropped 1", describeIssues(second), dropped, want)
+	}
+}
@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// FailRule picks the issues that fail a run: those of Linter, or those at
+// least as severe as Severity, as BySeverity ranks them.
+type FailRule struct {
+	Severity string
+	Linter   string
+}
+
+// ParseFailRule reads a rule written "severity=error" or "linter=gosec".
+func ParseFailRule(spec string) (FailRule, error) {
+	key, value, _ := strings.Cut(spec, "=")
+	switch {
+	case value == "":
+	case key == "severity" && ValidSeverity(value):
+		return FailRule{Severity: value}, nil
+	case key == "severity":
+		return FailRule{}, fmt.Errorf("fail-on rule %q: severity is not one of %s", spec, strings.Join(Severities, ", "))
+	case key == "linter":
+		return FailRule{Linter: value}, nil
+	}
+	return FailRule{}, fmt.Errorf("fail-on rule %q is not severity=<severity> or linter=<linter>", spec)
+}
+
+// Failing returns the issues any of the rules picks, or every issue when
+// there are no rules.
+func Failing(issues []result.Issue, rules []FailRule) []result.Issue {
+	if len(rules) == 0 {
+		return issues
+	}
+	failing := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		for _, rule := range rules {
+			if rule.matches(issue) {
+				failing = append(failing, issue)
+				break
+			}
+		}
+	}
+	return failing
+}
+
+func (r FailRule) matches(issue result.Issue) bool {
+	if r.Linter != "" {
+		return issue.FromLinter == r.Linter
+	}
+	return len(BySeverity([]result.Issue{issue}, r.Severity)) == 1
+}
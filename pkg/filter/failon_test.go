@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestParseFailRule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    FailRule
+		wantErr bool
+	}{
+		{spec: "severity=error", want: FailRule{Severity: "error"}},
+		{spec: "severity=note", want: FailRule{Severity: "note"}},
+		{spec: "linter=gosec", want: FailRule{Linter: "gosec"}},
+		{spec: "severity=fatal", wantErr: true},
+		{spec: "linter=", wantErr: true},
+		{spec: "gosec", wantErr: true},
+		{spec: "path=main.go", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFailRule(tt.spec)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFailRule(%q) = %+v, %v; want %+v, error %v", tt.spec, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFailing(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "godot", Severity: "info"},
+		{FromLinter: "gosec", Severity: "warning"},
+		{FromLinter: "govet", Severity: "error"},
+		{FromLinter: "lll"},
+	}
+	tests := []struct {
+		name  string
+		rules []FailRule
+		want  []string
+	}{
+		{name: "no rules", want: []string{"godot", "gosec", "govet", "lll"}},
+		{name: "errors", rules: []FailRule{{Severity: "error"}}, want: []string{"govet"}},
+		{name: "warnings up", rules: []FailRule{{Severity: "warning"}}, want: []string{"gosec", "govet", "lll"}},
+		{name: "errors or gosec", rules: []FailRule{{Severity: "error"}, {Linter: "gosec"}}, want: []string{"gosec", "govet"}},
+		{name: "no match", rules: []FailRule{{Linter: "errcheck"}}, want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := linters(Failing(issues, tt.rules)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: failing %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
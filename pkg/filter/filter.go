@@ -0,0 +1,137 @@
+// Package filter keeps only the lint issues that touch changed lines.
+package filter
+
+import (
+	"path"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+)
+
+// Scopes are the values of --scope, from the strictest: issues on changed
+// lines, in the hunks around them, in changed files, or in the packages of
+// changed files.
+var Scopes = []string{ScopeLine, ScopeHunk, ScopeFile, ScopePackage}
+
+const (
+	ScopeLine    = "line"
+	ScopeHunk    = "hunk"
+	ScopeFile    = "file"
+	ScopePackage = "package"
+)
+
+// ValidScope reports whether SetScope accepts name.
+func ValidScope(name string) bool {
+	for _, scope := range Scopes {
+		if scope == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueFilter keeps the issues reported on changed lines, or in the wider
+// scope set with SetScope.
+type IssueFilter struct {
+	changesByFileName map[string]diff.FileChange
+	packages          map[string]bool
+	scope             string
+	margin            int
+	deletions         bool
+}
+
+// NewIssueFilter returns a filter matching issues against changes.
+func NewIssueFilter(changes []diff.FileChange) *IssueFilter {
+	packages := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		packages[path.Dir(change.Path)] = true
+	}
+	return &IssueFilter{
+		changesByFileName: diff.ByFileName(changes),
+		packages:          packages,
+		scope:             ScopeLine,
+	}
+}
+
+// SetScope widens what Keep accepts to one of Scopes. Covers and Changed
+// still look at changed lines only.
+func (f *IssueFilter) SetScope(scope string) *IssueFilter {
+	f.scope = scope
+	return f
+}
+
+// SetMargin makes Keep count the lines lines around each changed range, or
+// around each hunk with the hunk scope, as changed too, so an issue right
+// next to an edit is kept.
+func (f *IssueFilter) SetMargin(lines int) *IssueFilter {
+	f.margin = lines
+	return f
+}
+
+// SetDeletions makes Keep also count the lines right before and after lines
+// removed outright as changed, since a removal can leave an unused
+// variable or an unreachable statement next to it.
+func (f *IssueFilter) SetDeletions(deletions bool) *IssueFilter {
+	f.deletions = deletions
+	return f
+}
+
+// Keep reports whether issue falls within the scope: by default, whether
+// any line it spans was changed. An issue on a whole construct, such as
+// funlen on a function, is kept when a line in its middle changed.
+func (f *IssueFilter) Keep(issue result.Issue) bool {
+	if f.scope == ScopePackage {
+		return f.packages[path.Dir(issue.FilePath())]
+	}
+	changes, ok := f.changesByFileName[issue.FilePath()]
+	if !ok {
+		return false
+	}
+	lines := issue.GetLineRange()
+	from, to := lines.From-f.margin, lines.To+f.margin
+	if f.deletions && changes.DeletionOverlaps(from, to) {
+		return true
+	}
+	switch f.scope {
+	case ScopeHunk:
+		return changes.HunkOverlaps(from, to)
+	case ScopeFile:
+		return true
+	default:
+		return changes.Overlaps(from, to)
+	}
+}
+
+// Covers reports whether every line issue spans was changed, as needed
+// before rewriting them with its suggested fix.
+func (f *IssueFilter) Covers(issue result.Issue) bool {
+	changes, ok := f.changesByFileName[issue.FilePath()]
+	if !ok {
+		return false
+	}
+	lines := issue.GetLineRange()
+	for line := lines.From; line <= lines.To; line++ {
+		if !changes.Contains(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// Changed reports whether line of the file at path was changed.
+func (f *IssueFilter) Changed(path string, line int) bool {
+	changes, ok := f.changesByFileName[path]
+	return ok && changes.Contains(line)
+}
+
+// Filter returns the issues Keep accepts, preserving their order.
+func (f *IssueFilter) Filter(issues []result.Issue) []result.Issue {
+	filtered := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if f.Keep(issue) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,266 @@
+package filter
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+)
+
+func issueAt(file string, line int) result.Issue {
+	return result.Issue{
+		FromLinter: "test",
+		Text:       "problem",
+		Pos:        token.Position{Filename: file, Line: line},
+	}
+}
+
+func TestIssueFilter(t *testing.T) {
+	f := NewIssueFilter([]diff.FileChange{{
+		Path:    "pkg/a.go",
+		Changes: []*diff.Change{{Start: 5, End: 7}},
+	}})
+
+	issues := []result.Issue{
+		issueAt("pkg/a.go", 4),
+		issueAt("pkg/a.go", 5),
+		issueAt("pkg/a.go", 7),
+		issueAt("pkg/a.go", 8),
+		issueAt("pkg/b.go", 5),
+	}
+
+	got := f.Filter(issues)
+	if len(got) != 2 || got[0].Line() != 5 || got[1].Line() != 7 {
+		t.Errorf("Filter kept %v, want lines 5 and 7 of pkg/a.go", got)
+	}
+	funlen := issueAt("pkg/a.go", 2)
+	funlen.LineRange = &result.Range{From: 2, To: 20}
+	if !f.Keep(funlen) {
+		t.Error("Keep dropped an issue spanning the changed lines")
+	}
+	funlen.LineRange = &result.Range{From: 8, To: 20}
+	if f.Keep(funlen) {
+		t.Error("Keep kept an issue spanning only unchanged lines")
+	}
+	if !f.Changed("pkg/a.go", 6) || f.Changed("pkg/a.go", 8) || f.Changed("pkg/b.go", 6) {
+		t.Error("Changed does not match the changed lines of pkg/a.go")
+	}
+}
+
+func TestIssueFilterScope(t *testing.T) {
+	changes := []diff.FileChange{{
+		Path:    "pkg/a.go",
+		Changes: []*diff.Change{{Start: 5, End: 7}},
+		Hunks:   []*diff.Change{{Start: 2, End: 10}},
+	}}
+	issues := []result.Issue{
+		issueAt("pkg/a.go", 6),
+		issueAt("pkg/a.go", 9),
+		issueAt("pkg/a.go", 30),
+		issueAt("pkg/b.go", 1),
+		issueAt("pkg/sub/c.go", 1),
+		issueAt("other/a.go", 6),
+	}
+
+	tests := []struct {
+		scope string
+		want  []string
+	}{
+		{scope: ScopeLine, want: []string{"pkg/a.go:6"}},
+		{scope: ScopeHunk, want: []string{"pkg/a.go:6", "pkg/a.go:9"}},
+		{scope: ScopeFile, want: []string{"pkg/a.go:6", "pkg/a.go:9", "pkg/a.go:30"}},
+		{scope: ScopePackage, want: []string{"pkg/a.go:6", "pkg/a.go:9", "pkg/a.go:30", "pkg/b.go:1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			var got []string
+			for _, issue := range NewIssueFilter(changes).SetScope(tt.scope).Filter(issues) {
+				got = append(got, fmt.Sprintf("%s:%d", issue.FilePath(), issue.Line()))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !ValidScope("package") || ValidScope("module") {
+		t.Error("ValidScope does not match Scopes")
+	}
+}
+
+func TestIssueFilterMargin(t *testing.T) {
+	changes := []diff.FileChange{{
+		Path:    "pkg/a.go",
+		Changes: []*diff.Change{{Start: 5, End: 7}},
+		Hunks:   []*diff.Change{{Start: 2, End: 10}},
+	}}
+
+	tests := []struct {
+		name   string
+		scope  string
+		margin int
+		line   int
+		want   bool
+	}{
+		{name: "line before the change", scope: ScopeLine, margin: 2, line: 3, want: true},
+		{name: "line after the change", scope: ScopeLine, margin: 2, line: 9, want: true},
+		{name: "beyond the margin", scope: ScopeLine, margin: 2, line: 10, want: false},
+		{name: "no margin", scope: ScopeLine, line: 8, want: false},
+		{name: "around the hunk", scope: ScopeHunk, margin: 1, line: 11, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewIssueFilter(changes).SetScope(tt.scope).SetMargin(tt.margin)
+			if got := f.Keep(issueAt("pkg/a.go", tt.line)); got != tt.want {
+				t.Errorf("Keep(line %d) = %v, want %v", tt.line, got, tt.want)
+			}
+			if tt.line == 8 && f.Changed("pkg/a.go", tt.line) {
+				t.Error("Changed counts the margin")
+			}
+		})
+	}
+}
+
+func TestIssueFilterDeletions(t *testing.T) {
+	changes := []diff.FileChange{
+		{Path: "pkg/a.go", Changes: []*diff.Change{{Start: 5, End: 5}}, Deletions: []int{12}},
+		{Path: "pkg/b.go", Deletions: []int{0}},
+	}
+	issues := []result.Issue{
+		issueAt("pkg/a.go", 5),
+		issueAt("pkg/a.go", 11),
+		issueAt("pkg/a.go", 12),
+		issueAt("pkg/a.go", 13),
+		issueAt("pkg/a.go", 14),
+		issueAt("pkg/b.go", 1),
+		issueAt("pkg/b.go", 2),
+	}
+
+	tests := []struct {
+		name      string
+		deletions bool
+		margin    int
+		want      []string
+	}{
+		{name: "added lines only", want: []string{"pkg/a.go:5"}},
+		{name: "around deletions", deletions: true, want: []string{"pkg/a.go:5", "pkg/a.go:12", "pkg/a.go:13", "pkg/b.go:1"}},
+		{name: "with a margin", deletions: true, margin: 1, want: []string{"pkg/a.go:5", "pkg/a.go:11", "pkg/a.go:12", "pkg/a.go:13", "pkg/a.go:14", "pkg/b.go:1", "pkg/b.go:2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range NewIssueFilter(changes).SetDeletions(tt.deletions).SetMargin(tt.margin).Filter(issues) {
+				got = append(got, fmt.Sprintf("%s:%d", issue.FilePath(), issue.Line()))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueFilterCovers(t *testing.T) {
+	f := NewIssueFilter([]diff.FileChange{{
+		Path:    "pkg/a.go",
+		Changes: []*diff.Change{{Start: 5, End: 7}},
+	}})
+
+	spanning := func(from, to int) result.Issue {
+		issue := issueAt("pkg/a.go", from)
+		issue.LineRange = &result.Range{From: from, To: to}
+		return issue
+	}
+
+	tests := []struct {
+		name  string
+		issue result.Issue
+		want  bool
+	}{
+		{name: "single changed line", issue: issueAt("pkg/a.go", 6), want: true},
+		{name: "range inside change", issue: spanning(5, 7), want: true},
+		{name: "range reaching untouched line", issue: spanning(6, 8), want: false},
+		{name: "other file", issue: issueAt("pkg/b.go", 6), want: false},
+	}
+	for _, tt := range tests {
+		if got := f.Covers(tt.issue); got != tt.want {
+			t.Errorf("%s: Covers = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIssueFilterNoChanges(t *testing.T) {
+	if got := NewIssueFilter(nil).Filter([]result.Issue{issueAt("a.go", 1)}); len(got) != 0 {
+		t.Errorf("Filter kept %v without any change", got)
+	}
+}
+
+func TestExcludePaths(t *testing.T) {
+	changes := []diff.FileChange{
+		{Path: "vendor/x/y.go"},
+		{Path: "api/types_gen.go"},
+		{Path: "cmd/main.go"},
+	}
+
+	got := diff.Paths(ExcludePaths(changes, []string{"vendor", "*_gen.go", "api/*_gen.go"}))
+	if len(got) != 1 || got[0] != "cmd/main.go" {
+		t.Errorf("ExcludePaths kept %v, want [cmd/main.go]", got)
+	}
+}
+
+func TestPathGlobs(t *testing.T) {
+	changes := []diff.FileChange{
+		{Path: "vendor/x/y.go"},
+		{Path: "api/v1/types_gen.go"},
+		{Path: "types_gen.go"},
+		{Path: "api/v1/types.go"},
+		{Path: "cmd/main.go"},
+	}
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{
+			name:    "double star spans directories",
+			exclude: []string{"vendor/**", "**/*_gen.go"},
+			want:    []string{"api/v1/types.go", "cmd/main.go"},
+		},
+		{
+			name:    "include directory",
+			include: []string{"api"},
+			want:    []string{"api/v1/types_gen.go", "api/v1/types.go"},
+		},
+		{
+			name:    "include then exclude",
+			include: []string{"api/**/*.go", "cmd"},
+			exclude: []string{"**/*_gen.go"},
+			want:    []string{"api/v1/types.go", "cmd/main.go"},
+		},
+		{
+			name:    "double star in the middle",
+			include: []string{"api/**/types.go"},
+			want:    []string{"api/v1/types.go"},
+		},
+	}
+	for _, tt := range tests {
+		got := diff.Paths(ExcludePaths(IncludePaths(changes, tt.include), tt.exclude))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: kept %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGoFiles(t *testing.T) {
+	changes := []diff.FileChange{{Path: "README.md"}, {Path: "api/v1/types.go"}, {Path: "api/v1/types.proto"}, {Path: "go.mod"}}
+	if got := diff.Paths(GoFiles(changes)); !reflect.DeepEqual(got, []string{"api/v1/types.go"}) {
+		t.Errorf("GoFiles kept %v", got)
+	}
+	if got := diff.Paths(NonGoFiles(changes)); !reflect.DeepEqual(got, []string{"README.md", "api/v1/types.proto", "go.mod"}) {
+		t.Errorf("NonGoFiles kept %v", got)
+	}
+}
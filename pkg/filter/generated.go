@@ -0,0 +1,62 @@
+package filter
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"linter/pkg/diff"
+)
+
+// generatedPattern is the comment marking a Go file as generated, as
+// https://go.dev/s/generatedcode defines it.
+var generatedPattern = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// IsGenerated reports whether the Go file at path has the generated code
+// comment before its package clause.
+func IsGenerated(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if generatedPattern.MatchString(line) {
+			return true, nil
+		}
+		if strings.HasPrefix(line, "package ") {
+			return false, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// SkipGenerated drops the changed Go files that are generated, reading
+// them from dir, which their paths are relative to, and returns the paths
+// of those dropped. Files gone from dir, such as deleted ones, are kept.
+func SkipGenerated(changes []diff.FileChange, dir string) ([]diff.FileChange, []string, error) {
+	kept := make([]diff.FileChange, 0, len(changes))
+	var skipped []string
+	for _, change := range changes {
+		if strings.HasSuffix(change.Path, ".go") {
+			generated, err := IsGenerated(filepath.Join(dir, change.Path))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, nil, err
+			}
+			if generated {
+				skipped = append(skipped, change.Path)
+				continue
+			}
+		}
+		kept = append(kept, change)
+	}
+	return kept, skipped, nil
+}
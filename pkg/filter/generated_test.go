@@ -0,0 +1,47 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"linter/pkg/diff"
+)
+
+func TestSkipGenerated(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api.pb.go":    "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api\n",
+		"mock.go":      "// Package mock is for tests.\n//\n// Code generated by MockGen. DO NOT EDIT.\npackage mock\n",
+		"crlf_gen.go":  "// Code generated by stringer; DO NOT EDIT.\r\n\r\npackage a\r\n",
+		"hand.go":      "package a\n\n// Code generated by hand. DO NOT EDIT.\nvar x = 1\n",
+		"inline.go":    "package a // Code generated by x. DO NOT EDIT.\n",
+		"near_miss.go": "// Code generated by x. Do not edit.\npackage a\n",
+		"README.md":    "// Code generated by x. DO NOT EDIT.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var changes []diff.FileChange
+	for _, name := range []string{"api.pb.go", "mock.go", "crlf_gen.go", "hand.go", "inline.go", "near_miss.go", "README.md", "deleted.go"} {
+		changes = append(changes, diff.FileChange{Path: name})
+	}
+
+	kept, skipped, err := SkipGenerated(changes, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"api.pb.go", "mock.go", "crlf_gen.go"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+	var paths []string
+	for _, change := range kept {
+		paths = append(paths, change.Path)
+	}
+	if want := []string{"hand.go", "inline.go", "near_miss.go", "README.md", "deleted.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("kept = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Gitignore holds the patterns of the .gitignore files of a repository.
+type Gitignore struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is a .gitignore pattern, a glob relative to base, the
+// directory of its file.
+type ignoreRule struct {
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// LoadGitignore reads the .gitignore files of root and of the directories
+// of files, given relative to root, deeper files taking precedence.
+func LoadGitignore(root string, files []string) (*Gitignore, error) {
+	dirs := map[string]bool{".": true}
+	for _, file := range files {
+		for dir := path.Dir(filepath.ToSlash(file)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if di, dj := depth(sorted[i]), depth(sorted[j]); di != dj {
+			return di < dj
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	g := &Gitignore{}
+	for _, dir := range sorted {
+		content, err := os.ReadFile(filepath.Join(root, dir, ".gitignore"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		g.rules = append(g.rules, parseGitignore(dir, content)...)
+	}
+	return g, nil
+}
+
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// parseGitignore reads the patterns of a .gitignore file in base. A
+// pattern with a slash but at its end is anchored to base, others match at
+// any depth.
+func parseGitignore(base string, content []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(strings.TrimSuffix(scanner.Text(), "\r"), " ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Ignored reports whether file, relative to the root, is ignored by a
+// pattern or lies in an ignored directory, which git does not look into.
+// The last matching pattern decides, and "!" patterns re-include.
+func (g *Gitignore) Ignored(file string) bool {
+	parts := strings.Split(filepath.ToSlash(file), "/")
+	for i := 1; i <= len(parts); i++ {
+		if g.matches(strings.Join(parts[:i], "/"), i < len(parts)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Gitignore) matches(name string, isDir bool) bool {
+	for i := len(g.rules) - 1; i >= 0; i-- {
+		rule := g.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := name
+		if rule.base != "." {
+			if !strings.HasPrefix(name, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, rule.base+"/")
+		}
+		if MatchGlob(rel, rule.pattern) {
+			return !rule.negate
+		}
+	}
+	return false
+}
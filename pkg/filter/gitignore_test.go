@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"linter/pkg/diff"
+)
+
+func TestGitignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":     "# build output\n/bin/\n*.log\n!keep.log\ntmp/\n\\#notes\ndocs/*.go\n",
+		"pkg/.gitignore": "gen/\n/local.go\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file    string
+		ignored bool
+	}{
+		{file: "main.go"},
+		{file: "bin/tool.go", ignored: true},
+		{file: "cmd/bin/tool.go"},
+		{file: "bin"},
+		{file: "debug.log", ignored: true},
+		{file: "a/b/debug.log", ignored: true},
+		{file: "keep.log"},
+		{file: "a/tmp/x.go", ignored: true},
+		{file: "#notes", ignored: true},
+		{file: "docs/a.go", ignored: true},
+		{file: "docs/sub/a.go"},
+		{file: "pkg/gen/a.go", ignored: true},
+		{file: "pkg/local.go", ignored: true},
+		{file: "pkg/sub/local.go"},
+		{file: "local.go"},
+		{file: "gen/a.go"},
+	}
+	var paths []string
+	for _, tt := range tests {
+		paths = append(paths, tt.file)
+	}
+	ignore, err := LoadGitignore(root, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []diff.FileChange
+	for _, tt := range tests {
+		if got := ignore.Ignored(tt.file); got != tt.ignored {
+			t.Errorf("Ignored(%q) = %v, want %v", tt.file, got, tt.ignored)
+		}
+		if !tt.ignored {
+			want = append(want, diff.FileChange{Path: tt.file})
+		}
+	}
+
+	var changes []diff.FileChange
+	for _, path := range paths {
+		changes = append(changes, diff.FileChange{Path: path})
+	}
+	if got := SkipPaths(changes, ignore.Ignored); !reflect.DeepEqual(got, want) {
+		t.Errorf("SkipPaths kept %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package filter
+
+import "github.com/golangci/golangci-lint/pkg/result"
+
+// Limit keeps the first perFile issues of each file and the first total
+// issues overall, in their order, and returns how many it left out. Zero
+// means no limit.
+func Limit(issues []result.Issue, perFile, total int) ([]result.Issue, int) {
+	if perFile <= 0 && total <= 0 {
+		return issues, 0
+	}
+	kept := make([]result.Issue, 0, len(issues))
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		if total > 0 && len(kept) == total {
+			break
+		}
+		path := issue.FilePath()
+		if perFile > 0 && counts[path] == perFile {
+			continue
+		}
+		counts[path]++
+		kept = append(kept, issue)
+	}
+	return kept, len(issues) - len(kept)
+}
@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestLimit(t *testing.T) {
+	var issues []result.Issue
+	for _, pos := range []string{"gen.go", "gen.go", "gen.go", "a.go", "gen.go", "b.go", "a.go"} {
+		issues = append(issues, result.Issue{Pos: token.Position{Filename: pos}})
+	}
+	tests := []struct {
+		perFile, total int
+		want           string
+		wantHidden     int
+	}{
+		{want: "gen.go gen.go gen.go a.go gen.go b.go a.go"},
+		{perFile: 2, want: "gen.go gen.go a.go b.go a.go", wantHidden: 2},
+		{total: 3, want: "gen.go gen.go gen.go", wantHidden: 4},
+		{perFile: 1, total: 2, want: "gen.go a.go", wantHidden: 5},
+		{perFile: 1, total: 10, want: "gen.go a.go b.go", wantHidden: 4},
+	}
+	for _, tt := range tests {
+		kept, hidden := Limit(issues, tt.perFile, tt.total)
+		var paths []string
+		for _, issue := range kept {
+			paths = append(paths, issue.FilePath())
+		}
+		if got := strings.Join(paths, " "); got != tt.want || hidden != tt.wantHidden {
+			t.Errorf("Limit(%d, %d) = %s, %d hidden; want %s, %d hidden", tt.perFile, tt.total, got, hidden, tt.want, tt.wantHidden)
+		}
+	}
+}
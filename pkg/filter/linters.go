@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Severities are the levels BySeverity knows, from the lowest. Issues
+// without a severity count as warnings, and "note" is another name for
+// "info".
+var Severities = []string{"info", "warning", "error"}
+
+// ByLinter keeps the issues of the only linters, or of all of them when
+// only is empty, minus those of the exclude linters.
+func ByLinter(issues []result.Issue, only, exclude []string) []result.Issue {
+	if len(only) == 0 && len(exclude) == 0 {
+		return issues
+	}
+
+	allowed := toSet(only)
+	excluded := toSet(exclude)
+	kept := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if len(allowed) > 0 && !allowed[issue.FromLinter] {
+			continue
+		}
+		if excluded[issue.FromLinter] {
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// ValidSeverity reports whether BySeverity accepts name as its minimum.
+func ValidSeverity(name string) bool {
+	_, ok := severityRank(name)
+	return ok
+}
+
+// BySeverity keeps the issues at least as severe as min, or every issue
+// when min is empty.
+func BySeverity(issues []result.Issue, min string) []result.Issue {
+	if min == "" {
+		return issues
+	}
+	threshold, _ := severityRank(min)
+
+	kept := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		rank, ok := severityRank(issue.Severity)
+		if !ok {
+			rank, _ = severityRank("warning")
+		}
+		if rank >= threshold {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+func severityRank(name string) (int, bool) {
+	if name == "note" {
+		name = "info"
+	}
+	for rank, severity := range Severities {
+		if severity == name {
+			return rank, true
+		}
+	}
+	return 0, false
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func linters(issues []result.Issue) []string {
+	names := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		names = append(names, issue.FromLinter)
+	}
+	return names
+}
+
+func TestByLinter(t *testing.T) {
+	issues := []result.Issue{{FromLinter: "lll"}, {FromLinter: "errcheck"}, {FromLinter: "godot"}, {FromLinter: "govet"}}
+
+	tests := []struct {
+		name          string
+		only, exclude []string
+		want          []string
+	}{
+		{name: "no flags", want: []string{"lll", "errcheck", "godot", "govet"}},
+		{name: "exclude", exclude: []string{"lll", "godot"}, want: []string{"errcheck", "govet"}},
+		{name: "only", only: []string{"govet", "lll"}, want: []string{"lll", "govet"}},
+		{name: "only minus exclude", only: []string{"govet", "lll"}, exclude: []string{"lll"}, want: []string{"govet"}},
+	}
+	for _, tt := range tests {
+		if got := linters(ByLinter(issues, tt.only, tt.exclude)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: kept %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBySeverity(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "a", Severity: "error"},
+		{FromLinter: "b", Severity: "warning"},
+		{FromLinter: "c"},
+		{FromLinter: "d", Severity: "note"},
+		{FromLinter: "e", Severity: "info"},
+	}
+
+	tests := []struct {
+		min  string
+		want []string
+	}{
+		{min: "", want: []string{"a", "b", "c", "d", "e"}},
+		{min: "info", want: []string{"a", "b", "c", "d", "e"}},
+		{min: "warning", want: []string{"a", "b", "c"}},
+		{min: "error", want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		if got := linters(BySeverity(issues, tt.min)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BySeverity(%q) kept %v, want %v", tt.min, got, tt.want)
+		}
+	}
+
+	if ValidSeverity("fatal") {
+		t.Error(`ValidSeverity("fatal") = true`)
+	}
+}
@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"linter/pkg/diff"
+)
+
+// ExcludePaths drops the changed files matching any of the glob patterns.
+// A pattern also matches every file below a matching directory, and a "**"
+// element matches any number of directories.
+func ExcludePaths(changes []diff.FileChange, patterns []string) []diff.FileChange {
+	if len(patterns) == 0 {
+		return changes
+	}
+	return keepPaths(changes, func(file string) bool { return !matchAny(file, patterns) })
+}
+
+// IncludePaths keeps only the changed files matching one of the glob
+// patterns, with the rules of ExcludePaths. No pattern keeps every file.
+func IncludePaths(changes []diff.FileChange, patterns []string) []diff.FileChange {
+	if len(patterns) == 0 {
+		return changes
+	}
+	return keepPaths(changes, func(file string) bool { return matchAny(file, patterns) })
+}
+
+// GoFiles keeps the changed Go source files, the only ones golangci-lint
+// reports issues on.
+func GoFiles(changes []diff.FileChange) []diff.FileChange {
+	return keepPaths(changes, func(file string) bool { return strings.HasSuffix(file, ".go") })
+}
+
+// NonGoFiles keeps the changed files other than Go source files.
+func NonGoFiles(changes []diff.FileChange) []diff.FileChange {
+	return keepPaths(changes, func(file string) bool { return !strings.HasSuffix(file, ".go") })
+}
+
+// SkipPaths drops the changed files skip reports.
+func SkipPaths(changes []diff.FileChange, skip func(file string) bool) []diff.FileChange {
+	return keepPaths(changes, func(file string) bool { return !skip(file) })
+}
+
+func keepPaths(changes []diff.FileChange, keep func(file string) bool) []diff.FileChange {
+	kept := make([]diff.FileChange, 0, len(changes))
+	for _, change := range changes {
+		if keep(change.Path) {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// MatchPath reports whether file matches pattern with the rules of
+// ExcludePaths.
+func MatchPath(file, pattern string) bool {
+	return matchAny(file, []string{pattern})
+}
+
+// MatchGlob reports whether the whole of file matches pattern, where "*"
+// stays within one directory and "**" spans any number of them.
+func MatchGlob(file, pattern string) bool {
+	return matchGlob(strings.Split(pattern, "/"), strings.Split(filepath.ToSlash(file), "/"))
+}
+
+func matchAny(file string, patterns []string) bool {
+	file = filepath.ToSlash(file)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		for dir := file; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if matchGlob(strings.Split(pattern, "/"), strings.Split(dir, "/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchGlob matches the elements of a path against those of a pattern,
+// where "**" stands for zero or more elements.
+func matchGlob(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchGlob(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+// SeverityRule gives the issues of Linter, or only those of its Rule when
+// set, the severity a team wants instead of the one reported.
+type SeverityRule struct {
+	Linter   string
+	Rule     string
+	Severity string
+}
+
+// Validate checks the rule names a linter and a severity BySeverity knows.
+func (r SeverityRule) Validate() error {
+	switch {
+	case r.Linter == "":
+		return fmt.Errorf("severity rule %s needs a linter", r.Severity)
+	case !ValidSeverity(r.Severity):
+		return fmt.Errorf("severity rule for %s: %q is not one of %s", r.Linter, r.Severity, strings.Join(Severities, ", "))
+	}
+	return nil
+}
+
+// Remap sets the severity of the issues the rules match. A rule naming the
+// rule of an issue wins over one naming only its linter, and otherwise the
+// first matching rule applies.
+func Remap(issues []result.Issue, rules []SeverityRule) []result.Issue {
+	if len(rules) == 0 {
+		return issues
+	}
+	remapped := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		var byLinter, byRule *SeverityRule
+		for i, rule := range rules {
+			switch {
+			case rule.Linter != issue.FromLinter:
+			case rule.Rule == "" && byLinter == nil:
+				byLinter = &rules[i]
+			case rule.Rule != "" && byRule == nil && rule.Rule == fingerprint.Rule(issue):
+				byRule = &rules[i]
+			}
+		}
+		switch {
+		case byRule != nil:
+			issue.Severity = byRule.Severity
+		case byLinter != nil:
+			issue.Severity = byLinter.Severity
+		}
+		remapped = append(remapped, issue)
+	}
+	return remapped
+}
@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestSeverityRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule    SeverityRule
+		wantErr bool
+	}{
+		{rule: SeverityRule{Linter: "errcheck", Severity: "error"}},
+		{rule: SeverityRule{Linter: "staticcheck", Rule: "SA1019", Severity: "note"}},
+		{rule: SeverityRule{Severity: "error"}, wantErr: true},
+		{rule: SeverityRule{Linter: "godox", Severity: "fatal"}, wantErr: true},
+		{rule: SeverityRule{Linter: "godox"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() = %v, want error %v", tt.rule, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRemap(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked"},
+		{FromLinter: "godox", Text: "TODO found", Severity: "warning"},
+		{FromLinter: "staticcheck", Text: "SA1019: deprecated", Severity: "error"},
+		{FromLinter: "staticcheck", Text: "SA4006: unused value", Severity: "error"},
+		{FromLinter: "govet", Text: "shadow", Severity: "warning"},
+	}
+	rules := []SeverityRule{
+		{Linter: "errcheck", Severity: "error"},
+		{Linter: "godox", Severity: "info"},
+		{Linter: "staticcheck", Severity: "warning"},
+		{Linter: "staticcheck", Rule: "SA1019", Severity: "info"},
+		{Linter: "errcheck", Severity: "info"},
+	}
+
+	var got []string
+	for _, issue := range Remap(issues, rules) {
+		got = append(got, issue.Severity)
+	}
+	want := []string{"error", "info", "info", "warning", "warning"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Remap severities = %v, want %v", got, want)
+	}
+	if issues[0].Severity != "" {
+		t.Errorf("Remap changed its input: %q", issues[0].Severity)
+	}
+	if got := Remap(issues, nil); !reflect.DeepEqual(got, issues) {
+		t.Errorf("Remap without rules = %+v", got)
+	}
+}
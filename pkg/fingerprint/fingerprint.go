@@ -0,0 +1,101 @@
+// Package fingerprint identifies lint issues independently of their line.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/blame"
+)
+
+// ShortLength is how many characters of a fingerprint are shown where a
+// full hash would not fit, such as in a Markdown table.
+const ShortLength = 12
+
+var (
+	numberPattern     = regexp.MustCompile(`\d+`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	// rulePattern finds the check identifier golangci-lint puts in front
+	// of the message for linters with several rules, as in "SA1019: ..."
+	// or "var-naming: ...".
+	rulePattern = regexp.MustCompile(`^([A-Z]+\d+|[a-z]+(?:-[a-z]+)*): `)
+	// agreementPattern matches the linters filter.Deduper appends to the
+	// message of an issue others reported too, as in " (also staticcheck)".
+	agreementPattern = regexp.MustCompile(` \(also [^()]*\)$`)
+)
+
+// Of hashes the file, linter, rule, normalized message and source lines of
+// issue. Line numbers are left out, digits in the message are masked and
+// indentation is ignored, so the fingerprint survives unrelated edits
+// elsewhere in the file but not a change of the offending code itself.
+// The author --blame adds to the message and the linters that agreed with
+// it are left out as well.
+func Of(issue result.Issue) string {
+	issue.Text = agreementPattern.ReplaceAllString(blame.StripAnnotation(issue.Text), "")
+	rule := Rule(issue)
+	source := make([]string, 0, len(issue.SourceLines))
+	for _, line := range issue.SourceLines {
+		source = append(source, normalize(line))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		filepath.ToSlash(issue.FilePath()),
+		issue.FromLinter,
+		rule,
+		normalize(strings.TrimPrefix(issue.Text, rule+": ")),
+		strings.Join(source, "\n"),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Short returns the beginning of the fingerprint of issue.
+func Short(issue result.Issue) string {
+	return Of(issue)[:ShortLength]
+}
+
+// Occurrence is the fingerprint of an issue and how many issues before it
+// in the same list share it.
+type Occurrence struct {
+	Hash  string
+	Index int
+}
+
+// String joins the fingerprint and the index, as in "3f2a...:1".
+func (o Occurrence) String() string {
+	return o.Hash + ":" + strconv.Itoa(o.Index)
+}
+
+// Occurrences returns the occurrence of every issue. Identical messages in
+// one file share a fingerprint, and the index keeps them apart.
+func Occurrences(issues []result.Issue) []Occurrence {
+	seen := make(map[string]int)
+	occurrences := make([]Occurrence, 0, len(issues))
+	for _, issue := range issues {
+		hash := Of(issue)
+		occurrences = append(occurrences, Occurrence{Hash: hash, Index: seen[hash]})
+		seen[hash]++
+	}
+	return occurrences
+}
+
+// Rule returns the rule of the linter that reported issue, when its message
+// names one, or an empty string.
+func Rule(issue result.Issue) string {
+	match := rulePattern.FindStringSubmatch(issue.Text)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func normalize(text string) string {
+	text = numberPattern.ReplaceAllString(text, "N")
+	text = whitespacePattern.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
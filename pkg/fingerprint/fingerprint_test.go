@@ -0,0 +1,104 @@
+package fingerprint
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/blame"
+)
+
+func issue(file string, line int, text string, source ...string) result.Issue {
+	return result.Issue{
+		FromLinter:  "staticcheck",
+		Text:        text,
+		SourceLines: source,
+		Pos:         token.Position{Filename: file, Line: line},
+	}
+}
+
+func TestOfIgnoresPositionAndFormatting(t *testing.T) {
+	base := Of(issue("a.go", 10, "SA4006: x declared at line 10 is never used", "\tx := f()"))
+
+	same := []result.Issue{
+		issue("a.go", 42, "SA4006: x declared at line 42 is never used", "\tx := f()"),
+		issue("a.go", 10, "SA4006: x  declared at line 10 is never used", "\t\t\tx :=   f()"),
+	}
+	for _, i := range same {
+		if got := Of(i); got != base {
+			t.Errorf("Of(%q, %q) changed the fingerprint", i.Text, i.SourceLines)
+		}
+	}
+
+	different := []result.Issue{
+		issue("b.go", 10, "SA4006: x declared at line 10 is never used", "\tx := f()"),
+		issue("a.go", 10, "SA4006: y declared at line 10 is never used", "\tx := f()"),
+		issue("a.go", 10, "SA4006: x declared at line 10 is never used", "\tx := g()"),
+		issue("a.go", 10, "SA4010: x declared at line 10 is never used", "\tx := f()"),
+	}
+	for _, i := range different {
+		if got := Of(i); got == base {
+			t.Errorf("Of(%s, %q, %q) kept the fingerprint", i.FilePath(), i.Text, i.SourceLines)
+		}
+	}
+
+	other := issue("a.go", 10, "SA4006: x declared at line 10 is never used", "\tx := f()")
+	other.FromLinter = "govet"
+	if Of(other) == base {
+		t.Error("another linter kept the fingerprint")
+	}
+}
+
+func TestShort(t *testing.T) {
+	i := issue("a.go", 1, "unused")
+	if got := Short(i); len(got) != ShortLength || Of(i)[:ShortLength] != got {
+		t.Errorf("Short = %q, want the start of %q", got, Of(i))
+	}
+}
+
+func TestRule(t *testing.T) {
+	tests := map[string]string{
+		"SA1019: strings.Title is deprecated":               "SA1019",
+		"G104: Errors unhandled.":                           "G104",
+		"var-naming: func Foo_bar should be FooBar":         "var-naming",
+		"exported: exported function X should have comment": "exported",
+		"Error return value of `f.Close` is not checked":    "",
+		"line is 130 characters":                            "",
+	}
+	for text, want := range tests {
+		if got := Rule(issue("a.go", 1, text)); got != want {
+			t.Errorf("Rule(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestOfIgnoresBlame(t *testing.T) {
+	plain := issue("a.go", 1, "x is unused")
+	annotated := plain
+	annotated.Text = blame.Annotate(plain.Text, blame.Author{Name: "Ada", Email: "ada@example.com"})
+	if Of(annotated) != Of(plain) {
+		t.Error("the blame annotation changed the fingerprint")
+	}
+}
+
+func TestOfIgnoresAgreement(t *testing.T) {
+	plain := issue("a.go", 1, "x is unused")
+	agreed := plain
+	agreed.Text = blame.Annotate(plain.Text+" (also unused, gocritic)", blame.Author{Name: "Ada", Email: "ada@example.com"})
+	if Of(agreed) != Of(plain) {
+		t.Error("the linters that agreed changed the fingerprint")
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	a := issue("a.go", 1, "x is unused")
+	b := issue("a.go", 2, "y is unused")
+	got := Occurrences([]result.Issue{a, b, a, a})
+	want := []string{Of(a) + ":0", Of(b) + ":0", Of(a) + ":1", Of(a) + ":2"}
+	for i, o := range got {
+		if o.String() != want[i] {
+			t.Errorf("occurrence %d = %s, want %s", i, o, want[i])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package fix applies the replacements golangci-lint suggests for issues.
+package fix
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// edit replaces the inclusive line range from..to of a file with lines.
+type edit struct {
+	from, to int
+	lines    []string
+}
+
+// File is the fixed content of one file.
+type File struct {
+	Path   string
+	before []string
+	after  []string
+	edits  []edit
+}
+
+// Plan holds the fixes of a set of issues, grouped by file.
+type Plan struct {
+	dir   string
+	Files []*File
+	// Fixed holds the indexes of the issues whose fix is part of the plan.
+	Fixed []int
+	// Skipped counts fixes dropped for overlapping an earlier one.
+	Skipped int
+}
+
+// NewPlan reads the files of issues carrying a replacement from dir, where
+// their paths are relative to, and prepares the fixed content.
+func NewPlan(dir string, issues []result.Issue) (*Plan, error) {
+	plan := &Plan{dir: dir}
+
+	byPath := make(map[string][]int)
+	var paths []string
+	for i, issue := range issues {
+		if issue.Replacement == nil {
+			continue
+		}
+		path := issue.FilePath()
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], i)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(plan.resolve(path))
+		if err != nil {
+			return nil, err
+		}
+		file := &File{Path: path, before: strings.Split(string(content), "\n")}
+
+		indexes := byPath[path]
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return issues[indexes[i]].GetLineRange().From < issues[indexes[j]].GetLineRange().From
+		})
+
+		last := 0
+		for _, index := range indexes {
+			e, err := toEdit(issues[index], file.before)
+			if err != nil {
+				return nil, err
+			}
+			if e.from <= last {
+				plan.Skipped++
+				continue
+			}
+			last = e.to
+			file.edits = append(file.edits, e)
+			plan.Fixed = append(plan.Fixed, index)
+		}
+
+		file.after = apply(file.before, file.edits)
+		plan.Files = append(plan.Files, file)
+	}
+	return plan, nil
+}
+
+func toEdit(issue result.Issue, lines []string) (edit, error) {
+	fix := issue.Replacement
+	lineRange := issue.GetLineRange()
+	if lineRange.From < 1 || lineRange.To > len(lines) || lineRange.From > lineRange.To {
+		return edit{}, fmt.Errorf("%s:%d: fix outside of the file", issue.FilePath(), lineRange.From)
+	}
+
+	switch {
+	case fix.Inline != nil:
+		line := lines[issue.Line()-1]
+		start, end := fix.Inline.StartCol, fix.Inline.StartCol+fix.Inline.Length
+		if start < 0 || end > len(line) {
+			return edit{}, fmt.Errorf("%s:%d: inline fix outside of the line", issue.FilePath(), issue.Line())
+		}
+		return edit{
+			from:  issue.Line(),
+			to:    issue.Line(),
+			lines: []string{line[:start] + fix.Inline.NewString + line[end:]},
+		}, nil
+	case fix.NeedOnlyDelete:
+		return edit{from: lineRange.From, to: lineRange.To}, nil
+	default:
+		return edit{from: lineRange.From, to: lineRange.To, lines: fix.NewLines}, nil
+	}
+}
+
+func apply(lines []string, edits []edit) []string {
+	fixed := make([]string, 0, len(lines))
+	next := 1
+	for _, e := range edits {
+		fixed = append(fixed, lines[next-1:e.from-1]...)
+		fixed = append(fixed, e.lines...)
+		next = e.to + 1
+	}
+	return append(fixed, lines[next-1:]...)
+}
+
+func (p *Plan) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.dir, path)
+}
+
+// Write saves every fixed file in place.
+func (p *Plan) Write() error {
+	for _, file := range p.Files {
+		path := p.resolve(file.Path)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(file.after, "\n")), info.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff writes the plan as a unified diff without context lines, which
+// git apply --unidiff-zero accepts.
+func (p *Plan) Diff(w io.Writer) error {
+	var b strings.Builder
+	for _, file := range p.Files {
+		path := filepath.ToSlash(file.Path)
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+
+		offset := 0
+		for _, e := range file.edits {
+			oldCount, newCount := e.to-e.from+1, len(e.lines)
+			newStart := e.from + offset
+			if newCount == 0 {
+				// An empty side starts at the line before the change.
+				newStart--
+			}
+			fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", e.from, oldCount, newStart, newCount)
+			for _, line := range file.before[e.from-1 : e.to] {
+				b.WriteString("-" + line + "\n")
+			}
+			for _, line := range e.lines {
+				b.WriteString("+" + line + "\n")
+			}
+			offset += newCount - oldCount
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
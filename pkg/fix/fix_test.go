@@ -0,0 +1,112 @@
+package fix
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+const source = `package a
+
+import "fmt"
+
+func A() {
+	fmt.Println("a" )
+	x := 1
+	_ = x
+}
+`
+
+func issueAt(line int, fix *result.Replacement, lineRange *result.Range) result.Issue {
+	return result.Issue{
+		FromLinter:  "test",
+		Pos:         token.Position{Filename: "a.go", Line: line},
+		LineRange:   lineRange,
+		Replacement: fix,
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []result.Issue{
+		issueAt(7, &result.Replacement{NeedOnlyDelete: true}, &result.Range{From: 7, To: 8}),
+		issueAt(6, &result.Replacement{Inline: &result.InlineFix{StartCol: 16, Length: 1, NewString: ""}}, nil),
+		issueAt(3, &result.Replacement{NewLines: []string{"import (", `	"fmt"`, ")"}}, nil),
+		// Overlaps the deletion above and is skipped.
+		issueAt(8, &result.Replacement{NeedOnlyDelete: true}, nil),
+		// Without a fix the issue plays no part.
+		issueAt(1, nil, nil),
+	}
+
+	plan, err := NewPlan(dir, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Fixed, []int{2, 1, 0}) || plan.Skipped != 1 {
+		t.Errorf("fixed %v, skipped %d; want [2 1 0], 1", plan.Fixed, plan.Skipped)
+	}
+
+	var patch bytes.Buffer
+	if err := plan.Diff(&patch); err != nil {
+		t.Fatal(err)
+	}
+	wantPatch := `--- a/a.go
++++ b/a.go
+@@ -3,1 +3,3 @@
+-import "fmt"
++import (
++	"fmt"
++)
+@@ -6,1 +8,1 @@
+-	fmt.Println("a" )
++	fmt.Println("a")
+@@ -7,2 +8,0 @@
+-	x := 1
+-	_ = x
+`
+	if patch.String() != wantPatch {
+		t.Errorf("Diff =\n%s\nwant\n%s", patch.String(), wantPatch)
+	}
+
+	if err := plan.Write(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package a
+
+import (
+	"fmt"
+)
+
+func A() {
+	fmt.Println("a")
+}
+`
+	if string(got) != want {
+		t.Errorf("fixed file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlanRejectsFixesOutsideTheFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []result.Issue{issueAt(9, &result.Replacement{NeedOnlyDelete: true}, nil)}
+	if _, err := NewPlan(dir, issues); err == nil {
+		t.Error("NewPlan expected an error")
+	}
+}
@@ -0,0 +1,81 @@
+// Package forge holds what the clients of code forges share: JSON requests
+// to their REST APIs, and the hidden marker that lets later runs recognise
+// the comments of earlier ones.
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// ErrNotFound is returned by Do for a 404 response.
+var ErrNotFound = errors.New("not found")
+
+// markerPattern finds the marker Comment ends a comment with.
+var markerPattern = regexp.MustCompile(`<!-- linterdiff:([0-9a-f]+:\d+) -->`)
+
+// Do sends in, encoded as JSON unless nil, to path under baseURL with
+// header, and decodes the JSON response into out unless nil. Responses
+// other than 2xx are errors quoting the start of their body.
+func Do(client *http.Client, method, baseURL, path string, header http.Header, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%s %s: %w: %s", method, path, ErrNotFound, strings.TrimSpace(string(message)))
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Comment is the Markdown body of a comment on issue, ending with a hidden
+// marker of key, which Key reads back.
+func Comment(issue result.Issue, key string) string {
+	return fmt.Sprintf("**%s**: %s\n\n<!-- linterdiff:%s -->", issue.FromLinter, issue.Text, key)
+}
+
+// Key returns the key marked in the body of a comment, if it has one.
+func Key(body string) (string, bool) {
+	match := markerPattern.FindStringSubmatch(body)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
@@ -0,0 +1,94 @@
+package forge
+
+import (
+	"encoding/json"
+	"errors"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/echo":
+			if r.Header.Get("Content-Type") != "application/json" {
+				http.Error(w, "no JSON", http.StatusUnsupportedMediaType)
+				return
+			}
+			var in map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"echo": in["say"]})
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		token    string
+		path     string
+		in       interface{}
+		want     string
+		wantErr  string
+		notFound bool
+	}{
+		{name: "decoded", token: "secret", path: "/echo", in: map[string]string{"say": "hi"}, want: "hi"},
+		{name: "no content", token: "secret", path: "/empty"},
+		{name: "not found", token: "secret", path: "/missing", wantErr: "POST /missing: not found", notFound: true},
+		{name: "error status", token: "wrong", path: "/echo", wantErr: `POST /echo: 401 Unauthorized: {"message":"Bad credentials"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Authorization", "Bearer "+tt.token)
+			// Responses without a body are not decoded.
+			var out map[string]string
+			var dst interface{}
+			if tt.want != "" {
+				dst = &out
+			}
+			err := Do(server.Client(), http.MethodPost, server.URL, tt.path, header, tt.in, dst)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) || errors.Is(err, ErrNotFound) != tt.notFound {
+					t.Errorf("Do = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out["echo"] != tt.want {
+				t.Errorf("decoded %v, want echo %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentKey(t *testing.T) {
+	issue := result.Issue{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 1}}
+	body := Comment(issue, "3f2a:1")
+	if !strings.HasPrefix(body, "**errcheck**: unchecked\n\n") {
+		t.Errorf("Comment = %q", body)
+	}
+	if key, ok := Key(body); !ok || key != "3f2a:1" {
+		t.Errorf("Key = %q, %v, want 3f2a:1", key, ok)
+	}
+	if _, ok := Key("**errcheck**: written by hand"); ok {
+		t.Error("Key found a marker in a comment without one")
+	}
+}
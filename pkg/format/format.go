@@ -0,0 +1,191 @@
+// Package format runs gofmt, gofumpt or goimports in diff mode and turns
+// every change they would make into an issue carrying its fix.
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"os/exec"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/command"
+	"linter/pkg/diff"
+)
+
+// Tools lists the formatters a check can run.
+var Tools = []string{ToolGofmt, ToolGofumpt, ToolGoimports}
+
+const (
+	ToolGofmt     = "gofmt"
+	ToolGofumpt   = "gofumpt"
+	ToolGoimports = "goimports"
+)
+
+// ValidTool reports whether tool is one of Tools.
+func ValidTool(tool string) bool {
+	for _, known := range Tools {
+		if tool == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Checker runs one formatter over Go files.
+type Checker struct {
+	ctx  context.Context
+	tool string
+	bin  string
+	pwd  string
+}
+
+// New returns a checker running tool, found on $PATH, in the current
+// directory.
+func New(tool string) *Checker {
+	return &Checker{
+		ctx:  context.Background(),
+		tool: tool,
+		bin:  tool,
+	}
+}
+
+// SetBin sets the formatter binary to run.
+func (c *Checker) SetBin(bin string) *Checker {
+	c.bin = bin
+	return c
+}
+
+// SetContext sets the context the formatter runs under.
+func (c *Checker) SetContext(ctx context.Context) *Checker {
+	c.ctx = ctx
+	return c
+}
+
+// SetPwd sets the directory the formatter runs in, and the paths of files
+// and issues are relative to.
+func (c *Checker) SetPwd(pwd string) *Checker {
+	c.pwd = pwd
+	return c
+}
+
+// Run formats files without writing them and returns an issue for every
+// block of lines the formatter would change. No files means no issues.
+func (c *Checker) Run(files ...string) ([]result.Issue, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	output, err := command.New(c.bin, append([]string{"-d"}, files...)...).
+		SetContext(c.ctx).
+		SetDir(c.pwd).
+		Output()
+	if err != nil && !foundDiffs(err) {
+		return nil, fmt.Errorf("%s: %w", c.tool, err)
+	}
+	patches, err := diff.ParsePatches(bytes.NewReader(output))
+	if err != nil {
+		return nil, fmt.Errorf("%s -d: %w", c.tool, err)
+	}
+
+	var issues []result.Issue
+	for _, patch := range patches {
+		for _, hunk := range patch.Hunks {
+			issues = append(issues, c.hunkIssues(patch.NewPath, hunk)...)
+		}
+	}
+	return issues, nil
+}
+
+// foundDiffs reports whether err is gofmt or gofumpt exiting with 1 to say
+// that files are not formatted, rather than failing: a failure writes why
+// to stderr.
+func foundDiffs(err error) bool {
+	var cmdErr *command.Error
+	var exitErr *exec.ExitError
+	return errors.As(err, &cmdErr) && cmdErr.Stderr == "" &&
+		errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+}
+
+// hunkIssues turns each run of removed and added lines of hunk into an
+// issue on the lines of the file as it is, the old side of the diff. A run
+// only adding lines is anchored on a neighbouring context line, which its
+// fix keeps.
+func (c *Checker) hunkIssues(path string, hunk diff.Hunk) []result.Issue {
+	var (
+		issues []result.Issue
+		line   = hunk.OldStart
+		// before is the context line preceding the run, if any.
+		before    string
+		hasBefore bool
+		removed   int
+		added     []string
+		runStart  int
+	)
+	if hunk.OldCount == 0 {
+		line++
+	}
+	flush := func(next string, hasNext bool) {
+		if removed == 0 && len(added) == 0 {
+			return
+		}
+		issue := c.issue(path, runStart, removed, added)
+		if removed == 0 {
+			switch {
+			case hasBefore:
+				issue = c.issue(path, runStart-1, 1, append([]string{before}, added...))
+			case hasNext:
+				issue = c.issue(path, runStart, 1, append(append([]string{}, added...), next))
+			default:
+				issue.Replacement = nil
+				issue.Pos.Line = max(runStart-1, 1)
+			}
+		}
+		issues = append(issues, issue)
+		removed, added = 0, nil
+	}
+	for _, body := range hunk.Lines {
+		switch {
+		case strings.HasPrefix(body, "-"):
+			if removed == 0 && len(added) == 0 {
+				runStart = line
+			}
+			removed++
+			line++
+		case strings.HasPrefix(body, "+"):
+			if removed == 0 && len(added) == 0 {
+				runStart = line
+			}
+			added = append(added, body[1:])
+		case strings.HasPrefix(body, " "), body == "":
+			text := strings.TrimPrefix(body, " ")
+			flush(text, true)
+			before, hasBefore = text, true
+			line++
+		}
+	}
+	flush("", false)
+	return issues
+}
+
+// issue reports count lines from line, to be replaced by lines.
+func (c *Checker) issue(path string, line, count int, lines []string) result.Issue {
+	issue := result.Issue{
+		FromLinter: c.tool,
+		Text:       fmt.Sprintf("File is not `%s`-ed", c.tool),
+		Pos:        token.Position{Filename: path, Line: line},
+		Replacement: &result.Replacement{
+			NewLines: lines,
+		},
+	}
+	if len(lines) == 0 {
+		issue.Replacement = &result.Replacement{NeedOnlyDelete: true}
+	}
+	if count > 1 {
+		issue.LineRange = &result.Range{From: line, To: line + count - 1}
+	}
+	return issue
+}
@@ -0,0 +1,232 @@
+package format
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"linter/pkg/fix"
+)
+
+// fakeFormatter writes a formatter printing output whatever it is given.
+func fakeFormatter(t *testing.T, output string, code int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake formatter is a shell script")
+	}
+
+	script := "#!/bin/sh\ncat <<'EOF'\n" + output + "EOF\nexit " + fmt.Sprint(code) + "\n"
+	path := filepath.Join(t.TempDir(), "gofumpt")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []string
+	}{
+		{
+			name: "no diff",
+			diff: "",
+			want: nil,
+		},
+		{
+			name: "changed line",
+			diff: `diff a.go.orig a.go
+--- a.go.orig
++++ a.go
+@@ -2,3 +2,3 @@
+
+-func f(){
++func f() {
+ }
+`,
+			want: []string{"a.go:3 [func f() {]"},
+		},
+		{
+			name: "lines joined",
+			diff: `--- a.go.orig
++++ a.go
+@@ -1,4 +1,3 @@
+ package a
+-var x = []int{1,
+-	2}
++var x = []int{1, 2}
+ var y int
+`,
+			want: []string{"a.go:2-3 [var x = []int{1, 2}]"},
+		},
+		{
+			name: "line removed",
+			diff: `--- b/a.go.orig
++++ b/a.go
+@@ -1,4 +1,3 @@
+ package a
+-
+
+ func f() {}
+`,
+			want: []string{"a.go:2 delete"},
+		},
+		{
+			name: "line added after context",
+			diff: `--- a.go.orig
++++ a.go
+@@ -1,2 +1,3 @@
+ package a
++
+ func f() {}
+`,
+			want: []string{"a.go:1 [package a ]"},
+		},
+		{
+			name: "line added at the top",
+			diff: `--- a.go.orig
++++ a.go
+@@ -1,2 +1,3 @@
++// Package a.
+ package a
+ func f() {}
+`,
+			want: []string{"a.go:1 [// Package a. package a]"},
+		},
+		{
+			name: "two runs in two files",
+			diff: `--- a.go.orig
++++ a.go
+@@ -1,5 +1,5 @@
+-package  a
++package a
+
+ import "fmt"
+
+-var x=1
++var x = 1
+--- b.go.orig
++++ b.go
+@@ -3,1 +3,1 @@
+-var y=2
++var y = 2
+`,
+			want: []string{"a.go:1 [package a]", "a.go:5 [var x = 1]", "b.go:3 [var y = 2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := New(ToolGofumpt).
+				SetBin(fakeFormatter(t, tt.diff, 0)).
+				Run("a.go", "b.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, issue := range issues {
+				if issue.FromLinter != ToolGofumpt || issue.Text != "File is not `gofumpt`-ed" {
+					t.Errorf("issue from %s: %s", issue.FromLinter, issue.Text)
+				}
+				where := fmt.Sprintf("%s:%d", issue.FilePath(), issue.Line())
+				if lines := issue.GetLineRange(); lines.To != lines.From {
+					where += fmt.Sprintf("-%d", lines.To)
+				}
+				if issue.Replacement.NeedOnlyDelete {
+					got = append(got, where+" delete")
+				} else {
+					got = append(got, fmt.Sprintf("%s %v", where, issue.Replacement.NewLines))
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRunNoFiles(t *testing.T) {
+	issues, err := New(ToolGofumpt).SetBin("/nonexistent").Run()
+	if err != nil || issues != nil {
+		t.Errorf("Run() = %v, %v, want nothing", issues, err)
+	}
+}
+
+func TestRunExitOneWithDiffs(t *testing.T) {
+	diff := "--- a.go.orig\n+++ a.go\n@@ -1 +1 @@\n-package  a\n+package a\n"
+	issues, err := New(ToolGofumpt).SetBin(fakeFormatter(t, diff, 1)).Run("a.go")
+	if err != nil || len(issues) != 1 {
+		t.Errorf("Run() = %v, %v, want one issue", issues, err)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	_, err := New(ToolGofumpt).SetBin(fakeFormatter(t, "a.go:1:1: expected 'package'\n", 2)).Run("a.go")
+	if err == nil || !strings.HasPrefix(err.Error(), "gofumpt: ") {
+		t.Errorf("error = %v, want a gofumpt failure", err)
+	}
+}
+
+// TestFixesMatchGofmt applies the fixes of the issues gofmt finds and
+// expects the file gofmt would write.
+func TestFixesMatchGofmt(t *testing.T) {
+	bin, err := exec.LookPath("gofmt")
+	if err != nil {
+		t.Skip("gofmt not found")
+	}
+	dir := t.TempDir()
+	source := `// Package a is badly formatted.
+package a
+import "fmt"
+
+
+func f( x int ) {
+if x>1 { fmt.Println( "big" ) }
+}
+var y = []int{1,
+2,
+3}
+type t struct{
+a int
+bcd string
+}
+`
+	path := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want, err := exec.Command(bin, path).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := New(ToolGofmt).SetBin(bin).SetPwd(dir).Run("a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) == 0 {
+		t.Fatal("no issues found")
+	}
+	plan, err := fix.NewPlan(dir, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Skipped > 0 {
+		t.Errorf("%d fixes skipped", plan.Skipped)
+	}
+	if err := plan.Write(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("fixed file:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package gerrit turns lint issues into robot comments on a Gerrit change.
+package gerrit
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/forge"
+	"linter/pkg/span"
+)
+
+// RobotID names us as the robot of the comments.
+const RobotID = "linterdiff"
+
+// ReviewInput is the body of a Gerrit review carrying robot comments, keyed
+// by file path.
+type ReviewInput struct {
+	Tag           string                    `json:"tag"`
+	RobotComments map[string][]RobotComment `json:"robot_comments"`
+}
+
+type RobotComment struct {
+	RobotID        string            `json:"robot_id"`
+	RobotRunID     string            `json:"robot_run_id"`
+	Line           int               `json:"line,omitempty"`
+	Range          *Range            `json:"range,omitempty"`
+	Message        string            `json:"message"`
+	URL            string            `json:"url,omitempty"`
+	Properties     map[string]string `json:"properties,omitempty"`
+	FixSuggestions []FixSuggestion   `json:"fix_suggestions,omitempty"`
+}
+
+// Range spans lines and 0-based character offsets within them.
+type Range struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+type FixSuggestion struct {
+	Description  string           `json:"description"`
+	Replacements []FixReplacement `json:"replacements"`
+}
+
+type FixReplacement struct {
+	Path        string `json:"path"`
+	Range       Range  `json:"range"`
+	Replacement string `json:"replacement"`
+}
+
+// NewReviewInput returns one robot comment per issue. Its run ID is derived
+// from the issues, so repeating a run yields the same review.
+func NewReviewInput(issues []result.Issue) ReviewInput {
+	run := sha256.New()
+	for _, issue := range issues {
+		fmt.Fprintln(run, fingerprint.Of(issue))
+	}
+	runID := hex.EncodeToString(run.Sum(nil))[:fingerprint.ShortLength]
+
+	review := ReviewInput{
+		Tag:           "autogenerated:" + RobotID,
+		RobotComments: make(map[string][]RobotComment),
+	}
+	for _, issue := range issues {
+		path := filepath.ToSlash(issue.FilePath())
+		comment := RobotComment{
+			RobotID:    RobotID,
+			RobotRunID: runID,
+			Line:       issue.Line(),
+			Message:    fmt.Sprintf("%s: %s", issue.FromLinter, issue.Text),
+			URL:        "https://golangci-lint.run/usage/linters/#" + strings.ToLower(issue.FromLinter),
+			Properties: map[string]string{"linter": issue.FromLinter, "fingerprint": fingerprint.Short(issue)},
+		}
+		if issue.Severity != "" {
+			comment.Properties["severity"] = issue.Severity
+		}
+		if start, end, ok := span.Token(issue); ok {
+			line := issue.SourceLines[0]
+			comment.Range = &Range{
+				StartLine:      issue.Line(),
+				StartCharacter: span.Runes(line, start) - 1,
+				EndLine:        issue.Line(),
+				EndCharacter:   span.Runes(line, end) - 1,
+			}
+		}
+
+		// Whole-line replacements map onto a fix; inline fixes are left out
+		// like in the other formats.
+		if fix := issue.Replacement; fix != nil && fix.Inline == nil {
+			lines := issue.GetLineRange()
+			replacement := FixReplacement{
+				Path:  path,
+				Range: Range{StartLine: lines.From, EndLine: lines.To + 1},
+			}
+			if !fix.NeedOnlyDelete {
+				replacement.Replacement = strings.Join(fix.NewLines, "\n") + "\n"
+			}
+			comment.FixSuggestions = []FixSuggestion{{
+				Description:  "Fix suggested by " + issue.FromLinter,
+				Replacements: []FixReplacement{replacement},
+			}}
+		}
+		review.RobotComments[path] = append(review.RobotComments[path], comment)
+	}
+	return review
+}
+
+// Client posts reviews through the Gerrit REST API, authenticating with an
+// HTTP password.
+type Client struct {
+	baseURL  string
+	user     string
+	password string
+	http     *http.Client
+}
+
+// NewClient returns a client for the Gerrit server at baseURL.
+func NewClient(baseURL, user, password string) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		user:     user,
+		password: password,
+		http:     http.DefaultClient,
+	}
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (c *Client) SetHTTPClient(client *http.Client) *Client {
+	c.http = client
+	return c
+}
+
+// Review posts review on revision of change, such as "current" or a commit
+// hash. The change is anything Gerrit accepts as a change ID, like its
+// number or project~number.
+func (c *Client) Review(change, revision string, review ReviewInput) error {
+	path := fmt.Sprintf("/a/changes/%s/revisions/%s/review", url.PathEscape(change), url.PathEscape(revision))
+	credentials := base64.StdEncoding.EncodeToString([]byte(c.user + ":" + c.password))
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+credentials)
+	return forge.Do(c.http, http.MethodPost, c.baseURL, path, header, review, nil)
+}
@@ -0,0 +1,97 @@
+package gerrit
+
+import (
+	"encoding/json"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestNewReviewInput(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}},
+		{
+			FromLinter:  "gofmt",
+			Text:        "not formatted",
+			Pos:         token.Position{Filename: "b.go", Line: 5},
+			LineRange:   &result.Range{From: 5, To: 6},
+			Replacement: &result.Replacement{NewLines: []string{"x := 1"}},
+		},
+	}
+
+	review := NewReviewInput(issues)
+	if review.Tag != "autogenerated:linterdiff" || len(review.RobotComments) != 2 {
+		t.Fatalf("review = %+v", review)
+	}
+	first := review.RobotComments["a.go"][0]
+	fixed := review.RobotComments["b.go"][0]
+	if first.RobotRunID == "" || first.RobotRunID != fixed.RobotRunID {
+		t.Errorf("run IDs %q and %q differ", first.RobotRunID, fixed.RobotRunID)
+	}
+	if first.FixSuggestions != nil {
+		t.Errorf("issue without replacement has fixes %+v", first.FixSuggestions)
+	}
+	want := FixReplacement{Path: "b.go", Range: Range{StartLine: 5, EndLine: 7}, Replacement: "x := 1\n"}
+	if len(fixed.FixSuggestions) != 1 || fixed.FixSuggestions[0].Replacements[0] != want {
+		t.Errorf("fix = %+v, want %+v", fixed.FixSuggestions, want)
+	}
+
+	if first.Range != nil {
+		t.Errorf("issue without column has range %+v", first.Range)
+	}
+
+	ranged := NewReviewInput([]result.Issue{{
+		FromLinter:  "errcheck",
+		Pos:         token.Position{Filename: "c.go", Line: 4, Column: 12},
+		SourceLines: []string{`	x := "é"+f()`},
+	}}).RobotComments["c.go"][0]
+	wantRange := Range{StartLine: 4, StartCharacter: 10, EndLine: 4, EndCharacter: 11}
+	if ranged.Range == nil || *ranged.Range != wantRange {
+		t.Errorf("range = %+v, want %+v", ranged.Range, wantRange)
+	}
+
+	if NewReviewInput(issues).RobotComments["a.go"][0].RobotRunID != first.RobotRunID {
+		t.Error("same issues got another run ID")
+	}
+	if NewReviewInput(issues[:1]).RobotComments["a.go"][0].RobotRunID == first.RobotRunID {
+		t.Error("other issues got the same run ID")
+	}
+}
+
+func TestReview(t *testing.T) {
+	var got ReviewInput
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "bot" || password != "secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		path = r.URL.EscapedPath()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(")]}'\n{}"))
+	}))
+	defer server.Close()
+
+	review := NewReviewInput([]result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}})
+	if err := NewClient(server.URL+"/", "bot", "secret").Review("proj~42", "current", review); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/a/changes/proj~42/revisions/current/review" {
+		t.Errorf("path = %q", path)
+	}
+	if len(got.RobotComments["a.go"]) != 1 {
+		t.Errorf("posted review = %+v", got)
+	}
+
+	err := NewClient(server.URL, "bot", "wrong").Review("42", "current", review)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Review error = %v, want a 401", err)
+	}
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "app.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return key, path
+}
+
+func TestAppJWT(t *testing.T) {
+	key, path := writeKey(t)
+	app, err := LoadApp(123, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	jwt, err := app.jwt(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt %q has %d parts", jwt, len(parts))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var got map[string]int64
+	if err := json.Unmarshal(claims, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["iss"] != 123 || got["iat"] != now.Unix()-60 || got["exp"] != now.Unix()+540 {
+		t.Errorf("claims = %v", got)
+	}
+}
+
+func TestLoadAppErrors(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing.pem"), garbage} {
+		if _, err := LoadApp(1, path); err == nil {
+			t.Errorf("LoadApp(%s) expected an error", path)
+		}
+	}
+}
+
+func TestInstallationToken(t *testing.T) {
+	_, path := writeKey(t)
+	app, err := LoadApp(123, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/99/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"ghs_installation"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("unused").SetBaseURL(server.URL)
+	token, err := client.InstallationToken(app, 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghs_installation" || !strings.HasPrefix(auth, "Bearer ey") {
+		t.Errorf("token = %q, authorized with %q", token, auth)
+	}
+	if _, err := client.InstallationToken(app, 0); err == nil {
+		t.Error("InstallationToken without an installation expected an error")
+	}
+}
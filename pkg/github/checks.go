@@ -0,0 +1,137 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// CheckName names the check runs we create.
+const CheckName = "linter"
+
+// annotationsPerRequest is how many annotations the Checks API takes in
+// one request.
+const annotationsPerRequest = 50
+
+// maxSummary is the longest summary the Checks API takes, in bytes; longer
+// ones are cut at a line and end with truncated.
+const maxSummary = 65535 - len(truncated)
+
+const truncated = "\n_The summary is truncated; every issue is annotated._\n"
+
+type checkRun struct {
+	Name       string       `json:"name,omitempty"`
+	HeadSHA    string       `json:"head_sha,omitempty"`
+	Status     string       `json:"status,omitempty"`
+	Conclusion string       `json:"conclusion,omitempty"`
+	Output     *checkOutput `json:"output,omitempty"`
+}
+
+type checkOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+type annotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"annotation_level"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+}
+
+// StartCheck creates a check run in progress on commit sha of repo, and
+// returns its ID.
+func (c *Client) StartCheck(repo Repository, sha string) (int64, error) {
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	err := c.do(http.MethodPost, repoPath(repo, "check-runs"), checkRun{
+		Name:    CheckName,
+		HeadSHA: sha,
+		Status:  "in_progress",
+	}, &created)
+	return created.ID, err
+}
+
+// CompleteCheck concludes the check run id with an annotation per issue,
+// whose paths must be relative to the repository root, and the Markdown
+// summary. total counts the issues found, which issues may be the first of.
+// The check fails unless passed.
+func (c *Client) CompleteCheck(repo Repository, id int64, issues []result.Issue, total int, summary string, passed bool) error {
+	conclusion := "failure"
+	if passed {
+		conclusion = "success"
+	}
+	title := fmt.Sprintf("%d issue(s) on changed lines", total)
+	if len(issues) < total {
+		title += fmt.Sprintf(", the first %d annotated", len(issues))
+	}
+	if len(summary) > maxSummary {
+		summary = summary[:strings.LastIndex(summary[:maxSummary], "\n")+1] + truncated
+	}
+	annotations := make([]annotation, 0, len(issues))
+	for _, issue := range issues {
+		line := issue.Line()
+		end := line
+		if issue.LineRange != nil && issue.LineRange.To > line {
+			end = issue.LineRange.To
+		}
+		annotations = append(annotations, annotation{
+			Path:      filepath.ToSlash(issue.FilePath()),
+			StartLine: line,
+			EndLine:   end,
+			Level:     annotationLevel(issue),
+			Title:     issue.FromLinter,
+			Message:   issue.Text,
+		})
+	}
+
+	// Annotations are sent in batches, each update adding to the earlier
+	// ones; the last one concludes the run.
+	for start := 0; ; start += annotationsPerRequest {
+		end := start + annotationsPerRequest
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		update := checkRun{Output: &checkOutput{Title: title, Summary: summary, Annotations: annotations[start:end]}}
+		last := end == len(annotations)
+		if last {
+			update.Status = "completed"
+			update.Conclusion = conclusion
+		}
+		if err := c.do(http.MethodPatch, repoPath(repo, "check-runs/%d", id), update, nil); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// FailCheck concludes the check run id with conclusion, such as failure or
+// timed_out, explaining that the check could not run because of err.
+func (c *Client) FailCheck(repo Repository, id int64, conclusion string, err error) error {
+	return c.do(http.MethodPatch, repoPath(repo, "check-runs/%d", id), checkRun{
+		Status:     "completed",
+		Conclusion: conclusion,
+		Output:     &checkOutput{Title: "The check could not run", Summary: "```\n" + err.Error() + "\n```"},
+	}, nil)
+}
+
+// annotationLevel maps golangci-lint severities onto annotation levels.
+func annotationLevel(issue result.Issue) string {
+	switch strings.ToLower(issue.Severity) {
+	case "info", "note":
+		return "notice"
+	case "warning":
+		return "warning"
+	default:
+		return "failure"
+	}
+}
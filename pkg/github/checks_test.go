@@ -0,0 +1,144 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// fakeChecks records the check runs created and the updates sent to them.
+type fakeChecks struct {
+	mu      sync.Mutex
+	created []checkRun
+	updates []checkRun
+}
+
+func (f *fakeChecks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var run checkRun
+	if err := json.NewDecoder(r.Body).Decode(&run); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/check-runs":
+		f.created = append(f.created, run)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":42}`))
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/check-runs/42":
+		f.updates = append(f.updates, run)
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	repo := Repository{Owner: "o", Repo: "r"}
+	issues := make([]result.Issue, 0, 120)
+	for i := 1; i <= 120; i++ {
+		issues = append(issues, result.Issue{
+			FromLinter: "errcheck", Text: fmt.Sprintf("unchecked %d", i), Severity: "warning",
+			Pos: token.Position{Filename: "pkg/a.go", Line: i},
+		})
+	}
+	issues[0].LineRange = &result.Range{From: 1, To: 4}
+
+	tests := []struct {
+		name           string
+		issues         []result.Issue
+		total          int
+		summary        string
+		passed         bool
+		wantBatches    []int
+		wantConclusion string
+		wantTitle      string
+		wantAnnotation annotation
+		wantSummary    string
+	}{
+		{
+			name: "clean", summary: "### Lint: 0 issue(s)", passed: true, wantBatches: []int{0}, wantConclusion: "success",
+			wantTitle: "0 issue(s) on changed lines", wantSummary: "### Lint: 0 issue(s)",
+		},
+		{
+			name: "truncated", summary: strings.Repeat("| a.go | 1 | errcheck | unchecked |\n", 2000), passed: true,
+			wantBatches: []int{0}, wantConclusion: "success", wantTitle: "0 issue(s) on changed lines", wantSummary: truncated,
+		},
+		{
+			name: "batched", issues: issues, total: len(issues), wantBatches: []int{50, 50, 20}, wantConclusion: "failure",
+			wantTitle:      "120 issue(s) on changed lines",
+			wantAnnotation: annotation{Path: "pkg/a.go", StartLine: 1, EndLine: 4, Level: "warning", Title: "errcheck", Message: "unchecked 1"},
+		},
+		{
+			name: "limited", issues: issues[:1], total: 3, wantBatches: []int{1}, wantConclusion: "failure",
+			wantTitle:      "3 issue(s) on changed lines, the first 1 annotated",
+			wantAnnotation: annotation{Path: "pkg/a.go", StartLine: 1, EndLine: 4, Level: "warning", Title: "errcheck", Message: "unchecked 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeChecks{}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			client := NewClient("secret").SetBaseURL(server.URL)
+
+			id, err := client.StartCheck(repo, "abc123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != 42 || fake.created[0] != (checkRun{Name: CheckName, HeadSHA: "abc123", Status: "in_progress"}) {
+				t.Errorf("StartCheck = %d, created %+v", id, fake.created)
+			}
+
+			if err := client.CompleteCheck(repo, id, tt.issues, tt.total, tt.summary, tt.passed); err != nil {
+				t.Fatal(err)
+			}
+			if len(fake.updates) != len(tt.wantBatches) {
+				t.Fatalf("sent %d updates, want %d", len(fake.updates), len(tt.wantBatches))
+			}
+			for i, update := range fake.updates {
+				last := i == len(fake.updates)-1
+				if len(update.Output.Annotations) != tt.wantBatches[i] {
+					t.Errorf("update %d has %d annotations, want %d", i, len(update.Output.Annotations), tt.wantBatches[i])
+				}
+				if last != (update.Conclusion == tt.wantConclusion && update.Status == "completed") {
+					t.Errorf("update %d concludes %q %q", i, update.Status, update.Conclusion)
+				}
+			}
+			if title := fake.updates[0].Output.Title; title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			if summary := fake.updates[0].Output.Summary; !strings.HasSuffix(summary, tt.wantSummary) || len(summary) > 65535 {
+				t.Errorf("summary of %d bytes, want it to end with %q", len(summary), tt.wantSummary)
+			}
+			if tt.wantBatches[0] > 0 && fake.updates[0].Output.Annotations[0] != tt.wantAnnotation {
+				t.Errorf("first annotation = %+v, want %+v", fake.updates[0].Output.Annotations[0], tt.wantAnnotation)
+			}
+		})
+	}
+}
+
+func TestFailCheck(t *testing.T) {
+	fake := &fakeChecks{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := Repository{Owner: "o", Repo: "r"}
+	if err := NewClient("secret").SetBaseURL(server.URL).FailCheck(repo, 42, "timed_out", errors.New("timed out after 5m")); err != nil {
+		t.Fatal(err)
+	}
+	update := fake.updates[0]
+	if update.Status != "completed" || update.Conclusion != "timed_out" || !strings.Contains(update.Output.Summary, "timed out after 5m") {
+		t.Errorf("update = %+v", update)
+	}
+}
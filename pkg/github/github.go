@@ -0,0 +1,194 @@
+// Package github posts lint issues as review comments on a pull request, or
+// reports them as a check run or a commit status.
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/forge"
+)
+
+// DefaultBaseURL is the REST endpoint of github.com.
+const DefaultBaseURL = "https://api.github.com"
+
+var (
+	repositoryPattern  = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)$`)
+	pullRequestPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+)
+
+// Repository names a repository as owner/repo.
+type Repository struct {
+	Owner string
+	Repo  string
+}
+
+// ParseRepository reads "owner/repo".
+func ParseRepository(s string) (Repository, error) {
+	match := repositoryPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Repository{}, fmt.Errorf("malformed repository %q, expected owner/repo", s)
+	}
+	return Repository{Owner: match[1], Repo: match[2]}, nil
+}
+
+func (r Repository) String() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// PullRequest names a pull request as owner/repo#number.
+type PullRequest struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// ParsePullRequest reads "owner/repo#123".
+func ParsePullRequest(s string) (PullRequest, error) {
+	match := pullRequestPattern.FindStringSubmatch(s)
+	if match == nil {
+		return PullRequest{}, fmt.Errorf("malformed pull request %q, expected owner/repo#number", s)
+	}
+	number, err := strconv.Atoi(match[3])
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return PullRequest{Owner: match[1], Repo: match[2], Number: number}, nil
+}
+
+func (p PullRequest) String() string {
+	return fmt.Sprintf("%s/%s#%d", p.Owner, p.Repo, p.Number)
+}
+
+// Repository names the repository of the pull request.
+func (p PullRequest) Repository() Repository {
+	return Repository{Owner: p.Owner, Repo: p.Repo}
+}
+
+// Client talks to the GitHub REST API with a token.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for github.com authenticating with token.
+func NewClient(token string) *Client {
+	return &Client{
+		baseURL: DefaultBaseURL,
+		token:   token,
+		http:    http.DefaultClient,
+	}
+}
+
+// SetBaseURL points the client at another API endpoint, such as GitHub
+// Enterprise Server.
+func (c *Client) SetBaseURL(url string) *Client {
+	c.baseURL = strings.TrimRight(url, "/")
+	return c
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (c *Client) SetHTTPClient(client *http.Client) *Client {
+	c.http = client
+	return c
+}
+
+type comment struct {
+	ID       int64  `json:"id,omitempty"`
+	Body     string `json:"body"`
+	CommitID string `json:"commit_id,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Side     string `json:"side,omitempty"`
+}
+
+// Review comments on pr for every issue, whose paths must be relative to
+// the repository root. A comment left by an earlier run for the same issue
+// is updated in place rather than posted again. It returns how many
+// comments were created and updated.
+func (c *Client) Review(pr PullRequest, issues []result.Issue) (created, updated int, err error) {
+	var pull struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.do(http.MethodGet, repoPath(pr.Repository(), "pulls/%d", pr.Number), nil, &pull); err != nil {
+		return 0, 0, err
+	}
+
+	existing, err := c.comments(pr)
+	if err != nil {
+		return 0, 0, err
+	}
+	byKey := make(map[string]comment)
+	for _, posted := range existing {
+		if key, ok := forge.Key(posted.Body); ok {
+			byKey[key] = posted
+		}
+	}
+
+	occurrences := fingerprint.Occurrences(issues)
+	for i, issue := range issues {
+		key := occurrences[i].String()
+		body := forge.Comment(issue, key)
+
+		if posted, ok := byKey[key]; ok {
+			if posted.Body == body {
+				continue
+			}
+			err := c.do(http.MethodPatch, repoPath(pr.Repository(), "pulls/comments/%d", posted.ID), comment{Body: body}, nil)
+			if err != nil {
+				return created, updated, err
+			}
+			updated++
+			continue
+		}
+
+		err := c.do(http.MethodPost, repoPath(pr.Repository(), "pulls/%d/comments", pr.Number), comment{
+			Body:     body,
+			CommitID: pull.Head.SHA,
+			Path:     filepath.ToSlash(issue.FilePath()),
+			Line:     issue.Line(),
+			Side:     "RIGHT",
+		}, nil)
+		if err != nil {
+			return created, updated, err
+		}
+		created++
+	}
+	return created, updated, nil
+}
+
+func (c *Client) comments(pr PullRequest) ([]comment, error) {
+	var all []comment
+	for page := 1; ; page++ {
+		var batch []comment
+		path := repoPath(pr.Repository(), "pulls/%d/comments?per_page=100&page=%d", pr.Number, page)
+		if err := c.do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		all = append(all, batch...)
+		if len(batch) < 100 {
+			return all, nil
+		}
+	}
+}
+
+func repoPath(repo Repository, format string, a ...interface{}) string {
+	return fmt.Sprintf("/repos/%s/%s/", repo.Owner, repo.Repo) + fmt.Sprintf(format, a...)
+}
+
+func (c *Client) do(method, path string, in, out interface{}) error {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("Authorization", "Bearer "+c.token)
+	return forge.Do(c.http, method, c.baseURL, path, header, in, out)
+}
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"encoding/json"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	pr, err := ParsePullRequest("metailurini/linter#42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr != (PullRequest{Owner: "metailurini", Repo: "linter", Number: 42}) {
+		t.Errorf("ParsePullRequest = %+v", pr)
+	}
+	if pr.String() != "metailurini/linter#42" {
+		t.Errorf("String = %q", pr.String())
+	}
+
+	for _, bad := range []string{"", "linter#1", "a/b", "a/b#x", "a/b/c#1"} {
+		if _, err := ParsePullRequest(bad); err == nil {
+			t.Errorf("ParsePullRequest(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestParseRepository(t *testing.T) {
+	repo, err := ParseRepository("metailurini/linter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo != (Repository{Owner: "metailurini", Repo: "linter"}) || repo.String() != "metailurini/linter" {
+		t.Errorf("ParseRepository = %+v", repo)
+	}
+	for _, bad := range []string{"", "linter", "a/b#1", "a/b/c"} {
+		if _, err := ParseRepository(bad); err == nil {
+			t.Errorf("ParseRepository(%q) expected an error", bad)
+		}
+	}
+}
+
+// fakeGitHub serves one pull request and records the comments posted to it.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []comment
+	nextID   int64
+	patched  int
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7":
+		_, _ = w.Write([]byte(`{"head":{"sha":"abc123"}}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7/comments":
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.comments)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/pulls/7/comments":
+		var c comment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		c.ID = f.nextID
+		f.comments = append(f.comments, c)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/o/r/pulls/comments/"):
+		var c comment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for i := range f.comments {
+			if "/repos/o/r/pulls/comments/"+itoa(f.comments[i].ID) == r.URL.Path {
+				f.comments[i].Body = c.Body
+			}
+		}
+		f.patched++
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func itoa(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func TestReviewDeduplicatesComments(t *testing.T) {
+	fake := &fakeGitHub{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient("secret").SetBaseURL(server.URL)
+	pr := PullRequest{Owner: "o", Repo: "r", Number: 7}
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked error in call 1", Pos: token.Position{Filename: "pkg/a.go", Line: 3}},
+		{FromLinter: "errcheck", Text: "unchecked error in call 1", Pos: token.Position{Filename: "pkg/a.go", Line: 8}},
+	}
+
+	created, updated, err := client.Review(pr, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 2 || updated != 0 {
+		t.Fatalf("first run created %d, updated %d; want 2, 0", created, updated)
+	}
+	if c := fake.comments[0]; c.CommitID != "abc123" || c.Path != "pkg/a.go" || c.Line != 3 || c.Side != "RIGHT" {
+		t.Errorf("posted comment = %+v", c)
+	}
+
+	created, updated, err = client.Review(pr, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 || updated != 0 || len(fake.comments) != 2 {
+		t.Errorf("second run created %d, updated %d, total %d; want 0, 0, 2", created, updated, len(fake.comments))
+	}
+
+	// Masked digits keep the fingerprint while the text changes.
+	issues[0].Text = "unchecked error in call 2"
+	created, updated, err = client.Review(pr, issues[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 || updated != 1 || !strings.Contains(fake.comments[0].Body, "call 2") {
+		t.Errorf("third run created %d, updated %d, body %q", created, updated, fake.comments[0].Body)
+	}
+}
+
+func TestReviewReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeGitHub{})
+	defer server.Close()
+
+	_, _, err := NewClient("wrong").SetBaseURL(server.URL).Review(PullRequest{Owner: "o", Repo: "r", Number: 7}, nil)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Review error = %v, want a 401", err)
+	}
+}
@@ -0,0 +1,18 @@
+package github
+
+import "net/http"
+
+// Status is a commit status, shown next to the commit under its context.
+type Status struct {
+	// State is one of pending, success, failure and error.
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// SetStatus sets status on commit sha of repo, replacing the one of the
+// same context.
+func (c *Client) SetStatus(repo Repository, sha string, status Status) error {
+	return c.do(http.MethodPost, repoPath(repo, "statuses/%s", sha), status, nil)
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetStatus(t *testing.T) {
+	var (
+		path string
+		got  Status
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	want := Status{State: "failure", TargetURL: "https://ci.example.com/1", Description: "3 issues", Context: "lint/changed-lines"}
+	err := NewClient("secret").SetBaseURL(server.URL).SetStatus(Repository{Owner: "o", Repo: "r"}, "abc123", want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "POST /repos/o/r/statuses/abc123" || got != want {
+		t.Errorf("sent %s %+v, want %+v", path, got, want)
+	}
+}
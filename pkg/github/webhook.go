@@ -0,0 +1,94 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrSignature is returned for a webhook delivery not signed with the
+// secret.
+var ErrSignature = errors.New("webhook signature does not match the secret")
+
+// maxPayload bounds the webhook payloads read; GitHub caps them at 25MB.
+const maxPayload = 25 << 20
+
+// PullRequestEvent is the part of a pull_request webhook payload a check
+// needs.
+type PullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name     string `json:"name"`
+		CloneURL string `json:"clone_url"`
+		Owner    struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	// Installation is set for the events of a GitHub App.
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+}
+
+// PR names the pull request of the event.
+func (e *PullRequestEvent) PR() PullRequest {
+	return PullRequest{Owner: e.Repository.Owner.Login, Repo: e.Repository.Name, Number: e.Number}
+}
+
+// ReadPullRequestEvent reads a webhook delivery signed with secret. It
+// returns nil for deliveries that need no check: other events, and pull
+// request actions leaving the code as it was, such as labeling.
+func ReadPullRequestEvent(r *http.Request, secret string) (*PullRequestEvent, error) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayload))
+	if err != nil {
+		return nil, err
+	}
+	if !validSignature(payload, r.Header.Get("X-Hub-Signature-256"), secret) {
+		return nil, ErrSignature
+	}
+	if r.Header.Get("X-GitHub-Event") != "pull_request" {
+		return nil, nil
+	}
+
+	var event PullRequestEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("malformed pull_request event: %w", err)
+	}
+	switch event.Action {
+	case "opened", "reopened", "synchronize":
+		return &event, nil
+	default:
+		return nil, nil
+	}
+}
+
+// validSignature checks the sha256=<hex HMAC> signature of payload.
+func validSignature(payload []byte, signature, secret string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
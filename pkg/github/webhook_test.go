@@ -0,0 +1,71 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sign(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestReadPullRequestEvent(t *testing.T) {
+	payload := func(action string) string {
+		return `{"action":"` + action + `","number":7,` +
+			`"pull_request":{"head":{"sha":"abc"},"base":{"sha":"def","ref":"main"}},` +
+			`"repository":{"name":"r","clone_url":"https://github.com/o/r.git","owner":{"login":"o"}},` +
+			`"installation":{"id":99}}`
+	}
+	tests := []struct {
+		name      string
+		event     string
+		payload   string
+		signature string
+		wantEvent bool
+		wantErr   error
+	}{
+		{name: "opened", event: "pull_request", payload: payload("opened"), wantEvent: true},
+		{name: "synchronize", event: "pull_request", payload: payload("synchronize"), wantEvent: true},
+		{name: "labeled", event: "pull_request", payload: payload("labeled")},
+		{name: "push", event: "push", payload: `{}`},
+		{name: "bad signature", event: "pull_request", payload: payload("opened"), signature: sign("other", "secret"), wantErr: ErrSignature},
+		{name: "wrong secret", event: "pull_request", payload: payload("opened"), signature: sign(payload("opened"), "guess"), wantErr: ErrSignature},
+		{name: "unsigned", event: "pull_request", payload: payload("opened"), signature: "none", wantErr: ErrSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/github", strings.NewReader(tt.payload))
+			r.Header.Set("X-GitHub-Event", tt.event)
+			signature := tt.signature
+			if signature == "" {
+				signature = sign(tt.payload, "secret")
+			} else if signature == "none" {
+				signature = ""
+			}
+			r.Header.Set("X-Hub-Signature-256", signature)
+
+			event, err := ReadPullRequestEvent(r, "secret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if (event != nil) != tt.wantEvent {
+				t.Fatalf("event = %+v, want one: %v", event, tt.wantEvent)
+			}
+			if event == nil {
+				return
+			}
+			if event.PR() != (PullRequest{Owner: "o", Repo: "r", Number: 7}) || event.PullRequest.Head.SHA != "abc" ||
+				event.PullRequest.Base.Ref != "main" || event.Installation.ID != 99 {
+				t.Errorf("event = %+v", event)
+			}
+		})
+	}
+}
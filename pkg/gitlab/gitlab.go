@@ -0,0 +1,234 @@
+// Package gitlab posts lint issues as discussions on a merge request, or
+// sums them up in a commit status.
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/forge"
+)
+
+// DefaultBaseURL is the REST endpoint of gitlab.com.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+var (
+	mergeRequestPattern = regexp.MustCompile(`^([\w.-]+(?:/[\w.-]+)+)!(\d+)$`)
+)
+
+// MergeRequest names a merge request as group/project!iid.
+type MergeRequest struct {
+	// Project is the full path of the project, subgroups included.
+	Project string
+	IID     int
+}
+
+// ParseMergeRequest reads "group/project!42".
+func ParseMergeRequest(s string) (MergeRequest, error) {
+	match := mergeRequestPattern.FindStringSubmatch(s)
+	if match == nil {
+		return MergeRequest{}, fmt.Errorf("malformed merge request %q, expected group/project!iid", s)
+	}
+	iid, err := strconv.Atoi(match[2])
+	if err != nil {
+		return MergeRequest{}, err
+	}
+	return MergeRequest{Project: match[1], IID: iid}, nil
+}
+
+func (m MergeRequest) String() string {
+	return fmt.Sprintf("%s!%d", m.Project, m.IID)
+}
+
+// Client talks to the GitLab REST API with a token.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for gitlab.com authenticating with token.
+func NewClient(token string) *Client {
+	return &Client{
+		baseURL: DefaultBaseURL,
+		token:   token,
+		http:    http.DefaultClient,
+	}
+}
+
+// SetBaseURL points the client at another API endpoint, such as a
+// self-managed instance.
+func (c *Client) SetBaseURL(url string) *Client {
+	c.baseURL = strings.TrimRight(url, "/")
+	return c
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (c *Client) SetHTTPClient(client *http.Client) *Client {
+	c.http = client
+	return c
+}
+
+type note struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	Resolved bool   `json:"resolved"`
+}
+
+type discussion struct {
+	ID    string `json:"id"`
+	Notes []note `json:"notes"`
+}
+
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+type position struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	HeadSHA      string `json:"head_sha"`
+	StartSHA     string `json:"start_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+type newDiscussion struct {
+	Body     string   `json:"body"`
+	Position position `json:"position"`
+}
+
+// Result counts what Discuss changed on the merge request.
+type Result struct {
+	Created, Updated, Resolved int
+}
+
+// Discuss starts a discussion on the changed line of every issue, whose
+// paths must be relative to the repository root. A discussion started by an
+// earlier run for the same issue is updated, and reopened if it was
+// resolved, rather than started again; those whose issue is gone are
+// resolved.
+func (c *Client) Discuss(mr MergeRequest, issues []result.Issue) (Result, error) {
+	var res Result
+	var merge struct {
+		DiffRefs diffRefs `json:"diff_refs"`
+	}
+	if err := c.do(http.MethodGet, mrPath(mr, ""), nil, &merge); err != nil {
+		return res, err
+	}
+
+	existing, err := c.discussions(mr)
+	if err != nil {
+		return res, err
+	}
+	byKey := make(map[string]discussion)
+	for _, posted := range existing {
+		if len(posted.Notes) == 0 {
+			continue
+		}
+		if key, ok := forge.Key(posted.Notes[0].Body); ok {
+			byKey[key] = posted
+		}
+	}
+
+	current := make(map[string]bool)
+	occurrences := fingerprint.Occurrences(issues)
+	for i, issue := range issues {
+		key := occurrences[i].String()
+		current[key] = true
+		body := forge.Comment(issue, key)
+
+		posted, ok := byKey[key]
+		if !ok {
+			path := filepath.ToSlash(issue.FilePath())
+			err := c.do(http.MethodPost, mrPath(mr, "/discussions"), newDiscussion{
+				Body: body,
+				Position: position{
+					PositionType: "text",
+					BaseSHA:      merge.DiffRefs.BaseSHA,
+					HeadSHA:      merge.DiffRefs.HeadSHA,
+					StartSHA:     merge.DiffRefs.StartSHA,
+					OldPath:      path,
+					NewPath:      path,
+					NewLine:      issue.Line(),
+				},
+			}, nil)
+			if err != nil {
+				return res, err
+			}
+			res.Created++
+			continue
+		}
+
+		first := posted.Notes[0]
+		if first.Body == body && !first.Resolved {
+			continue
+		}
+		if first.Body != body {
+			path := mrPath(mr, fmt.Sprintf("/discussions/%s/notes/%d", posted.ID, first.ID))
+			if err := c.do(http.MethodPut, path, map[string]string{"body": body}, nil); err != nil {
+				return res, err
+			}
+		}
+		if first.Resolved {
+			if err := c.resolve(mr, posted.ID, false); err != nil {
+				return res, err
+			}
+		}
+		res.Updated++
+	}
+
+	for key, posted := range byKey {
+		if current[key] || posted.Notes[0].Resolved {
+			continue
+		}
+		if err := c.resolve(mr, posted.ID, true); err != nil {
+			return res, err
+		}
+		res.Resolved++
+	}
+	return res, nil
+}
+
+func (c *Client) resolve(mr MergeRequest, id string, resolved bool) error {
+	path := mrPath(mr, "/discussions/"+id+"?resolved="+strconv.FormatBool(resolved))
+	return c.do(http.MethodPut, path, nil, nil)
+}
+
+func (c *Client) discussions(mr MergeRequest) ([]discussion, error) {
+	var all []discussion
+	for page := 1; ; page++ {
+		var batch []discussion
+		path := mrPath(mr, fmt.Sprintf("/discussions?per_page=100&page=%d", page))
+		if err := c.do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		all = append(all, batch...)
+		if len(batch) < 100 {
+			return all, nil
+		}
+	}
+}
+
+// mrPath returns the API path of mr followed by suffix. The project path is
+// escaped into a single segment, as GitLab expects.
+func mrPath(mr MergeRequest, suffix string) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(mr.Project), mr.IID) + suffix
+}
+
+func (c *Client) do(method, path string, in, out interface{}) error {
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", c.token)
+	return forge.Do(c.http, method, c.baseURL, path, header, in, out)
+}
@@ -0,0 +1,184 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestParseMergeRequest(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MergeRequest
+	}{
+		{"metailurini/linter!42", MergeRequest{Project: "metailurini/linter", IID: 42}},
+		{"group/sub.group/my-project!7", MergeRequest{Project: "group/sub.group/my-project", IID: 7}},
+	}
+	for _, tt := range tests {
+		got, err := ParseMergeRequest(tt.in)
+		if err != nil {
+			t.Errorf("ParseMergeRequest(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want || got.String() != tt.in {
+			t.Errorf("ParseMergeRequest(%q) = %+v", tt.in, got)
+		}
+	}
+
+	for _, bad := range []string{"", "linter!1", "a/b", "a/b!x", "a/b#1"} {
+		if _, err := ParseMergeRequest(bad); err == nil {
+			t.Errorf("ParseMergeRequest(%q) expected an error", bad)
+		}
+	}
+}
+
+// fakeGitLab serves one merge request and records its discussions.
+type fakeGitLab struct {
+	mu          sync.Mutex
+	discussions []discussion
+	positions   []position
+	nextID      int64
+}
+
+const mrURL = "/projects/g%2Fp/merge_requests/7"
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodGet && path == mrURL:
+		_, _ = w.Write([]byte(`{"diff_refs":{"base_sha":"b","head_sha":"h","start_sha":"s"}}`))
+	case r.Method == http.MethodGet && path == mrURL+"/discussions":
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.discussions)
+	case r.Method == http.MethodPost && path == mrURL+"/discussions":
+		var d newDiscussion
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		f.discussions = append(f.discussions, discussion{
+			ID:    "d" + itoa(f.nextID),
+			Notes: []note{{ID: f.nextID, Body: d.Body}},
+		})
+		f.positions = append(f.positions, d.Position)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodPut && strings.HasPrefix(path, mrURL+"/discussions/"):
+		parts := strings.Split(strings.TrimPrefix(path, mrURL+"/discussions/"), "/")
+		d := f.find(parts[0])
+		if d == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if len(parts) == 3 && parts[1] == "notes" {
+			var body struct{ Body string }
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			d.Notes[0].Body = body.Body
+		} else {
+			d.Notes[0].Resolved = r.URL.Query().Get("resolved") == "true"
+		}
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitLab) find(id string) *discussion {
+	for i := range f.discussions {
+		if f.discussions[i].ID == id {
+			return &f.discussions[i]
+		}
+	}
+	return nil
+}
+
+func itoa(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func TestDiscussResolvesStaleDiscussions(t *testing.T) {
+	fake := &fakeGitLab{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient("secret").SetBaseURL(server.URL)
+	mr := MergeRequest{Project: "g/p", IID: 7}
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked error in call 1", Pos: token.Position{Filename: "pkg/a.go", Line: 3}},
+		{FromLinter: "govet", Text: "unreachable code", Pos: token.Position{Filename: "pkg/b.go", Line: 8}},
+	}
+
+	res, err := client.Discuss(mr, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (Result{Created: 2}) {
+		t.Fatalf("first run = %+v, want 2 created", res)
+	}
+	want := position{PositionType: "text", BaseSHA: "b", HeadSHA: "h", StartSHA: "s", OldPath: "pkg/a.go", NewPath: "pkg/a.go", NewLine: 3}
+	if fake.positions[0] != want {
+		t.Errorf("position = %+v, want %+v", fake.positions[0], want)
+	}
+
+	res, err = client.Discuss(mr, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (Result{}) || len(fake.discussions) != 2 {
+		t.Errorf("second run = %+v with %d discussions, want no change", res, len(fake.discussions))
+	}
+
+	// The govet issue is fixed and the errcheck message changed.
+	issues[0].Text = "unchecked error in call 2"
+	res, err = client.Discuss(mr, issues[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (Result{Updated: 1, Resolved: 1}) {
+		t.Errorf("third run = %+v, want 1 updated and 1 resolved", res)
+	}
+	if !strings.Contains(fake.discussions[0].Notes[0].Body, "call 2") || !fake.discussions[1].Notes[0].Resolved {
+		t.Errorf("discussions = %+v", fake.discussions)
+	}
+
+	// The govet issue is back: its discussion is reopened.
+	res, err = client.Discuss(mr, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (Result{Updated: 1}) || fake.discussions[1].Notes[0].Resolved {
+		t.Errorf("fourth run = %+v, discussions %+v", res, fake.discussions)
+	}
+}
+
+func TestDiscussReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeGitLab{})
+	defer server.Close()
+
+	_, err := NewClient("wrong").SetBaseURL(server.URL).Discuss(MergeRequest{Project: "g/p", IID: 7}, nil)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Discuss error = %v, want a 401", err)
+	}
+}
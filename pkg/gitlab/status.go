@@ -0,0 +1,21 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Status is a commit status, shown next to the commit under its name.
+type Status struct {
+	// State is one of pending, running, success, failed and canceled.
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name"`
+}
+
+// SetStatus sets status on commit sha of project, given by its full path.
+func (c *Client) SetStatus(project, sha string, status Status) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/projects/%s/statuses/%s", url.PathEscape(project), sha), status, nil)
+}
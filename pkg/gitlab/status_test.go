@@ -0,0 +1,32 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetStatus(t *testing.T) {
+	var (
+		path string
+		got  Status
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.EscapedPath()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	want := Status{State: "failed", TargetURL: "https://gitlab.example.com/-/jobs/1", Description: "3 issues", Name: "lint/changed-lines"}
+	if err := NewClient("secret").SetBaseURL(server.URL).SetStatus("group/sub/project", "abc123", want); err != nil {
+		t.Fatal(err)
+	}
+	if path != "POST /projects/group%2Fsub%2Fproject/statuses/abc123" || got != want {
+		t.Errorf("sent %s %+v, want %+v", path, got, want)
+	}
+}
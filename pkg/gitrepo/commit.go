@@ -0,0 +1,212 @@
+package gitrepo
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Commit is the part of a commit the history walks need.
+type Commit struct {
+	Hash    Hash
+	Tree    Hash
+	Parents []Hash
+	// Time is when it was committed, in seconds since the epoch.
+	Time int64
+}
+
+// Commit reads the commit hash.
+func (r *Repo) Commit(hash Hash) (*Commit, error) {
+	r.mu.Lock()
+	commit, ok := r.commits[hash]
+	r.mu.Unlock()
+	if ok {
+		return commit, nil
+	}
+
+	kind, data, err := r.objects.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if kind != objCommit {
+		return nil, fmt.Errorf("%s is a %s, not a commit", hash, kind)
+	}
+	commit = &Commit{Hash: hash}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			break
+		}
+		key, value, _ := strings.Cut(string(line), " ")
+		switch key {
+		case "tree":
+			if commit.Tree, err = ParseHash(value); err != nil {
+				return nil, err
+			}
+		case "parent":
+			if r.shallow[hash] {
+				// Its parents were not fetched.
+				continue
+			}
+			parent, err := ParseHash(value)
+			if err != nil {
+				return nil, err
+			}
+			commit.Parents = append(commit.Parents, parent)
+		case "committer":
+			// "name <email> 1700000000 +0100"
+			fields := strings.Fields(value[strings.LastIndexByte(value, '>')+1:])
+			if len(fields) > 0 {
+				commit.Time, _ = strconv.ParseInt(fields[0], 10, 64)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.commits[hash] = commit
+	r.mu.Unlock()
+	return commit, nil
+}
+
+// Flags painting the commits reached while looking for a merge base.
+const (
+	fromA = 1 << iota
+	fromB
+	stale
+	found
+)
+
+// MergeBase returns a best common ancestor of a and b, as git merge-base
+// does: one reachable from both that no other such ancestor descends from.
+func (r *Repo) MergeBase(a, b Hash) (Hash, error) {
+	if a == b {
+		return a, nil
+	}
+	candidates, err := r.commonAncestors(a, b)
+	if err != nil {
+		return Hash{}, err
+	}
+	if len(candidates) == 0 {
+		return Hash{}, fmt.Errorf("%w of %s and %s", ErrNoMergeBase, a, b)
+	}
+
+	// Drop the candidates another one descends from.
+	best := candidates[:0:0]
+	for i, candidate := range candidates {
+		redundant := false
+		for j, other := range candidates {
+			if i == j {
+				continue
+			}
+			if ok, err := r.isAncestor(candidate.Hash, other.Hash); err != nil {
+				return Hash{}, err
+			} else if ok {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			best = append(best, candidate)
+		}
+	}
+	return best[0].Hash, nil
+}
+
+// commonAncestors walks back from a and b, newest first, and collects the
+// commits both reach before any other commit both reach.
+func (r *Repo) commonAncestors(a, b Hash) ([]*Commit, error) {
+	flags := make(map[Hash]int)
+	queue := &commitQueue{}
+	for hash, flag := range map[Hash]int{a: fromA, b: fromB} {
+		commit, err := r.Commit(hash)
+		if err != nil {
+			return nil, err
+		}
+		flags[hash] = flag
+		heap.Push(queue, commit)
+	}
+
+	var common []*Commit
+	for queue.active(flags) {
+		commit := heap.Pop(queue).(*Commit)
+		paint := flags[commit.Hash] & (fromA | fromB | stale)
+		if paint&(fromA|fromB) == fromA|fromB {
+			if flags[commit.Hash]&found == 0 {
+				flags[commit.Hash] |= found
+				common = append(common, commit)
+			}
+			paint |= stale
+		}
+		for _, parentHash := range commit.Parents {
+			if flags[parentHash]&paint == paint {
+				continue
+			}
+			parent, err := r.Commit(parentHash)
+			if err != nil {
+				return nil, err
+			}
+			flags[parentHash] |= paint
+			heap.Push(queue, parent)
+		}
+	}
+	return common, nil
+}
+
+// isAncestor reports whether ancestor is reachable from descendant.
+func (r *Repo) isAncestor(ancestor, descendant Hash) (bool, error) {
+	target, err := r.Commit(ancestor)
+	if err != nil {
+		return false, err
+	}
+	seen := map[Hash]bool{descendant: true}
+	stack := []Hash{descendant}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if hash == ancestor {
+			return true, nil
+		}
+		commit, err := r.Commit(hash)
+		if err != nil {
+			return false, err
+		}
+		// Commits older than the ancestor rarely lead back to it; clock
+		// skew aside, their history can be skipped.
+		if commit.Time < target.Time-86400 {
+			continue
+		}
+		for _, parent := range commit.Parents {
+			if !seen[parent] {
+				seen[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return false, nil
+}
+
+// commitQueue orders commits newest first.
+type commitQueue []*Commit
+
+func (q commitQueue) Len() int            { return len(q) }
+func (q commitQueue) Less(i, j int) bool  { return q[i].Time > q[j].Time }
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(*Commit)) }
+
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// active reports whether a commit not yet known to be stale is queued.
+func (q commitQueue) active(flags map[Hash]int) bool {
+	for _, commit := range q {
+		if flags[commit.Hash]&stale == 0 {
+			return true
+		}
+	}
+	return false
+}
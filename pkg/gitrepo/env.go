@@ -0,0 +1,54 @@
+package gitrepo
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Env are the variables that point git at a repository other than the one
+// containing its working directory, as git sets some of them for hooks.
+// Commands run on another repository, such as a submodule, go without
+// them.
+var Env = []string{
+	"GIT_DIR",
+	"GIT_WORK_TREE",
+	"GIT_INDEX_FILE",
+	"GIT_COMMON_DIR",
+	"GIT_OBJECT_DIRECTORY",
+	"GIT_ALTERNATE_OBJECT_DIRECTORIES",
+}
+
+// pathEnv are the variables of Env holding a single path, which git reads
+// relative to its working directory.
+var pathEnv = []string{"GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE", "GIT_COMMON_DIR", "GIT_OBJECT_DIRECTORY"}
+
+// AbsEnv makes the relative paths of the variables of Env absolute, so
+// that git run in any other directory still finds the same repository.
+func AbsEnv() error {
+	for _, key := range pathEnv {
+		value := os.Getenv(key)
+		if value == "" || filepath.IsAbs(value) {
+			continue
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv(key, abs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envPath returns the absolute path key holds, or "" when it is unset.
+func envPath(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(value); err == nil {
+		return abs
+	}
+	return value
+}
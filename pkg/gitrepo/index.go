@@ -0,0 +1,135 @@
+package gitrepo
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// IndexEntry is a file staged in the index, with the size and time of
+// modification the working tree file had when it was staged.
+type IndexEntry struct {
+	Entry
+	Size    uint32
+	ModTime time.Time
+	// IntentToAdd marks a file git add --intent-to-add left without
+	// content.
+	IntentToAdd bool
+	// SkipWorktree marks a file a sparse checkout left out of the working
+	// tree, which is taken to be unchanged.
+	SkipWorktree bool
+}
+
+// Index lists the files of the index by path, and when the index was
+// written. Unmerged files are left out.
+func (r *Repo) Index() (map[string]IndexEntry, time.Time, error) {
+	path := r.indexFile
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// A new repository has no index until something is staged.
+		return map[string]IndexEntry{}, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	entries, err := parseIndex(data)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, info.ModTime(), nil
+}
+
+var errBadIndex = errors.New("malformed index")
+
+func parseIndex(data []byte) (map[string]IndexEntry, error) {
+	if len(data) < 12+sha1.Size || string(data[:4]) != "DIRC" {
+		return nil, errBadIndex
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index version %d is not supported", version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+
+	entries := make(map[string]IndexEntry, count)
+	at := 12
+	var previous []byte
+	for i := 0; i < count; i++ {
+		start := at
+		if len(data) < at+62 {
+			return nil, errBadIndex
+		}
+		var entry IndexEntry
+		seconds := binary.BigEndian.Uint32(data[at+8:])
+		nanos := binary.BigEndian.Uint32(data[at+12:])
+		entry.ModTime = time.Unix(int64(seconds), int64(nanos))
+		entry.Mode = binary.BigEndian.Uint32(data[at+24:])
+		entry.Size = binary.BigEndian.Uint32(data[at+36:])
+		copy(entry.Hash[:], data[at+40:at+60])
+		flags := binary.BigEndian.Uint16(data[at+60:])
+		at += 62
+		if flags&0x4000 != 0 {
+			if version < 3 || len(data) < at+2 {
+				return nil, errBadIndex
+			}
+			extended := binary.BigEndian.Uint16(data[at:])
+			entry.IntentToAdd = extended&0x2000 != 0
+			entry.SkipWorktree = extended&0x4000 != 0
+			at += 2
+		}
+		stage := flags >> 12 & 3
+
+		var name []byte
+		if version == 4 {
+			// The name drops n bytes of the previous one and adds a suffix.
+			n, size := binary.Uvarint(data[at:])
+			if size <= 0 || int(n) > len(previous) {
+				return nil, errBadIndex
+			}
+			at += size
+			end := bytes.IndexByte(data[at:], 0)
+			if end < 0 {
+				return nil, errBadIndex
+			}
+			name = append(append([]byte{}, previous[:len(previous)-int(n)]...), data[at:at+end]...)
+			at += end + 1
+		} else {
+			end := bytes.IndexByte(data[at:], 0)
+			if end < 0 {
+				return nil, errBadIndex
+			}
+			name = data[at : at+end]
+			// Entries are padded with 1 to 8 NULs to a multiple of 8 bytes.
+			at = start + (at+end-start+8)&^7
+		}
+		previous = name
+
+		if entry.Mode == modeTree {
+			return nil, ErrSparseIndex
+		}
+		if stage != 0 {
+			continue
+		}
+		entries[string(name)] = entry
+	}
+	return entries, nil
+}
+
+// HashBlob returns the hash git gives a blob of content.
+func HashBlob(content []byte) Hash {
+	h := sha1.New()
+	h.Write([]byte("blob " + strconv.Itoa(len(content)) + "\x00"))
+	h.Write(content)
+	var hash Hash
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
@@ -0,0 +1,483 @@
+package gitrepo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type objectType int
+
+const (
+	objCommit   objectType = 1
+	objTree     objectType = 2
+	objBlob     objectType = 3
+	objTag      objectType = 4
+	objOfsDelta objectType = 6
+	objRefDelta objectType = 7
+)
+
+func (t objectType) String() string {
+	switch t {
+	case objCommit:
+		return "commit"
+	case objTree:
+		return "tree"
+	case objBlob:
+		return "blob"
+	case objTag:
+		return "tag"
+	}
+	return fmt.Sprintf("object type %d", int(t))
+}
+
+func parseObjectType(name string) (objectType, error) {
+	for _, t := range []objectType{objCommit, objTree, objBlob, objTag} {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown object type %q", name)
+}
+
+// objectStore reads the objects of a repository and of its alternates.
+type objectStore struct {
+	dirs  []string
+	packs []*pack
+}
+
+func openObjects(dir string) (*objectStore, error) {
+	store := &objectStore{}
+	if err := store.add(dir, 0); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// add reads the packs of dir, then of the alternates it lists.
+func (s *objectStore) add(dir string, depth int) error {
+	if depth > 5 {
+		return fmt.Errorf("%s: too many levels of alternates", dir)
+	}
+	s.dirs = append(s.dirs, dir)
+	indexes, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		p, err := openPack(index)
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, p)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		if err := s.add(line, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read returns the type and content of the object hash.
+func (s *objectStore) read(hash Hash) (objectType, []byte, error) {
+	for _, p := range s.packs {
+		if offset, ok := p.find(hash); ok {
+			return p.read(s, offset)
+		}
+	}
+	for _, dir := range s.dirs {
+		name := hash.String()
+		kind, data, err := readLoose(filepath.Join(dir, name[:2], name[2:]))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		return kind, data, err
+	}
+	return 0, nil, fmt.Errorf("%w: %s", ErrMissingObject, hash)
+}
+
+func readLoose(path string) (objectType, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+	z, err := zlib.NewReader(file)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer z.Close()
+	r := bufio.NewReader(z)
+	head, err := r.ReadString(0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	name, size, ok := strings.Cut(strings.TrimSuffix(head, "\x00"), " ")
+	if !ok {
+		return 0, nil, fmt.Errorf("%s: malformed object header", path)
+	}
+	kind, err := parseObjectType(name)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: malformed object size", path)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return kind, data, nil
+}
+
+// findPrefix resolves an abbreviated hash, which must be unambiguous.
+func (s *objectStore) findPrefix(prefix string) (Hash, error) {
+	found := make(map[Hash]bool)
+	for _, p := range s.packs {
+		for _, hash := range p.withPrefix(prefix) {
+			found[hash] = true
+		}
+	}
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, prefix[:2]))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Hash{}, err
+		}
+		for _, entry := range entries {
+			if name := prefix[:2] + entry.Name(); strings.HasPrefix(name, prefix) {
+				if hash, err := ParseHash(name); err == nil {
+					found[hash] = true
+				}
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Hash{}, fmt.Errorf("%w %q", ErrUnknownRevision, prefix)
+	case 1:
+		for hash := range found {
+			return hash, nil
+		}
+	}
+	return Hash{}, fmt.Errorf("ambiguous revision %q", prefix)
+}
+
+// pack is a packfile with its version 2 index, read into memory on first
+// use.
+type pack struct {
+	indexPath string
+	packPath  string
+
+	once    sync.Once
+	err     error
+	fanout  [256]uint32
+	hashes  []byte
+	offsets []int64
+	data    []byte
+	cache   map[int64]cachedObject
+	mu      sync.Mutex
+}
+
+type cachedObject struct {
+	kind objectType
+	data []byte
+}
+
+func openPack(indexPath string) (*pack, error) {
+	return &pack{
+		indexPath: indexPath,
+		packPath:  strings.TrimSuffix(indexPath, ".idx") + ".pack",
+	}, nil
+}
+
+func (p *pack) load() error {
+	p.once.Do(func() {
+		p.err = p.loadIndex()
+		if p.err == nil {
+			p.data, p.err = os.ReadFile(p.packPath)
+		}
+		if p.err == nil && (len(p.data) < 12 || string(p.data[:4]) != "PACK") {
+			p.err = fmt.Errorf("%s: not a packfile", p.packPath)
+		}
+		p.cache = make(map[int64]cachedObject)
+	})
+	return p.err
+}
+
+func (p *pack) loadIndex() error {
+	index, err := os.ReadFile(p.indexPath)
+	if err != nil {
+		return err
+	}
+	if len(index) < 8+256*4 || !bytes.Equal(index[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(index[4:8]) != 2 {
+		return fmt.Errorf("%s: unsupported pack index", p.indexPath)
+	}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(index[8+i*4:])
+	}
+	n := int(p.fanout[255])
+	hashesAt := 8 + 256*4
+	offsetsAt := hashesAt + n*20 + n*4
+	largeAt := offsetsAt + n*4
+	if len(index) < largeAt {
+		return fmt.Errorf("%s: truncated pack index", p.indexPath)
+	}
+	p.hashes = index[hashesAt : hashesAt+n*20]
+	p.offsets = make([]int64, n)
+	for i := 0; i < n; i++ {
+		offset := binary.BigEndian.Uint32(index[offsetsAt+i*4:])
+		if offset&0x80000000 == 0 {
+			p.offsets[i] = int64(offset)
+			continue
+		}
+		// Offsets past 2GiB live in a table of their own.
+		at := largeAt + int(offset&0x7fffffff)*8
+		if len(index) < at+8 {
+			return fmt.Errorf("%s: truncated pack index", p.indexPath)
+		}
+		p.offsets[i] = int64(binary.BigEndian.Uint64(index[at:]))
+	}
+	return nil
+}
+
+func (p *pack) hashAt(i int) []byte {
+	return p.hashes[i*20 : i*20+20]
+}
+
+// find returns the offset of hash in the pack.
+func (p *pack) find(hash Hash) (int64, bool) {
+	if p.load() != nil {
+		return 0, false
+	}
+	lo := 0
+	if hash[0] > 0 {
+		lo = int(p.fanout[hash[0]-1])
+	}
+	hi := int(p.fanout[hash[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.hashAt(lo+i), hash[:]) >= 0
+	})
+	if i < hi && bytes.Equal(p.hashAt(i), hash[:]) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+func (p *pack) withPrefix(prefix string) []Hash {
+	if p.load() != nil {
+		return nil
+	}
+	var found []Hash
+	for i := 0; i < len(p.offsets); i++ {
+		name := hex.EncodeToString(p.hashAt(i))
+		if strings.HasPrefix(name, prefix) {
+			var hash Hash
+			copy(hash[:], p.hashAt(i))
+			found = append(found, hash)
+		}
+	}
+	return found
+}
+
+// read returns the object at offset, applying deltas against their base,
+// which for REF_DELTA may lie in another pack of s.
+func (p *pack) read(s *objectStore, offset int64) (objectType, []byte, error) {
+	if err := p.load(); err != nil {
+		return 0, nil, err
+	}
+	p.mu.Lock()
+	cached, ok := p.cache[offset]
+	p.mu.Unlock()
+	if ok {
+		return cached.kind, cached.data, nil
+	}
+
+	if offset < 12 || offset >= int64(len(p.data)) {
+		return 0, nil, fmt.Errorf("%s: offset %d out of range", p.packPath, offset)
+	}
+	at := offset
+	b := p.data[at]
+	at++
+	kind := objectType(b >> 4 & 7)
+	size := uint64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if at >= int64(len(p.data)) {
+			return 0, nil, fmt.Errorf("%s: truncated object at %d", p.packPath, offset)
+		}
+		b = p.data[at]
+		at++
+		size |= uint64(b&0x7f) << shift
+	}
+
+	var (
+		baseKind objectType
+		base     []byte
+		err      error
+	)
+	switch kind {
+	case objCommit, objTree, objBlob, objTag:
+	case objOfsDelta:
+		var rel int64
+		for first := true; ; first = false {
+			if at >= int64(len(p.data)) || rel > offset {
+				return 0, nil, fmt.Errorf("%s: bad delta base at %d", p.packPath, offset)
+			}
+			b = p.data[at]
+			at++
+			if !first {
+				rel++
+			}
+			rel = rel<<7 | int64(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		// The base comes earlier in the pack, which also rules out loops.
+		if rel <= 0 || rel > offset {
+			return 0, nil, fmt.Errorf("%s: bad delta base at %d", p.packPath, offset)
+		}
+		baseKind, base, err = p.read(s, offset-rel)
+	case objRefDelta:
+		if at+20 > int64(len(p.data)) {
+			return 0, nil, fmt.Errorf("%s: truncated object at %d", p.packPath, offset)
+		}
+		var baseHash Hash
+		copy(baseHash[:], p.data[at:at+20])
+		at += 20
+		baseKind, base, err = s.read(baseHash)
+	default:
+		return 0, nil, fmt.Errorf("%s: unknown object type %d at %d", p.packPath, kind, offset)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	z, err := zlib.NewReader(bytes.NewReader(p.data[at:]))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: object at %d: %w", p.packPath, offset, err)
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(z, data)
+	z.Close()
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: object at %d: %w", p.packPath, offset, err)
+	}
+	if base != nil {
+		if data, err = applyDelta(base, data); err != nil {
+			return 0, nil, fmt.Errorf("%s: object at %d: %w", p.packPath, offset, err)
+		}
+		kind = baseKind
+	}
+
+	// Bases of long delta chains are read over and over; trees and commits
+	// are small and worth keeping.
+	if kind != objBlob {
+		p.mu.Lock()
+		p.cache[offset] = cachedObject{kind: kind, data: data}
+		p.mu.Unlock()
+	}
+	return kind, data, nil
+}
+
+var errBadDelta = errors.New("malformed delta")
+
+// applyDelta rebuilds an object from its base and a delta of copy and
+// insert instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	readSize := func() (int, bool) {
+		size, shift := 0, 0
+		for {
+			if len(delta) == 0 {
+				return 0, false
+			}
+			b := delta[0]
+			delta = delta[1:]
+			size |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				return size, true
+			}
+		}
+	}
+	baseSize, ok := readSize()
+	if !ok || baseSize != len(base) {
+		return nil, errBadDelta
+	}
+	size, ok := readSize()
+	if !ok {
+		return nil, errBadDelta
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			n := int(op)
+			if n == 0 || n > len(delta) {
+				return nil, errBadDelta
+			}
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+			continue
+		}
+		var offset, n int
+		for i := 0; i < 4; i++ {
+			if op&(1<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errBadDelta
+				}
+				offset |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if op&(0x10<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errBadDelta
+				}
+				n |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		if n == 0 {
+			n = 0x10000
+		}
+		if offset+n > len(base) {
+			return nil, errBadDelta
+		}
+		out = append(out, base[offset:offset+n]...)
+	}
+	if len(out) != size {
+		return nil, errBadDelta
+	}
+	return out, nil
+}
@@ -0,0 +1,431 @@
+// Package gitrepo reads git repositories in-process: refs, commits, trees,
+// blobs and the index, from loose objects and packfiles alike. It covers
+// what finding the changes of a working tree needs, without the git binary.
+package gitrepo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotRepository is returned by Open outside of a git working tree.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrUnknownRevision is returned by Resolve for names that are no ref
+	// or object, such as HEAD before the first commit.
+	ErrUnknownRevision = errors.New("unknown revision")
+	// ErrMissingObject is returned for objects the repository lacks, such
+	// as the blobs a partial clone did not fetch.
+	ErrMissingObject = errors.New("object not found")
+	// ErrNoMergeBase is returned by MergeBase for commits without a common
+	// ancestor, or none in the history a shallow clone fetched.
+	ErrNoMergeBase = errors.New("no merge base")
+	// ErrSparseIndex is returned by Index for the sparse index of a sparse
+	// checkout, which lists directories outside the cone instead of their
+	// files.
+	ErrSparseIndex = errors.New("sparse indexes are not supported")
+)
+
+// Hash is the SHA-1 of an object.
+type Hash [20]byte
+
+// ParseHash reads a full hexadecimal hash.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	if len(s) != 40 {
+		return h, fmt.Errorf("malformed object name %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("malformed object name %q", s)
+	}
+	return h, nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IsZero reports whether h is all zeros, as the index has for entries
+// added with git add --intent-to-add.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// Repo is a git working tree and its repository.
+type Repo struct {
+	root string
+	// gitDir holds HEAD and the index of the working tree, commonDir the
+	// objects and refs shared by all working trees.
+	gitDir    string
+	commonDir string
+	indexFile string
+	objects   *objectStore
+	// shallow are the commits a shallow clone has without their parents.
+	shallow map[Hash]bool
+
+	mu      sync.Mutex
+	commits map[Hash]*Commit
+}
+
+// Open finds the working tree containing dir and its repository, or takes
+// them from GIT_DIR and GIT_WORK_TREE when set, as git does.
+func Open(dir string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if gitDir := envPath("GIT_DIR"); gitDir != "" {
+		root := envPath("GIT_WORK_TREE")
+		if root == "" {
+			root = dir
+			if found, _, err := discover(dir); err == nil {
+				root = found
+			}
+		}
+		return open(root, gitDir)
+	}
+
+	root, gitDir, err := discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	return open(root, gitDir)
+}
+
+// discover walks up from dir to the first working tree, returning its root
+// and git directory.
+func discover(dir string) (root, gitDir string, err error) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		switch {
+		case err == nil && info.IsDir():
+			return dir, dotGit, nil
+		case err == nil:
+			// Linked working trees and submodules point at their git
+			// directory from a .git file.
+			gitDir, err := readGitFile(dotGit)
+			if err != nil {
+				return "", "", err
+			}
+			return dir, gitDir, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return "", "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+func readGitFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "gitdir: ") {
+		return "", fmt.Errorf("%s: %w", path, ErrNotRepository)
+	}
+	gitDir := strings.TrimPrefix(line, "gitdir: ")
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir, nil
+}
+
+func open(root, gitDir string) (*Repo, error) {
+	commonDir := gitDir
+	if env := envPath("GIT_COMMON_DIR"); env != "" {
+		commonDir = env
+	} else if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := checkFormat(commonDir); err != nil {
+		return nil, err
+	}
+	objects, err := openObjects(filepath.Join(commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	shallow, err := readShallow(filepath.Join(commonDir, "shallow"))
+	if err != nil {
+		return nil, err
+	}
+	indexFile := envPath("GIT_INDEX_FILE")
+	if indexFile == "" {
+		indexFile = filepath.Join(gitDir, "index")
+	}
+	return &Repo{
+		root:      root,
+		gitDir:    gitDir,
+		commonDir: commonDir,
+		indexFile: indexFile,
+		shallow:   shallow,
+		objects:   objects,
+		commits:   make(map[Hash]*Commit),
+	}, nil
+}
+
+// checkFormat rejects repositories using SHA-256 object names, which this
+// package cannot read.
+func checkFormat(commonDir string) error {
+	file, err := os.Open(filepath.Join(commonDir, "config"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "objectformat") && strings.TrimSpace(value) != "sha1" {
+			return fmt.Errorf("object format %s is not supported", strings.TrimSpace(value))
+		}
+	}
+	return scanner.Err()
+}
+
+// readShallow reads the commits listed in the shallow file at path, if any.
+func readShallow(path string) (map[Hash]bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	shallow := make(map[Hash]bool)
+	for _, line := range strings.Fields(string(content)) {
+		hash, err := ParseHash(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		shallow[hash] = true
+	}
+	return shallow, nil
+}
+
+// Shallow reports whether the repository is a shallow clone, whose history
+// stops short of the first commits.
+func (r *Repo) Shallow() bool {
+	return len(r.shallow) > 0
+}
+
+// Root returns the top directory of the working tree.
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// Resolve turns a revision into the commit it names: a full or abbreviated
+// hash, HEAD, a branch, tag or remote-tracking branch, optionally followed
+// by ~n and ^n steps to ancestors, as in origin/main~2.
+func (r *Repo) Resolve(rev string) (Hash, error) {
+	name, steps := rev, ""
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		name, steps = rev[:i], rev[i:]
+	}
+	if name == "" || name == "@" {
+		name = "HEAD"
+	}
+	hash, err := r.resolveName(name)
+	if err != nil {
+		return Hash{}, err
+	}
+	if hash, err = r.peel(hash); err != nil {
+		return Hash{}, err
+	}
+
+	for steps != "" {
+		op := steps[0]
+		steps = steps[1:]
+		digits := 0
+		for digits < len(steps) && steps[digits] >= '0' && steps[digits] <= '9' {
+			digits++
+		}
+		n := 1
+		if digits > 0 {
+			if n, err = strconv.Atoi(steps[:digits]); err != nil {
+				return Hash{}, fmt.Errorf("%w %q", ErrUnknownRevision, rev)
+			}
+		}
+		steps = steps[digits:]
+		if op == '^' {
+			if n == 0 {
+				continue
+			}
+			commit, err := r.Commit(hash)
+			if err != nil {
+				return Hash{}, err
+			}
+			if n > len(commit.Parents) {
+				return Hash{}, fmt.Errorf("%w %q: no parent %d", ErrUnknownRevision, rev, n)
+			}
+			hash = commit.Parents[n-1]
+			continue
+		}
+		for ; n > 0; n-- {
+			commit, err := r.Commit(hash)
+			if err != nil {
+				return Hash{}, err
+			}
+			if len(commit.Parents) == 0 {
+				return Hash{}, fmt.Errorf("%w %q: history too short", ErrUnknownRevision, rev)
+			}
+			hash = commit.Parents[0]
+		}
+	}
+	return hash, nil
+}
+
+// resolveName looks name up the way git rev-parse does.
+func (r *Repo) resolveName(name string) (Hash, error) {
+	if hash, err := ParseHash(name); err == nil {
+		return hash, nil
+	}
+	for _, format := range []string{"%s", "refs/%s", "refs/tags/%s", "refs/heads/%s", "refs/remotes/%s", "refs/remotes/%s/HEAD"} {
+		hash, err := r.ref(fmt.Sprintf(format, name), 0)
+		if err == nil {
+			return hash, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return Hash{}, err
+		}
+	}
+	if len(name) >= 4 && isHex(name) {
+		return r.objects.findPrefix(strings.ToLower(name))
+	}
+	return Hash{}, fmt.Errorf("%w %q", ErrUnknownRevision, name)
+}
+
+// ref reads a loose or packed ref, following symbolic refs.
+func (r *Repo) ref(name string, depth int) (Hash, error) {
+	if depth > 5 {
+		return Hash{}, fmt.Errorf("ref %s: too many levels of symbolic refs", name)
+	}
+	// HEAD and the other refs outside refs/ belong to the working tree.
+	dir := r.commonDir
+	if !strings.HasPrefix(name, "refs/") {
+		dir = r.gitDir
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	// A directory such as refs/heads/feature of refs/heads/feature/x is
+	// not the ref.
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return Hash{}, err
+		}
+		line := strings.TrimSpace(string(content))
+		if target, ok := strings.CutPrefix(line, "ref: "); ok {
+			return r.ref(target, depth+1)
+		}
+		return ParseHash(line)
+	}
+	return r.packedRef(name)
+}
+
+func (r *Repo) packedRef(name string) (Hash, error) {
+	content, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return Hash{}, err
+	}
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		hash, refName, ok := strings.Cut(string(line), " ")
+		if ok && refName == name {
+			return ParseHash(hash)
+		}
+	}
+	return Hash{}, os.ErrNotExist
+}
+
+// HeadBranch returns the branch checked out, or an empty string when HEAD
+// is detached.
+func (r *Repo) HeadBranch() (string, error) {
+	content, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "ref: refs/heads/")
+	if !ok {
+		return "", nil
+	}
+	return target, nil
+}
+
+// peel follows tags to the commit they point at.
+func (r *Repo) peel(hash Hash) (Hash, error) {
+	for depth := 0; depth < 10; depth++ {
+		kind, data, err := r.objects.read(hash)
+		if err != nil {
+			return Hash{}, err
+		}
+		switch kind {
+		case objCommit:
+			return hash, nil
+		case objTag:
+			target, ok := header(data, "object")
+			if !ok {
+				return Hash{}, fmt.Errorf("tag %s: no object", hash)
+			}
+			if hash, err = ParseHash(target); err != nil {
+				return Hash{}, err
+			}
+		default:
+			return Hash{}, fmt.Errorf("%s is a %s, not a commit", hash, kind)
+		}
+	}
+	return Hash{}, fmt.Errorf("%s: too many levels of tags", hash)
+}
+
+// Blob returns the content of the blob hash.
+func (r *Repo) Blob(hash Hash) ([]byte, error) {
+	kind, data, err := r.objects.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if kind != objBlob {
+		return nil, fmt.Errorf("%s is a %s, not a blob", hash, kind)
+	}
+	return data, nil
+}
+
+// header finds the value of the first header line called name in a commit
+// or tag.
+func header(data []byte, name string) (string, bool) {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			break
+		}
+		if value, ok := bytes.CutPrefix(line, []byte(name+" ")); ok {
+			return string(value), true
+		}
+	}
+	return "", false
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,343 @@
+package gitrepo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRepo builds a repository with a branch forked from main, a merge, an
+// annotated tag and a remote-tracking branch, and returns a git runner in
+// it.
+func testRepo(t *testing.T) (string, func(...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	git := func(argv ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false",
+		}, argv...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2024-01-01T00:00:00Z", "GIT_AUTHOR_DATE=2024-01-01T00:00:00Z")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	write("a.go", "package a\n")
+	write("pkg/b/b.go", "package b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "-a", "v1", "-m", "v1")
+	write("a.go", "package a\n\nvar x = 1\n")
+	git("commit", "-q", "-am", "main 1")
+	git("checkout", "-q", "-b", "feature", "HEAD~1")
+	write("pkg/b/b.go", "package b\n\nvar y = 2\n")
+	git("commit", "-q", "-am", "feature 1")
+	git("merge", "-q", "--no-edit", "main")
+	write("pkg/c.go", "package pkg\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "feature 2")
+	git("update-ref", "refs/remotes/origin/main", "main")
+	return dir, git
+}
+
+func TestRepo(t *testing.T) {
+	dir, git := testRepo(t)
+	for _, packed := range []bool{false, true} {
+		name := "loose"
+		if packed {
+			name = "packed"
+			git("gc", "-q")
+			git("pack-refs", "--all")
+		}
+		t.Run(name, func(t *testing.T) {
+			repo, err := Open(filepath.Join(dir, "pkg", "b"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.Root() != dir {
+				t.Errorf("Root = %s, want %s", repo.Root(), dir)
+			}
+
+			revs := []string{"HEAD", "main", "feature", "v1", "origin/main", "refs/heads/main", "HEAD~1", "HEAD~1^2", "HEAD~2^", "@~1", git("rev-parse", "--short", "main")}
+			for _, rev := range revs {
+				hash, err := repo.Resolve(rev)
+				if err != nil {
+					t.Errorf("Resolve(%s): %v", rev, err)
+					continue
+				}
+				if want := git("rev-parse", rev+"^{commit}"); hash.String() != want {
+					t.Errorf("Resolve(%s) = %s, want %s", rev, hash, want)
+				}
+			}
+			if _, err := repo.Resolve("nope"); err == nil {
+				t.Error("Resolve(nope) expected an error")
+			}
+
+			head, _ := repo.Resolve("HEAD")
+			main, _ := repo.Resolve("main")
+			base, err := repo.MergeBase(head, main)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := git("merge-base", "HEAD", "main"); base.String() != want {
+				t.Errorf("MergeBase = %s, want %s", base, want)
+			}
+
+			files, err := repo.Files(head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make(map[string]Entry)
+			for _, line := range strings.Split(git("ls-tree", "-r", "HEAD"), "\n") {
+				meta, path, _ := strings.Cut(line, "\t")
+				fields := strings.Fields(meta)
+				hash, _ := ParseHash(fields[2])
+				mode := uint32(ModeRegular)
+				want[path] = Entry{Mode: mode, Hash: hash}
+			}
+			if !reflect.DeepEqual(files, want) {
+				t.Errorf("Files = %v, want %v", files, want)
+			}
+
+			blob, err := repo.Blob(files["pkg/b/b.go"].Hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(blob) != "package b\n\nvar y = 2\n" {
+				t.Errorf("Blob = %q", blob)
+			}
+			if HashBlob(blob) != files["pkg/b/b.go"].Hash {
+				t.Errorf("HashBlob = %s, want %s", HashBlob(blob), files["pkg/b/b.go"].Hash)
+			}
+
+			branch, err := repo.HeadBranch()
+			if err != nil || branch != "feature" {
+				t.Errorf("HeadBranch = %q, %v", branch, err)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	for _, version := range []string{"2", "3", "4"} {
+		t.Run("v"+version, func(t *testing.T) {
+			dir, git := testRepo(t)
+			if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package a\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			git("add", "--intent-to-add", "new.go")
+			git("update-index", "--index-version", version)
+
+			repo, err := Open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, _, err := repo.Index()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, line := range strings.Split(git("ls-files", "-s"), "\n") {
+				meta, path, _ := strings.Cut(line, "\t")
+				paths = append(paths, path)
+				fields := strings.Fields(meta)
+				if entry := entries[path]; entry.Hash.String() != fields[1] {
+					t.Errorf("%s: hash %s, want %s", path, entry.Hash, fields[1])
+				}
+			}
+			if len(entries) != len(paths) {
+				t.Errorf("Index has %d entries, want %v", len(entries), paths)
+			}
+			if !entries["new.go"].IntentToAdd || entries["a.go"].IntentToAdd {
+				t.Errorf("intent-to-add flags wrong: %+v", entries)
+			}
+		})
+	}
+}
+
+func TestOpenLinkedWorktree(t *testing.T) {
+	_, git := testRepo(t)
+	linked := filepath.Join(t.TempDir(), "linked")
+	git("worktree", "add", "-q", "--detach", linked, "main")
+
+	repo, err := Open(linked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Resolve("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := git("rev-parse", "main"); head.String() != want {
+		t.Errorf("HEAD of the linked worktree = %s, want %s", head, want)
+	}
+	if branch, _ := repo.HeadBranch(); branch != "" {
+		t.Errorf("HeadBranch = %q, want detached", branch)
+	}
+	if _, err := repo.Resolve("feature"); err != nil {
+		t.Errorf("branches of the main worktree: %v", err)
+	}
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Open outside a repository expected an error")
+	}
+}
+
+func TestOpenGitEnv(t *testing.T) {
+	dir, git := testRepo(t)
+	index := filepath.Join(t.TempDir(), "index")
+	content, err := os.ReadFile(filepath.Join(dir, ".git", "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(index, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("rm", "-q", "--cached", "a.go")
+	t.Setenv("GIT_DIR", filepath.Join(dir, ".git"))
+	t.Setenv("GIT_WORK_TREE", dir)
+	t.Setenv("GIT_INDEX_FILE", index)
+
+	repo, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Root() != dir {
+		t.Errorf("Root = %s, want GIT_WORK_TREE %s", repo.Root(), dir)
+	}
+	if _, err := repo.Resolve("feature"); err != nil {
+		t.Errorf("branches of GIT_DIR: %v", err)
+	}
+	entries, _, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := entries["a.go"]; !ok {
+		t.Errorf("Index = %v, want GIT_INDEX_FILE, which still has a.go", entries)
+	}
+}
+
+func TestMissingObject(t *testing.T) {
+	dir, _ := testRepo(t)
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Blob(Hash{1}); !errors.Is(err, ErrMissingObject) {
+		t.Errorf("Blob of an absent object = %v, want ErrMissingObject", err)
+	}
+	if repo.Shallow() {
+		t.Error("Shallow = true for a full repository")
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	dir, git := testRepo(t)
+	git("sparse-checkout", "set", "--cone", "--no-sparse-index")
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entries["pkg/b/b.go"].SkipWorktree || entries["a.go"].SkipWorktree {
+		t.Errorf("skip-worktree flags wrong: %+v", entries)
+	}
+
+	git("sparse-checkout", "set", "--cone", "--sparse-index")
+	if _, _, err := repo.Index(); !errors.Is(err, ErrSparseIndex) {
+		t.Errorf("Index of a sparse index = %v, want ErrSparseIndex", err)
+	}
+}
+
+func TestAbsEnv(t *testing.T) {
+	t.Setenv("GIT_DIR", ".git")
+	t.Setenv("GIT_INDEX_FILE", "/abs/index")
+	t.Setenv("GIT_WORK_TREE", "")
+	if err := AbsEnv(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.Abs(".git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("GIT_DIR"); got != want {
+		t.Errorf("GIT_DIR = %q, want %q", got, want)
+	}
+	if got := os.Getenv("GIT_INDEX_FILE"); got != "/abs/index" {
+		t.Errorf("GIT_INDEX_FILE = %q, want it unchanged", got)
+	}
+	if got := os.Getenv("GIT_WORK_TREE"); got != "" {
+		t.Errorf("GIT_WORK_TREE = %q, want it left empty", got)
+	}
+}
+
+func TestPackDeltas(t *testing.T) {
+	dir, git := testRepo(t)
+	var lines []string
+	for i := 0; i < 300; i++ {
+		lines = append(lines, "// line "+strings.Repeat("x", i%40))
+	}
+	var versions []string
+	for i := 0; i < 5; i++ {
+		lines[i*50] = "// edited in version " + string(rune('a'+i))
+		content := strings.Join(lines, "\n") + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "big.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "big.go")
+		git("commit", "-q", "-m", "version")
+		versions = append(versions, content)
+	}
+	git("gc", "-q", "--aggressive")
+	indexes, _ := filepath.Glob(filepath.Join(dir, ".git", "objects", "pack", "*.idx"))
+	if len(indexes) != 1 || !strings.Contains(git("verify-pack", "-v", indexes[0]), "chain length") {
+		t.Skip("git packed no deltas")
+	}
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range versions {
+		commit, err := repo.Resolve("HEAD~" + string(rune('0'+len(versions)-1-i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := repo.Files(commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.Blob(files["big.go"].Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("version %d differs", i)
+		}
+	}
+}
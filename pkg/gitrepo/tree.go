@@ -0,0 +1,68 @@
+package gitrepo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// File modes of tree and index entries.
+const (
+	ModeRegular    = 0o100644
+	ModeExecutable = 0o100755
+	ModeSymlink    = 0o120000
+	ModeSubmodule  = 0o160000
+	modeTree       = 0o040000
+)
+
+// Entry is a file of a tree or the index.
+type Entry struct {
+	Mode uint32
+	Hash Hash
+}
+
+// Files lists every file of the tree of commit, by its slash-separated
+// path from the root.
+func (r *Repo) Files(commit Hash) (map[string]Entry, error) {
+	c, err := r.Commit(commit)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]Entry)
+	return files, r.walkTree(c.Tree, "", files)
+}
+
+func (r *Repo) walkTree(hash Hash, prefix string, files map[string]Entry) error {
+	kind, data, err := r.objects.read(hash)
+	if err != nil {
+		return err
+	}
+	if kind != objTree {
+		return fmt.Errorf("%s is a %s, not a tree", hash, kind)
+	}
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return fmt.Errorf("tree %s: malformed entry", hash)
+		}
+		mode, err := strconv.ParseUint(string(data[:space]), 8, 32)
+		if err != nil {
+			return fmt.Errorf("tree %s: malformed mode", hash)
+		}
+		name := prefix + string(data[space+1:nul])
+		var entry Entry
+		entry.Mode = uint32(mode)
+		copy(entry.Hash[:], data[nul+1:nul+21])
+		data = data[nul+21:]
+
+		if entry.Mode == modeTree {
+			if err := r.walkTree(entry.Hash, name+"/", files); err != nil {
+				return err
+			}
+			continue
+		}
+		files[name] = entry
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package history records lint runs in a SQLite database and queries how
+// the issues evolve across them. The database is read and written with the
+// sqlite3 command line shell, which must be installed.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/command"
+	"linter/pkg/fingerprint"
+)
+
+// schema creates the tables of a new database; existing ones are kept.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id        INTEGER PRIMARY KEY,
+	time      TEXT NOT NULL,
+	commit_id TEXT NOT NULL,
+	branch    TEXT NOT NULL,
+	found     INTEGER NOT NULL,
+	reported  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS issues (
+	run         INTEGER NOT NULL REFERENCES runs (id),
+	fingerprint TEXT NOT NULL,
+	file        TEXT NOT NULL,
+	line        INTEGER NOT NULL,
+	linter      TEXT NOT NULL,
+	rule        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS issues_run ON issues (run);
+`
+
+// Run is a lint run to record.
+type Run struct {
+	Time           time.Time
+	Commit, Branch string
+	// Found counts the issues before filtering; Issues are those reported.
+	Found  int
+	Issues []result.Issue
+}
+
+// DB is a history database.
+type DB struct {
+	ctx  context.Context
+	path string
+	bin  string
+}
+
+// Open creates the tables of the database at path unless they exist.
+func Open(ctx context.Context, path string) (*DB, error) {
+	db := &DB{ctx: ctx, path: path, bin: "sqlite3"}
+	if _, err := exec.LookPath(db.bin); err != nil {
+		return nil, errors.New("the history database needs the sqlite3 command installed")
+	}
+	if _, err := db.exec(schema); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Record adds run, with the fingerprint, place and rule of each issue.
+func (db *DB) Record(run Run) error {
+	var sql strings.Builder
+	sql.WriteString("BEGIN;\n")
+	fmt.Fprintf(&sql, "INSERT INTO runs (time, commit_id, branch, found, reported) VALUES (%s, %s, %s, %d, %d);\n",
+		quote(run.Time.UTC().Format(time.RFC3339)), quote(run.Commit), quote(run.Branch), run.Found, len(run.Issues))
+	for _, issue := range run.Issues {
+		fmt.Fprintf(&sql, "INSERT INTO issues VALUES ((SELECT max(id) FROM runs), %s, %s, %d, %s, %s);\n",
+			quote(fingerprint.Of(issue)), quote(filepath.ToSlash(issue.FilePath())), issue.Line(),
+			quote(issue.FromLinter), quote(fingerprint.Rule(issue)))
+	}
+	sql.WriteString("COMMIT;\n")
+	_, err := db.exec(sql.String())
+	return err
+}
+
+// Count is the number of issues reported by a run.
+type Count struct {
+	Time     time.Time
+	Commit   string
+	Branch   string
+	Found    int
+	Reported int
+}
+
+// Counts returns the issue counts of the last limit runs, oldest first.
+func (db *DB) Counts(limit int) ([]Count, error) {
+	var rows []struct {
+		Time     string `json:"time"`
+		Commit   string `json:"commit_id"`
+		Branch   string `json:"branch"`
+		Found    int    `json:"found"`
+		Reported int    `json:"reported"`
+	}
+	err := db.query(&rows, fmt.Sprintf(`SELECT * FROM (
+	SELECT id, time, commit_id, branch, found, reported FROM runs ORDER BY id DESC LIMIT %d
+) ORDER BY id;`, limit))
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]Count, 0, len(rows))
+	for _, row := range rows {
+		at, err := time.Parse(time.RFC3339, row.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%s: malformed run time %q", db.path, row.Time)
+		}
+		counts = append(counts, Count{Time: at, Commit: row.Commit, Branch: row.Branch, Found: row.Found, Reported: row.Reported})
+	}
+	return counts, nil
+}
+
+// Tally is how many issues were reported for a file or a rule.
+type Tally struct {
+	Name   string `json:"name"`
+	Issues int    `json:"issues"`
+}
+
+// TopFiles returns the limit files with the most issues across all runs.
+func (db *DB) TopFiles(limit int) ([]Tally, error) {
+	var tallies []Tally
+	return tallies, db.query(&tallies, fmt.Sprintf(
+		"SELECT file AS name, count(*) AS issues FROM issues GROUP BY file ORDER BY issues DESC, name LIMIT %d;", limit))
+}
+
+// TopRules returns the limit rules reported most across all runs, named
+// after their linter, followed by the rule for linters with several.
+func (db *DB) TopRules(limit int) ([]Tally, error) {
+	var tallies []Tally
+	return tallies, db.query(&tallies, fmt.Sprintf(
+		"SELECT trim(linter || ' ' || rule) AS name, count(*) AS issues FROM issues GROUP BY name ORDER BY issues DESC, name LIMIT %d;", limit))
+}
+
+// query runs sql and decodes the rows it selects into dest.
+func (db *DB) query(dest any, sql string) error {
+	output, err := db.exec(".mode json\n" + sql)
+	if err != nil {
+		return err
+	}
+	// No rows selected prints nothing.
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(output, dest); err != nil {
+		return fmt.Errorf("%s: reading the query result: %w", db.path, err)
+	}
+	return nil
+}
+
+// exec feeds sql to sqlite3, stopping at the first error.
+func (db *DB) exec(sql string) ([]byte, error) {
+	return command.New(db.bin, "-bail", "-batch", db.path).
+		SetContext(db.ctx).
+		SetStdin(strings.NewReader(sql)).
+		Output()
+}
+
+// quote renders s as an SQL string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
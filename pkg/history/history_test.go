@@ -0,0 +1,97 @@
+package history
+
+import (
+	"context"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func issue(file string, line int, linter, text string) result.Issue {
+	return result.Issue{FromLinter: linter, Text: text, Pos: token.Position{Filename: file, Line: line}}
+}
+
+func openDB(t *testing.T) *DB {
+	t.Helper()
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 binary not available")
+	}
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "history.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	db := openDB(t)
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{Time: start, Commit: "abc", Branch: "main", Found: 5, Issues: []result.Issue{
+			issue("a.go", 1, "errcheck", "Error return value is not checked"),
+			issue("a.go", 9, "staticcheck", "SA1019: io/ioutil is deprecated"),
+			issue("b.go", 3, "errcheck", "Error return value is not checked"),
+		}},
+		{Time: start.Add(time.Hour), Commit: "def", Branch: "it's-a-branch", Found: 2, Issues: []result.Issue{
+			issue("a.go", 1, "errcheck", "Error return value is not checked"),
+		}},
+		{Time: start.Add(2 * time.Hour), Commit: "123", Branch: "main", Found: 0},
+	}
+	for _, run := range runs {
+		if err := db.Record(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := db.Counts(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCounts := []Count{
+		{Time: start.Add(time.Hour), Commit: "def", Branch: "it's-a-branch", Found: 2, Reported: 1},
+		{Time: start.Add(2 * time.Hour), Commit: "123", Branch: "main", Found: 0, Reported: 0},
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("Counts = %+v, want %+v", counts, wantCounts)
+	}
+
+	files, err := db.TopFiles(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Tally{{Name: "a.go", Issues: 3}, {Name: "b.go", Issues: 1}}; !reflect.DeepEqual(files, want) {
+		t.Errorf("TopFiles = %+v, want %+v", files, want)
+	}
+
+	rules, err := db.TopRules(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Tally{{Name: "errcheck", Issues: 3}}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("TopRules = %+v, want %+v", rules, want)
+	}
+	rules, err = db.TopRules(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[1] != (Tally{Name: "staticcheck SA1019", Issues: 1}) {
+		t.Errorf("TopRules = %+v, want staticcheck SA1019 second", rules)
+	}
+}
+
+func TestQueryEmpty(t *testing.T) {
+	db := openDB(t)
+	counts, err := db.Counts(10)
+	if err != nil || len(counts) != 0 {
+		t.Errorf("Counts of an empty history = %v, %v", counts, err)
+	}
+	files, err := db.TopFiles(10)
+	if err != nil || len(files) != 0 {
+		t.Errorf("TopFiles of an empty history = %v, %v", files, err)
+	}
+}
@@ -0,0 +1,117 @@
+// Package hooks installs git hooks that run the linter before commits and
+// pushes, chaining to any hook that was already in place.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// Kind names a git hook.
+type Kind string
+
+const (
+	PreCommit Kind = "pre-commit"
+	PrePush   Kind = "pre-push"
+)
+
+const (
+	marker        = "# installed by linter hooks install"
+	chainedSuffix = ".pre-linter"
+)
+
+// Args are the linter arguments each hook runs with.
+var Args = map[Kind]string{
+	PreCommit: "--staged",
+	PrePush:   `--base-ref "${LINTERDIFF_BASE_REF:-origin/HEAD}"`,
+}
+
+// Dir returns the hooks directory of the repository containing pwd,
+// honouring core.hooksPath and linked worktrees.
+func Dir(pwd string) (string, error) {
+	output, err := command.New("git", "rev-parse", "--git-path", "hooks").
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return "", err
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(pwd, dir)
+	}
+	return dir, nil
+}
+
+// Install writes the hook script into dir. An existing foreign hook is kept
+// next to it and run after the linter succeeds; reinstalling only refreshes
+// the script.
+func Install(dir string, kind Kind, executable string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, string(kind))
+	installed, err := isInstalled(path)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		if err := os.Rename(path, path+chainedSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, []byte(script(kind, executable)), 0o755)
+}
+
+// Uninstall removes the hook and restores the hook it was chaining to.
+// Hooks that were not installed by Install are left alone.
+func Uninstall(dir string, kind Kind) error {
+	path := filepath.Join(dir, string(kind))
+	installed, err := isInstalled(path)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		return fmt.Errorf("%s is not a hook installed by this tool", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if err := os.Rename(path+chainedSuffix, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func isInstalled(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), marker), nil
+}
+
+func script(kind Kind, executable string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+%s %s || exit $?
+if [ -x "$0%s" ]; then
+	exec "$0%s" "$@"
+fi
+`, marker, shellQuote(executable), Args[kind], chainedSuffix, chainedSuffix)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,64 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallChainsAndUninstallRestores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, string(PreCommit))
+	existing := "#!/bin/sh\necho existing\n"
+	if err := os.WriteFile(path, []byte(existing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := Install(dir, PreCommit, "/usr/local/bin/linter"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "'/usr/local/bin/linter' --staged") {
+		t.Errorf("hook does not run the linter:\n%s", content)
+	}
+	chained, err := os.ReadFile(path + chainedSuffix)
+	if err != nil || string(chained) != existing {
+		t.Fatalf("existing hook not preserved after reinstall: %q, %v", chained, err)
+	}
+
+	if err := Uninstall(dir, PreCommit); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := os.ReadFile(path)
+	if err != nil || string(restored) != existing {
+		t.Errorf("existing hook not restored: %q, %v", restored, err)
+	}
+}
+
+func TestUninstallLeavesForeignHooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, string(PrePush))
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Uninstall(dir, PrePush); err == nil {
+		t.Error("Uninstall of a foreign hook expected an error")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("foreign hook was removed: %v", err)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/a b/it's"); got != `'/a b/it'\''s'` {
+		t.Errorf("shellQuote = %s", got)
+	}
+}
@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+var ErrBinaryNotFound = errors.New("golangci-lint binary not found")
+
+// FindBinary resolves the golangci-lint executable. An explicit path must
+// point at an executable file; otherwise $PATH, $GOBIN and
+// $(go env GOPATH)/bin are tried in order.
+func FindBinary(explicit string) (string, error) {
+	if explicit != "" {
+		if !isExecutable(explicit) {
+			return "", fmt.Errorf("%w: %s is missing or not executable", ErrBinaryNotFound, explicit)
+		}
+		return explicit, nil
+	}
+
+	searched := make([]string, 0, 3)
+
+	if path, err := exec.LookPath(binaryName()); err == nil {
+		return path, nil
+	}
+	searched = append(searched, "$PATH")
+
+	if dir := os.Getenv("GOBIN"); dir != "" {
+		path := filepath.Join(dir, binaryName())
+		if isExecutable(path) {
+			return path, nil
+		}
+		searched = append(searched, fmt.Sprintf("$GOBIN (%s)", path))
+	}
+
+	if gopath, err := goEnv("GOPATH"); err == nil && gopath != "" {
+		for _, dir := range filepath.SplitList(gopath) {
+			path := filepath.Join(dir, "bin", binaryName())
+			if isExecutable(path) {
+				return path, nil
+			}
+			searched = append(searched, fmt.Sprintf("$GOPATH/bin (%s)", path))
+		}
+	}
+
+	return "", fmt.Errorf("%w, searched: %s", ErrBinaryNotFound, strings.Join(searched, ", "))
+}
+
+func binaryName() string {
+	if runtime.GOOS == "windows" {
+		return "golangci-lint.exe"
+	}
+	return "golangci-lint"
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0o111 != 0
+}
+
+func goEnv(key string) (string, error) {
+	output, err := command.New("go", "env", key).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
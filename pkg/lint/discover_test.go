@@ -0,0 +1,52 @@
+package lint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeExecutable(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, binaryName())
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindBinaryExplicit(t *testing.T) {
+	path := writeExecutable(t, t.TempDir())
+
+	got, err := FindBinary(path)
+	if err != nil || got != path {
+		t.Errorf("FindBinary(%q) = %q, %v", path, got, err)
+	}
+}
+
+func TestFindBinaryExplicitMissingFailsFast(t *testing.T) {
+	// A valid binary on GOBIN must not be picked up when --bin is wrong.
+	t.Setenv("GOBIN", filepath.Dir(writeExecutable(t, t.TempDir())))
+
+	missing := filepath.Join(t.TempDir(), "golangci-lint-typo")
+	_, err := FindBinary(missing)
+	if !errors.Is(err, ErrBinaryNotFound) {
+		t.Fatalf("FindBinary(%q) error = %v, want ErrBinaryNotFound", missing, err)
+	}
+}
+
+func TestFindBinaryFromGOBIN(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on an empty PATH")
+	}
+	path := writeExecutable(t, t.TempDir())
+	t.Setenv("PATH", "")
+	t.Setenv("GOBIN", filepath.Dir(path))
+
+	got, err := FindBinary("")
+	if err != nil || got != path {
+		t.Errorf("FindBinary(\"\") = %q, %v, want %q", got, err, path)
+	}
+}
@@ -0,0 +1,38 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/golangci/golangci-lint/pkg/exitcodes"
+)
+
+var exitReasons = map[int]string{
+	exitcodes.WarningInTest:        "warnings in tests",
+	exitcodes.Failure:              "failed to analyze",
+	exitcodes.Timeout:              "timed out",
+	exitcodes.NoGoFiles:            "no Go files to analyze",
+	exitcodes.NoConfigFileDetected: "no config file detected",
+	exitcodes.ErrorWasLogged:       "an error was logged",
+}
+
+// ExecError is a golangci-lint run that failed rather than found issues.
+type ExecError struct {
+	Code int
+	Err  error
+}
+
+// Reason describes the exit code the way golangci-lint documents it.
+func (e *ExecError) Reason() string {
+	if reason, ok := exitReasons[e.Code]; ok {
+		return reason
+	}
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("golangci-lint %s: %v", e.Reason(), e.Err)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
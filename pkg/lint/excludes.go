@@ -0,0 +1,230 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSkipDirs are the directories golangci-lint v1 skips unless
+// skip-dirs-use-default is false.
+var defaultSkipDirs = []string{
+	`(^|/)vendor($|/)`, `(^|/)third_party($|/)`, `(^|/)testdata($|/)`,
+	`(^|/)examples($|/)`, `(^|/)Godeps($|/)`, `(^|/)builtin($|/)`,
+}
+
+// ExcludeRule is an exclude rule of a golangci-lint config, matching the
+// issues that meet every condition it sets.
+type ExcludeRule struct {
+	Path       string   `yaml:"path"`
+	PathExcept string   `yaml:"path-except"`
+	Text       string   `yaml:"text"`
+	Source     string   `yaml:"source"`
+	Linters    []string `yaml:"linters"`
+}
+
+// golangciConfig holds the parts of a golangci-lint v1 or v2 config that
+// exclude files and issues.
+type golangciConfig struct {
+	Run struct {
+		SkipDirs           []string `yaml:"skip-dirs"`
+		SkipFiles          []string `yaml:"skip-files"`
+		SkipDirsUseDefault *bool    `yaml:"skip-dirs-use-default"`
+	} `yaml:"run"`
+	Issues struct {
+		Exclude               []string      `yaml:"exclude"`
+		ExcludeRules          []ExcludeRule `yaml:"exclude-rules"`
+		ExcludeDirs           []string      `yaml:"exclude-dirs"`
+		ExcludeFiles          []string      `yaml:"exclude-files"`
+		ExcludeDirsUseDefault *bool         `yaml:"exclude-dirs-use-default"`
+	} `yaml:"issues"`
+	Linters struct {
+		Exclusions struct {
+			Rules []ExcludeRule `yaml:"rules"`
+			Paths []string      `yaml:"paths"`
+		} `yaml:"exclusions"`
+	} `yaml:"linters"`
+	Version string `yaml:"version"`
+}
+
+// Excludes are the files and issues a golangci-lint config leaves out, to
+// leave them out of issues golangci-lint did not report itself too. A nil
+// Excludes leaves nothing out.
+type Excludes struct {
+	dirs, files []*regexp.Regexp
+	rules       []excludeRule
+}
+
+type excludeRule struct {
+	path, pathExcept, text, source *regexp.Regexp
+	linters                        []string
+}
+
+// LoadExcludes reads the skipped directories and files and the exclude
+// rules of the golangci-lint config at path, in YAML or JSON. A TOML
+// config excludes nothing, as it cannot be read here.
+func LoadExcludes(path string) (*Excludes, error) {
+	if strings.HasSuffix(path, ".toml") {
+		return &Excludes{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg golangciConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	e, err := cfg.excludes()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+func (cfg *golangciConfig) excludes() (*Excludes, error) {
+	e := &Excludes{}
+	dirs := append(cfg.Run.SkipDirs, cfg.Issues.ExcludeDirs...)
+	files := append(cfg.Run.SkipFiles, cfg.Issues.ExcludeFiles...)
+	files = append(files, cfg.Linters.Exclusions.Paths...)
+	rules := append(cfg.Issues.ExcludeRules, cfg.Linters.Exclusions.Rules...)
+	for _, text := range cfg.Issues.Exclude {
+		rules = append(rules, ExcludeRule{Text: text})
+	}
+	useDefault := cfg.Run.SkipDirsUseDefault
+	if useDefault == nil {
+		useDefault = cfg.Issues.ExcludeDirsUseDefault
+	}
+	if cfg.Version != "2" && (useDefault == nil || *useDefault) {
+		dirs = append(dirs, defaultSkipDirs...)
+	}
+
+	var err error
+	if e.dirs, err = compileAll(dirs); err != nil {
+		return nil, err
+	}
+	if e.files, err = compileAll(files); err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		compiled, err := rule.compile()
+		if err != nil {
+			return nil, fmt.Errorf("exclude rule %d: %w", i+1, err)
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// compile checks the rule sets a condition; text and source match
+// regardless of case, as golangci-lint does.
+func (r ExcludeRule) compile() (excludeRule, error) {
+	if r.Path == "" && r.PathExcept == "" && r.Text == "" && r.Source == "" && len(r.Linters) == 0 {
+		return excludeRule{}, errors.New("sets no path, path-except, text, source or linters")
+	}
+	compiled := excludeRule{linters: r.Linters}
+	for _, field := range []struct {
+		pattern, flags string
+		re             **regexp.Regexp
+	}{
+		{r.Path, "", &compiled.path},
+		{r.PathExcept, "", &compiled.pathExcept},
+		{r.Text, "(?i)", &compiled.text},
+		{r.Source, "(?i)", &compiled.source},
+	} {
+		if field.pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(field.flags + field.pattern)
+		if err != nil {
+			return excludeRule{}, err
+		}
+		*field.re = re
+	}
+	return compiled, nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Skipped reports whether file, relative to the directory golangci-lint
+// runs in, is in a skipped directory or is a skipped file.
+func (e *Excludes) Skipped(file string) bool {
+	if e == nil {
+		return false
+	}
+	file = filepath.ToSlash(file)
+	for _, re := range e.files {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		for _, re := range e.dirs {
+			if re.MatchString(dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter drops the issues of skipped files and those an exclude rule
+// matches.
+func (e *Excludes) Filter(issues []result.Issue) []result.Issue {
+	if e == nil || len(e.dirs) == 0 && len(e.files) == 0 && len(e.rules) == 0 {
+		return issues
+	}
+	kept := make([]result.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if e.Skipped(issue.FilePath()) || e.excluded(issue) {
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+func (e *Excludes) excluded(issue result.Issue) bool {
+	for _, rule := range e.rules {
+		if rule.matches(issue) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r excludeRule) matches(issue result.Issue) bool {
+	file := filepath.ToSlash(issue.FilePath())
+	switch {
+	case r.path != nil && !r.path.MatchString(file):
+		return false
+	case r.pathExcept != nil && r.pathExcept.MatchString(file):
+		return false
+	case r.text != nil && !r.text.MatchString(issue.Text):
+		return false
+	case len(r.linters) > 0 && !slices.Contains(r.linters, issue.FromLinter):
+		return false
+	}
+	if r.source != nil {
+		return len(issue.SourceLines) > 0 && r.source.MatchString(issue.SourceLines[0])
+	}
+	return true
+}
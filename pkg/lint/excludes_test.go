@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExcludesSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		skipped []string
+		kept    []string
+	}{
+		{
+			name: "v1",
+			config: `run:
+  skip-dirs: [^internal/legacy$]
+  skip-files: ['_mock\.go$']
+`,
+			skipped: []string{"internal/legacy/a.go", "internal/legacy/sub/a.go", "pkg/a_mock.go", "vendor/x/a.go", "pkg/testdata/a.go"},
+			kept:    []string{"internal/a.go", "pkg/a.go", "vendored/a.go"},
+		},
+		{
+			name: "v1 without default dirs",
+			config: `issues:
+  exclude-dirs-use-default: false
+`,
+			kept: []string{"vendor/x/a.go"},
+		},
+		{
+			name: "v2",
+			config: `version: "2"
+linters:
+  exclusions:
+    paths: ['^third_party/']
+`,
+			skipped: []string{"third_party/a.go"},
+			kept:    []string{"vendor/x/a.go"},
+		},
+	}
+	for _, tt := range tests {
+		excludes, err := LoadExcludes(writeConfig(t, ".golangci.yml", tt.config))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, file := range tt.skipped {
+			if !excludes.Skipped(file) {
+				t.Errorf("%s: %s is not skipped", tt.name, file)
+			}
+		}
+		for _, file := range tt.kept {
+			if excludes.Skipped(file) {
+				t.Errorf("%s: %s is skipped", tt.name, file)
+			}
+		}
+	}
+}
+
+func TestExcludesFilter(t *testing.T) {
+	excludes, err := LoadExcludes(writeConfig(t, ".golangci.yml", `issues:
+  exclude:
+    - 'should have comment'
+  exclude-rules:
+    - path: _test\.go
+      linters: [errcheck, gosec]
+    - path-except: ^cmd/
+      text: "G104"
+    - source: "^//go:generate "
+      linters: [lll]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := func(linter, file, text, source string) result.Issue {
+		return result.Issue{FromLinter: linter, Text: text, Pos: token.Position{Filename: file}, SourceLines: []string{source}}
+	}
+	issues := []result.Issue{
+		issue("errcheck", "a_test.go", "unchecked", ""),
+		issue("govet", "a_test.go", "shadow", ""),
+		issue("gosec", "pkg/a.go", "g104: errors unhandled", ""),
+		issue("gosec", "cmd/a.go", "G104: errors unhandled", ""),
+		issue("lll", "a.go", "line is 130 characters", "//go:generate stringer -type=Kind"),
+		issue("lll", "a.go", "line is 130 characters", "var x = 1"),
+		issue("revive", "a.go", "exported A Should Have Comment", ""),
+		issue("errcheck", "vendor/x/a.go", "unchecked", ""),
+	}
+	var kept []string
+	for _, issue := range excludes.Filter(issues) {
+		kept = append(kept, issue.FromLinter+" "+issue.FilePath())
+	}
+	if got, want := strings.Join(kept, ", "), "govet a_test.go, gosec cmd/a.go, lll a.go"; got != want {
+		t.Errorf("kept %s, want %s", got, want)
+	}
+}
+
+func TestLoadExcludesErrors(t *testing.T) {
+	for _, config := range []string{
+		"issues:\n  exclude-rules:\n    - {}\n",
+		"issues:\n  exclude-rules:\n    - text: '('\n",
+		"run:\n  skip-dirs: ['[']\n",
+		"run: [",
+	} {
+		if _, err := LoadExcludes(writeConfig(t, ".golangci.yml", config)); err == nil {
+			t.Errorf("LoadExcludes(%q) expected an error", config)
+		}
+	}
+	excludes, err := LoadExcludes(writeConfig(t, ".golangci.toml", "[run]\nskip-dirs = ['a']\n"))
+	if err != nil || excludes.Skipped("a/b.go") {
+		t.Errorf("a TOML config excludes %v, %v; want nothing", excludes, err)
+	}
+}
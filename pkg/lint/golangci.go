@@ -0,0 +1,397 @@
+// Package lint runs golangci-lint and loads the issues it reports.
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/exitcodes"
+	"github.com/golangci/golangci-lint/pkg/printers"
+
+	"linter/pkg/command"
+	"linter/pkg/trace"
+)
+
+// Runner produces the issues of a lint run.
+type Runner interface {
+	Run() (*printers.JSONResult, error)
+}
+
+// GolangCILint runs the golangci-lint binary and reads its JSON report.
+type GolangCILint struct {
+	ctx           context.Context
+	binPath       string
+	pwdPath       string
+	outputFormat  string
+	outputFile    string
+	checkingPaths []string
+	configPath    string
+	extraArgs     []string
+	stderr        io.Writer
+	image         string
+	mount         string
+	env           []string
+	isolated      bool
+}
+
+var _ Runner = (*GolangCILint)(nil)
+
+// Runners lists the ways golangci-lint can be run: the local binary, or in
+// a Docker container.
+var Runners = []string{RunnerLocal, RunnerDocker}
+
+const (
+	RunnerLocal  = "local"
+	RunnerDocker = "docker"
+)
+
+// DefaultImage is the golangci-lint image run by default, of the release
+// whose report format this package reads.
+const DefaultImage = "golangci/golangci-lint:v1.51.1"
+
+// ValidRunner reports whether runner is one of Runners.
+func ValidRunner(runner string) bool {
+	for _, known := range Runners {
+		if runner == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsolatedEnv are the variables an isolated golangci-lint still gets, for
+// the go command to find itself, a home and a temporary directory.
+var IsolatedEnv = []string{
+	"PATH", "HOME", "TMPDIR",
+	// Windows
+	"PATHEXT", "SYSTEMROOT", "USERPROFILE", "LOCALAPPDATA", "APPDATA", "TEMP", "TMP",
+}
+
+// ownedFlags are the golangci-lint flags the report depends on.
+var ownedFlags = []string{"--out-format", "--output.json.path", "--issues-exit-code"}
+
+// imageVersionPattern finds the major version in the tag of an image.
+var imageVersionPattern = regexp.MustCompile(`:v?(\d+)\.`)
+
+// NewGolangCILint returns a runner for golangci-lint found on $PATH, run in
+// the current directory.
+func NewGolangCILint() *GolangCILint {
+	return &GolangCILint{
+		ctx:     context.Background(),
+		binPath: binaryName(),
+		pwdPath: ".",
+	}
+}
+
+// SetContext stops golangci-lint when ctx is done.
+func (g *GolangCILint) SetContext(ctx context.Context) *GolangCILint {
+	g.ctx = ctx
+	return g
+}
+
+// SetBin sets the golangci-lint executable to run.
+func (g *GolangCILint) SetBin(path string) *GolangCILint {
+	g.binPath = path
+	return g
+}
+
+// SetPwd sets the directory golangci-lint runs in.
+func (g *GolangCILint) SetPwd(path string) *GolangCILint {
+	g.pwdPath = path
+	return g
+}
+
+// SetOutputJSON sets the file the JSON report is written to and read from.
+func (g *GolangCILint) SetOutputJSON(filename string) *GolangCILint {
+	g.outputFormat = fmt.Sprintf("json:%s", filename)
+	g.outputFile = filename
+	return g
+}
+
+// SetStderr forwards the diagnostics golangci-lint prints to w.
+func (g *GolangCILint) SetStderr(w io.Writer) *GolangCILint {
+	g.stderr = w
+	return g
+}
+
+// SetConfig sets the golangci-lint config file, instead of the one it
+// would find on its own.
+func (g *GolangCILint) SetConfig(path string) *GolangCILint {
+	g.configPath = path
+	return g
+}
+
+// SetExtraArgs sets further golangci-lint run flags, such as the enabled
+// linters, build tags or timeout.
+func (g *GolangCILint) SetExtraArgs(args ...string) *GolangCILint {
+	g.extraArgs = args
+	return g
+}
+
+// SetEnv adds the key=value pairs env to the environment golangci-lint and
+// the go command it runs see, such as GOFLAGS or GOCACHE. In Docker they
+// are passed on to the container.
+func (g *GolangCILint) SetEnv(env ...string) *GolangCILint {
+	g.env = append(g.env, env...)
+	return g
+}
+
+// SetIsolatedEnv runs a local golangci-lint with only the variables of
+// IsolatedEnv from the current environment, plus those of SetEnv, so the
+// GOFLAGS or GOPATH of the caller do not change what it lints. A container
+// is isolated already.
+func (g *GolangCILint) SetIsolatedEnv(isolated bool) *GolangCILint {
+	g.isolated = isolated
+	return g
+}
+
+// SetDocker runs golangci-lint in a container of image instead of the
+// binary, with the directory mount, which must hold the pwd, bind-mounted at
+// the same path so the report names the same files.
+func (g *GolangCILint) SetDocker(image, mount string) *GolangCILint {
+	g.image = image
+	g.mount = mount
+	return g
+}
+
+// SetInspectDes sets the package patterns to lint.
+func (g *GolangCILint) SetInspectDes(paths ...string) *GolangCILint {
+	g.checkingPaths = paths
+	return g
+}
+
+// Run lints the configured paths and returns the reported issues. A report
+// left over from an earlier run is removed first, so a failing golangci-lint
+// can never be mistaken for a clean one.
+func (g *GolangCILint) Run() (*printers.JSONResult, error) {
+	if err := os.Remove(g.outputPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	_, span := trace.Start(g.ctx, "golangci-lint")
+	span.SetAttribute("paths", strings.Join(g.checkingPaths, " "))
+	err := g.Execute()
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		// An interrupted run may leave a partial report behind.
+		if g.ctx.Err() != nil {
+			_ = os.Remove(g.outputPath())
+		}
+		return nil, err
+	}
+
+	_, span = trace.Start(g.ctx, "parse report")
+	result, err := g.FindJSONIssues()
+	if err == nil {
+		span.SetAttribute("issues", len(result.Issues))
+	}
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return nil, err
+	}
+	if result.Report != nil && result.Report.Error != "" {
+		return nil, fmt.Errorf("golangci-lint: %s", result.Report.Error)
+	}
+	return result, nil
+}
+
+// Execute runs golangci-lint, writing its JSON report to the output file.
+// Finding issues is not an error; any other failure is returned as an
+// *ExecError carrying the command line and stderr.
+func (g *GolangCILint) Execute() error {
+	for _, arg := range g.extraArgs {
+		for _, owned := range ownedFlags {
+			if arg == owned || strings.HasPrefix(arg, owned+"=") {
+				return fmt.Errorf("%s is set by the linter itself and cannot be passed to golangci-lint", owned)
+			}
+		}
+	}
+
+	cmd := command.New(g.binPath)
+	outputFormat, outputFile := g.outputFormat, g.outputFile
+	if g.image != "" {
+		docker, err := g.dockerArgs()
+		if err != nil {
+			return err
+		}
+		// The report goes to stdout, the one place the container writes
+		// to wherever the output file lies.
+		cmd, outputFormat, outputFile = command.New("docker", docker...), "json", "stdout"
+	}
+	cmd.AppendArgs("run")
+	if g.majorVersion() >= 2 {
+		// golangci-lint v2 replaced --out-format with a flag per format.
+		cmd.AppendArgs("--output.json.path", outputFile)
+	} else {
+		cmd.AppendArgs("--out-format", outputFormat)
+	}
+	cmd.AppendArgs("--issues-exit-code", strconv.Itoa(exitcodes.IssuesFound))
+	cmd.SetContext(g.ctx).SetEnv(g.env...)
+	if g.isolated && g.image == "" {
+		cmd.SetIsolated(IsolatedEnv...)
+	}
+	if g.configPath != "" {
+		cmd.AppendArgs("--config", g.configPath)
+	}
+	report, err := cmd.
+		AppendArgs(g.extraArgs...).
+		AppendArgs(g.checkingPaths...).
+		SetDir(g.pwdPath).
+		SetStderr(g.stderr).
+		Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if g.ctx.Err() != nil || !errors.As(err, &exitErr) {
+			return err
+		}
+		if exitErr.ExitCode() != exitcodes.IssuesFound {
+			return &ExecError{Code: exitErr.ExitCode(), Err: err}
+		}
+	}
+	if g.image != "" {
+		return os.WriteFile(g.outputPath(), report, 0o644)
+	}
+	return nil
+}
+
+// majorVersion is the major version of golangci-lint, from the tag of the
+// image or asked of the binary. It is 1, whose flags this package knows
+// best, when it cannot tell.
+func (g *GolangCILint) majorVersion() int {
+	if g.image != "" {
+		if match := imageVersionPattern.FindStringSubmatch(g.image); match != nil {
+			major, _ := strconv.Atoi(match[1])
+			return major
+		}
+		return 1
+	}
+	version, err := BinaryVersion(g.ctx, g.binPath)
+	if err != nil {
+		return 1
+	}
+	major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	return major
+}
+
+// dockerArgs runs golangci-lint in the image, in the pwd at the same path
+// as outside. Named volumes keep the module and build caches across runs.
+func (g *GolangCILint) dockerArgs() ([]string, error) {
+	pwd, err := filepath.Abs(g.pwdPath)
+	if err != nil {
+		return nil, err
+	}
+	mount, err := filepath.Abs(g.mount)
+	if err != nil {
+		return nil, err
+	}
+	if !within(mount, pwd) {
+		return nil, fmt.Errorf("%s is outside the directory mounted into the container, %s", pwd, mount)
+	}
+	args := []string{"run", "--rm",
+		"-v", mount + ":" + mount,
+		"-w", pwd,
+		"-v", "linterdiff-go-mod:/go/pkg/mod",
+		"-v", "linterdiff-go-build:/root/.cache",
+	}
+	for _, pair := range g.env {
+		// Only the name goes on the command line, docker takes the value
+		// from its own environment.
+		key, _, _ := strings.Cut(pair, "=")
+		args = append(args, "-e", key)
+	}
+	if g.configPath != "" {
+		config := g.configPath
+		if !filepath.IsAbs(config) {
+			config = filepath.Join(pwd, config)
+		}
+		if !within(mount, config) {
+			args = append(args, "-v", config+":"+config+":ro")
+		}
+	}
+	return append(args, g.image, "golangci-lint"), nil
+}
+
+// within reports whether path lies in dir, both absolute.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// FindJSONIssues reads the JSON report written by Execute.
+func (g *GolangCILint) FindJSONIssues() (*printers.JSONResult, error) {
+	file, err := os.Open(g.outputPath())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReport(bytes)
+}
+
+// ParseReport reads a golangci-lint JSON report.
+func ParseReport(report []byte) (*printers.JSONResult, error) {
+	if err := checkSchema(report); err != nil {
+		return nil, err
+	}
+	var jsonResult printers.JSONResult
+	if err := json.Unmarshal(report, &jsonResult); err != nil {
+		return nil, err
+	}
+	return &jsonResult, nil
+}
+
+// ErrSchema is a report unlike those this package reads, as from a
+// golangci-lint release that renamed its fields.
+var ErrSchema = errors.New("golangci-lint report has an unknown format")
+
+// issueFields are the fields of a reported issue the filtering relies on.
+var issueFields = []string{"FromLinter", "Text", "Pos"}
+
+// checkSchema makes sure report has the fields read from it, so a renamed
+// field fails the run instead of decoding into zero values and reporting
+// nothing.
+func checkSchema(report []byte) error {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(report, &top); err != nil {
+		return err
+	}
+	raw, ok := top["Issues"]
+	if !ok {
+		return fmt.Errorf("%w: no Issues field", ErrSchema)
+	}
+	var issues []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &issues); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchema, err)
+	}
+	for i, issue := range issues {
+		for _, field := range issueFields {
+			if _, ok := issue[field]; !ok {
+				return fmt.Errorf("%w: issue %d has no %s field", ErrSchema, i, field)
+			}
+		}
+	}
+	return nil
+}
+
+// outputPath locates the report from this process; golangci-lint resolves a
+// relative output file against its own working directory.
+func (g *GolangCILint) outputPath() string {
+	if filepath.IsAbs(g.outputFile) {
+		return g.outputFile
+	}
+	return filepath.Join(g.pwdPath, g.outputFile)
+}
@@ -0,0 +1,379 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/exitcodes"
+)
+
+// fakeLinter writes a shell script standing in for golangci-lint: it writes
+// report to the file named in --out-format json:<file> and exits with code.
+// Its arguments are echoed to stderr.
+func fakeLinter(t *testing.T, report string, code int, stderr string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+
+	script := `#!/bin/sh
+echo "args: $*" >&2
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+`
+	if report != "" {
+		script += "printf '%s' '" + report + "' > \"$out\"\n"
+	}
+	if stderr != "" {
+		script += "echo '" + stderr + "' >&2\n"
+	}
+	script += "exit " + strconv.Itoa(code) + "\n"
+
+	path := filepath.Join(t.TempDir(), "golangci-lint")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunIssuesFound(t *testing.T) {
+	bin := fakeLinter(t, `{"Issues":[{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"a.go","Line":3}}]}`, exitcodes.IssuesFound, "")
+
+	result, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(t.TempDir()).
+		SetOutputJSON("report.json").
+		SetInspectDes("./...").
+		Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].FromLinter != "errcheck" {
+		t.Errorf("Issues = %+v", result.Issues)
+	}
+}
+
+func TestRunFailureIsNotMaskedByStaleReport(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "report.json")
+	if err := os.WriteFile(stale, []byte(`{"Issues":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := fakeLinter(t, "", 3, "invalid config")
+	_, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(dir).
+		SetOutputJSON(stale).
+		Run()
+	if err == nil {
+		t.Fatal("Run expected an error from a failing golangci-lint")
+	}
+	if !strings.Contains(err.Error(), "invalid config") {
+		t.Errorf("error %q does not include stderr", err)
+	}
+	var execErr *ExecError
+	if !errors.As(err, &execErr) || execErr.Reason() != "failed to analyze" {
+		t.Errorf("error %v is not an ExecError for a failure", err)
+	}
+	if _, statErr := os.Stat(stale); !os.IsNotExist(statErr) {
+		t.Errorf("stale report was not removed: %v", statErr)
+	}
+}
+
+func TestRunReportError(t *testing.T) {
+	bin := fakeLinter(t, `{"Issues":[],"Report":{"Error":"context loading failed"}}`, 0, "")
+
+	_, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(t.TempDir()).
+		SetOutputJSON("report.json").
+		Run()
+	if err == nil || !strings.Contains(err.Error(), "context loading failed") {
+		t.Errorf("Run error = %v, want the report error", err)
+	}
+}
+
+func TestRunForwardsStderr(t *testing.T) {
+	bin := fakeLinter(t, `{"Issues":[]}`, 0, "level=warning msg=deprecated")
+
+	var stderr strings.Builder
+	if _, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(t.TempDir()).
+		SetOutputJSON("report.json").
+		SetStderr(&stderr).
+		Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "deprecated") {
+		t.Errorf("stderr %q was not forwarded", stderr.String())
+	}
+}
+
+func TestRunPassesConfigAndExtraArgs(t *testing.T) {
+	bin := fakeLinter(t, `{"Issues":[]}`, 0, "")
+
+	var stderr strings.Builder
+	if _, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(t.TempDir()).
+		SetOutputJSON("report.json").
+		SetConfig("ci/.golangci.yml").
+		SetExtraArgs("--build-tags", "integration", "--timeout=5m").
+		SetInspectDes("./pkg/...").
+		SetStderr(&stderr).
+		Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--config ci/.golangci.yml --build-tags integration --timeout=5m ./pkg/..."
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("arguments %q do not end with %q", stderr.String(), want)
+	}
+}
+
+func TestRunSetsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	t.Setenv("GOFLAGS", "-tags=leaked")
+	t.Setenv("LEAKED", "yes")
+	script := `#!/bin/sh
+echo "env: GOFLAGS=$GOFLAGS LEAKED=$LEAKED" >&2
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+printf '%s' '{"Issues":[]}' > "$out"
+`
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		isolated bool
+		want     string
+	}{
+		{want: "env: GOFLAGS=-mod=mod LEAKED=yes"},
+		{isolated: true, want: "env: GOFLAGS=-mod=mod LEAKED="},
+	}
+	for _, tt := range tests {
+		var stderr strings.Builder
+		if _, err := NewGolangCILint().
+			SetBin(bin).
+			SetPwd(t.TempDir()).
+			SetOutputJSON("report.json").
+			SetEnv("GOFLAGS=-mod=mod").
+			SetIsolatedEnv(tt.isolated).
+			SetStderr(&stderr).
+			Run(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(stderr.String(), tt.want+"\n") {
+			t.Errorf("isolated %v: stderr %q, want %q", tt.isolated, stderr.String(), tt.want)
+		}
+	}
+}
+
+func TestRunRejectsOwnedFlags(t *testing.T) {
+	bin := fakeLinter(t, `{"Issues":[]}`, 0, "")
+
+	for _, arg := range []string{"--out-format", "--out-format=checkstyle", "--issues-exit-code=0"} {
+		_, err := NewGolangCILint().
+			SetBin(bin).
+			SetPwd(t.TempDir()).
+			SetOutputJSON("report.json").
+			SetExtraArgs(arg).
+			Run()
+		if err == nil {
+			t.Errorf("Run with %s expected an error", arg)
+		}
+	}
+}
+
+func TestRunCancelledRemovesPartialReport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+printf '{"Issues":[' > "$out"
+exec sleep 10
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	pwd := t.TempDir()
+	_, err := NewGolangCILint().
+		SetContext(ctx).
+		SetBin(bin).
+		SetPwd(pwd).
+		SetOutputJSON("report.json").
+		Run()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run error = %v, want a deadline error", err)
+	}
+	if _, err := os.Stat(filepath.Join(pwd, "report.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial report left behind: %v", err)
+	}
+}
+
+func TestRunInDocker(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker is a shell script")
+	}
+	// The stand-in docker records its arguments and prints the report, as
+	// golangci-lint in the container would.
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := `#!/bin/sh
+echo "$*" > "` + argsFile + `"
+printf '%s' '{"Issues":[{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"a.go","Line":3}}]}'
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	pwd := filepath.Join(root, "sub")
+	if err := os.Mkdir(pwd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "golangci.yml")
+	result, err := NewGolangCILint().
+		SetPwd(pwd).
+		SetDocker("golangci/golangci-lint:v1.59", root).
+		SetOutputJSON(filepath.Join(t.TempDir(), "report.json")).
+		SetConfig(config).
+		SetEnv("GOFLAGS=-mod=mod").
+		SetInspectDes("./...").
+		Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].FromLinter != "errcheck" {
+		t.Errorf("Issues = %+v", result.Issues)
+	}
+
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "run --rm -v " + root + ":" + root + " -w " + pwd +
+		" -v linterdiff-go-mod:/go/pkg/mod -v linterdiff-go-build:/root/.cache -e GOFLAGS" +
+		" -v " + config + ":" + config + ":ro golangci/golangci-lint:v1.59 golangci-lint" +
+		" run --out-format json --issues-exit-code 1 --config " + config + " ./...\n"
+	if string(got) != want {
+		t.Errorf("docker %s\nwant docker %s", got, want)
+	}
+
+	_, err = NewGolangCILint().SetPwd(t.TempDir()).SetDocker("golangci/golangci-lint", root).SetOutputJSON("report.json").Run()
+	if err == nil || !strings.Contains(err.Error(), "outside the directory mounted") {
+		t.Errorf("Run outside the mount = %v", err)
+	}
+}
+
+func TestRunAdaptsToVersion2(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	// golangci-lint v2 takes the report file in --output.json.path and
+	// rejects --out-format.
+	script := `#!/bin/sh
+if [ "$1" = --version ]; then
+	echo "golangci-lint has version 2.1.6 built with go1.24.2"
+	exit 0
+fi
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) echo "unknown flag: --out-format" >&2; exit 3 ;;
+	--output.json.path) out="$2"; shift ;;
+	esac
+	shift
+done
+printf '%s' '{"Issues":[{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"a.go","Line":3}}]}' > "$out"
+exit 1
+`
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewGolangCILint().
+		SetBin(bin).
+		SetPwd(t.TempDir()).
+		SetOutputJSON("report.json").
+		SetInspectDes("./...").
+		Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].FromLinter != "errcheck" {
+		t.Errorf("Issues = %+v", result.Issues)
+	}
+}
+
+func TestRunRejectsUnknownSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		wantErr string
+	}{
+		{name: "renamed issues", report: `{"Findings":[]}`, wantErr: "no Issues field"},
+		{name: "renamed field", report: `{"Issues":[{"Linter":"errcheck","Text":"x","Pos":{"Filename":"a.go"}}]}`, wantErr: "issue 0 has no FromLinter field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := fakeLinter(t, tt.report, exitcodes.IssuesFound, "")
+			_, err := NewGolangCILint().
+				SetBin(bin).
+				SetPwd(t.TempDir()).
+				SetOutputJSON("report.json").
+				Run()
+			if !errors.Is(err, ErrSchema) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageMajorVersion(t *testing.T) {
+	for image, want := range map[string]int{
+		"golangci/golangci-lint:v1.59.1":        1,
+		"golangci/golangci-lint:v2.1.6-alpine":  2,
+		"registry:5000/golangci-lint:2.0.0":     2,
+		"golangci/golangci-lint":                1,
+		"golangci/golangci-lint@sha256:0123abc": 1,
+	} {
+		if got := NewGolangCILint().SetDocker(image, ".").majorVersion(); got != want {
+			t.Errorf("majorVersion of %s = %d, want %d", image, got, want)
+		}
+	}
+}
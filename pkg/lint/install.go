@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// DefaultReleaseURL is where the golangci-lint releases are downloaded from.
+const DefaultReleaseURL = "https://github.com/golangci/golangci-lint/releases/download"
+
+var (
+	versionPattern = regexp.MustCompile(`version v?(\d+\.\d+\.\d+)`)
+	releasePattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+)
+
+// ValidVersion reports whether version names a release, such as v1.59.1.
+func ValidVersion(version string) bool {
+	return releasePattern.MatchString(version)
+}
+
+// Image is the golangci-lint image of version.
+func Image(version string) string {
+	return "golangci/golangci-lint:v" + strings.TrimPrefix(version, "v")
+}
+
+// BinaryVersion returns the version the golangci-lint at bin reports, such
+// as "1.59.1".
+func BinaryVersion(ctx context.Context, bin string) (string, error) {
+	output, err := command.New(bin, "--version").SetContext(ctx).Output()
+	if err != nil {
+		return "", err
+	}
+	match := versionPattern.FindSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("%s --version: no version in %q", bin, strings.TrimSpace(string(output)))
+	}
+	return string(match[1]), nil
+}
+
+// Installer downloads golangci-lint releases into a directory, one
+// subdirectory per version.
+type Installer struct {
+	dir     string
+	baseURL string
+	goos    string
+	goarch  string
+	http    *http.Client
+}
+
+// NewInstaller returns an installer into dir of the releases for this
+// platform.
+func NewInstaller(dir string) *Installer {
+	return &Installer{
+		dir:     dir,
+		baseURL: DefaultReleaseURL,
+		goos:    runtime.GOOS,
+		goarch:  runtime.GOARCH,
+		http:    http.DefaultClient,
+	}
+}
+
+// SetBaseURL downloads the releases from a mirror laid out like
+// DefaultReleaseURL.
+func (i *Installer) SetBaseURL(url string) *Installer {
+	i.baseURL = strings.TrimRight(url, "/")
+	return i
+}
+
+// SetPlatform installs the releases for another GOOS and GOARCH.
+func (i *Installer) SetPlatform(goos, goarch string) *Installer {
+	i.goos = goos
+	i.goarch = goarch
+	return i
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (i *Installer) SetHTTPClient(client *http.Client) *Installer {
+	i.http = client
+	return i
+}
+
+// Install returns the golangci-lint binary of version, such as "v1.59.1",
+// downloading it first unless an earlier call did. The archive must match
+// the checksum the release publishes.
+func (i *Installer) Install(ctx context.Context, version string) (string, error) {
+	version = strings.TrimPrefix(version, "v")
+	name := "golangci-lint"
+	if i.goos == "windows" {
+		name += ".exe"
+	}
+	bin := filepath.Join(i.dir, "v"+version, name)
+	if isExecutable(bin) {
+		return bin, nil
+	}
+
+	release := fmt.Sprintf("golangci-lint-%s-%s-%s", version, i.goos, i.goarch)
+	archive := release + ".tar.gz"
+	if i.goos == "windows" {
+		archive = release + ".zip"
+	}
+	sums, err := i.download(ctx, version, fmt.Sprintf("golangci-lint-%s-checksums.txt", version))
+	if err != nil {
+		return "", err
+	}
+	want, err := checksum(sums, archive)
+	if err != nil {
+		return "", err
+	}
+	data, err := i.download(ctx, version, archive)
+	if err != nil {
+		return "", err
+	}
+	if got := sha256.Sum256(data); hex.EncodeToString(got[:]) != want {
+		return "", fmt.Errorf("%s: checksum mismatch, got %x, want %s", archive, got, want)
+	}
+
+	var binary []byte
+	if i.goos == "windows" {
+		binary, err = fromZip(data, release+"/"+name)
+	} else {
+		binary, err = fromTarGz(data, release+"/"+name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", archive, err)
+	}
+
+	// Written aside and renamed, so a concurrent run never sees half a
+	// binary.
+	if err := os.MkdirAll(filepath.Dir(bin), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(bin), name+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), bin); err != nil {
+		return "", err
+	}
+	return bin, nil
+}
+
+func (i *Installer) download(ctx context.Context, version, file string) ([]byte, error) {
+	url := fmt.Sprintf("%s/v%s/%s", i.baseURL, version, file)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := i.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// checksum finds the SHA-256 of file in a checksums.txt.
+func checksum(sums []byte, file string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == file {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum published for %s", file)
+}
+
+func fromTarGz(data []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s is missing", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+func fromZip(data []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	file, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
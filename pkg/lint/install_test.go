@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeRelease serves the archives of golangci-lint v1.59.1 for linux/amd64
+// and windows/amd64, holding content as the binary, with their checksums.
+func fakeRelease(t *testing.T, content string, corrupt bool) (*httptest.Server, *int32) {
+	t.Helper()
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"README.md", "golangci-lint"} {
+		header := &tar.Header{Name: "golangci-lint-1.59.1-linux-amd64/" + name, Mode: 0o755, Size: int64(len(content))}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("golangci-lint-1.59.1-windows-amd64/golangci-lint.exe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"golangci-lint-1.59.1-linux-amd64.tar.gz": tgz.Bytes(),
+		"golangci-lint-1.59.1-windows-amd64.zip":  zipped.Bytes(),
+	}
+	var sums strings.Builder
+	for name, data := range files {
+		sum := sha256.Sum256(data)
+		if corrupt {
+			sum[0]++
+		}
+		fmt.Fprintf(&sums, "%x  %s\n", sum, name)
+	}
+	files["golangci-lint-1.59.1-checksums.txt"] = []byte(sums.String())
+
+	requests := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/v1.59.1/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestInstall(t *testing.T) {
+	for _, goos := range []string{"linux", "windows"} {
+		t.Run(goos, func(t *testing.T) {
+			server, requests := fakeRelease(t, "binary", false)
+			dir := t.TempDir()
+			installer := NewInstaller(dir).SetBaseURL(server.URL).SetPlatform(goos, "amd64")
+
+			bin, err := installer.Install(context.Background(), "v1.59.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			name := "golangci-lint"
+			if goos == "windows" {
+				name += ".exe"
+			}
+			if want := filepath.Join(dir, "v1.59.1", name); bin != want {
+				t.Errorf("Install = %s, want %s", bin, want)
+			}
+			if data, err := os.ReadFile(bin); err != nil || string(data) != "binary" {
+				t.Errorf("installed %q, %v", data, err)
+			}
+
+			// Installed once, the binary is reused without a download.
+			if _, err := installer.Install(context.Background(), "1.59.1"); err != nil {
+				t.Fatal(err)
+			}
+			if *requests != 2 {
+				t.Errorf("sent %d requests, want 2", *requests)
+			}
+		})
+	}
+}
+
+func TestInstallRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt bool
+		goarch  string
+		wantErr string
+	}{
+		{name: "checksum mismatch", corrupt: true, goarch: "amd64", wantErr: "checksum mismatch"},
+		{name: "unpublished platform", goarch: "mips", wantErr: "no checksum published"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := fakeRelease(t, "binary", tt.corrupt)
+			dir := t.TempDir()
+			_, err := NewInstaller(dir).SetBaseURL(server.URL).SetPlatform("linux", tt.goarch).Install(context.Background(), "v1.59.1")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Install = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "v1.59.1", "golangci-lint")); !os.IsNotExist(err) {
+				t.Errorf("a binary was installed: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidVersion(t *testing.T) {
+	for version, want := range map[string]bool{"v1.59.1": true, "1.59.1": true, "v1.59": false, "latest": false, "": false} {
+		if got := ValidVersion(version); got != want {
+			t.Errorf("ValidVersion(%q) = %v, want %v", version, got, want)
+		}
+	}
+	if got := Image("1.59.1"); got != "golangci/golangci-lint:v1.59.1" {
+		t.Errorf("Image = %q", got)
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := "#!/bin/sh\necho 'golangci-lint has version 1.59.1 built with go1.22.3 from 1a55854a on 2024-06-09T18:08:33Z'\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	version, err := BinaryVersion(context.Background(), bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.59.1" {
+		t.Errorf("BinaryVersion = %q, want 1.59.1", version)
+	}
+}
@@ -0,0 +1,113 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Module is a Go module of the repository with the changed files inside it.
+type Module struct {
+	// Dir is the absolute directory holding go.mod.
+	Dir string
+	// Files are the changed files of the module, relative to the
+	// repository root like the files given to ChangedModules.
+	Files []string
+}
+
+// ChangedModules groups changed files, given relative to the repository
+// root, by the innermost module containing them. Files outside of every
+// module are dropped. Files of deleted directories count for the module of
+// their closest existing parent.
+func ChangedModules(root string, files []string) ([]Module, error) {
+	return groupFiles(root, files, "go.mod")
+}
+
+// ChangedSubmodules groups changed files, given relative to the repository
+// root, by the innermost git submodule below root containing them, as
+// Modules whose Dir is the top of the submodule. Files outside of every
+// submodule are dropped.
+func ChangedSubmodules(root string, files []string) ([]Module, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	submodules, err := groupFiles(abs, files, ".git")
+	if err != nil {
+		return nil, err
+	}
+	kept := submodules[:0]
+	for _, submodule := range submodules {
+		if submodule.Dir != abs {
+			kept = append(kept, submodule)
+		}
+	}
+	return kept, nil
+}
+
+// groupFiles groups files by the innermost directory up to root holding
+// marker.
+func groupFiles(root string, files []string, marker string) ([]Module, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	byDir := make(map[string]*Module)
+	// moduleOf caches the module of every directory looked at.
+	moduleOf := make(map[string]string)
+	for _, file := range files {
+		dir := findModule(root, filepath.Dir(filepath.Join(root, filepath.FromSlash(file))), marker, moduleOf)
+		if dir == "" {
+			continue
+		}
+		module, ok := byDir[dir]
+		if !ok {
+			module = &Module{Dir: dir}
+			byDir[dir] = module
+		}
+		module.Files = append(module.Files, file)
+	}
+
+	modules := make([]Module, 0, len(byDir))
+	for _, module := range byDir {
+		modules = append(modules, *module)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
+
+// findModule walks up from dir, staying inside root, to the first
+// directory holding marker, returning "" when there is none.
+func findModule(root, dir, marker string, moduleOf map[string]string) string {
+	var visited []string
+	found := ""
+	for {
+		if module, ok := moduleOf[dir]; ok {
+			found = module
+			break
+		}
+		visited = append(visited, dir)
+
+		// A directory that is gone, or anything else unreadable, simply
+		// holds no module.
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			found = dir
+			break
+		}
+
+		if dir == root {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for _, d := range visited {
+		moduleOf[d] = found
+	}
+	return found
+}
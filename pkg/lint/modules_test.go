@@ -0,0 +1,74 @@
+package lint
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedModules(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root\n")
+	writeFile(t, filepath.Join(root, "svc", "go.mod"), "module example.com/svc\n")
+	writeFile(t, filepath.Join(root, "svc", "api", "api.go"), "package api\n")
+	writeFile(t, filepath.Join(root, "svc", "tools", "go.mod"), "module example.com/tools\n")
+	writeFile(t, filepath.Join(root, "lib", "lib.go"), "package lib\n")
+
+	got, err := ChangedModules(root, []string{
+		"svc/api/api.go",
+		"svc/gone/deleted.go",
+		"svc/tools/main.go",
+		"lib/lib.go",
+		"README.md",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Module{
+		{Dir: root, Files: []string{"lib/lib.go", "README.md"}},
+		{Dir: filepath.Join(root, "svc"), Files: []string{"svc/api/api.go", "svc/gone/deleted.go"}},
+		{Dir: filepath.Join(root, "svc", "tools"), Files: []string{"svc/tools/main.go"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedModules = %+v, want %+v", got, want)
+	}
+}
+
+func TestChangedModulesOutsideModules(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "svc", "go.mod"), "module example.com/svc\n")
+
+	got, err := ChangedModules(root, []string{"docs/index.md", "main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ChangedModules = %+v, want none", got)
+	}
+}
+
+func TestChangedSubmodules(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, "lib", ".git"), "gitdir: ../.git/modules/lib\n")
+	writeFile(t, filepath.Join(root, "lib", "vendor", "dep", ".git"), "gitdir: ../../../.git/modules/lib/modules/dep\n")
+
+	got, err := ChangedSubmodules(root, []string{
+		"main.go",
+		"lib/lib.go",
+		"lib/vendor/dep/dep.go",
+		"lib/sub/x.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Module{
+		{Dir: filepath.Join(root, "lib"), Files: []string{"lib/lib.go", "lib/sub/x.go"}},
+		{Dir: filepath.Join(root, "lib", "vendor", "dep"), Files: []string{"lib/vendor/dep/dep.go"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSubmodules = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"linter/pkg/command"
+)
+
+// ChangedPackages maps changed files, given relative to the repository root,
+// to the package directories containing them, as ./patterns relative to pwd
+// that golangci-lint accepts. Directories that no longer exist are skipped,
+// but changed Go files that belong to no package are an error rather than a
+// silently empty lint run.
+func ChangedPackages(ctx context.Context, pwd, root string, files []string) ([]string, error) {
+	base, err := filepath.Abs(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	patterns := make([]string, 0, len(files))
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+
+		dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(file)))
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, "./"+filepath.ToSlash(rel))
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	output, err := command.New("go", "list", "-e", "-f", "{{if not .Error}}{{.Dir}}{{end}}").
+		SetContext(ctx).
+		AppendArgs(patterns...).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]string, 0, len(patterns))
+	for _, dir := range strings.Split(string(output), "\n") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, "./"+filepath.ToSlash(rel))
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("changed Go files in %s belong to no package under %s", strings.Join(patterns, ", "), pwd)
+	}
+
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// depsFormat has go list print the directory, import path and whether the
+// module is a main one of every package, then the import paths of all the
+// packages it depends on, separated by tabs.
+const depsFormat = "{{.Dir}}\t{{.ImportPath}}\t{{with .Module}}{{.Main}}{{end}}{{range .Deps}}\t{{.}}{{end}}"
+
+// PackageDeps maps the package directories dirs, absolute, to the
+// directories of the packages they depend on, directly or not and in their
+// tests as well, that belong to the main module or the workspace: a change
+// there may change their issues. Dependencies from the module cache are left
+// out; go.sum pins them.
+func PackageDeps(ctx context.Context, pwd string, dirs []string) (map[string][]string, error) {
+	base, err := filepath.Abs(pwd)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(dirs))
+	patterns := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			return nil, err
+		}
+		wanted[dir] = true
+		patterns = append(patterns, "./"+filepath.ToSlash(rel))
+	}
+	if len(patterns) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	output, err := command.New("go", "list", "-e", "-deps", "-test", "-f", depsFormat).
+		SetContext(ctx).
+		AppendArgs(patterns...).
+		SetDir(pwd).
+		Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Test variants, such as "p [p.test]", share the directory of p.
+	local := make(map[string]string)
+	imports := make(map[string][]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 || fields[0] == "" || fields[2] != "true" {
+			continue
+		}
+		dir, path := fields[0], strings.SplitN(fields[1], " ", 2)[0]
+		local[path] = dir
+		if wanted[dir] {
+			imports[dir] = append(imports[dir], fields[3:]...)
+		}
+	}
+
+	deps := make(map[string][]string, len(imports))
+	for dir, paths := range imports {
+		seen := map[string]bool{dir: true}
+		for _, path := range paths {
+			dep, ok := local[strings.SplitN(path, " ", 2)[0]]
+			if ok && !seen[dep] {
+				seen[dep] = true
+				deps[dir] = append(deps[dir], dep)
+			}
+		}
+		sort.Strings(deps[dir])
+	}
+	return deps, nil
+}
@@ -0,0 +1,93 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedPackagesFromSubdirectory(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	root := t.TempDir()
+	module := filepath.Join(root, "service")
+	writeFile(t, filepath.Join(module, "go.mod"), "module example.com/service\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(module, "api", "api.go"), "package api\n")
+	writeFile(t, filepath.Join(module, "api", "types.go"), "package api\n")
+	writeFile(t, filepath.Join(module, "cmd", "main.go"), "package main\n")
+
+	got, err := ChangedPackages(context.Background(), module, root, []string{
+		"service/api/api.go",
+		"service/api/types.go",
+		"service/cmd/main.go",
+		"service/README.md",
+		"service/gone/deleted.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"./api", "./cmd"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedPackages = %v, want %v", got, want)
+	}
+}
+
+func TestChangedPackagesWithoutPackageIsAnError(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/x\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(root, "testdata", "bad.go"), "not go\n")
+
+	if _, err := ChangedPackages(context.Background(), root, root, []string{"testdata/bad.go"}); err == nil {
+		t.Error("ChangedPackages expected an error for Go files outside any package")
+	}
+}
+
+func TestPackageDeps(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	t.Setenv("GOWORK", "off")
+
+	module, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(module, "go.mod"), "module example.com/x\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(module, "api", "api.go"), "package api\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/x/model\"\n)\n\nvar _ = fmt.Sprint(model.X)\n")
+	writeFile(t, filepath.Join(module, "api", "api_test.go"), "package api\n\nimport _ \"example.com/x/testutil\"\n")
+	writeFile(t, filepath.Join(module, "model", "model.go"), "package model\n\nimport _ \"example.com/x/types\"\n\nvar X int\n")
+	writeFile(t, filepath.Join(module, "types", "types.go"), "package types\n")
+	writeFile(t, filepath.Join(module, "testutil", "testutil.go"), "package testutil\n")
+
+	api, types := filepath.Join(module, "api"), filepath.Join(module, "types")
+	got, err := PackageDeps(context.Background(), module, []string{api, types})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		api: {filepath.Join(module, "model"), filepath.Join(module, "testutil"), types},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PackageDeps = %v, want %v", got, want)
+	}
+}
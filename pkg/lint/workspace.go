@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// WorkspaceFileName is the file declaring a Go workspace.
+const WorkspaceFileName = "go.work"
+
+// Workspace is a go.work file and the modules it uses.
+type Workspace struct {
+	// Dir is the absolute directory holding go.work.
+	Dir string
+	// Modules are the absolute directories of the modules it uses.
+	Modules []string
+}
+
+// FindWorkspace returns the workspace the go command uses in dir: the one
+// GOWORK names, or else the first go.work found walking up from dir. It
+// returns nil when there is none or GOWORK is off.
+func FindWorkspace(dir string) (*Workspace, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return nil, nil
+	case "", "auto":
+	default:
+		return LoadWorkspace(gowork)
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, WorkspaceFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadWorkspace(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspace reads the go.work file at path.
+func LoadWorkspace(path string) (*Workspace, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Workspace{Dir: filepath.Dir(path)}
+	for _, use := range work.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(w.Dir, dir)
+		}
+		w.Modules = append(w.Modules, filepath.Clean(dir))
+	}
+	sort.Strings(w.Modules)
+	return w, nil
+}
+
+// ModuleOf returns the innermost module of the workspace containing path,
+// or "" when none does.
+func (w *Workspace) ModuleOf(path string) string {
+	found := ""
+	for _, module := range w.Modules {
+		if (path == module || strings.HasPrefix(path, module+string(filepath.Separator))) && len(module) > len(found) {
+			found = module
+		}
+	}
+	return found
+}
+
+// ChangedModules groups changed files, given relative to the repository
+// root, by the innermost workspace module containing them, like the
+// package-level ChangedModules. Files of modules the workspace does not use
+// are dropped.
+func (w *Workspace) ChangedModules(root string, files []string) ([]Module, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	byDir := make(map[string]*Module)
+	for _, file := range files {
+		dir := w.ModuleOf(filepath.Join(root, filepath.FromSlash(file)))
+		if dir == "" {
+			continue
+		}
+		module, ok := byDir[dir]
+		if !ok {
+			module = &Module{Dir: dir}
+			byDir[dir] = module
+		}
+		module.Files = append(module.Files, file)
+	}
+
+	modules := make([]Module, 0, len(byDir))
+	for _, module := range byDir {
+		modules = append(modules, *module)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
@@ -0,0 +1,79 @@
+package lint
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.work"), "go 1.21\n\n// The services.\nuse (\n\t./svc\n\t./svc/tools // nested\n)\n\nuse ./lib\n")
+	writeFile(t, filepath.Join(root, "svc", "api", "api.go"), "package api\n")
+
+	tests := []struct {
+		name   string
+		gowork string
+		dir    string
+		want   *Workspace
+	}{
+		{"found above", "", filepath.Join(root, "svc", "api"), &Workspace{
+			Dir:     root,
+			Modules: []string{filepath.Join(root, "lib"), filepath.Join(root, "svc"), filepath.Join(root, "svc", "tools")},
+		}},
+		{"none", "", t.TempDir(), nil},
+		{"off", "off", root, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOWORK", tt.gowork)
+			got, err := FindWorkspace(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindWorkspace = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindWorkspaceGOWORK(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ci.work")
+	writeFile(t, path, "go 1.21\n\nuse ./app\n")
+	t.Setenv("GOWORK", path)
+
+	got, err := FindWorkspace(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Workspace{Dir: dir, Modules: []string{filepath.Join(dir, "app")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindWorkspace = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorkspaceChangedModules(t *testing.T) {
+	root := t.TempDir()
+	w := &Workspace{Dir: root, Modules: []string{filepath.Join(root, "svc"), filepath.Join(root, "svc", "tools")}}
+
+	got, err := w.ChangedModules(root, []string{
+		"svc/api/api.go",
+		"svc/tools/main.go",
+		"svc/toolsx/x.go",
+		"unused/unused.go",
+		"README.md",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Module{
+		{Dir: filepath.Join(root, "svc"), Files: []string{"svc/api/api.go", "svc/toolsx/x.go"}},
+		{Dir: filepath.Join(root, "svc", "tools"), Files: []string{"svc/tools/main.go"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedModules = %+v, want %+v", got, want)
+	}
+}
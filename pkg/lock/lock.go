@@ -0,0 +1,87 @@
+// Package lock takes advisory locks on files, so that runs sharing a
+// report, an output, the cache or the history database take turns instead
+// of clobbering each other's.
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned by TryAcquire when another process holds the lock.
+var ErrLocked = errors.New("locked by another run")
+
+// pollInterval is how often Acquire tries again.
+const pollInterval = 100 * time.Millisecond
+
+// Lock is a lock held until Release.
+type Lock struct {
+	file *os.File
+}
+
+// DefaultDir is where the lock files live, in the user cache directory but
+// outside of the lint cache, which can be removed while locked.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "linter-locks"), nil
+}
+
+// Path returns the lock file in dir guarding path. It is named after the
+// absolute path, so that nothing is written next to the shared file, which
+// writers replace or truncate.
+func Path(dir, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock"), nil
+}
+
+// TryAcquire locks the file at path, creating it, or fails at once with
+// ErrLocked.
+func TryAcquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Acquire is TryAcquire, waiting for the lock until ctx is done.
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		l, err := TryAcquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrLocked, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release unlocks and closes the lock file, which stays for the next run.
+func (l *Lock) Release() error {
+	return errors.Join(unlockFile(l.file), l.file.Close())
+}
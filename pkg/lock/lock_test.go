@@ -0,0 +1,67 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "a.lock")
+	held, err := TryAcquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second TryAcquire = %v, want ErrLocked", err)
+	}
+	if err := held.Release(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire after Release: %v", err)
+	}
+	again.Release()
+}
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.lock")
+	held, err := TryAcquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*pollInterval)
+	defer cancel()
+	if _, err := Acquire(ctx, path); !errors.Is(err, ErrLocked) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire of a held lock = %v, want ErrLocked once the context is done", err)
+	}
+
+	time.AfterFunc(2*pollInterval, func() { held.Release() })
+	waited, err := Acquire(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Acquire once released: %v", err)
+	}
+	waited.Release()
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Path(dir, "report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs("report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := Path(dir, abs); same != a {
+		t.Errorf("Path of the absolute path = %s, want %s", same, a)
+	}
+	if other, _ := Path(dir, "other.json"); other == a || filepath.Dir(other) != dir {
+		t.Errorf("Path of another file = %s, want a different file in %s", other, dir)
+	}
+}
@@ -0,0 +1,278 @@
+// Package lsp is a minimal language server publishing lint issues as
+// diagnostics over stdio.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/span"
+)
+
+// codeMethodNotFound is the JSON-RPC error for requests we do not serve.
+const codeMethodNotFound = -32601
+
+// CheckFunc returns the issues to publish, with paths relative to the
+// server directory.
+type CheckFunc func() ([]result.Issue, error)
+
+// Server answers one editor session.
+type Server struct {
+	dir   string
+	check CheckFunc
+	out   io.Writer
+	// published remembers the URIs holding diagnostics, so they can be
+	// cleared once their issues are gone.
+	published map[string]bool
+}
+
+// NewServer returns a server publishing the issues of check, resolving
+// their paths against dir.
+func NewServer(dir string, check CheckFunc) *Server {
+	return &Server{
+		dir:       dir,
+		check:     check,
+		published: make(map[string]bool),
+	}
+}
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type diagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type publishParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends exit or closes r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	reader := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch msg.Method {
+		case "initialize":
+			err = s.reply(msg.ID, map[string]interface{}{
+				"capabilities": map[string]interface{}{
+					"textDocumentSync": map[string]interface{}{
+						"openClose": true,
+						"save":      true,
+					},
+				},
+				"serverInfo": map[string]string{"name": "linter"},
+			})
+		case "initialized", "textDocument/didSave":
+			err = s.publish()
+		case "shutdown":
+			err = s.reply(msg.ID, nil)
+		case "exit":
+			return nil
+		default:
+			// Notifications we do not handle are dropped, requests are not.
+			if msg.ID != nil {
+				err = s.write(message{ID: msg.ID, Error: &responseError{
+					Code:    codeMethodNotFound,
+					Message: "method not found: " + msg.Method,
+				}})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// publish runs the check and replaces every diagnostic sent before.
+func (s *Server) publish() error {
+	issues, err := s.check()
+	if err != nil {
+		return s.notify("window/showMessage", map[string]interface{}{
+			"type":    1,
+			"message": "linter: " + err.Error(),
+		})
+	}
+
+	byURI := make(map[string][]diagnostic)
+	for _, issue := range issues {
+		uri := s.uri(issue.FilePath())
+		byURI[uri] = append(byURI[uri], toDiagnostic(issue))
+	}
+	for uri := range s.published {
+		if _, ok := byURI[uri]; !ok {
+			byURI[uri] = []diagnostic{}
+		}
+	}
+
+	uris := make([]string, 0, len(byURI))
+	for uri := range byURI {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	s.published = make(map[string]bool)
+	for _, uri := range uris {
+		if len(byURI[uri]) > 0 {
+			s.published[uri] = true
+		}
+		if err := s.notify("textDocument/publishDiagnostics", publishParams{
+			URI:         uri,
+			Diagnostics: byURI[uri],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) uri(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.dir, path)
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func toDiagnostic(issue result.Issue) diagnostic {
+	// LSP counts characters in UTF-16 code units by default.
+	var line string
+	if len(issue.SourceLines) > 0 {
+		line = issue.SourceLines[0]
+	}
+	start := position{Line: zeroBased(issue.Line())}
+	if issue.Column() > 0 {
+		start.Character = span.UTF16(line, issue.Column()) - 1
+	}
+	end := start
+	if _, column, ok := span.Token(issue); ok {
+		end.Character = span.UTF16(line, column) - 1
+	}
+	return diagnostic{
+		Range:    lspRange{Start: start, End: end},
+		Severity: severity(issue.Severity),
+		Code:     issue.FromLinter,
+		Source:   "golangci-lint",
+		Message:  issue.Text,
+	}
+}
+
+func severity(s string) int {
+	switch s {
+	case "error":
+		return 1
+	case "info", "note":
+		return 3
+	default:
+		return 2
+	}
+}
+
+// zeroBased converts golangci-lint's one-based positions, where 0 means
+// unknown, to LSP's zero-based ones.
+func zeroBased(n int) int {
+	if n > 0 {
+		return n - 1
+	}
+	return 0
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	if result == nil {
+		// A null result must still be present in the response.
+		result = json.RawMessage("null")
+	}
+	return s.write(message{ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(message{Method: method, Params: encoded})
+}
+
+func (s *Server) write(msg message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func readMessage(r *bufio.Reader) (message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return message{}, io.EOF
+		}
+		return message{}, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return message{}, fmt.Errorf("bad Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return message{}, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return message{}, fmt.Errorf("malformed message: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readAll(t *testing.T, out []byte) []message {
+	t.Helper()
+	r := bufio.NewReader(bytes.NewReader(out))
+	var msgs []message
+	for {
+		msg, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return msgs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestServePublishesAndClearsDiagnostics(t *testing.T) {
+	runs := [][]result.Issue{
+		{
+			{FromLinter: "errcheck", Text: "unchecked", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 5}},
+			{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "b.go", Line: 1}},
+		},
+		{
+			{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "b.go", Line: 1}},
+		},
+	}
+	calls := 0
+	server := NewServer("/repo", func() ([]result.Issue, error) {
+		issues := runs[calls]
+		calls++
+		return issues, nil
+	})
+
+	input := frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"initialized","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"file:///repo/b.go"}}}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`) +
+		frame(`{"jsonrpc":"2.0","method":"exit"}`) +
+		frame(`{"jsonrpc":"2.0","method":"initialized"}`)
+
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("check ran %d times, want 2", calls)
+	}
+
+	msgs := readAll(t, out.Bytes())
+	var got []string
+	for _, msg := range msgs {
+		switch {
+		case msg.Method != "":
+			got = append(got, msg.Method+" "+string(msg.Params))
+		case msg.Error != nil:
+			got = append(got, fmt.Sprintf("error %s %d", *msg.ID, msg.Error.Code))
+		default:
+			got = append(got, "result "+string(*msg.ID))
+		}
+	}
+
+	want := []string{
+		"result 1",
+		`textDocument/publishDiagnostics {"uri":"file:///repo/b.go","diagnostics":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"severity":2,"code":"unused","source":"golangci-lint","message":"x is unused"}]}`,
+		`textDocument/publishDiagnostics {"uri":"file:///repo/pkg/a.go","diagnostics":[{"range":{"start":{"line":2,"character":4},"end":{"line":2,"character":4}},"severity":1,"code":"errcheck","source":"golangci-lint","message":"unchecked"}]}`,
+		`textDocument/publishDiagnostics {"uri":"file:///repo/b.go","diagnostics":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"severity":2,"code":"unused","source":"golangci-lint","message":"x is unused"}]}`,
+		`textDocument/publishDiagnostics {"uri":"file:///repo/pkg/a.go","diagnostics":[]}`,
+		"error 2 -32601",
+		"result 3",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d:\n got %s\nwant %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestServeReportsCheckFailures(t *testing.T) {
+	server := NewServer("/repo", func() ([]result.Issue, error) {
+		return nil, errors.New("golangci-lint not found")
+	})
+
+	var out bytes.Buffer
+	input := frame(`{"jsonrpc":"2.0","method":"initialized"}`)
+	if err := server.Serve(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := readAll(t, out.Bytes())
+	if len(msgs) != 1 || msgs[0].Method != "window/showMessage" || !strings.Contains(string(msgs[0].Params), "not found") {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestReadMessageErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"missing length": "Content-Type: x\r\n\r\n{}",
+		"short body":     "Content-Length: 10\r\n\r\n{}",
+		"bad json":       frame("{"),
+	} {
+		if _, err := readMessage(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestToDiagnosticRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		issue      result.Issue
+		start, end position
+	}{
+		{
+			name:  "token after a wide character",
+			issue: result.Issue{Pos: token.Position{Line: 2, Column: 13}, SourceLines: []string{`s := "😀"+x`}},
+			start: position{Line: 1, Character: 10},
+			end:   position{Line: 1, Character: 11},
+		},
+		{
+			name:  "no source",
+			issue: result.Issue{Pos: token.Position{Line: 2, Column: 4}},
+			start: position{Line: 1, Character: 3},
+			end:   position{Line: 1, Character: 3},
+		},
+		{
+			name:  "no column",
+			issue: result.Issue{Pos: token.Position{Line: 2}, SourceLines: []string{"x := 1"}},
+			start: position{Line: 1},
+			end:   position{Line: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toDiagnostic(tt.issue).Range
+			if got.Start != tt.start || got.End != tt.end {
+				t.Errorf("range = %+v, want %+v to %+v", got, tt.start, tt.end)
+			}
+		})
+	}
+}
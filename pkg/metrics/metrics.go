@@ -0,0 +1,156 @@
+// Package metrics exports a run summary as Prometheus gauges, to a file
+// for the textfile collector of node_exporter or to a Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"linter/pkg/summary"
+)
+
+// DefaultJob is the Pushgateway job runs are grouped under.
+const DefaultJob = "linter"
+
+// contentType is that of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// pushTimeout bounds a push, so that an unreachable Pushgateway does not
+// hold up the end of the run.
+const pushTimeout = 5 * time.Second
+
+// Write writes the gauges of s, as of now, in the text exposition format.
+func Write(w io.Writer, s *summary.Summary, now time.Time) error {
+	var buf bytes.Buffer
+	gauge := func(name, help string, samples ...sample) {
+		fmt.Fprintf(&buf, "# HELP linter_%s %s\n# TYPE linter_%s gauge\n", name, help, name)
+		for _, sample := range samples {
+			fmt.Fprintf(&buf, "linter_%s%s %v\n", name, sample.labels, sample.value)
+		}
+	}
+
+	gauge("changed_files", "Files changed.", sample{value: s.Changed})
+	gauge("found_issue_count", "Issues golangci-lint reported, before filtering.", sample{value: s.Found})
+	gauge("filtered_issue_count", "Issues reported after filtering.", sample{value: s.Reported})
+	gauge("filtered_issues_by_linter", "Issues reported after filtering, by linter.", byLabel("linter", s.Linters)...)
+	for _, step := range sortedKeys(s.Durations) {
+		seconds := float64(s.Durations[step]) / 1000
+		gauge(step+"_duration_seconds", "Time the "+step+" step took.", sample{value: seconds})
+	}
+	gauge("exit_code", "Exit code of the run.", sample{value: s.ExitCode})
+	gauge("last_run_timestamp_seconds", "Unix time the run ended.", sample{value: now.Unix()})
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type sample struct {
+	labels string
+	value  any
+}
+
+func byLabel[V any](label string, values map[string]V) []sample {
+	samples := make([]sample, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		samples = append(samples, sample{labels: "{" + label + `="` + escape.Replace(key) + `"}`, value: values[key]})
+	}
+	return samples
+}
+
+// escape escapes label values as the text format requires.
+var escape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteFile writes the gauges of s to path. The file is replaced at once,
+// so the textfile collector never reads it half written.
+func WriteFile(path string, s *summary.Summary, now time.Time) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// The collector only reads *.prom files, so the temporary one is skipped.
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(tmp, s, now); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Pusher sends the gauges of a run to a Pushgateway.
+type Pusher struct {
+	url  string
+	job  string
+	http *http.Client
+}
+
+// NewPusher returns a pusher to the Pushgateway at url, under DefaultJob.
+func NewPusher(url string) *Pusher {
+	return &Pusher{
+		url:  strings.TrimRight(url, "/"),
+		job:  DefaultJob,
+		http: &http.Client{Timeout: pushTimeout},
+	}
+}
+
+// SetJob sets the job the gauges are grouped under.
+func (p *Pusher) SetJob(job string) *Pusher {
+	p.job = job
+	return p
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (p *Pusher) SetHTTPClient(client *http.Client) *Pusher {
+	p.http = client
+	return p
+}
+
+// Push replaces the gauges of the job with those of s, as of now.
+func (p *Pusher) Push(s *summary.Summary, now time.Time) error {
+	var body bytes.Buffer
+	if err := Write(&body, s, now); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, p.url+"/metrics/job/"+url.PathEscape(p.job), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"bytes"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/summary"
+)
+
+func run() *summary.Summary {
+	s := summary.New()
+	s.SetChanged(4)
+	s.SetFound(7)
+	s.SetReported([]result.Issue{
+		{FromLinter: "errcheck", Pos: token.Position{Filename: "a.go"}},
+		{FromLinter: "errcheck", Pos: token.Position{Filename: "b.go"}},
+		{FromLinter: `we"ird`, Pos: token.Position{Filename: "a.go"}},
+	})
+	s.Time("diff", 20*time.Millisecond)
+	s.Time("lint", 1500*time.Millisecond)
+	s.SetExit(1, "3 issues reported")
+	return s
+}
+
+var now = time.Unix(1700000000, 0)
+
+const want = `# HELP linter_changed_files Files changed.
+# TYPE linter_changed_files gauge
+linter_changed_files 4
+# HELP linter_found_issue_count Issues golangci-lint reported, before filtering.
+# TYPE linter_found_issue_count gauge
+linter_found_issue_count 7
+# HELP linter_filtered_issue_count Issues reported after filtering.
+# TYPE linter_filtered_issue_count gauge
+linter_filtered_issue_count 3
+# HELP linter_filtered_issues_by_linter Issues reported after filtering, by linter.
+# TYPE linter_filtered_issues_by_linter gauge
+linter_filtered_issues_by_linter{linter="errcheck"} 2
+linter_filtered_issues_by_linter{linter="we\"ird"} 1
+# HELP linter_diff_duration_seconds Time the diff step took.
+# TYPE linter_diff_duration_seconds gauge
+linter_diff_duration_seconds 0.02
+# HELP linter_lint_duration_seconds Time the lint step took.
+# TYPE linter_lint_duration_seconds gauge
+linter_lint_duration_seconds 1.5
+# HELP linter_exit_code Exit code of the run.
+# TYPE linter_exit_code gauge
+linter_exit_code 1
+# HELP linter_last_run_timestamp_seconds Unix time the run ended.
+# TYPE linter_last_run_timestamp_seconds gauge
+linter_last_run_timestamp_seconds 1700000000
+`
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, run(), now); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "textfile", "linter.prom")
+	if err := WriteFile(path, run(), now); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != want {
+		t.Errorf("file =\n%s\nwant\n%s", content, want)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("directory has %d files, want only linter.prom", len(entries))
+	}
+}
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(content)
+	}))
+	defer server.Close()
+
+	if err := NewPusher(server.URL+"/").SetJob("lint ci").Push(run(), now); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/lint%20ci" || body != want {
+		t.Errorf("request %s %s with body\n%s", method, path, body)
+	}
+}
+
+func TestPushFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad metrics", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	if err := NewPusher(server.URL).Push(run(), now); err == nil {
+		t.Error("expected an error")
+	}
+}
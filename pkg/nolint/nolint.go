@@ -0,0 +1,88 @@
+// Package nolint writes the //nolint directives golangci-lint honors, to
+// suppress an issue where it is reported.
+package nolint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// directivePattern finds a nolint directive and the linters it lists, as
+// in "//nolint:errcheck,govet".
+var directivePattern = regexp.MustCompile(`//\s*nolint(?::([\w-]+(?:,[\w-]+)*))?`)
+
+// Directive returns the comment suppressing linter, explained by reason.
+func Directive(linter, reason string) string {
+	return fmt.Sprintf("//nolint:%s // %s", linter, reason)
+}
+
+// Annotate returns line with linter suppressed by reason. A directive
+// already on the line gets linter added to its list and keeps its own
+// explanation; otherwise one is added at the end of the line, in front of
+// the comment closing it if any.
+func Annotate(line, linter, reason string) (string, error) {
+	if match := directivePattern.FindStringSubmatchIndex(line); match != nil {
+		if match[2] < 0 {
+			return "", errors.New("the line already has a nolint directive for every linter")
+		}
+		linters := strings.Split(line[match[2]:match[3]], ",")
+		if slices.Contains(linters, linter) {
+			return "", fmt.Errorf("the line already has a nolint directive for %s", linter)
+		}
+		return line[:match[3]] + "," + linter + line[match[3]:], nil
+	}
+
+	code, comment := splitComment(line)
+	annotated := strings.TrimRight(code, " \t") + " " + Directive(linter, reason)
+	if comment != "" {
+		annotated += " " + comment
+	}
+	return annotated, nil
+}
+
+// splitComment cuts the line comment ending line off its code, skipping
+// the slashes within string and rune literals.
+func splitComment(line string) (code, comment string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case strings.HasPrefix(line[i:], "//"):
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
+
+// Suppress returns issue with a replacement adding the directive that
+// suppresses it to its line, which the fix package applies.
+func Suppress(issue result.Issue, reason string) (result.Issue, error) {
+	if strings.TrimSpace(reason) == "" {
+		return issue, errors.New("a nolint directive needs a reason")
+	}
+	// The source lines start with the line range of the issue.
+	index := issue.Line() - issue.GetLineRange().From
+	if index < 0 || index >= len(issue.SourceLines) {
+		return issue, fmt.Errorf("%s:%d: no source line to annotate", issue.FilePath(), issue.Line())
+	}
+	line, err := Annotate(issue.SourceLines[index], issue.FromLinter, reason)
+	if err != nil {
+		return issue, fmt.Errorf("%s:%d: %w", issue.FilePath(), issue.Line(), err)
+	}
+	issue.LineRange = nil
+	issue.Replacement = &result.Replacement{NewLines: []string{line}}
+	return issue, nil
+}
@@ -0,0 +1,71 @@
+package nolint
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestAnnotate(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{line: "\tf.Close()", want: "\tf.Close() //nolint:errcheck // closed twice"},
+		{line: "\tf.Close()  ", want: "\tf.Close() //nolint:errcheck // closed twice"},
+		{line: "\tf.Close() // the last use", want: "\tf.Close() //nolint:errcheck // closed twice // the last use"},
+		{line: `	fmt.Fprint(w, "http://a") // log`, want: `	fmt.Fprint(w, "http://a") //nolint:errcheck // closed twice // log`},
+		{line: "\tx := `//`", want: "\tx := `//` //nolint:errcheck // closed twice"},
+		{line: `	c := '\'' // quote`, want: `	c := '\'' //nolint:errcheck // closed twice // quote`},
+		{line: "\tf.Close() //nolint:govet // shadowed", want: "\tf.Close() //nolint:govet,errcheck // shadowed"},
+		{line: "\tf.Close() //nolint:govet,errcheck // both", wantErr: true},
+		{line: "\tf.Close() //nolint", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Annotate(tt.line, "errcheck", "closed twice")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Annotate(%q) error = %v, want error %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Annotate(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	issue := result.Issue{
+		FromLinter:  "errcheck",
+		Pos:         token.Position{Filename: "a.go", Line: 8},
+		LineRange:   &result.Range{From: 7, To: 8},
+		SourceLines: []string{"\tdefer func() {", "\t\tf.Close()"},
+	}
+	tests := []struct {
+		issue   result.Issue
+		reason  string
+		want    string
+		wantErr bool
+	}{
+		{issue: issue, reason: "closed twice", want: "\t\tf.Close() //nolint:errcheck // closed twice"},
+		{issue: issue, reason: " ", wantErr: true},
+		{issue: result.Issue{FromLinter: "errcheck", Pos: token.Position{Filename: "a.go", Line: 8}}, reason: "closed twice", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Suppress(tt.issue, tt.reason)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Suppress(%q) error = %v, want error %v", tt.reason, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if r := got.GetLineRange(); r.From != 8 || r.To != 8 {
+			t.Errorf("Suppress(%q) replaces lines %d-%d, want 8-8", tt.reason, r.From, r.To)
+		}
+		if got.Replacement == nil || len(got.Replacement.NewLines) != 1 || got.Replacement.NewLines[0] != tt.want {
+			t.Errorf("Suppress(%q) replacement = %+v, want %q", tt.reason, got.Replacement, tt.want)
+		}
+	}
+}
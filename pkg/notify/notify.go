@@ -0,0 +1,199 @@
+// Package notify posts a summary of a failed run to a webhook, such as a
+// Slack incoming webhook, so a team hears about it without watching CI.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Payload formats.
+const (
+	FormatSlack = "slack"
+	FormatJSON  = "json"
+)
+
+// Formats lists the payload formats.
+var Formats = []string{FormatSlack, FormatJSON}
+
+// ValidFormat reports whether format is one of Formats.
+func ValidFormat(format string) bool {
+	for _, known := range Formats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TopIssues is how many issues a notification lists.
+const TopIssues = 5
+
+// notifyTimeout bounds a post, so that an unreachable webhook does not hold
+// up the end of the run.
+const notifyTimeout = 10 * time.Second
+
+// Run is the failed run to notify about.
+type Run struct {
+	Branch string
+	Commit string
+	// Issues are those reported, of which the first TopIssues are listed.
+	Issues []result.Issue
+	// Error is why the run failed, when it did not get to report issues.
+	Error string
+	// URL links to the report or the CI job.
+	URL string
+}
+
+// Notifier posts to a webhook.
+type Notifier struct {
+	url    string
+	format string
+	http   *http.Client
+}
+
+// New returns a notifier posting Slack payloads to url.
+func New(url string) *Notifier {
+	return &Notifier{
+		url:    url,
+		format: FormatSlack,
+		http:   &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// SetFormat sets the payload format, one of Formats.
+func (n *Notifier) SetFormat(format string) *Notifier {
+	n.format = format
+	return n
+}
+
+// SetHTTPClient sets the client requests are sent with.
+func (n *Notifier) SetHTTPClient(client *http.Client) *Notifier {
+	n.http = client
+	return n
+}
+
+// Notify posts run to the webhook.
+func (n *Notifier) Notify(run Run) error {
+	var payload interface{}
+	switch n.format {
+	case FormatSlack:
+		payload = slackMessage{Text: slackText(run)}
+	case FormatJSON:
+		payload = newJSONMessage(run)
+	default:
+		return fmt.Errorf("unknown notification format %q", n.format)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.http.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("POST %s: %s: %s", n.url, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// slackText writes run in Slack mrkdwn, escaping what Slack would read as
+// markup.
+func slackText(run Run) string {
+	var b strings.Builder
+	b.WriteString("*Lint failed*")
+	if run.Branch != "" {
+		fmt.Fprintf(&b, " on `%s`", slackEscape(run.Branch))
+	}
+	if run.Commit != "" {
+		fmt.Fprintf(&b, " at `%s`", shortCommit(run.Commit))
+	}
+	if run.Error != "" {
+		fmt.Fprintf(&b, ": %s", slackEscape(run.Error))
+	} else {
+		fmt.Fprintf(&b, ": %d issue(s) on changed lines", len(run.Issues))
+	}
+	if run.URL != "" {
+		fmt.Fprintf(&b, " (<%s|report>)", run.URL)
+	}
+	for _, issue := range top(run.Issues) {
+		fmt.Fprintf(&b, "\n• `%s:%d` %s: %s",
+			slackEscape(filepath.ToSlash(issue.FilePath())), issue.Line(), slackEscape(issue.FromLinter), slackEscape(issue.Text))
+	}
+	if more := len(run.Issues) - TopIssues; more > 0 {
+		fmt.Fprintf(&b, "\n…and %d more", more)
+	}
+	return b.String()
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+type jsonMessage struct {
+	Branch string      `json:"branch,omitempty"`
+	Commit string      `json:"commit,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Count  int         `json:"issue_count"`
+	Issues []jsonIssue `json:"top_issues"`
+	URL    string      `json:"url,omitempty"`
+}
+
+type jsonIssue struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Linter string `json:"linter"`
+	Text   string `json:"text"`
+}
+
+func newJSONMessage(run Run) jsonMessage {
+	message := jsonMessage{
+		Branch: run.Branch,
+		Commit: run.Commit,
+		Error:  run.Error,
+		Count:  len(run.Issues),
+		Issues: []jsonIssue{},
+		URL:    run.URL,
+	}
+	for _, issue := range top(run.Issues) {
+		message.Issues = append(message.Issues, jsonIssue{
+			File:   filepath.ToSlash(issue.FilePath()),
+			Line:   issue.Line(),
+			Linter: issue.FromLinter,
+			Text:   issue.Text,
+		})
+	}
+	return message
+}
+
+func top(issues []result.Issue) []result.Issue {
+	if len(issues) > TopIssues {
+		return issues[:TopIssues]
+	}
+	return issues
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
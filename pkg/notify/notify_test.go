@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestNotify(t *testing.T) {
+	issues := make([]result.Issue, 0, 7)
+	for i := 1; i <= 7; i++ {
+		issues = append(issues, result.Issue{
+			FromLinter: "errcheck", Text: fmt.Sprintf("unchecked <err> %d", i),
+			Pos: token.Position{Filename: "pkg/a.go", Line: i},
+		})
+	}
+	run := Run{Branch: "main", Commit: "0123456789abcdef", Issues: issues, URL: "https://ci.example.com/1"}
+
+	tests := []struct {
+		name   string
+		format string
+		run    Run
+		want   string
+	}{
+		{
+			name: "slack", format: FormatSlack, run: run,
+			want: `{"text":"*Lint failed* on ` + "`main`" + ` at ` + "`0123456`" + `: 7 issue(s) on changed lines (<https://ci.example.com/1|report>)` +
+				`\n• ` + "`pkg/a.go:1`" + ` errcheck: unchecked &lt;err&gt; 1` +
+				`\n• ` + "`pkg/a.go:2`" + ` errcheck: unchecked &lt;err&gt; 2` +
+				`\n• ` + "`pkg/a.go:3`" + ` errcheck: unchecked &lt;err&gt; 3` +
+				`\n• ` + "`pkg/a.go:4`" + ` errcheck: unchecked &lt;err&gt; 4` +
+				`\n• ` + "`pkg/a.go:5`" + ` errcheck: unchecked &lt;err&gt; 5` +
+				`\n…and 2 more"}`,
+		},
+		{
+			name: "slack error", format: FormatSlack, run: Run{Error: "golangci-lint: exit status 3"},
+			want: `{"text":"*Lint failed*: golangci-lint: exit status 3"}`,
+		},
+		{
+			name: "json", format: FormatJSON, run: Run{Commit: "abc", Issues: issues[:1]},
+			want: `{"commit":"abc","issue_count":1,"top_issues":[{"file":"pkg/a.go","line":1,"linter":"errcheck","text":"unchecked <err> 1"}]}`,
+		},
+		{
+			name: "json error", format: FormatJSON, run: Run{Error: "timed out"},
+			want: `{"error":"timed out","issue_count":0,"top_issues":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = string(body)
+			}))
+			defer server.Close()
+
+			if err := New(server.URL).SetFormat(tt.format).Notify(tt.run); err != nil {
+				t.Fatal(err)
+			}
+			var gotJSON, wantJSON interface{}
+			if err := json.Unmarshal([]byte(got), &gotJSON); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantJSON); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotJSON, wantJSON) {
+				t.Errorf("posted:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := New(server.URL).Notify(Run{Error: "failed"})
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("Notify = %v, want the response in the error", err)
+	}
+	if err := New(server.URL).SetFormat("xml").Notify(Run{}); err == nil {
+		t.Error("unknown format expected an error")
+	}
+}
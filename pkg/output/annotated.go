@@ -0,0 +1,163 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+)
+
+var (
+	addedColor   = color.New(color.FgGreen)
+	removedColor = color.New(color.FgRed)
+	hunkColor    = color.New(color.FgCyan)
+)
+
+// AnnotatedDiff prints the diff of the changes with each issue right under
+// its line, behind a "#" no diff line starts with, so one artifact shows
+// both the change and its problems. Issues off the hunks, or in files whose
+// diff is not known, follow the hunks of their file.
+type AnnotatedDiff struct {
+	links
+	w       io.Writer
+	changes []diff.FileChange
+}
+
+func NewAnnotatedDiff(w io.Writer) Printer {
+	return &AnnotatedDiff{w: w}
+}
+
+// SetChanges sets the changed files whose diff is printed.
+func (a *AnnotatedDiff) SetChanges(changes []diff.FileChange) *AnnotatedDiff {
+	a.changes = changes
+	return a
+}
+
+func (a *AnnotatedDiff) Print(issues []result.Issue) error {
+	var paths []string
+	byPath := make(map[string][]result.Issue)
+	for _, issue := range issues {
+		path := filepath.ToSlash(issue.FilePath())
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], issue)
+	}
+
+	var b strings.Builder
+	for _, change := range a.changes {
+		fileIssues, ok := byPath[change.Path]
+		if len(change.Patch) == 0 && !ok {
+			continue
+		}
+		delete(byPath, change.Path)
+		a.writeFile(&b, change, fileIssues)
+	}
+	for _, path := range paths {
+		if fileIssues, ok := byPath[path]; ok {
+			a.writeFile(&b, diff.FileChange{Path: path}, fileIssues)
+		}
+	}
+	_, err := io.WriteString(a.w, b.String())
+	return err
+}
+
+// writeFile writes the header and hunks of change, each issue under the
+// new line it is on, then the issues left.
+func (a *AnnotatedDiff) writeFile(b *strings.Builder, change diff.FileChange, issues []result.Issue) {
+	oldPath := "a/" + firstNonEmpty(change.OldPath, change.Path)
+	if len(change.Patch) == 1 && change.Patch[0].OldStart == 0 && change.Patch[0].OldCount == 0 {
+		oldPath = "/dev/null"
+	}
+	fmt.Fprintln(b, positionColor.Sprint("--- "+oldPath))
+	fmt.Fprintln(b, positionColor.Sprint("+++ b/"+change.Path))
+
+	byLine := make(map[int][]result.Issue)
+	for _, issue := range issues {
+		byLine[issue.Line()] = append(byLine[issue.Line()], issue)
+	}
+	for _, hunk := range change.Patch {
+		fmt.Fprintln(b, hunkColor.Sprintf("@@ -%s +%s @@", hunkRange(hunk.OldStart, hunk.OldCount), hunkRange(hunk.NewStart, hunk.NewCount)))
+		line := hunk.NewStart
+		for _, body := range hunk.Lines {
+			if body == "" {
+				// Some tools trim the space of empty context lines.
+				body = " "
+			}
+			switch {
+			case strings.HasPrefix(body, "+"):
+				fmt.Fprintln(b, addedColor.Sprint(body))
+			case strings.HasPrefix(body, "-"):
+				fmt.Fprintln(b, removedColor.Sprint(body))
+				continue
+			case strings.HasPrefix(body, `\`):
+				fmt.Fprintln(b, body)
+				continue
+			default:
+				fmt.Fprintln(b, body)
+			}
+			for _, issue := range byLine[line] {
+				a.writeAnnotation(b, issue, body)
+			}
+			delete(byLine, line)
+			line++
+		}
+	}
+	for _, issue := range issues {
+		if _, ok := byLine[issue.Line()]; ok {
+			a.writeAnnotation(b, issue, "")
+		}
+	}
+}
+
+// writeAnnotation writes issue with a caret under its column of body, the
+// diff line it is on, or with its position when it is on no diff line.
+func (a *AnnotatedDiff) writeAnnotation(b *strings.Builder, issue result.Issue, body string) {
+	marker := fmt.Sprintf(" %d", issue.Line())
+	if issue.Column() != 0 {
+		marker += fmt.Sprintf(":%d", issue.Column())
+	}
+	if body != "" {
+		// Keep the tabs of the line so the caret lines up under the
+		// column, counted in characters past the diff prefix.
+		var prefix strings.Builder
+		for i, r := range body[1:] {
+			if i >= issue.Column()-1 {
+				break
+			}
+			if r == '\t' {
+				prefix.WriteByte('\t')
+			} else {
+				prefix.WriteByte(' ')
+			}
+		}
+		marker = prefix.String() + caretColor.Sprint("^")
+	}
+	fmt.Fprintf(b, "#%s %s: %s\n", marker, linterColor.Sprint(issue.FromLinter),
+		severityColor(issue.Severity).Sprint(strings.TrimSpace(issue.Text)))
+	if url := a.url(issue); url != "" {
+		fmt.Fprintf(b, "# %s\n", url)
+	}
+}
+
+// hunkRange writes start and count as git does, leaving out a count of 1.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
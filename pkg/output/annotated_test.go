@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+)
+
+func TestAnnotatedDiff(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = true
+
+	changes := []diff.FileChange{
+		{Path: "a.go", Patch: []diff.Hunk{{
+			OldStart: 3, OldCount: 3, NewStart: 3, NewCount: 4,
+			Lines: []string{" func f() {", "-\tg()", "+\tf, _ := os.Open(name)", "+\tf.Close()", "", " }"},
+		}}},
+		{Path: "new.go", Patch: []diff.Hunk{{
+			NewStart: 1, NewCount: 1, Lines: []string{"+package a"},
+		}}},
+		{Path: "README.md", OldPath: "README", Patch: []diff.Hunk{{
+			OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1, Lines: []string{"-# Old", "+# New"},
+		}}},
+		{Path: "merged.go"},
+	}
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "Error return value of `f.Close` is not checked", Pos: token.Position{Filename: "a.go", Line: 5, Column: 9}},
+		{FromLinter: "ineffassign", Text: "ineffectual assignment to f", Pos: token.Position{Filename: "a.go", Line: 4, Column: 2}},
+		{FromLinter: "lll", Text: "line is 130 characters", Pos: token.Position{Filename: "a.go", Line: 40}},
+		{FromLinter: "revive", Text: "should have a package comment", Pos: token.Position{Filename: "new.go", Line: 1, Column: 1}},
+		{FromLinter: "gocritic", Text: "unnamed result", Pos: token.Position{Filename: "merged.go", Line: 2, Column: 6}},
+	}
+	var buf bytes.Buffer
+	printer := NewAnnotatedDiff(&buf).(*AnnotatedDiff).SetChanges(changes)
+	printer.SetLink(func(issue result.Issue) string {
+		if issue.FromLinter != "revive" {
+			return ""
+		}
+		return "https://github.com/org/repo/blob/abc/new.go#L1"
+	})
+	if err := printer.Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--- a/a.go\n+++ b/a.go\n" +
+		"@@ -3,3 +3,4 @@\n" +
+		" func f() {\n" +
+		"-\tg()\n" +
+		"+\tf, _ := os.Open(name)\n" +
+		"#\t^ ineffassign: ineffectual assignment to f\n" +
+		"+\tf.Close()\n" +
+		"#\t       ^ errcheck: Error return value of `f.Close` is not checked\n" +
+		" \n" +
+		" }\n" +
+		"# 40 lll: line is 130 characters\n" +
+		"--- /dev/null\n+++ b/new.go\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+package a\n" +
+		"#^ revive: should have a package comment\n" +
+		"# https://github.com/org/repo/blob/abc/new.go#L1\n" +
+		"--- a/README\n+++ b/README.md\n" +
+		"@@ -1 +1 @@\n" +
+		"-# Old\n" +
+		"+# New\n" +
+		"--- a/merged.go\n+++ b/merged.go\n" +
+		"# 2:6 gocritic: unnamed result\n"
+	if buf.String() != want {
+		t.Errorf("printed:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
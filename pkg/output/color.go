@@ -0,0 +1,54 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ColorModes are the accepted values of --color.
+var ColorModes = []string{"auto", "always", "never"}
+
+// SetColor turns the colors of the text output on or off. In auto mode
+// they are on only when out is a terminal, $TERM is not dumb and $NO_COLOR
+// is empty, as https://no-color.org asks.
+func SetColor(mode string, out *os.File) error {
+	switch mode {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	case "auto":
+		color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !IsTerminal(out)
+	default:
+		return fmt.Errorf("--color %q is not one of %s", mode, strings.Join(ColorModes, ", "))
+	}
+	return nil
+}
+
+// IsTerminal tells whether f is a terminal rather than a file or a pipe.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var (
+	positionColor = color.New(color.Bold)
+	linterColor   = color.New(color.FgCyan)
+	caretColor    = color.New(color.FgYellow)
+)
+
+// severityColor colors the message of an issue. Issues without a severity
+// stay red, as golangci-lint prints them.
+func severityColor(severity string) *color.Color {
+	switch severity {
+	case "warning":
+		return color.New(color.FgYellow)
+	case "info", "note":
+		return color.New(color.FgBlue)
+	default:
+		return color.New(color.FgRed)
+	}
+}
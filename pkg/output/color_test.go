@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestSetColor(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		mode, noColorEnv string
+		wantNoColor      bool
+	}{
+		{"always", "1", false},
+		{"never", "", true},
+		{"auto", "", true}, // a file is no terminal
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColorEnv)
+		if err := SetColor(tt.mode, file); err != nil {
+			t.Fatal(err)
+		}
+		if color.NoColor != tt.wantNoColor {
+			t.Errorf("SetColor(%q) with NO_COLOR=%q: NoColor = %v", tt.mode, tt.noColorEnv, color.NoColor)
+		}
+	}
+
+	if err := SetColor("sometimes", file); err == nil {
+		t.Error("SetColor of an unknown mode expected an error")
+	}
+}
+
+func TestTextColors(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = false
+
+	issue := result.Issue{FromLinter: "errcheck", Text: "unchecked", Severity: "warning", SourceLines: []string{"\tf()"}, Pos: token.Position{Filename: "a.go", Line: 4, Column: 2}}
+	var buf bytes.Buffer
+	if err := NewText(&buf).Print([]result.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"\x1b[1ma.go:4\x1b[0m:2",    // bold position
+		"\x1b[33munchecked\x1b[0m",  // yellow warning
+		"(\x1b[36merrcheck\x1b[0m)", // cyan linter
+		"\t\x1b[33m^\x1b[0m\n",      // caret under the column
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output %q lacks %q", buf.String(), want)
+		}
+	}
+
+	color.NoColor = true
+	buf.Reset()
+	if err := NewText(&buf).Print([]result.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a.go:4:2: unchecked (errcheck)\n\tf()\n\t^\n"; buf.String() != want {
+		t.Errorf("without colors got %q, want %q", buf.String(), want)
+	}
+}
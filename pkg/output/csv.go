@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+// csvHeader names the columns of the csv and tsv formats.
+var csvHeader = []string{"file", "line", "column", "linter", "severity", "message", "fingerprint"}
+
+// CSV writes a header and one row per issue, for spreadsheets and BI
+// tools. Messages spanning lines are quoted, not split. With links, a url
+// column comes last.
+type CSV struct {
+	links
+	w     io.Writer
+	comma rune
+}
+
+func NewCSV(w io.Writer) Printer {
+	return &CSV{w: w, comma: ','}
+}
+
+// NewTSV writes the rows of the csv format separated by tabs.
+func NewTSV(w io.Writer) Printer {
+	return &CSV{w: w, comma: '\t'}
+}
+
+func (c *CSV) Print(issues []result.Issue) error {
+	w := csv.NewWriter(c.w)
+	w.Comma = c.comma
+	header := csvHeader
+	if c.link != nil {
+		header = append(header[:len(header):len(header)], "url")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		column := ""
+		if issue.Column() > 0 {
+			column = strconv.Itoa(issue.Column())
+		}
+		record := []string{
+			filepath.ToSlash(issue.FilePath()),
+			strconv.Itoa(issue.Line()),
+			column,
+			issue.FromLinter,
+			issue.Severity,
+			issue.Text,
+			fingerprint.Of(issue),
+		}
+		if c.link != nil {
+			record = append(record, c.url(issue))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+func TestCSV(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "staticcheck", Text: `SA1019: "strings.Title" is deprecated, use cases`, Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 2}},
+		{FromLinter: "lll", Text: "line is 130 characters", Pos: token.Position{Filename: "b.go", Line: 9}},
+	}
+	tests := []struct {
+		format string
+		comma  rune
+		want   string
+	}{
+		{"csv", ',', "file,line,column,linter,severity,message,fingerprint\n" +
+			`pkg/a.go,3,2,staticcheck,error,"SA1019: ""strings.Title"" is deprecated, use cases",` + fingerprint.Of(issues[0]) + "\n" +
+			"b.go,9,,lll,,line is 130 characters," + fingerprint.Of(issues[1]) + "\n"},
+		{"tsv", '\t', "file\tline\tcolumn\tlinter\tseverity\tmessage\tfingerprint\n" +
+			"pkg/a.go\t3\t2\tstaticcheck\terror\t\"SA1019: \"\"strings.Title\"\" is deprecated, use cases\"\t" + fingerprint.Of(issues[0]) + "\n" +
+			"b.go\t9\t\tlll\t\tline is 130 characters\t" + fingerprint.Of(issues[1]) + "\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		printer, err := New(tt.format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := printer.Print(issues); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s printed\n%s\nwant\n%s", tt.format, buf.String(), tt.want)
+		}
+
+		r := csv.NewReader(strings.NewReader(buf.String()))
+		r.Comma = tt.comma
+		rows, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%s does not read back: %v", tt.format, err)
+		}
+		if len(rows) != 3 || rows[1][5] != issues[0].Text {
+			t.Errorf("%s reads back as %q", tt.format, rows)
+		}
+	}
+}
+
+func TestCSVWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCSV(&buf).Print(nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "file,line,column,linter,severity,message,fingerprint\n" {
+		t.Errorf("got %q, want the header alone", buf.String())
+	}
+}
@@ -0,0 +1,26 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/gerrit"
+)
+
+// Gerrit writes the issues as a review of robot comments, the body of
+// Gerrit's "set review" REST call.
+type Gerrit struct {
+	w io.Writer
+}
+
+func NewGerrit(w io.Writer) Printer {
+	return &Gerrit{w: w}
+}
+
+func (g *Gerrit) Print(issues []result.Issue) error {
+	encoder := json.NewEncoder(g.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(gerrit.NewReviewInput(issues))
+}
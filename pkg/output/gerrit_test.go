@@ -0,0 +1,36 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/gerrit"
+)
+
+func TestGerritRobotComments(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 3}},
+		{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "pkg/a.go", Line: 9}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewGerrit(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	var got gerrit.ReviewInput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	comments := got.RobotComments["pkg/a.go"]
+	if len(got.RobotComments) != 1 || len(comments) != 2 {
+		t.Fatalf("robot comments = %+v", got.RobotComments)
+	}
+	if c := comments[0]; c.Line != 3 || c.Message != "errcheck: unchecked" || c.RobotID != gerrit.RobotID || c.Properties["severity"] != "error" {
+		t.Errorf("first comment = %+v", c)
+	}
+}
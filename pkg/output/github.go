@@ -0,0 +1,92 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/span"
+)
+
+const stepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+type GitHubActions struct {
+	w io.Writer
+}
+
+func NewGitHubActions(w io.Writer) Printer {
+	return &GitHubActions{w: w}
+}
+
+func (g *GitHubActions) Print(issues []result.Issue) error {
+	for _, issue := range issues {
+		properties := []string{
+			"file=" + escapeProperty(issue.FilePath()),
+			fmt.Sprintf("line=%d", issue.Line()),
+		}
+		if issue.Column() > 0 {
+			line := firstLine(issue)
+			properties = append(properties, fmt.Sprintf("col=%d", span.Runes(line, issue.Column())))
+			if _, end, ok := span.Token(issue); ok {
+				properties = append(properties, fmt.Sprintf("endColumn=%d", span.Runes(line, end)))
+			}
+		}
+		properties = append(properties, "title="+escapeProperty(issue.FromLinter))
+
+		if _, err := fmt.Fprintf(g.w, "::%s %s::%s\n",
+			githubLevel(issue.Severity),
+			strings.Join(properties, ","),
+			escapeData(issue.Text),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendStepSummary adds the Markdown report of the issues to the job summary
+// GitHub Actions renders from the file named by $GITHUB_STEP_SUMMARY, with a
+// footer counting the hidden issues left out of them.
+func AppendStepSummary(issues []result.Issue, hidden int) error {
+	path := os.Getenv(stepSummaryEnv)
+	if path == "" {
+		return errors.New(stepSummaryEnv + " is not set")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	summary := NewMarkdown(file).(*Markdown)
+	summary.SetHidden(hidden)
+	return summary.Print(issues)
+}
+
+func githubLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "info", "note":
+		return "notice"
+	default:
+		return "warning"
+	}
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ", "<", "&lt;").Replace(s)
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestGitHubActions(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue result.Issue
+		want  string
+	}{
+		{
+			name:  "warning by default",
+			issue: result.Issue{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}},
+			want:  "::warning file=a.go,line=3,title=errcheck::unchecked\n",
+		},
+		{
+			name:  "error",
+			issue: result.Issue{FromLinter: "govet", Text: "bad verb", Severity: "error", Pos: token.Position{Filename: "a.go", Line: 1}},
+			want:  "::error file=a.go,line=1,title=govet::bad verb\n",
+		},
+		{
+			name:  "notice",
+			issue: result.Issue{FromLinter: "godot", Text: "no period", Severity: "info", Pos: token.Position{Filename: "a.go", Line: 1}},
+			want:  "::notice file=a.go,line=1,title=godot::no period\n",
+		},
+		{
+			name: "columns",
+			issue: result.Issue{
+				FromLinter: "unused", Text: "x is unused",
+				Pos:         token.Position{Filename: "a.go", Line: 2, Column: 5},
+				SourceLines: []string{"var ñame = 1"},
+			},
+			want: "::warning file=a.go,line=2,col=5,endColumn=9,title=unused::x is unused\n",
+		},
+		{
+			name: "escaped",
+			issue: result.Issue{
+				FromLinter: "lint:er,x", Text: "100% wrong\r\nsee: a, b",
+				Pos: token.Position{Filename: "dir,1/a:b%.go", Line: 1},
+			},
+			want: "::warning file=dir%2C1/a%3Ab%25.go,line=1,title=lint%3Aer%2Cx::100%25 wrong%0D%0Asee: a, b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewGitHubActions(&buf).Print([]result.Issue{tt.issue}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendStepSummary(t *testing.T) {
+	issues := []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 1}}}
+
+	tests := []struct {
+		name     string
+		existing string
+		hidden   int
+		want     []string
+	}{
+		{name: "new file", want: []string{"### Lint: 1 issue(s)", "| a.go | 1 | errcheck | unchecked |"}},
+		{name: "appended", existing: "## Tests\n\n", want: []string{"## Tests\n\n### Lint"}},
+		{name: "hidden", hidden: 4, want: []string{"… and 4 more issue(s)\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "summary.md")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv(stepSummaryEnv, path)
+
+			if err := AppendStepSummary(issues, tt.hidden); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("summary does not contain %q:\n%s", want, content)
+				}
+			}
+			if tt.hidden == 0 && strings.Contains(string(content), "more issue(s)") {
+				t.Errorf("summary counts hidden issues:\n%s", content)
+			}
+		})
+	}
+
+	t.Setenv(stepSummaryEnv, "")
+	if err := AppendStepSummary(issues, 0); err == nil {
+		t.Error("AppendStepSummary without $GITHUB_STEP_SUMMARY expected an error")
+	}
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"go/scanner"
+	"go/token"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+// HTML writes a standalone page grouping issues per file, with their
+// highlighted source lines and a breakdown per linter and severity.
+type HTML struct {
+	links
+	w       io.Writer
+	groupBy string
+}
+
+func NewHTML(w io.Writer) Printer {
+	return &HTML{w: w, groupBy: "file"}
+}
+
+// SetGroupBy groups the issues by linter or package instead, one of
+// GroupKeys; the issues then name their file.
+func (h *HTML) SetGroupBy(key string) *HTML {
+	h.groupBy = key
+	return h
+}
+
+type htmlReport struct {
+	Total      int
+	Severities []htmlCount
+	Linters    []htmlCount
+	Files      []htmlFile
+	ShowFile   bool
+}
+
+type htmlCount struct {
+	Name    string
+	Count   int
+	Percent int
+}
+
+type htmlFile struct {
+	Path   string
+	Issues []htmlIssue
+}
+
+type htmlIssue struct {
+	File         string
+	Line, Column int
+	Linter       string
+	Severity     string
+	Text         string
+	ID           string
+	URL          string
+	Source       []htmlLine
+}
+
+type htmlLine struct {
+	Number int
+	Tokens []htmlToken
+}
+
+type htmlToken struct {
+	Class string
+	Text  string
+}
+
+func (h *HTML) Print(issues []result.Issue) error {
+	report := htmlReport{Total: len(issues), ShowFile: h.groupBy != "file"}
+
+	severities := make(map[string]int)
+	linters := make(map[string]int)
+	indexes := make(map[string]int)
+	for _, issue := range issues {
+		severity := issue.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		severities[severity]++
+		linters[issue.FromLinter]++
+
+		name := groupName(issue, h.groupBy)
+		index, ok := indexes[name]
+		if !ok {
+			index = len(report.Files)
+			indexes[name] = index
+			report.Files = append(report.Files, htmlFile{Path: name})
+		}
+
+		source := make([]htmlLine, 0, len(issue.SourceLines))
+		for i, line := range issue.SourceLines {
+			source = append(source, htmlLine{Number: issue.Line() + i, Tokens: highlight(line)})
+		}
+		report.Files[index].Issues = append(report.Files[index].Issues, htmlIssue{
+			File:     filepath.ToSlash(issue.FilePath()),
+			Line:     issue.Line(),
+			Column:   issue.Column(),
+			Linter:   issue.FromLinter,
+			Severity: severity,
+			Text:     issue.Text,
+			ID:       fingerprint.Short(issue),
+			URL:      h.url(issue),
+			Source:   source,
+		})
+	}
+	report.Severities = counts(severities, len(issues))
+	report.Linters = counts(linters, len(issues))
+
+	return htmlTemplate.Execute(h.w, report)
+}
+
+// counts orders the tallies by decreasing count, then by name.
+func counts(tally map[string]int, total int) []htmlCount {
+	list := make([]htmlCount, 0, len(tally))
+	for name, count := range tally {
+		list = append(list, htmlCount{Name: name, Count: count, Percent: count * 100 / total})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// highlight splits one line of Go into tokens classed for the page styles.
+// A line cut out of a longer construct, such as a raw string, may not scan
+// cleanly; whatever the scanner gives up on is kept as plain text.
+func highlight(line string) []htmlToken {
+	src := []byte(line)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var tokens []htmlToken
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit != ";" {
+			// Inserted automatically at the end of the line.
+			continue
+		}
+		offset := file.Offset(pos)
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		if offset < last || offset+len(text) > len(src) {
+			break
+		}
+		if offset > last {
+			tokens = append(tokens, htmlToken{Text: line[last:offset]})
+		}
+		tokens = append(tokens, htmlToken{Class: tokenClass(tok), Text: line[offset : offset+len(text)]})
+		last = offset + len(text)
+	}
+	if last < len(line) {
+		tokens = append(tokens, htmlToken{Text: line[last:]})
+	}
+	return tokens
+}
+
+func tokenClass(tok token.Token) string {
+	switch {
+	case tok.IsKeyword():
+		return "kw"
+	case tok == token.STRING || tok == token.CHAR:
+		return "str"
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return "num"
+	case tok == token.COMMENT:
+		return "com"
+	default:
+		return ""
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Lint report: {{.Total}} issue(s)</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { font-size: 1.5rem; }
+h2 { font-size: 1.1rem; margin-top: 2rem; font-family: ui-monospace, monospace; }
+table.chart { border-collapse: collapse; margin-bottom: 1rem; }
+table.chart td { padding: 0.15rem 0.5rem; }
+.bar { background: #0969da; height: 0.8rem; min-width: 2px; }
+.issue { border: 1px solid #d0d7de; border-radius: 6px; margin: 0.75rem 0; padding: 0.5rem 0.75rem; }
+.badge { border-radius: 1rem; color: #fff; font-size: 0.75rem; padding: 0.1rem 0.5rem; background: #9a6700; }
+.badge.error { background: #cf222e; }
+.badge.info, .badge.note { background: #0969da; }
+.linter, .id { color: #59636e; }
+pre { background: #f6f8fa; padding: 0.5rem; overflow-x: auto; margin: 0.5rem 0 0; }
+.num-line { color: #8c959f; display: inline-block; min-width: 3rem; user-select: none; }
+.kw { color: #cf222e; } .str { color: #0a3069; } .num { color: #0550ae; } .com { color: #6e7781; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Total}} issue(s) on changed lines</h1>
+{{- if .Total}}
+<h3>Severity</h3>
+<table class="chart">
+{{- range .Severities}}
+<tr><td><span class="badge {{.Name}}">{{.Name}}</span></td><td>{{.Count}}</td><td><div class="bar" style="width: {{.Percent}}%"></div></td></tr>
+{{- end}}
+</table>
+<h3>Linters</h3>
+<table class="chart">
+{{- range .Linters}}
+<tr><td>{{.Name}}</td><td>{{.Count}}</td><td style="width: 20rem"><div class="bar" style="width: {{.Percent}}%"></div></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Files}}
+<h2>{{.Path}}</h2>
+{{- range .Issues}}
+<div class="issue">
+<span class="badge {{.Severity}}">{{.Severity}}</span> {{if $.ShowFile}}<code>{{.File}}</code> {{end}}{{if .URL}}<a href="{{.URL}}">line {{.Line}}</a>{{else}}line {{.Line}}{{end}}{{if .Column}}:{{.Column}}{{end}} <span class="linter">{{.Linter}}</span> <code class="id" title="fingerprint">{{.ID}}</code>
+<div>{{.Text}}</div>
+{{- if .Source}}
+<pre>{{range .Source}}<span class="num-line">{{.Number}}</span>{{range .Tokens}}{{if .Class}}<span class="{{.Class}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}
+{{end}}</pre>
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestHTMLGroupsByFile(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Severity: "error", SourceLines: []string{"\tdefer f.Close()"}, Pos: token.Position{Filename: "b.go", Line: 3}},
+		{FromLinter: "govet", Text: "<printf> & friends", Pos: token.Position{Filename: "a.go", Line: 7, Column: 2}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "b.go", Line: 9}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewHTML(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"<h1>3 issue(s) on changed lines</h1>",
+		`<span class="badge error">error</span> line 3 <span class="linter">errcheck</span>`,
+		"&lt;printf&gt; &amp; friends",
+		`<span class="num-line">3</span>	<span class="kw">defer</span> f.Close()`,
+		`<tr><td>errcheck</td><td>2</td><td style="width: 20rem"><div class="bar" style="width: 66%"></div></td></tr>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Index(page, "<h2>b.go</h2>") > strings.Index(page, "<h2>a.go</h2>") {
+		t.Error("files are not in the order of their first issue")
+	}
+	if strings.Count(page, "<h2>") != 2 {
+		t.Errorf("want one section per file:\n%s", page)
+	}
+}
+
+func TestHTMLGroupsByLinter(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "b.go", Line: 3}},
+		{FromLinter: "govet", Text: "unreachable code", Pos: token.Position{Filename: "a.go", Line: 7}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 9}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewHTML(&buf).(*HTML).SetGroupBy("linter").Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	if strings.Count(page, "<h2>") != 2 || strings.Index(page, "<h2>errcheck</h2>") > strings.Index(page, "<h2>govet</h2>") {
+		t.Errorf("want one section per linter, errcheck first:\n%s", page)
+	}
+	if !strings.Contains(page, `<code>a.go</code> line 9 <span class="linter">errcheck</span>`) {
+		t.Errorf("issues grouped by linter do not name their file:\n%s", page)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := highlight(`	return fmt.Sprintf("%d", 42) // done`)
+	want := []htmlToken{
+		{Text: "\t"},
+		{Class: "kw", Text: "return"},
+		{Text: " "},
+		{Text: "fmt"},
+		{Text: "."},
+		{Text: "Sprintf"},
+		{Text: "("},
+		{Class: "str", Text: `"%d"`},
+		{Text: ","},
+		{Text: " "},
+		{Class: "num", Text: "42"},
+		{Text: ")"},
+		{Text: " "},
+		{Class: "com", Text: "// done"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("highlight = %q", got)
+	}
+
+	// A line inside a raw string does not scan, but keeps its text.
+	var text strings.Builder
+	for _, tok := range highlight("still `inside a raw string") {
+		text.WriteString(tok.Text)
+	}
+	if text.String() != "still `inside a raw string" {
+		t.Errorf("highlight lost text: %q", text.String())
+	}
+}
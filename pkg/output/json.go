@@ -0,0 +1,92 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/span"
+)
+
+type jsonReport struct {
+	Issues []jsonIssue `json:"issues"`
+}
+
+type jsonIssue struct {
+	Fingerprint string   `json:"fingerprint"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column,omitempty"`
+	EndColumn   int      `json:"end_column,omitempty"`
+	Offset      int      `json:"offset,omitempty"`
+	Linter      string   `json:"linter"`
+	Rule        string   `json:"rule,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Message     string   `json:"message"`
+	URL         string   `json:"url,omitempty"`
+	SourceLines []string `json:"source_lines,omitempty"`
+}
+
+// JSON writes the issues with their fingerprints, for scripts that track
+// issues across runs.
+type JSON struct {
+	links
+	w io.Writer
+}
+
+func NewJSON(w io.Writer) Printer {
+	return &JSON{w: w}
+}
+
+func (j *JSON) Print(issues []result.Issue) error {
+	report := jsonReport{Issues: make([]jsonIssue, 0, len(issues))}
+	for _, issue := range issues {
+		report.Issues = append(report.Issues, newJSONIssue(issue, j.url(issue)))
+	}
+
+	encoder := json.NewEncoder(j.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// JSONL writes the issues of the json format one per line, so they can be
+// printed as they are found and read before the run ends.
+type JSONL struct {
+	links
+	w io.Writer
+}
+
+func NewJSONL(w io.Writer) Printer {
+	return &JSONL{w: w}
+}
+
+func (j *JSONL) Print(issues []result.Issue) error {
+	encoder := json.NewEncoder(j.w)
+	for _, issue := range issues {
+		if err := encoder.Encode(newJSONIssue(issue, j.url(issue))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newJSONIssue(issue result.Issue, url string) jsonIssue {
+	_, end, _ := span.Token(issue)
+	return jsonIssue{
+		Fingerprint: fingerprint.Of(issue),
+		File:        filepath.ToSlash(issue.FilePath()),
+		Line:        issue.Line(),
+		Column:      issue.Column(),
+		EndColumn:   end,
+		Offset:      issue.Pos.Offset,
+		Linter:      issue.FromLinter,
+		Rule:        fingerprint.Rule(issue),
+		Severity:    issue.Severity,
+		Message:     issue.Text,
+		URL:         url,
+		SourceLines: issue.SourceLines,
+	}
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+func TestJSONCarriesFingerprints(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "staticcheck", Text: "SA1019: deprecated", Severity: "error", SourceLines: []string{"\tstrings.Title(s)"}, Pos: token.Position{Filename: "a.go", Line: 3, Column: 2}},
+		{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "b.go", Line: 4}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSON(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Issues) != 2 {
+		t.Fatalf("issues = %+v", got.Issues)
+	}
+	first := got.Issues[0]
+	if first.Fingerprint != fingerprint.Of(issues[0]) || first.Rule != "SA1019" || first.Line != 3 || first.Column != 2 {
+		t.Errorf("first issue = %+v", first)
+	}
+	if got.Issues[1].Fingerprint == first.Fingerprint {
+		t.Error("different issues share a fingerprint")
+	}
+}
+
+func TestJSONWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewJSON(&buf).Print(nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\n  \"issues\": []\n}\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestJSONLWritesOneIssuePerLine(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "staticcheck", Text: "SA1019: deprecated", Pos: token.Position{Filename: "a.go", Line: 3, Column: 2}},
+		{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "b.go", Line: 4}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSONL(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != len(issues) {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var got jsonIssue
+		if err := json.Unmarshal(line, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Fingerprint != fingerprint.Of(issues[i]) || got.File != issues[i].FilePath() || got.Line != issues[i].Line() {
+			t.Errorf("line %d = %+v", i+1, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnit writes issues as failed test cases, one suite per file, for CI
+// systems that render JUnit test reports.
+type JUnit struct {
+	links
+	w io.Writer
+}
+
+func NewJUnit(w io.Writer) Printer {
+	return &JUnit{w: w}
+}
+
+func (j *JUnit) Print(issues []result.Issue) error {
+	suites := junitTestSuites{Tests: len(issues), Failures: len(issues)}
+
+	indexes := make(map[string]int)
+	for _, issue := range issues {
+		path := filepath.ToSlash(issue.FilePath())
+		index, ok := indexes[path]
+		if !ok {
+			index = len(suites.Suites)
+			indexes[path] = index
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: path})
+		}
+
+		content := fmt.Sprintf("%s:%d:%d: %s (%s)", path, issue.Line(), issue.Column(), issue.Text, issue.FromLinter)
+		if url := j.url(issue); url != "" {
+			content += "\n" + url
+		}
+		suite := &suites.Suites[index]
+		suite.Tests++
+		suite.Failures++
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      fmt.Sprintf("%s:%d:%d", path, issue.Line(), issue.Column()),
+			ClassName: issue.FromLinter,
+			Failure: junitFailure{
+				Message: issue.Text,
+				Type:    issue.Severity,
+				Content: content,
+			},
+		})
+	}
+
+	if _, err := io.WriteString(j.w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(j.w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n")
+	return err
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestJUnitGroupsByFile(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Severity: "error", Pos: token.Position{Filename: "a.go", Line: 1, Column: 2}},
+		{FromLinter: "unused", Text: "x is unused", Pos: token.Position{Filename: "b.go", Line: 4}},
+		{FromLinter: "govet", Text: "<printf> & friends", Pos: token.Position{Filename: "a.go", Line: 7}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewJUnit(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("missing XML header:\n%s", buf.String())
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tests != 3 || got.Failures != 3 || len(got.Suites) != 2 {
+		t.Fatalf("testsuites = %+v", got)
+	}
+
+	first := got.Suites[0]
+	if first.Name != "a.go" || first.Tests != 2 || len(first.TestCases) != 2 {
+		t.Fatalf("first suite = %+v", first)
+	}
+	if c := first.TestCases[1]; c.Name != "a.go:7:0" || c.ClassName != "govet" || c.Failure.Message != "<printf> & friends" {
+		t.Errorf("test case = %+v", c)
+	}
+}
+
+func TestJUnitWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewJUnit(&buf).Print(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<testsuites tests="0" failures="0">`) {
+		t.Errorf("output = %s", buf.String())
+	}
+}
@@ -0,0 +1,27 @@
+package output
+
+import "github.com/golangci/golangci-lint/pkg/result"
+
+// Linkable is a printer that can show the web link of each issue, such as
+// the permalink to its line on the code host.
+type Linkable interface {
+	SetLink(link func(issue result.Issue) string)
+}
+
+// links is embedded by the printers that show links.
+type links struct {
+	link func(issue result.Issue) string
+}
+
+// SetLink shows the link returned for each issue; an empty one is left out.
+func (l *links) SetLink(link func(issue result.Issue) string) {
+	l.link = link
+}
+
+// url returns the link of issue, or "" when no links are shown.
+func (l *links) url(issue result.Issue) string {
+	if l.link == nil {
+		return ""
+	}
+	return l.link(issue)
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func issueLink(issue result.Issue) string {
+	if issue.FilePath() == "vendor/x.go" {
+		return ""
+	}
+	return fmt.Sprintf("https://github.com/org/repo/blob/abc/%s#L%d", issue.FilePath(), issue.Line())
+}
+
+func TestLinks(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = true
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 2}},
+		{FromLinter: "lll", Text: "long line", Pos: token.Position{Filename: "vendor/x.go", Line: 9}},
+	}
+	link := "https://github.com/org/repo/blob/abc/pkg/a.go#L3"
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"pkg/a.go:3:2: unchecked (errcheck)\n" + link + "\nvendor/x.go:9: long line (lll)\n"}},
+		{"json", []string{`"url": "` + link + `"`}},
+		{"jsonl", []string{`"url":"` + link + `"`}},
+		{"markdown", []string{"| pkg/a.go | [3](" + link + ") |", "| vendor/x.go | 9 |"}},
+		{"csv", []string{",fingerprint,url\n", "," + link + "\n", "long line,"}},
+		{"junit", []string{"(errcheck)&#xA;" + link + "</failure>"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		printer, err := New(tt.format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		printer.(Linkable).SetLink(issueLink)
+		if err := printer.Print(issues); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s output %q does not contain %q", tt.format, buf.String(), want)
+			}
+		}
+		if strings.Count(buf.String(), "https://") != 1 {
+			t.Errorf("%s output %q links %d times, want once", tt.format, buf.String(), strings.Count(buf.String(), "https://"))
+		}
+	}
+
+	var buf bytes.Buffer
+	html := NewHTML(&buf).(*HTML)
+	html.SetLink(issueLink)
+	if err := html.Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<a href="` + link + `">line 3</a>`; !strings.Contains(buf.String(), want) {
+		t.Errorf("html output does not contain %q", want)
+	}
+}
+
+func TestSinksSetLink(t *testing.T) {
+	var stdout bytes.Buffer
+	sinks, err := NewSinks([]string{"jsonl", "csv:" + t.TempDir() + "/issues.csv"}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinks.SetLink(issueLink)
+	stream, err := sinks.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	issue := result.Issue{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}
+	if err := stream.Print([]result.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"url":"https://github.com/org/repo/blob/abc/a.go#L3"`; !strings.Contains(stdout.String(), want) {
+		t.Errorf("streamed %q, want it to contain %q", stdout.String(), want)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+// Markdown writes a totals header and one collapsible table per file, to
+// be pasted into a pull request description or a GitHub job summary.
+type Markdown struct {
+	links
+	truncated
+	w io.Writer
+}
+
+func NewMarkdown(w io.Writer) Printer {
+	return &Markdown{w: w}
+}
+
+func (m *Markdown) Print(issues []result.Issue) error {
+	var (
+		paths  []string
+		byPath = make(map[string][]result.Issue)
+	)
+	linters := make(map[string]int)
+	for _, issue := range issues {
+		path := filepath.ToSlash(issue.FilePath())
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], issue)
+		linters[issue.FromLinter]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Lint: %d issue(s) on changed lines\n\n", len(issues))
+	if len(issues) > 0 {
+		perLinter := make([]string, 0, len(linters))
+		for _, linter := range counts(linters, len(issues)) {
+			perLinter = append(perLinter, fmt.Sprintf("%s %d", linter.Name, linter.Count))
+		}
+		fmt.Fprintf(&b, "%d file(s), by linter: %s\n\n", len(paths), strings.Join(perLinter, ", "))
+	}
+	for _, path := range paths {
+		fmt.Fprintf(&b, "<details>\n<summary><code>%s</code>: %d issue(s)</summary>\n\n", escapeCell(path), len(byPath[path]))
+		b.WriteString("| File | Line | Linter | Message | ID |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, issue := range byPath[path] {
+			line := strconv.Itoa(issue.Line())
+			if url := m.url(issue); url != "" {
+				line = "[" + line + "](" + url + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | `%s` |\n",
+				escapeCell(path), line, escapeCell(issue.FromLinter), escapeCell(issue.Text), fingerprint.Short(issue))
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+	if footer := Footer(m.hidden); footer != "" {
+		b.WriteString(footer + "\n")
+	}
+
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
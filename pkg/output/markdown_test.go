@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+func TestMarkdownGroupsByFile(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "govet", Text: "<printf> | friends", Pos: token.Position{Filename: "b.go", Line: 7}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "b.go", Line: 9}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewMarkdown(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	id := func(i int) string { return " `" + fingerprint.Short(issues[i]) + "` |\n" }
+	want := "### Lint: 3 issue(s) on changed lines\n\n" +
+		"2 file(s), by linter: errcheck 2, govet 1\n\n" +
+		"<details>\n<summary><code>b.go</code>: 2 issue(s)</summary>\n\n" +
+		"| File | Line | Linter | Message | ID |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| b.go | 7 | govet | &lt;printf> \\| friends |" + id(0) +
+		"| b.go | 9 | errcheck | unchecked |" + id(2) +
+		"\n</details>\n\n" +
+		"<details>\n<summary><code>a.go</code>: 1 issue(s)</summary>\n\n" +
+		"| File | Line | Linter | Message | ID |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| a.go | 1 | errcheck | unchecked |" + id(1) +
+		"\n</details>\n\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestMarkdownWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewMarkdown(&buf).Print(nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "### Lint: 0 issue(s) on changed lines\n\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// SortKeys are the orders Sort knows.
+var SortKeys = []string{"file", "line", "severity", "linter"}
+
+// GroupKeys are the groupings GroupIssues knows.
+var GroupKeys = []string{"file", "linter", "package"}
+
+// ValidSort reports whether Sort accepts key.
+func ValidSort(key string) bool {
+	return slices.Contains(SortKeys, key)
+}
+
+// ValidGroup reports whether GroupIssues accepts key.
+func ValidGroup(key string) bool {
+	return slices.Contains(GroupKeys, key)
+}
+
+// Sort returns the issues ordered by key: by file keeping the order of
+// the issues of each file, by line within each file, by severity, most
+// severe first, or by linter. Issues alike for key keep their position
+// order, and an empty key keeps the order the linters reported them in.
+func Sort(issues []result.Issue, key string) []result.Issue {
+	sorted := append([]result.Issue(nil), issues...)
+	var less func(a, b result.Issue) bool
+	switch key {
+	case "file":
+		less = func(a, b result.Issue) bool { return a.FilePath() < b.FilePath() }
+	case "line":
+		less = positionLess
+	case "severity":
+		less = func(a, b result.Issue) bool {
+			if ra, rb := severityOrder(a.Severity), severityOrder(b.Severity); ra != rb {
+				return ra > rb
+			}
+			return positionLess(a, b)
+		}
+	case "linter":
+		less = func(a, b result.Issue) bool {
+			if a.FromLinter != b.FromLinter {
+				return a.FromLinter < b.FromLinter
+			}
+			return positionLess(a, b)
+		}
+	default:
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted
+}
+
+func positionLess(a, b result.Issue) bool {
+	if a.FilePath() != b.FilePath() {
+		return a.FilePath() < b.FilePath()
+	}
+	if a.Line() != b.Line() {
+		return a.Line() < b.Line()
+	}
+	return a.Column() < b.Column()
+}
+
+// severityOrder ranks severities from info up; issues without one count
+// as warnings.
+func severityOrder(severity string) int {
+	switch severity {
+	case "info", "note":
+		return 0
+	case "error":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Group is the issues sharing a file, linter or package.
+type Group struct {
+	Name   string
+	Issues []result.Issue
+}
+
+// GroupIssues splits the issues by key, the groups in the order of their
+// first issue, so sorting first orders the groups too.
+func GroupIssues(issues []result.Issue, key string) []Group {
+	var groups []Group
+	indexes := make(map[string]int)
+	for _, issue := range issues {
+		name := groupName(issue, key)
+		index, ok := indexes[name]
+		if !ok {
+			index = len(groups)
+			indexes[name] = index
+			groups = append(groups, Group{Name: name})
+		}
+		groups[index].Issues = append(groups[index].Issues, issue)
+	}
+	return groups
+}
+
+func groupName(issue result.Issue, key string) string {
+	switch key {
+	case "linter":
+		return issue.FromLinter
+	case "package":
+		return path.Dir(filepath.ToSlash(issue.FilePath()))
+	default:
+		return filepath.ToSlash(issue.FilePath())
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func orderIssues() []result.Issue {
+	return []result.Issue{
+		{FromLinter: "govet", Severity: "warning", Pos: token.Position{Filename: "pkg/b.go", Line: 9}},
+		{FromLinter: "errcheck", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 7}},
+		{FromLinter: "lll", Severity: "info", Pos: token.Position{Filename: "main.go", Line: 2}},
+		{FromLinter: "govet", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 4}},
+	}
+}
+
+func positions(issues []result.Issue) []string {
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d %s", issue.FilePath(), issue.Line(), issue.FromLinter))
+	}
+	return got
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"", []string{"pkg/b.go:9 govet", "pkg/a.go:7 errcheck", "main.go:2 lll", "pkg/a.go:3 govet"}},
+		{"file", []string{"main.go:2 lll", "pkg/a.go:7 errcheck", "pkg/a.go:3 govet", "pkg/b.go:9 govet"}},
+		{"line", []string{"main.go:2 lll", "pkg/a.go:3 govet", "pkg/a.go:7 errcheck", "pkg/b.go:9 govet"}},
+		{"severity", []string{"pkg/a.go:7 errcheck", "pkg/a.go:3 govet", "pkg/b.go:9 govet", "main.go:2 lll"}},
+		{"linter", []string{"pkg/a.go:7 errcheck", "pkg/a.go:3 govet", "pkg/b.go:9 govet", "main.go:2 lll"}},
+	}
+	for _, tt := range tests {
+		issues := orderIssues()
+		if got := positions(Sort(issues, tt.key)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Sort(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+		if got := positions(issues); !reflect.DeepEqual(got, tests[0].want) {
+			t.Errorf("Sort(%q) reordered its argument to %q", tt.key, got)
+		}
+	}
+}
+
+func TestGroupIssues(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"file", []string{"pkg/b.go", "pkg/a.go", "main.go"}},
+		{"linter", []string{"govet", "errcheck", "lll"}},
+		{"package", []string{"pkg", "."}},
+	}
+	for _, tt := range tests {
+		var names []string
+		total := 0
+		for _, group := range GroupIssues(orderIssues(), tt.key) {
+			names = append(names, group.Name)
+			total += len(group.Issues)
+		}
+		if !reflect.DeepEqual(names, tt.want) || total != 4 {
+			t.Errorf("GroupIssues(%q) = %q holding %d issues, want %q holding 4", tt.key, names, total, tt.want)
+		}
+	}
+}
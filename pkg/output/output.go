@@ -0,0 +1,152 @@
+// Package output renders filtered issues in the supported formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/snippet"
+	"linter/pkg/span"
+)
+
+type Printer interface {
+	Print(issues []result.Issue) error
+}
+
+type factory func(w io.Writer) Printer
+
+var formats = map[string]factory{
+	"text":           NewText,
+	"sarif":          NewSARIF,
+	"github-actions": NewGitHubActions,
+	"junit":          NewJUnit,
+	"rdjson":         NewRDJSON,
+	"rdjsonl":        NewRDJSONL,
+	"markdown":       NewMarkdown,
+	"json":           NewJSON,
+	"csv":            NewCSV,
+	"tsv":            NewTSV,
+	"jsonl":          NewJSONL,
+	"gerrit":         NewGerrit,
+	"teamcity":       NewTeamCity,
+	"template":       NewTemplate,
+	"annotated-diff": NewAnnotatedDiff,
+	"quickfix":       NewQuickfix,
+	"vscode":         NewVSCode,
+}
+
+func New(format string, w io.Writer) (Printer, error) {
+	newPrinter, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, expected one of: %s", format, strings.Join(Formats(), ", "))
+	}
+	return newPrinter(w), nil
+}
+
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Text struct {
+	links
+	truncated
+	w      io.Writer
+	source func(issue result.Issue) ([]snippet.Line, error)
+}
+
+func NewText(w io.Writer) Printer {
+	return &Text{w: w}
+}
+
+// SetSource shows the lines source returns under each issue, instead of
+// the issue line alone.
+func (t *Text) SetSource(source func(issue result.Issue) ([]snippet.Line, error)) *Text {
+	t.source = source
+	return t
+}
+
+func (t *Text) Print(issues []result.Issue) error {
+	for _, issue := range issues {
+		if err := t.printIssue(issue); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(t.w, Footer(t.hidden))
+	return err
+}
+
+// printIssue writes the position, message and linter of issue, its link
+// when there is one, then its source: the lines from SetSource, or else the issue line with carets
+// under the token at the column when it is known.
+func (t *Text) printIssue(issue result.Issue) error {
+	pos := positionColor.Sprintf("%s:%d", issue.FilePath(), issue.Line())
+	if issue.Column() != 0 {
+		pos += fmt.Sprintf(":%d", issue.Column())
+	}
+	if _, err := fmt.Fprintf(t.w, "%s: %s (%s)\n", pos,
+		severityColor(issue.Severity).Sprint(strings.TrimSpace(issue.Text)),
+		linterColor.Sprint(issue.FromLinter),
+	); err != nil {
+		return err
+	}
+	if url := t.url(issue); url != "" {
+		if _, err := fmt.Fprintln(t.w, positionColor.Sprint(url)); err != nil {
+			return err
+		}
+	}
+
+	if t.source != nil {
+		lines, err := t.source(issue)
+		if err != nil {
+			// A file deleted since the lint run still has its issue listed.
+			return nil
+		}
+		return snippet.Write(t.w, lines)
+	}
+
+	for _, line := range issue.SourceLines {
+		if _, err := fmt.Fprintln(t.w, line); err != nil {
+			return err
+		}
+	}
+	if len(issue.SourceLines) != 1 || issue.Column() == 0 {
+		return nil
+	}
+	// Keep the tabs of the line so the carets line up, and count
+	// characters rather than bytes.
+	line := issue.SourceLines[0]
+	var prefix strings.Builder
+	for i, r := range line {
+		if i >= issue.Column()-1 {
+			break
+		}
+		if r == '\t' {
+			prefix.WriteByte('\t')
+		} else {
+			prefix.WriteByte(' ')
+		}
+	}
+	carets := 1
+	if start, end, ok := span.Token(issue); ok {
+		carets = span.Runes(line, end) - span.Runes(line, start)
+	}
+	_, err := fmt.Fprintf(t.w, "%s%s\n", prefix.String(), caretColor.Sprint(strings.Repeat("^", carets)))
+	return err
+}
+
+// firstLine returns the line of issue, or "" when the issue has no source.
+func firstLine(issue result.Issue) string {
+	if len(issue.SourceLines) == 0 {
+		return ""
+	}
+	return issue.SourceLines[0]
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/snippet"
+)
+
+func TestTextWithSource(t *testing.T) {
+	color.NoColor = true
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked", SourceLines: []string{"\tf()"}, Pos: token.Position{Filename: "a.go", Line: 4, Column: 2}},
+		{FromLinter: "unused", Text: "gone", Pos: token.Position{Filename: "deleted.go", Line: 1}},
+	}
+	source := func(issue result.Issue) ([]snippet.Line, error) {
+		if issue.FilePath() == "deleted.go" {
+			return nil, errors.New("no such file")
+		}
+		return []snippet.Line{
+			{Number: 3, Text: "func g() {"},
+			{Number: 4, Text: "\tf()", Changed: true, Issue: true},
+			{Number: 5, Text: "}"},
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := NewText(&buf).(*Text).SetSource(source).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a.go:4:2: unchecked (errcheck)\n   3 | func g() {\n>+ 4 | \tf()\n   5 | }\ndeleted.go:1: gone (unused)\n"
+	if buf.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := NewText(&buf).Print(issues[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\tf()\n\t^") {
+		t.Errorf("without source the issue line is not printed:\n%s", buf.String())
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	color.NoColor = true
+	issue := result.Issue{
+		FromLinter:  "errcheck",
+		Text:        "unchecked",
+		Pos:         token.Position{Filename: "a.go", Line: 3, Column: 14, Offset: 40},
+		SourceLines: []string{"\tlog(\"é\") ; f.Close()"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "text carets under the token", format: "text", want: "\n\t           ^\n"},
+		{name: "github columns in characters", format: "github-actions", want: "col=13,endColumn=14,"},
+		{name: "rdjson end column in bytes", format: "rdjsonl", want: `"end":{"line":3,"column":15}`},
+		{name: "json end column and offset", format: "json", want: `"end_column": 15,
+      "offset": 40,`},
+		{name: "sarif region in UTF-16", format: "sarif", want: `"startColumn": 13,
+                  "endColumn": 14,
+                  "byteOffset": 40,
+                  "byteLength": 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formats[tt.format](&buf).Print([]result.Issue{issue}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
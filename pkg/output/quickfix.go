@@ -0,0 +1,49 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Quickfix writes one "file:line:col: kind: message (linter)" line per
+// issue, the GNU style Emacs compilation-mode highlights by kind and Vim
+// reads with the errorformat in the README. Messages are kept on their
+// line; the kind is error, warning or note.
+type Quickfix struct {
+	w io.Writer
+}
+
+func NewQuickfix(w io.Writer) Printer {
+	return &Quickfix{w: w}
+}
+
+func (q *Quickfix) Print(issues []result.Issue) error {
+	for _, issue := range issues {
+		pos := fmt.Sprintf("%s:%d", filepath.ToSlash(issue.FilePath()), issue.Line())
+		if issue.Column() != 0 {
+			pos += fmt.Sprintf(":%d", issue.Column())
+		}
+		message := strings.Join(strings.Fields(issue.Text), " ")
+		if _, err := fmt.Fprintf(q.w, "%s: %s: %s (%s)\n", pos, quickfixKind(issue.Severity), message, issue.FromLinter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quickfixKind names the severity the way both editors understand. Issues
+// without one are errors, as the text output colors them.
+func quickfixKind(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info", "note":
+		return "note"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,28 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestQuickfix(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "Error return value is not checked", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 2}},
+		{FromLinter: "staticcheck", Text: "SA1019: deprecated", Severity: "warning", Pos: token.Position{Filename: "pkg/a.go", Line: 9, Column: 14}},
+		{FromLinter: "gocritic", Text: "commentFormatting:\n  put a space", Severity: "info", Pos: token.Position{Filename: "b.go", Line: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewQuickfix(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	want := "pkg/a.go:3:2: error: Error return value is not checked (errcheck)\n" +
+		"pkg/a.go:9:14: warning: SA1019: deprecated (staticcheck)\n" +
+		"b.go:1: note: commentFormatting: put a space (gocritic)\n"
+	if buf.String() != want {
+		t.Errorf("printed:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
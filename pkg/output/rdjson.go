@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/span"
+)
+
+// The Reviewdog Diagnostic Format, see
+// https://github.com/reviewdog/reviewdog/tree/master/proto/rdf.
+
+type rdSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdResult struct {
+	Source      rdSource       `json:"source"`
+	Diagnostics []rdDiagnostic `json:"diagnostics"`
+}
+
+type rdDiagnostic struct {
+	Message     string         `json:"message"`
+	Location    rdLocation     `json:"location"`
+	Severity    string         `json:"severity"`
+	Source      rdSource       `json:"source"`
+	Code        rdCode         `json:"code"`
+	Suggestions []rdSuggestion `json:"suggestions,omitempty"`
+}
+
+type rdLocation struct {
+	Path  string  `json:"path"`
+	Range rdRange `json:"range"`
+}
+
+type rdRange struct {
+	Start rdPosition  `json:"start"`
+	End   *rdPosition `json:"end,omitempty"`
+}
+
+type rdPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type rdCode struct {
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type rdSuggestion struct {
+	Range rdRange `json:"range"`
+	Text  string  `json:"text"`
+}
+
+// RDJSON writes issues as one Reviewdog diagnostic result, for
+// reviewdog -f=rdjson.
+type RDJSON struct {
+	w io.Writer
+}
+
+func NewRDJSON(w io.Writer) Printer {
+	return &RDJSON{w: w}
+}
+
+func (r *RDJSON) Print(issues []result.Issue) error {
+	diagnostics := make([]rdDiagnostic, 0, len(issues))
+	for _, issue := range issues {
+		diagnostics = append(diagnostics, rdjsonDiagnostic(issue))
+	}
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rdResult{
+		Source:      rdSource{Name: "golangci-lint", URL: "https://golangci-lint.run"},
+		Diagnostics: diagnostics,
+	})
+}
+
+// RDJSONL writes issues as one Reviewdog diagnostic per line, for
+// reviewdog -f=rdjsonl.
+type RDJSONL struct {
+	w io.Writer
+}
+
+func NewRDJSONL(w io.Writer) Printer {
+	return &RDJSONL{w: w}
+}
+
+func (r *RDJSONL) Print(issues []result.Issue) error {
+	encoder := json.NewEncoder(r.w)
+	for _, issue := range issues {
+		if err := encoder.Encode(rdjsonDiagnostic(issue)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rdjsonDiagnostic(issue result.Issue) rdDiagnostic {
+	diagnostic := rdDiagnostic{
+		Message: issue.Text,
+		Location: rdLocation{
+			Path: filepath.ToSlash(issue.FilePath()),
+			Range: rdRange{
+				Start: rdPosition{Line: issue.Line(), Column: issue.Column()},
+			},
+		},
+		Severity: rdjsonSeverity(issue.Severity),
+		Source:   rdSource{Name: issue.FromLinter},
+		Code: rdCode{
+			Value: issue.FromLinter,
+			URL:   lintersDocURL + strings.ToLower(issue.FromLinter),
+		},
+	}
+
+	// reviewdog counts columns in bytes, as golangci-lint does.
+	if _, end, ok := span.Token(issue); ok {
+		diagnostic.Location.Range.End = &rdPosition{Line: issue.Line(), Column: end}
+	}
+
+	// Whole-line replacements map onto a suggestion; inline fixes carry
+	// byte offsets reviewdog has no use for.
+	if fix := issue.Replacement; fix != nil && fix.Inline == nil {
+		lines := issue.GetLineRange()
+		suggestion := rdSuggestion{
+			Range: rdRange{
+				Start: rdPosition{Line: lines.From, Column: 1},
+				End:   &rdPosition{Line: lines.To + 1, Column: 1},
+			},
+		}
+		if !fix.NeedOnlyDelete {
+			suggestion.Text = strings.Join(fix.NewLines, "\n") + "\n"
+		}
+		diagnostic.Suggestions = []rdSuggestion{suggestion}
+	}
+	return diagnostic
+}
+
+func rdjsonSeverity(severity string) string {
+	switch severity {
+	case "error":
+		return "ERROR"
+	case "info", "note":
+		return "INFO"
+	default:
+		return "WARNING"
+	}
+}
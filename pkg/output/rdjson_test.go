@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestRDJSON(t *testing.T) {
+	issues := []result.Issue{
+		{
+			FromLinter: "gofmt",
+			Text:       "File is not gofmt-ed",
+			Severity:   "error",
+			Pos:        token.Position{Filename: "pkg/a.go", Line: 4, Column: 1},
+			LineRange:  &result.Range{From: 4, To: 5},
+			Replacement: &result.Replacement{
+				NewLines: []string{"x := 1", "y := 2"},
+			},
+		},
+		{
+			FromLinter: "errcheck",
+			Text:       "Error return value is not checked",
+			Pos:        token.Position{Filename: "b.go", Line: 9, Column: 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewRDJSON(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	var got rdResult
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Source.Name != "golangci-lint" || len(got.Diagnostics) != 2 {
+		t.Fatalf("result = %+v", got)
+	}
+
+	fixed := got.Diagnostics[0]
+	if fixed.Severity != "ERROR" || fixed.Location.Path != "pkg/a.go" || fixed.Code.Value != "gofmt" {
+		t.Errorf("diagnostic = %+v", fixed)
+	}
+	if len(fixed.Suggestions) != 1 {
+		t.Fatalf("suggestions = %+v, want one", fixed.Suggestions)
+	}
+	suggestion := fixed.Suggestions[0]
+	if suggestion.Range.Start.Line != 4 || suggestion.Range.End.Line != 6 || suggestion.Text != "x := 1\ny := 2\n" {
+		t.Errorf("suggestion = %+v", suggestion)
+	}
+
+	plain := got.Diagnostics[1]
+	if plain.Severity != "WARNING" || plain.Location.Range.Start.Column != 3 || plain.Suggestions != nil {
+		t.Errorf("diagnostic = %+v", plain)
+	}
+}
+
+func TestRDJSONL(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "a", Text: "one", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "b", Text: "two", Pos: token.Position{Filename: "b.go", Line: 2}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewRDJSONL(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var second rdDiagnostic
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if second.Message != "two" || second.Location.Path != "b.go" {
+		t.Errorf("second diagnostic = %+v", second)
+	}
+}
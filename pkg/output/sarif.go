@@ -0,0 +1,186 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/span"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifFingerprintKey names our own fingerprint scheme; GitHub reserves
+	// primaryLocationLineHash for a hash it computes from the source line.
+	sarifFingerprintKey = "linterdiff/v2"
+	lintersDocURL       = "https://golangci-lint.run/usage/linters/#"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+// sarifRegion counts columns in UTF-16 code units, the SARIF default.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+	ByteOffset  int `json:"byteOffset,omitempty"`
+	ByteLength  int `json:"byteLength,omitempty"`
+}
+
+type SARIF struct {
+	w io.Writer
+}
+
+func NewSARIF(w io.Writer) Printer {
+	return &SARIF{w: w}
+}
+
+func (s *SARIF) Print(issues []result.Issue) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "golangci-lint",
+				InformationURI: "https://golangci-lint.run",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: make([]sarifResult, 0, len(issues)),
+	}
+
+	ruleIndexes := make(map[string]int)
+	// Identical messages in one file share a fingerprint, so the
+	// occurrence index keeps them apart as separate alerts.
+	occurrences := fingerprint.Occurrences(issues)
+	for i, issue := range issues {
+		index, ok := ruleIndexes[issue.FromLinter]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[issue.FromLinter] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               issue.FromLinter,
+				Name:             issue.FromLinter,
+				ShortDescription: sarifMessage{Text: "Issues reported by the " + issue.FromLinter + " linter"},
+				HelpURI:          lintersDocURL + strings.ToLower(issue.FromLinter),
+			})
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    issue.FromLinter,
+			RuleIndex: index,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Text},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						URI:       filepath.ToSlash(issue.FilePath()),
+						URIBaseID: "%SRCROOT%",
+					},
+					Region: newSARIFRegion(issue),
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				sarifFingerprintKey: occurrences[i].String(),
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(s.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// newSARIFRegion spans the token at the column of issue when it is known,
+// with its byte offset in the file when golangci-lint reported one.
+func newSARIFRegion(issue result.Issue) sarifRegion {
+	region := sarifRegion{StartLine: issue.Line()}
+	if issue.Column() == 0 {
+		return region
+	}
+	line := firstLine(issue)
+	region.StartColumn = span.UTF16(line, issue.Column())
+	start, end, ok := span.Token(issue)
+	if ok {
+		region.EndColumn = span.UTF16(line, end)
+	}
+	if issue.Pos.Offset > 0 {
+		region.ByteOffset = issue.Pos.Offset
+		if ok {
+			region.ByteLength = end - start
+		}
+	}
+	return region
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "info", "note":
+		return "note"
+	default:
+		return "warning"
+	}
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestSARIFFingerprintsKeepDuplicatesApart(t *testing.T) {
+	issue := result.Issue{
+		FromLinter: "errcheck",
+		Text:       "Error return value is not checked",
+		Pos:        token.Position{Filename: "a.go", Line: 3, Column: 2},
+	}
+	second := issue
+	second.Pos.Line = 9
+
+	var buf bytes.Buffer
+	if err := NewSARIF(&buf).Print([]result.Issue{issue, second}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	first, other := results[0].PartialFingerprints[sarifFingerprintKey], results[1].PartialFingerprints[sarifFingerprintKey]
+	if first == "" || first == other {
+		t.Errorf("fingerprints %q and %q should be set and distinct", first, other)
+	}
+
+	rules := log.Runs[0].Tool.Driver.Rules
+	if len(rules) != 1 || rules[0].Name != "errcheck" || rules[0].HelpURI == "" {
+		t.Errorf("rules = %+v, want one errcheck rule with help", rules)
+	}
+}
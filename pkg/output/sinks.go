@@ -0,0 +1,229 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Sink is one destination of the issues: a format, written to a file or,
+// when Path is empty, to standard output.
+type Sink struct {
+	Format string
+	Path   string
+}
+
+// ParseSink reads "format" or "format:path".
+func ParseSink(spec string) (Sink, error) {
+	format, path, _ := strings.Cut(spec, ":")
+	if _, ok := formats[format]; !ok {
+		return Sink{}, fmt.Errorf("unknown output format %q, expected one of: %s", format, strings.Join(Formats(), ", "))
+	}
+	return Sink{Format: format, Path: path}, nil
+}
+
+func (s Sink) String() string {
+	if s.Path == "" {
+		return s.Format
+	}
+	return s.Format + ":" + s.Path
+}
+
+// Sinks prints the same issues to every sink, so one run can both show
+// them and archive them as artifacts.
+type Sinks struct {
+	sinks    []Sink
+	stdout   io.Writer
+	text     func(*Text)
+	template func(*Template)
+	diff     func(*AnnotatedDiff)
+	link     func(result.Issue) string
+	hidden   int
+	streamed bool
+}
+
+var _ Printer = (*Sinks)(nil)
+
+// NewSinks parses specs such as "text" and "sarif:report.sarif". At most
+// one of them may write to stdout.
+func NewSinks(specs []string, stdout io.Writer) (*Sinks, error) {
+	s := &Sinks{stdout: stdout}
+	toStdout := 0
+	for _, spec := range specs {
+		sink, err := ParseSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		if sink.Path == "" {
+			toStdout++
+		}
+		s.sinks = append(s.sinks, sink)
+	}
+	if toStdout > 1 {
+		return nil, errors.New("only one output may go to stdout, give the others a file as format:path")
+	}
+	return s, nil
+}
+
+// Sinks returns the parsed sinks.
+func (s *Sinks) Sinks() []Sink {
+	return s.sinks
+}
+
+// ConfigureText calls configure on every text printer before it prints.
+func (s *Sinks) ConfigureText(configure func(*Text)) *Sinks {
+	s.text = configure
+	return s
+}
+
+// ConfigureTemplate calls configure on every template printer before it
+// prints.
+func (s *Sinks) ConfigureTemplate(configure func(*Template)) *Sinks {
+	s.template = configure
+	return s
+}
+
+// ConfigureAnnotatedDiff calls configure on every annotated-diff printer
+// before it prints.
+func (s *Sinks) ConfigureAnnotatedDiff(configure func(*AnnotatedDiff)) *Sinks {
+	s.diff = configure
+	return s
+}
+
+// SetLink links each issue to the page link returns in every format that
+// shows links, streamed or not.
+func (s *Sinks) SetLink(link func(issue result.Issue) string) *Sinks {
+	s.link = link
+	return s
+}
+
+// SetHidden ends every format that can say so with how many issues were
+// left out of those printed.
+func (s *Sinks) SetHidden(hidden int) *Sinks {
+	s.hidden = hidden
+	return s
+}
+
+// Print writes the issues to every sink in turn. Files are created, along
+// with their directory, or truncated; they never get colors. Sinks handed
+// to Stream are left alone.
+func (s *Sinks) Print(issues []result.Issue) error {
+	for _, sink := range s.sinks {
+		if s.streamed && sink.Format == StreamFormat {
+			continue
+		}
+		if err := s.print(sink, issues); err != nil {
+			return fmt.Errorf("output %s: %w", sink, err)
+		}
+	}
+	return nil
+}
+
+func (s *Sinks) print(sink Sink, issues []result.Issue) error {
+	if sink.Path == "" {
+		return s.printer(sink, s.stdout).Print(issues)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sink.Path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(sink.Path)
+	if err != nil {
+		return err
+	}
+	noColor := color.NoColor
+	color.NoColor = true
+	err = s.printer(sink, file).Print(issues)
+	color.NoColor = noColor
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// StreamFormat is the format whose sinks Stream writes to.
+const StreamFormat = "jsonl"
+
+// Stream writes batches of issues to the jsonl sinks as soon as they are
+// known, instead of once at the end.
+type Stream struct {
+	printers []Printer
+	files    []*os.File
+}
+
+// Stream opens the jsonl sinks for streaming, after which Print skips them.
+// It fails when no sink is jsonl.
+func (s *Sinks) Stream() (*Stream, error) {
+	stream := &Stream{}
+	for _, sink := range s.sinks {
+		if sink.Format != StreamFormat {
+			continue
+		}
+		if sink.Path == "" {
+			stream.printers = append(stream.printers, s.printer(sink, s.stdout))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(sink.Path), 0o755); err != nil {
+			stream.Close()
+			return nil, fmt.Errorf("output %s: %w", sink, err)
+		}
+		file, err := os.Create(sink.Path)
+		if err != nil {
+			stream.Close()
+			return nil, fmt.Errorf("output %s: %w", sink, err)
+		}
+		stream.files = append(stream.files, file)
+		stream.printers = append(stream.printers, s.printer(sink, file))
+	}
+	if len(stream.printers) == 0 {
+		return nil, errors.New("no output to stream to, add --out " + StreamFormat)
+	}
+	s.streamed = true
+	return stream, nil
+}
+
+// Print writes a batch of issues to every streamed sink.
+func (s *Stream) Print(issues []result.Issue) error {
+	for _, printer := range s.printers {
+		if err := printer.Print(issues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the files of the streamed sinks.
+func (s *Stream) Close() error {
+	var errs []error
+	for _, file := range s.files {
+		errs = append(errs, file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sinks) printer(sink Sink, w io.Writer) Printer {
+	printer := formats[sink.Format](w)
+	if text, ok := printer.(*Text); ok && s.text != nil {
+		s.text(text)
+	}
+	if template, ok := printer.(*Template); ok && s.template != nil {
+		s.template(template)
+	}
+	if diff, ok := printer.(*AnnotatedDiff); ok && s.diff != nil {
+		s.diff(diff)
+	}
+	if linkable, ok := printer.(Linkable); ok && s.link != nil {
+		linkable.SetLink(s.link)
+	}
+	if truncatable, ok := printer.(Truncatable); ok && s.hidden > 0 {
+		truncatable.SetHidden(s.hidden)
+	}
+	return printer
+}
@@ -0,0 +1,140 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Sink
+		wantErr bool
+	}{
+		{spec: "text", want: Sink{Format: "text"}},
+		{spec: "sarif:out/report.sarif", want: Sink{Format: "sarif", Path: "out/report.sarif"}},
+		{spec: `json:C:\reports\issues.json`, want: Sink{Format: "json", Path: `C:\reports\issues.json`}},
+		{spec: "yaml:issues.yml", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSink(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSink(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSink(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.spec {
+			t.Errorf("String() = %q, want %q", got.String(), tt.spec)
+		}
+	}
+}
+
+func TestSinks(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = false
+
+	dir := t.TempDir()
+	issues := []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}}
+	var stdout bytes.Buffer
+	sinks, err := NewSinks([]string{
+		"text",
+		"json:" + filepath.Join(dir, "out", "issues.json"),
+		"text:" + filepath.Join(dir, "issues.txt"),
+	}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured := 0
+	sinks.ConfigureText(func(*Text) { configured++ })
+	if err := sinks.Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stdout.String(), "\x1b[") {
+		t.Errorf("stdout lost its colors: %q", stdout.String())
+	}
+	var report jsonReport
+	content, err := os.ReadFile(filepath.Join(dir, "out", "issues.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, &report); err != nil || len(report.Issues) != 1 {
+		t.Errorf("json sink = %s, %v", content, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "issues.txt")); string(content) != "a.go:3: unchecked (errcheck)\n" {
+		t.Errorf("text file = %q, want no colors", content)
+	}
+	if configured != 2 {
+		t.Errorf("ConfigureText ran for %d printers, want both text ones", configured)
+	}
+	if color.NoColor {
+		t.Error("printing to files left colors off")
+	}
+
+	if _, err := NewSinks([]string{"text", "markdown"}, &stdout); err == nil {
+		t.Error("two outputs to stdout expected an error")
+	}
+}
+
+func TestStream(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out", "issues.jsonl")
+	var stdout bytes.Buffer
+	sinks, err := NewSinks([]string{"text", "jsonl:" + path}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := sinks.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := result.Issue{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}
+	second := result.Issue{FromLinter: "gofmt", Text: "File is not `gofmt`-ed", Pos: token.Position{Filename: "b.go", Line: 7}}
+	if err := stream.Print([]result.Issue{first}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); strings.Count(string(content), "\n") != 1 {
+		t.Errorf("after the first batch the stream holds %q, want one line", content)
+	}
+	if err := stream.Print([]result.Issue{second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sinks.Print([]result.Issue{first, second}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"linter":"gofmt"`) {
+		t.Errorf("streamed file = %q, want each issue once", content)
+	}
+	if stdout.String() != "a.go:3: unchecked (errcheck)\nb.go:7: File is not `gofmt`-ed (gofmt)\n" {
+		t.Errorf("stdout = %q, want the text printed at the end", stdout.String())
+	}
+
+	text, err := NewSinks([]string{"text"}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := text.Stream(); err == nil {
+		t.Error("streaming without a jsonl output expected an error")
+	}
+}
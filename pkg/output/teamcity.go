@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// TeamCity writes the issues as inspection service messages, which
+// TeamCity lists on the Inspections tab of the build. Each linter is
+// declared once as an inspection type before its first issue.
+type TeamCity struct {
+	w io.Writer
+}
+
+func NewTeamCity(w io.Writer) Printer {
+	return &TeamCity{w: w}
+}
+
+func (t *TeamCity) Print(issues []result.Issue) error {
+	declared := make(map[string]bool)
+	for _, issue := range issues {
+		if !declared[issue.FromLinter] {
+			declared[issue.FromLinter] = true
+			if _, err := fmt.Fprintf(t.w, "##teamcity[inspectionType id='%s' name='%[1]s' description='%s' category='golangci-lint']\n",
+				escapeTeamCity(issue.FromLinter),
+				escapeTeamCity(lintersDocURL+strings.ToLower(issue.FromLinter)),
+			); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(t.w, "##teamcity[inspection typeId='%s' message='%s' file='%s' line='%d' SEVERITY='%s']\n",
+			escapeTeamCity(issue.FromLinter),
+			escapeTeamCity(issue.Text),
+			escapeTeamCity(filepath.ToSlash(issue.FilePath())),
+			issue.Line(),
+			teamCitySeverity(issue.Severity),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func teamCitySeverity(severity string) string {
+	switch severity {
+	case "error":
+		return "ERROR"
+	case "info", "note":
+		return "INFO"
+	default:
+		return "WARNING"
+	}
+}
+
+// escapeTeamCity escapes a service message value, where | is the escape
+// character.
+func escapeTeamCity(s string) string {
+	return strings.NewReplacer(
+		"|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]",
+		"\u0085", "|x", " ", "|l", " ", "|p",
+	).Replace(s)
+}
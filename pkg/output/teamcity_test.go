@@ -0,0 +1,32 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestTeamCityInspections(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked 'Close' [x]", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 3}},
+		{FromLinter: "errcheck", Text: "a|b\nc", Pos: token.Position{Filename: "pkg/b.go", Line: 7}},
+		{FromLinter: "unused", Text: "x is unused", Severity: "info", Pos: token.Position{Filename: "c.go", Line: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewTeamCity(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `##teamcity[inspectionType id='errcheck' name='errcheck' description='https://golangci-lint.run/usage/linters/#errcheck' category='golangci-lint']
+##teamcity[inspection typeId='errcheck' message='unchecked |'Close|' |[x|]' file='pkg/a.go' line='3' SEVERITY='ERROR']
+##teamcity[inspection typeId='errcheck' message='a||b|nc' file='pkg/b.go' line='7' SEVERITY='WARNING']
+##teamcity[inspectionType id='unused' name='unused' description='https://golangci-lint.run/usage/linters/#unused' category='golangci-lint']
+##teamcity[inspection typeId='unused' message='x is unused' file='c.go' line='1' SEVERITY='INFO']
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/summary"
+)
+
+// TemplateData is what a user template executes on.
+type TemplateData struct {
+	Issues  []TemplateIssue
+	Changes []TemplateChange
+	// Summary counts the changed files and the issues found and reported,
+	// per linter and file, and times the steps of the run so far.
+	Summary *summary.Summary
+}
+
+// TemplateIssue is an issue with the fields of the json format.
+type TemplateIssue struct {
+	Fingerprint string
+	File        string
+	Line        int
+	Column      int
+	EndColumn   int
+	Linter      string
+	Rule        string
+	Severity    string
+	Message     string
+	// URL links to the line of the issue, with --link-base.
+	URL         string
+	SourceLines []string
+}
+
+// TemplateChange is a changed file, with the lines added to it.
+type TemplateChange struct {
+	Path string
+	// OldPath is the path the file was renamed or copied from, if any.
+	OldPath string
+	// Lines counts the added lines, which Ranges list.
+	Lines  int
+	Ranges []TemplateRange
+}
+
+type TemplateRange struct {
+	Start, End int
+}
+
+// templateFuncs help templates write common formats.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		content, err := json.Marshal(v)
+		return string(content), err
+	},
+	// csv quotes its fields as one line of CSV, without the line break.
+	"csv": func(fields ...any) (string, error) {
+		record := make([]string, 0, len(fields))
+		for _, field := range fields {
+			record = append(record, fmt.Sprint(field))
+		}
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimSuffix(b.String(), "\n"), w.Error()
+	},
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+}
+
+// LoadTemplate parses the text/template at path, with the functions json,
+// csv, join, lower, upper and replace.
+func LoadTemplate(path string) (*template.Template, error) {
+	return template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+}
+
+// Template executes a user template on the issues, the changes and the
+// summary of the run, for formats the linter does not know.
+type Template struct {
+	links
+	w        io.Writer
+	template *template.Template
+	changes  []diff.FileChange
+	summary  *summary.Summary
+}
+
+func NewTemplate(w io.Writer) Printer {
+	return &Template{w: w}
+}
+
+// SetTemplate sets the template to execute, from LoadTemplate.
+func (t *Template) SetTemplate(tmpl *template.Template) *Template {
+	t.template = tmpl
+	return t
+}
+
+// SetChanges sets the changed files the template sees.
+func (t *Template) SetChanges(changes []diff.FileChange) *Template {
+	t.changes = changes
+	return t
+}
+
+// SetSummary sets the summary of the run the template sees.
+func (t *Template) SetSummary(s *summary.Summary) *Template {
+	t.summary = s
+	return t
+}
+
+func (t *Template) Print(issues []result.Issue) error {
+	if t.template == nil {
+		return errors.New("the template output needs --template-file")
+	}
+	data := TemplateData{
+		Issues:  make([]TemplateIssue, 0, len(issues)),
+		Changes: make([]TemplateChange, 0, len(t.changes)),
+		Summary: t.summary,
+	}
+	if data.Summary == nil {
+		data.Summary = summary.New()
+	}
+	for _, issue := range issues {
+		i := newJSONIssue(issue, t.url(issue))
+		data.Issues = append(data.Issues, TemplateIssue{
+			Fingerprint: i.Fingerprint,
+			File:        i.File,
+			Line:        i.Line,
+			Column:      i.Column,
+			EndColumn:   i.EndColumn,
+			Linter:      i.Linter,
+			Rule:        i.Rule,
+			Severity:    i.Severity,
+			Message:     i.Message,
+			URL:         i.URL,
+			SourceLines: i.SourceLines,
+		})
+	}
+	for _, change := range t.changes {
+		c := TemplateChange{Path: change.Path, OldPath: change.OldPath}
+		for _, lines := range change.Changes {
+			c.Lines += lines.End - lines.Start + 1
+			c.Ranges = append(c.Ranges, TemplateRange{Start: lines.Start, End: lines.End})
+		}
+		data.Changes = append(data.Changes, c)
+	}
+	return t.template.Execute(t.w, data)
+}
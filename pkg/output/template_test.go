@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/summary"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "issues.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTemplate(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "staticcheck", Text: `SA1019: "strings.Title" is deprecated`, Severity: "error", Pos: token.Position{Filename: "a.go", Line: 3, Column: 2}},
+		{FromLinter: "lll", Text: "line is 130 characters", Pos: token.Position{Filename: "b.go", Line: 9}},
+	}
+	changes := []diff.FileChange{
+		{Path: "a.go", Changes: []*diff.Change{{Start: 3, End: 5}, {Start: 9, End: 9}}},
+		{Path: "b.go", OldPath: "old.go", Changes: []*diff.Change{{Start: 9, End: 9}}},
+	}
+	stats := summary.New()
+	stats.SetChanged(2)
+	stats.SetReported(issues)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name: "csv",
+			template: `file,line,linter,rule,message
+{{range .Issues}}{{csv .File .Line .Linter .Rule .Message}}
+{{end}}`,
+			want: `file,line,linter,rule,message
+a.go,3,staticcheck,SA1019,"SA1019: ""strings.Title"" is deprecated"
+b.go,9,lll,,line is 130 characters
+`,
+		},
+		{
+			name: "changes and summary",
+			template: `{{range .Changes}}{{.Path}}{{with .OldPath}} (from {{.}}){{end}}: {{.Lines}} line(s){{range .Ranges}} {{.Start}}-{{.End}}{{end}}
+{{end}}{{.Summary.Reported}} issue(s) in {{.Summary.Changed}} file(s), {{index .Summary.Linters "lll"}} from lll
+`,
+			want: `a.go: 4 line(s) 3-5 9-9
+b.go (from old.go): 1 line(s) 9-9
+2 issue(s) in 2 file(s), 1 from lll
+`,
+		},
+		{
+			name:     "functions",
+			template: `{{range .Issues}}* {{upper .Severity | printf "%-5s"}} {{replace .Message "\"" "'"}} {{json .SourceLines}}{{"\n"}}{{end}}`,
+			want: `* ERROR SA1019: 'strings.Title' is deprecated null
+*       line is 130 characters null
+`,
+		},
+	}
+	for _, tt := range tests {
+		tmpl, err := LoadTemplate(writeTemplate(t, tt.template))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var buf bytes.Buffer
+		printer := NewTemplate(&buf).(*Template).SetTemplate(tmpl).SetChanges(changes).SetSummary(stats)
+		if err := printer.Print(issues); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: printed\n%s\nwant\n%s", tt.name, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if err := NewTemplate(&bytes.Buffer{}).Print(nil); err == nil {
+		t.Error("printing without a template expected an error")
+	}
+	if _, err := LoadTemplate(writeTemplate(t, "{{range .Issues}")); err == nil {
+		t.Error("loading a broken template expected an error")
+	}
+	tmpl, err := LoadTemplate(writeTemplate(t, "{{.Missing}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTemplate(&bytes.Buffer{}).(*Template).SetTemplate(tmpl).Print(nil); err == nil {
+		t.Error("a template using an unknown field expected an error")
+	}
+}
+
+func TestSinksConfigureTemplate(t *testing.T) {
+	tmpl, err := LoadTemplate(writeTemplate(t, "{{len .Issues}} issue(s), {{len .Changes}} change(s)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	sinks, err := NewSinks([]string{"template"}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinks.ConfigureTemplate(func(printer *Template) {
+		printer.SetTemplate(tmpl).SetChanges([]diff.FileChange{{Path: "a.go"}})
+	})
+	if err := sinks.Print([]result.Issue{{FromLinter: "errcheck", Pos: token.Position{Filename: "a.go", Line: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "1 issue(s), 1 change(s)\n" {
+		t.Errorf("printed %q", stdout.String())
+	}
+}
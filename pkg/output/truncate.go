@@ -0,0 +1,30 @@
+package output
+
+import "fmt"
+
+// Truncatable is a printer that can end with how many issues were left
+// out of what it was given to print.
+type Truncatable interface {
+	SetHidden(hidden int)
+}
+
+// truncated is embedded by the printers that end with a footer for the
+// issues left out.
+type truncated struct {
+	hidden int
+}
+
+// SetHidden ends the output with a footer counting hidden issues, unless
+// there are none.
+func (t *truncated) SetHidden(hidden int) {
+	t.hidden = hidden
+}
+
+// Footer returns the line saying hidden more issues were not printed, or
+// "" when there are none.
+func Footer(hidden int) string {
+	if hidden <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("… and %d more issue(s)\n", hidden)
+}
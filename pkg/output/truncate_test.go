@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestSinksSetHidden(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = true
+	issues := []result.Issue{{FromLinter: "errcheck", Text: "unchecked", Pos: token.Position{Filename: "a.go", Line: 3}}}
+
+	tests := []struct {
+		format string
+		hidden int
+		want   string
+	}{
+		{format: "text", hidden: 42, want: "a.go:3: unchecked (errcheck)\n… and 42 more issue(s)\n"},
+		{format: "text", want: "a.go:3: unchecked (errcheck)\n"},
+		{format: "markdown", hidden: 1, want: "</details>\n\n… and 1 more issue(s)\n\n"},
+		{format: "jsonl", hidden: 42, want: "}\n"},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		sinks, err := NewSinks([]string{tt.format}, &stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sinks.SetHidden(tt.hidden).Print(issues); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(stdout.String(), tt.want) {
+			t.Errorf("%s with %d hidden = %q, want it to end with %q", tt.format, tt.hidden, stdout.String(), tt.want)
+		}
+	}
+}
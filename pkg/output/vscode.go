@@ -0,0 +1,45 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// VSCodePattern matches the lines of the vscode format, its groups being
+// the file, line, column, severity, linter and message, in the order a
+// VS Code problem matcher numbers them.
+const VSCodePattern = `^(.+):(\d+):(\d+): (error|warning|info): (\S+): (.*)$`
+
+// VSCode writes one "file:line:col: severity: linter: message" line per
+// issue for the problem matcher of VS Code tasks. Every field is always
+// there, the column 1 when unknown, so VSCodePattern reads each line.
+type VSCode struct {
+	w io.Writer
+}
+
+func NewVSCode(w io.Writer) Printer {
+	return &VSCode{w: w}
+}
+
+func (v *VSCode) Print(issues []result.Issue) error {
+	for _, issue := range issues {
+		severity := "error"
+		switch issue.Severity {
+		case "warning":
+			severity = "warning"
+		case "info", "note":
+			severity = "info"
+		}
+		if _, err := fmt.Fprintf(v.w, "%s:%d:%d: %s: %s: %s\n",
+			filepath.ToSlash(issue.FilePath()), issue.Line(), max(issue.Column(), 1),
+			severity, issue.FromLinter, strings.Join(strings.Fields(issue.Text), " "),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
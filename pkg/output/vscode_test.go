@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bytes"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestVSCode(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "Error return value is not checked", Pos: token.Position{Filename: "pkg/a.go", Line: 3, Column: 2}},
+		{FromLinter: "staticcheck", Text: "SA1019: deprecated", Severity: "warning", Pos: token.Position{Filename: "C:/src/a b.go", Line: 9, Column: 14}},
+		{FromLinter: "gocritic", Text: "commentFormatting:\n  put a space", Severity: "note", Pos: token.Position{Filename: "b.go", Line: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewVSCode(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"pkg/a.go", "3", "2", "error", "errcheck", "Error return value is not checked"},
+		{"C:/src/a b.go", "9", "14", "warning", "staticcheck", "SA1019: deprecated"},
+		{"b.go", "1", "1", "info", "gocritic", "commentFormatting: put a space"},
+	}
+	pattern := regexp.MustCompile(VSCodePattern)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("printed %q, want %d lines", buf.String(), len(want))
+	}
+	for i, line := range lines {
+		match := pattern.FindStringSubmatch(line)
+		if match == nil || !reflect.DeepEqual(match[1:], want[i]) {
+			t.Errorf("line %q matched %q, want %q", line, match, want[i])
+		}
+	}
+}
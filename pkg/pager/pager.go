@@ -0,0 +1,108 @@
+// Package pager pipes long output through the user's pager, the way git
+// does.
+package pager
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Modes are the accepted values of --pager.
+var Modes = []string{"auto", "always", "never"}
+
+// ValidMode reports whether mode is one of Modes.
+func ValidMode(mode string) bool {
+	for _, m := range Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Command returns the pager to run, $PAGER or else less, split into its
+// arguments. It is empty when $PAGER is set to nothing or to cat, which
+// turns paging off.
+func Command() []string {
+	command, ok := os.LookupEnv("PAGER")
+	if !ok {
+		command = "less"
+	}
+	if fields := strings.Fields(command); len(fields) > 0 && fields[0] != "cat" {
+		return fields
+	}
+	return nil
+}
+
+// Pager writes to a pager process, started with the first write so that
+// nothing is paged when nothing is printed. Unless the user has their own
+// settings, less quits when the output fits on one screen and passes
+// colors through, and lv passes colors through too.
+type Pager struct {
+	command []string
+	out     io.Writer
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	err     error
+}
+
+// New pages to out, usually the terminal, with command.
+func New(command []string, out io.Writer) *Pager {
+	return &Pager{command: command, out: out}
+}
+
+func (p *Pager) start() error {
+	cmd := exec.Command(p.command[0], p.command[1:]...)
+	cmd.Stdout = p.out
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.cmd, p.in = cmd, in
+	return nil
+}
+
+// Write hands b to the pager. Once the reader quits the pager, the rest of
+// the output is dropped rather than failing.
+func (p *Pager) Write(b []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.cmd == nil {
+		if p.err = p.start(); p.err != nil {
+			return 0, p.err
+		}
+	}
+	n, err := p.in.Write(b)
+	if errors.Is(err, syscall.EPIPE) {
+		return len(b), nil
+	}
+	return n, err
+}
+
+// Close ends the output and waits for the reader to quit the pager. It can
+// be called more than once.
+func (p *Pager) Close() error {
+	if p.cmd == nil {
+		return nil
+	}
+	cmd := p.cmd
+	p.cmd, p.err = nil, errors.New("pager closed")
+	p.in.Close()
+	return cmd.Wait()
+}
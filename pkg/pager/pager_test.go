@@ -0,0 +1,76 @@
+package pager
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		pager string
+		unset bool
+		want  []string
+	}{
+		{unset: true, want: []string{"less"}},
+		{pager: "most -s", want: []string{"most", "-s"}},
+		{pager: "cat"},
+		{pager: ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("PAGER", tt.pager)
+		if tt.unset {
+			unsetenv(t, "PAGER")
+		}
+		if got := Command(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PAGER=%q: Command = %q, want %q", tt.pager, got, tt.want)
+		}
+	}
+}
+
+func TestPager(t *testing.T) {
+	t.Setenv("LESS", "")
+	unsetenv(t, "LESS")
+	var out bytes.Buffer
+	p := New([]string{"sh", "-c", `echo "LESS=$LESS"; tr a-z A-Z`}, &out)
+	if err := p.Close(); err != nil || out.Len() != 0 {
+		t.Fatalf("closing an unused pager: %v, printed %q", err, out.String())
+	}
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := p.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if want := "LESS=FRX\nFIRST\nSECOND\n"; out.String() != want {
+		t.Errorf("paged %q, want %q", out.String(), want)
+	}
+}
+
+func TestPagerQuitEarly(t *testing.T) {
+	var out bytes.Buffer
+	p := New([]string{"head", "-n", "1"}, &out)
+	long := strings.Repeat("line\n", 100000)
+	if _, err := p.Write([]byte(long)); err != nil {
+		t.Fatalf("writing after the pager quit: %v", err)
+	}
+	p.Close()
+	if out.String() != "line\n" {
+		t.Errorf("paged %q", out.String())
+	}
+}
+
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package permalink links lines of a repository at a commit on the web
+// pages of GitHub, GitLab and Bitbucket.
+package permalink
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// scpRemotePattern matches remotes in the scp-like form user@host:path.
+var scpRemotePattern = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):(.+)$`)
+
+// FromRemote returns the web page of the repository a git remote URL points
+// to, such as https://github.com/org/repo for git@github.com:org/repo.git.
+func FromRemote(remote string) (string, error) {
+	host, repo := "", ""
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, repo = u.Hostname(), u.Path
+	} else if match := scpRemotePattern.FindStringSubmatch(remote); match != nil {
+		host, repo = match[1], match[2]
+	}
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	if host == "" || repo == "" {
+		return "", fmt.Errorf("cannot link to files of remote %q", remote)
+	}
+	return "https://" + host + "/" + repo, nil
+}
+
+// Linker links lines of files at one commit. Hosts named like Bitbucket
+// and GitLab get their own layouts; GitHub's is understood by Gitea too.
+type Linker struct {
+	base   string
+	commit string
+	host   string
+}
+
+// New links to files at commit under base, the web page of a repository.
+func New(base, commit string) (*Linker, error) {
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("link base %q is not the http(s) URL of a repository", base)
+	}
+	if commit == "" {
+		return nil, fmt.Errorf("no commit to link to")
+	}
+	return &Linker{base: strings.TrimSuffix(base, "/"), commit: commit, host: u.Hostname()}, nil
+}
+
+// Link returns the link to the lines from to to of file, a slash-separated
+// path from the repository root. A to not after from links one line.
+func (l *Linker) Link(file string, from, to int) string {
+	file = path.Clean(file)
+	switch {
+	case strings.Contains(l.host, "bitbucket"):
+		anchor := fmt.Sprintf("lines-%d", from)
+		if to > from {
+			anchor += fmt.Sprintf(":%d", to)
+		}
+		return fmt.Sprintf("%s/src/%s/%s#%s", l.base, l.commit, file, anchor)
+	case strings.Contains(l.host, "gitlab"):
+		anchor := fmt.Sprintf("L%d", from)
+		if to > from {
+			anchor += fmt.Sprintf("-%d", to)
+		}
+		return fmt.Sprintf("%s/-/blob/%s/%s#%s", l.base, l.commit, file, anchor)
+	default:
+		anchor := fmt.Sprintf("L%d", from)
+		if to > from {
+			anchor += fmt.Sprintf("-L%d", to)
+		}
+		return fmt.Sprintf("%s/blob/%s/%s#%s", l.base, l.commit, file, anchor)
+	}
+}
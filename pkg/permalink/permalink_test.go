@@ -0,0 +1,64 @@
+package permalink
+
+import "testing"
+
+func TestFromRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:metailurini/linter.git", "https://github.com/metailurini/linter"},
+		{"https://github.com/metailurini/linter", "https://github.com/metailurini/linter"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/linter.git", "https://gitlab.example.com/group/sub/linter"},
+		{"https://ci@bitbucket.org/ws/linter.git", "https://bitbucket.org/ws/linter"},
+	}
+	for _, tt := range tests {
+		got, err := FromRemote(tt.remote)
+		if err != nil {
+			t.Errorf("FromRemote(%q): %v", tt.remote, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FromRemote(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+
+	if _, err := FromRemote("/srv/git/linter.git"); err == nil {
+		t.Error("FromRemote of a local remote expected an error")
+	}
+}
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		base     string
+		from, to int
+		want     string
+	}{
+		{"https://github.com/metailurini/linter", 7, 0, "https://github.com/metailurini/linter/blob/abc/pkg/a.go#L7"},
+		{"https://github.com/metailurini/linter/", 7, 9, "https://github.com/metailurini/linter/blob/abc/pkg/a.go#L7-L9"},
+		{"https://gitlab.example.com/group/sub/linter", 7, 7, "https://gitlab.example.com/group/sub/linter/-/blob/abc/pkg/a.go#L7"},
+		{"https://gitlab.com/group/linter", 7, 9, "https://gitlab.com/group/linter/-/blob/abc/pkg/a.go#L7-9"},
+		{"https://bitbucket.org/ws/linter", 7, 0, "https://bitbucket.org/ws/linter/src/abc/pkg/a.go#lines-7"},
+		{"https://bitbucket.org/ws/linter", 7, 9, "https://bitbucket.org/ws/linter/src/abc/pkg/a.go#lines-7:9"},
+	}
+	for _, tt := range tests {
+		linker, err := New(tt.base, "abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := linker.Link("./pkg/a.go", tt.from, tt.to); got != tt.want {
+			t.Errorf("Link on %s lines %d-%d = %q, want %q", tt.base, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, base := range []string{"github.com/org/repo", "ftp://example.com/repo", "https://"} {
+		if _, err := New(base, "abc"); err == nil {
+			t.Errorf("New(%q) expected an error", base)
+		}
+	}
+	if _, err := New("https://github.com/org/repo", ""); err == nil {
+		t.Error("New without a commit expected an error")
+	}
+}
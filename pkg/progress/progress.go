@@ -0,0 +1,221 @@
+// Package progress shows how far a long run got: a spinner with the share
+// of the work done on a terminal, and a log line every so often otherwise.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often a run not on a terminal logs its progress.
+const DefaultInterval = 30 * time.Second
+
+// redraw is how often the spinner turns.
+const redraw = 100 * time.Millisecond
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Reporter shows the step begun last and not ended yet. On a terminal it
+// redraws a line of its own, which Write clears before anything else is
+// written, so log records go through it.
+type Reporter struct {
+	w        io.Writer
+	terminal bool
+	interval time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	steps []*Step
+	frame int
+	drawn bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// New returns a reporter writing to w, with a spinner when w is a
+// terminal and with log lines otherwise.
+func New(w io.Writer, terminal bool) *Reporter {
+	return &Reporter{w: w, terminal: terminal, interval: DefaultInterval, now: time.Now}
+}
+
+// SetInterval sets how often progress is logged when not on a terminal.
+func (r *Reporter) SetInterval(interval time.Duration) *Reporter {
+	r.interval = interval
+	return r
+}
+
+// Start shows the progress until Stop is called.
+func (r *Reporter) Start() {
+	every := r.interval
+	if r.terminal {
+		every = redraw
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.tick()
+			}
+		}
+	}()
+}
+
+// Stop stops showing the progress and clears the spinner.
+func (r *Reporter) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+}
+
+// Write writes p to the reporter's writer, clearing the spinner first.
+// The next turn of the spinner draws it again.
+func (r *Reporter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+	return r.w.Write(p)
+}
+
+// tick draws the current step on a terminal, and logs it otherwise.
+func (r *Reporter) tick() {
+	r.mu.Lock()
+	if len(r.steps) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	step := r.steps[len(r.steps)-1]
+	if r.terminal {
+		fmt.Fprintf(r.w, "\r\x1b[K%s %s", frames[r.frame%len(frames)], step.describe())
+		r.frame++
+		r.drawn = true
+		r.mu.Unlock()
+		return
+	}
+	attrs := []any{"step", step.name, "elapsed", r.now().Sub(step.start).Round(time.Second)}
+	if step.total > 0 {
+		attrs = append(attrs, "done", step.done, "total", step.total)
+	}
+	r.mu.Unlock()
+	// The default logger may write through the reporter.
+	slog.Info("in progress", attrs...)
+}
+
+// clear erases the spinner line, with r.mu held.
+func (r *Reporter) clear() {
+	if r.drawn {
+		fmt.Fprint(r.w, "\r\x1b[K")
+		r.drawn = false
+	}
+}
+
+// Step is a step of the run. The methods of a nil step do nothing, so code
+// runs the same whether progress is shown or not.
+type Step struct {
+	r           *Reporter
+	name        string
+	unit        string
+	done, total int
+	start       time.Time
+}
+
+type contextKey int
+
+const (
+	reporterKey contextKey = iota
+	stepKey
+)
+
+// WithReporter returns a copy of ctx in which Begin shows steps with r.
+func WithReporter(ctx context.Context, r *Reporter) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, reporterKey, r)
+}
+
+// Begin shows the step name until it ends, and returns a copy of ctx
+// holding it. Without a reporter in ctx, the step is nil.
+func Begin(ctx context.Context, name string) (context.Context, *Step) {
+	r, _ := ctx.Value(reporterKey).(*Reporter)
+	if r == nil {
+		return ctx, nil
+	}
+	step := &Step{r: r, name: name, start: r.now()}
+
+	r.mu.Lock()
+	r.steps = append(r.steps, step)
+	r.mu.Unlock()
+	return context.WithValue(ctx, stepKey, step), step
+}
+
+// FromContext returns the step begun last in ctx, or nil.
+func FromContext(ctx context.Context) *Step {
+	step, _ := ctx.Value(stepKey).(*Step)
+	return step
+}
+
+// SetTotal sets how many units of work, such as "modules", the step has.
+func (s *Step) SetTotal(total int, unit string) {
+	if s == nil {
+		return
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.total, s.unit = total, unit
+}
+
+// Add records that n more units of work are done.
+func (s *Step) Add(n int) {
+	if s == nil {
+		return
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.done += n
+}
+
+// End stops showing the step, going back to the one begun before it.
+func (s *Step) End() {
+	if s == nil {
+		return
+	}
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if r.steps[i] == s {
+			r.steps = append(r.steps[:i], r.steps[i+1:]...)
+			break
+		}
+	}
+	if len(r.steps) == 0 {
+		r.clear()
+	}
+}
+
+// describe reads like "lint 2/5 modules (40%) 12s", with s.r.mu held.
+func (s *Step) describe() string {
+	elapsed := s.r.now().Sub(s.start).Round(time.Second)
+	if s.total == 0 {
+		return fmt.Sprintf("%s %s", s.name, elapsed)
+	}
+	return fmt.Sprintf("%s %d/%d %s (%d%%) %s", s.name, s.done, s.total, s.unit, s.done*100/s.total, elapsed)
+}
@@ -0,0 +1,120 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a reporter clock standing still at now until moved.
+func fakeClock(r *Reporter) *time.Time {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	return &now
+}
+
+func TestTerminalSpinner(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, true)
+	now := fakeClock(r)
+	ctx := WithReporter(context.Background(), r)
+
+	r.tick()
+	if buf.Len() != 0 {
+		t.Errorf("drew %q without a step", buf.String())
+	}
+
+	lintCtx, lint := Begin(ctx, "lint")
+	if FromContext(lintCtx) != lint {
+		t.Error("the context does not carry the step begun")
+	}
+	lint.SetTotal(4, "modules")
+	lint.Add(1)
+	*now = now.Add(12 * time.Second)
+	r.tick()
+	_, filter := Begin(ctx, "filter")
+	r.tick()
+	filter.End()
+	r.tick()
+
+	want := "\r\x1b[K⠋ lint 1/4 modules (25%) 12s" +
+		"\r\x1b[K⠙ filter 0s" +
+		"\r\x1b[K⠹ lint 1/4 modules (25%) 12s"
+	if buf.String() != want {
+		t.Errorf("drew %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if _, err := r.Write([]byte("level=INFO msg=\"module linted\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	lint.End()
+	if want := "\r\x1b[Klevel=INFO msg=\"module linted\"\n"; buf.String() != want {
+		t.Errorf("wrote %q, want the spinner cleared first, once", buf.String())
+	}
+}
+
+func TestLogLines(t *testing.T) {
+	saved := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(saved) })
+	var logs bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	var buf bytes.Buffer
+	r := New(&buf, false)
+	now := fakeClock(r)
+	_, diff := Begin(WithReporter(context.Background(), r), "diff")
+	*now = now.Add(90 * time.Second)
+	r.tick()
+	diff.SetTotal(3, "modules")
+	diff.Add(2)
+	r.tick()
+	diff.End()
+	r.tick()
+
+	want := []string{
+		`level=INFO msg="in progress" step=diff elapsed=1m30s`,
+		`level=INFO msg="in progress" step=diff elapsed=1m30s done=2 total=3`,
+	}
+	if got := strings.Split(strings.TrimSpace(logs.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged:\n%s\nwant:\n%s", logs.String(), strings.Join(want, "\n"))
+	}
+	if buf.Len() != 0 {
+		t.Errorf("drew %q off a terminal", buf.String())
+	}
+}
+
+func TestWithoutReporter(t *testing.T) {
+	ctx, step := Begin(context.Background(), "lint")
+	if step != nil || FromContext(ctx) != nil {
+		t.Fatal("a step began without a reporter")
+	}
+	step.SetTotal(2, "modules")
+	step.Add(1)
+	step.End()
+}
+
+func TestStartStop(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, true)
+	r.Start()
+	_, step := Begin(WithReporter(context.Background(), r), "lint")
+	time.Sleep(3 * redraw)
+	r.Stop()
+	step.End()
+	r.Stop()
+
+	if !strings.Contains(buf.String(), "lint") || !strings.HasSuffix(buf.String(), "\r\x1b[K") {
+		t.Errorf("drew %q, want the spinner cleared when stopped", buf.String())
+	}
+}
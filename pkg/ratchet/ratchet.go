@@ -0,0 +1,84 @@
+// Package ratchet stores the issue count of every linter across the whole
+// repository, so that no count is allowed to grow.
+package ratchet
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+type State struct {
+	Counts map[string]int `json:"counts"`
+}
+
+// Increase is a linter reporting more issues than the state allows.
+type Increase struct {
+	Linter        string
+	Before, After int
+}
+
+func New(issues []result.Issue) *State {
+	s := &State{Counts: make(map[string]int)}
+	for _, issue := range issues {
+		s.Counts[issue.FromLinter]++
+	}
+	return s
+}
+
+// Load reads the state at path, or returns nil when there is none yet.
+func Load(path string) (*State, error) {
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return nil, err
+	}
+	if s.Counts == nil {
+		s.Counts = make(map[string]int)
+	}
+	return &s, nil
+}
+
+func (s *State) Save(path string) error {
+	bytes, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bytes, '\n'), 0o644)
+}
+
+// Increases lists, by linter name, the counts of current above those of s.
+// A linter missing from s had no issue at all.
+func (s *State) Increases(current *State) []Increase {
+	var increases []Increase
+	for linter, after := range current.Counts {
+		if before := s.Counts[linter]; after > before {
+			increases = append(increases, Increase{Linter: linter, Before: before, After: after})
+		}
+	}
+	sort.Slice(increases, func(i, j int) bool { return increases[i].Linter < increases[j].Linter })
+	return increases
+}
+
+// Equal reports whether both states hold the same counts.
+func (s *State) Equal(other *State) bool {
+	if len(s.Counts) != len(other.Counts) {
+		return false
+	}
+	for linter, count := range s.Counts {
+		if other.Counts[linter] != count {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package ratchet
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func issues(linters ...string) []result.Issue {
+	list := make([]result.Issue, 0, len(linters))
+	for _, linter := range linters {
+		list = append(list, result.Issue{FromLinter: linter})
+	}
+	return list
+}
+
+func TestIncreases(t *testing.T) {
+	stored := New(issues("errcheck", "errcheck", "govet", "lll"))
+	current := New(issues("errcheck", "errcheck", "errcheck", "lll", "gosec"))
+
+	want := []Increase{
+		{Linter: "errcheck", Before: 2, After: 3},
+		{Linter: "gosec", Before: 0, After: 1},
+	}
+	if got := stored.Increases(current); !reflect.DeepEqual(got, want) {
+		t.Errorf("Increases = %+v, want %+v", got, want)
+	}
+	if got := current.Increases(stored); len(got) != 1 || got[0].Linter != "govet" {
+		t.Errorf("Increases after fixes = %+v, want only govet", got)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratchet.json")
+
+	missing, err := Load(path)
+	if err != nil || missing != nil {
+		t.Fatalf("Load of a missing state = %v, %v, want nil", missing, err)
+	}
+
+	state := New(issues("errcheck", "govet"))
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Equal(state) || loaded.Equal(New(issues("errcheck"))) {
+		t.Errorf("loaded state = %+v, want %+v", loaded, state)
+	}
+}
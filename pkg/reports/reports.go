@@ -0,0 +1,182 @@
+// Package reports reads the issues of lint reports written earlier, as
+// golangci-lint JSON, SARIF or checkstyle XML, so a lint run elsewhere can
+// be matched to the changes.
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/token"
+	"net/url"
+	"os"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/lint"
+)
+
+// Load reads the report at path, telling its format from its content.
+func Load(path string) ([]result.Issue, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return issues, nil
+}
+
+// Parse reads a golangci-lint JSON, SARIF or checkstyle report.
+func Parse(content []byte) ([]result.Issue, error) {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return parseCheckstyle(trimmed)
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &top); err != nil {
+		return nil, errors.New("not a golangci-lint JSON, SARIF or checkstyle report")
+	}
+	if _, ok := top["runs"]; ok {
+		return parseSARIF(trimmed)
+	}
+	report, err := lint.ParseReport(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return report.Issues, nil
+}
+
+type sarifLog struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Name string `json:"name"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID  string `json:"ruleId"`
+			Level   string `json:"level"`
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine   int `json:"startLine"`
+						StartColumn int `json:"startColumn"`
+						EndLine     int `json:"endLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+// parseSARIF reads the results with a location in a file. golangci-lint
+// names the linter as the rule; other tools are the linter themselves, and
+// their rule prefixes the message.
+func parseSARIF(content []byte) ([]result.Issue, error) {
+	var log sarifLog
+	if err := json.Unmarshal(content, &log); err != nil {
+		return nil, fmt.Errorf("SARIF: %w", err)
+	}
+	var issues []result.Issue
+	for _, run := range log.Runs {
+		tool := run.Tool.Driver.Name
+		for _, r := range run.Results {
+			if len(r.Locations) == 0 {
+				continue
+			}
+			location := r.Locations[0].PhysicalLocation
+			if location.ArtifactLocation.URI == "" || location.Region.StartLine == 0 {
+				continue
+			}
+			path, err := uriPath(location.ArtifactLocation.URI)
+			if err != nil {
+				return nil, fmt.Errorf("SARIF: %w", err)
+			}
+			issue := result.Issue{
+				FromLinter: r.RuleID,
+				Text:       r.Message.Text,
+				Severity:   r.Level,
+				Pos: token.Position{
+					Filename: path,
+					Line:     location.Region.StartLine,
+					Column:   location.Region.StartColumn,
+				},
+			}
+			if tool != "golangci-lint" && tool != "" {
+				issue.FromLinter = tool
+				if r.RuleID != "" {
+					issue.Text = r.RuleID + ": " + r.Message.Text
+				}
+			}
+			if end := location.Region.EndLine; end > issue.Pos.Line {
+				issue.LineRange = &result.Range{From: issue.Pos.Line, To: end}
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// uriPath turns a SARIF artifact URI, relative or file://, into a path.
+func uriPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "" && parsed.Scheme != "file" {
+		return "", fmt.Errorf("artifact %s is not a file", uri)
+	}
+	return parsed.Path, nil
+}
+
+type checkstyle struct {
+	Files []struct {
+		Name   string `xml:"name,attr"`
+		Errors []struct {
+			Line     int    `xml:"line,attr"`
+			Column   int    `xml:"column,attr"`
+			Severity string `xml:"severity,attr"`
+			Message  string `xml:"message,attr"`
+			Source   string `xml:"source,attr"`
+		} `xml:"error"`
+	} `xml:"file"`
+}
+
+// parseCheckstyle reads the errors of a checkstyle report, the source of
+// which golangci-lint sets to the linter.
+func parseCheckstyle(content []byte) ([]result.Issue, error) {
+	var report checkstyle
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return nil, fmt.Errorf("checkstyle: %w", err)
+	}
+	var issues []result.Issue
+	for _, file := range report.Files {
+		for _, e := range file.Errors {
+			if e.Line == 0 {
+				continue
+			}
+			linter := e.Source
+			if linter == "" {
+				linter = "checkstyle"
+			}
+			issues = append(issues, result.Issue{
+				FromLinter: linter,
+				Text:       e.Message,
+				Severity:   e.Severity,
+				Pos:        token.Position{Filename: file.Name, Line: e.Line, Column: e.Column},
+			})
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,122 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/output"
+)
+
+func describe(issues []result.Issue) string {
+	var lines []string
+	for _, issue := range issues {
+		where := fmt.Sprintf("%s:%d:%d", issue.FilePath(), issue.Line(), issue.Column())
+		if lines := issue.GetLineRange(); lines.To != lines.From {
+			where += fmt.Sprintf("-%d", lines.To)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s [%s] %s", where, issue.FromLinter, issue.Severity, issue.Text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   string
+	}{
+		{
+			name: "golangci-lint JSON",
+			report: `{"Issues":[{"FromLinter":"errcheck","Text":"Error return value is not checked","Severity":"","Pos":{"Filename":"pkg/a.go","Line":12,"Column":3}}],
+				"Report":{"Linters":[{"Name":"errcheck","Enabled":true}]}}`,
+			want: "pkg/a.go:12:3 errcheck [] Error return value is not checked",
+		},
+		{
+			name: "SARIF of another tool",
+			report: `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"gosec"}},"results":[
+				{"ruleId":"G104","level":"warning","message":{"text":"Errors unhandled."},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"file:///src/app/main.go"},"region":{"startLine":8,"startColumn":2,"endLine":9}}}]},
+				{"ruleId":"G101","level":"error","message":{"text":"No location."}}]}]}`,
+			want: "/src/app/main.go:8:2-9 gosec [warning] G104: Errors unhandled.",
+		},
+		{
+			name: "checkstyle",
+			report: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+  <file name="cmd/main.go">
+    <error column="6" line="10" message="func unused is unused" severity="error" source="unused"></error>
+    <error line="0" message="file level" severity="warning" source="unused"></error>
+  </file>
+  <file name="README.md">
+    <error line="3" message="line too long" severity="warning"></error>
+  </file>
+</checkstyle>`,
+			want: "cmd/main.go:10:6 unused [error] func unused is unused\nREADME.md:3:0 checkstyle [warning] line too long",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := Parse([]byte(tt.report))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(issues); got != tt.want {
+				t.Errorf("issues:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseOwnSARIF reads back the SARIF the linter writes.
+func TestParseOwnSARIF(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "govet", Text: "printf: wrong verb", Severity: "error", Pos: token.Position{Filename: "pkg/a.go", Line: 4, Column: 2}},
+		{FromLinter: "lll", Text: "line is 130 characters", Pos: token.Position{Filename: "b.go", Line: 9}},
+	}
+	var buf bytes.Buffer
+	if err := output.NewSARIF(&buf).Print(issues); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(issues) {
+		t.Fatalf("read %d issues, want %d:\n%s", len(got), len(issues), describe(got))
+	}
+	for i := range issues {
+		if got[i].FromLinter != issues[i].FromLinter || got[i].FilePath() != issues[i].FilePath() || got[i].Line() != issues[i].Line() {
+			t.Errorf("issue %d read as %s", i, describe(got[i:i+1]))
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, report := range []string{
+		"",
+		"not a report",
+		`{"issues":[]}`,
+		`{"runs":[{"results":[{"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://example.com/a.go"},"region":{"startLine":1}}}]}]}]}`,
+		`<checkstyle><file name="a.go">`,
+	} {
+		if _, err := Parse([]byte(report)); err == nil {
+			t.Errorf("Parse(%q) expected an error", report)
+		}
+	}
+}
+
+func TestLoadNamesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("Load error = %v, want one naming %s", err, path)
+	}
+}
@@ -0,0 +1,92 @@
+// Package snippet cuts the source lines around an issue out of its file.
+package snippet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// The colors follow color.NoColor, set by --color.
+var (
+	issueColor   = color.New(color.FgRed, color.Bold)
+	changedColor = color.New(color.FgGreen)
+	numberColor  = color.New(color.Faint)
+)
+
+// Line is one source line of a snippet.
+type Line struct {
+	Number int
+	Text   string
+	// Changed marks lines the diff adds or modifies.
+	Changed bool
+	// Issue marks the lines the issue spans.
+	Issue bool
+}
+
+// Around returns the lines of issue and up to context lines on each side,
+// reading its file from dir, which its path is relative to. changed
+// reports whether a line of the file is part of the diff; it may be nil.
+func Around(dir string, issue result.Issue, context int, changed func(line int) bool) ([]Line, error) {
+	path := issue.FilePath()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+
+	lines := issue.GetLineRange()
+	first, last := lines.From-context, lines.To+context
+	if first < 1 {
+		first = 1
+	}
+	if last > len(text) {
+		last = len(text)
+	}
+
+	snippet := make([]Line, 0, last-first+1)
+	for number := first; number <= last; number++ {
+		snippet = append(snippet, Line{
+			Number:  number,
+			Text:    strings.TrimSuffix(text[number-1], "\r"),
+			Changed: changed != nil && changed(number),
+			Issue:   lines.From <= number && number <= lines.To,
+		})
+	}
+	return snippet, nil
+}
+
+// Write prints lines with their numbers, a > before the lines of the
+// issue and a + before changed ones:
+//
+//	   11 | func f() {
+//	>+ 12 | 	g()
+//	   13 | }
+func Write(w io.Writer, lines []Line) error {
+	width := 0
+	if len(lines) > 0 {
+		width = len(fmt.Sprint(lines[len(lines)-1].Number))
+	}
+	for _, line := range lines {
+		issue, changed := " ", " "
+		if line.Issue {
+			issue = issueColor.Sprint(">")
+		}
+		if line.Changed {
+			changed = changedColor.Sprint("+")
+		}
+		number := numberColor.Sprintf("%*d |", width, line.Number)
+		if _, err := fmt.Fprintf(w, "%s%s %s %s\n", issue, changed, number, line.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
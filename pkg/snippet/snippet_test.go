@@ -0,0 +1,75 @@
+package snippet
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestAround(t *testing.T) {
+	color.NoColor = true
+	dir := t.TempDir()
+	source := "package a\n\nfunc f() {\n\tg()\n\th()\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed := func(line int) bool { return line == 4 || line == 5 }
+
+	tests := []struct {
+		name    string
+		issue   result.Issue
+		context int
+		want    string
+	}{
+		{
+			name:    "context",
+			issue:   result.Issue{Pos: token.Position{Filename: "a.go", Line: 4}},
+			context: 1,
+			want:    "   3 | func f() {\n>+ 4 | \tg()\n + 5 | \th()\n",
+		},
+		{
+			name:    "clipped at the end",
+			issue:   result.Issue{Pos: token.Position{Filename: "a.go", Line: 5}},
+			context: 3,
+			want:    "   2 | \n   3 | func f() {\n + 4 | \tg()\n>+ 5 | \th()\n   6 | }\n",
+		},
+		{
+			name:    "line range",
+			issue:   result.Issue{Pos: token.Position{Filename: "a.go", Line: 3}, LineRange: &result.Range{From: 3, To: 6}},
+			context: 0,
+			want:    ">  3 | func f() {\n>+ 4 | \tg()\n>+ 5 | \th()\n>  6 | }\n",
+		},
+		{
+			name:    "clipped at the start",
+			issue:   result.Issue{Pos: token.Position{Filename: "a.go", Line: 1}},
+			context: 2,
+			want:    ">  1 | package a\n   2 | \n   3 | func f() {\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := Around(dir, tt.issue, tt.context, changed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := Write(&b, lines); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAroundMissingFile(t *testing.T) {
+	if _, err := Around(t.TempDir(), result.Issue{Pos: token.Position{Filename: "gone.go", Line: 1}}, 1, nil); err == nil {
+		t.Error("Around expected an error for a missing file")
+	}
+}
@@ -0,0 +1,82 @@
+// Package span locates the token an issue points at within its line, and
+// converts the byte columns golangci-lint reports into the units other
+// tools count in.
+package span
+
+import (
+	"go/scanner"
+	"go/token"
+	"unicode/utf8"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Token returns the 1-based byte columns where the Go token at the column
+// of issue starts and ends, the end being exclusive. ok is false when the
+// issue has no column or source line, or no token starts at its column.
+func Token(issue result.Issue) (start, end int, ok bool) {
+	if issue.Column() <= 0 || len(issue.SourceLines) == 0 {
+		return 0, 0, false
+	}
+	line := issue.SourceLines[0]
+	offset := issue.Column() - 1
+	if offset >= len(line) {
+		return 0, 0, false
+	}
+
+	src := []byte(line)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	// Lines cut out of a longer construct may not scan; errors are fine as
+	// long as the token at the column is found.
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return 0, 0, false
+		}
+		at := file.Offset(pos)
+		if at < offset || (tok == token.SEMICOLON && lit != ";") {
+			continue
+		}
+		if at > offset {
+			return 0, 0, false
+		}
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		if at+len(text) > len(line) {
+			return 0, 0, false
+		}
+		return issue.Column(), issue.Column() + len(text), true
+	}
+}
+
+// UTF16 converts a 1-based byte column of line into a 1-based column
+// counted in UTF-16 code units, as SARIF and LSP count by default. Columns
+// past the end of the line keep counting one unit per byte.
+func UTF16(line string, column int) int {
+	units, offset := 0, 0
+	for offset < column-1 && offset < len(line) {
+		r, size := utf8.DecodeRuneInString(line[offset:])
+		if r > 0xFFFF {
+			// Outside the Basic Multilingual Plane, a surrogate pair.
+			units += 2
+		} else {
+			units++
+		}
+		offset += size
+	}
+	return units + (column - 1 - offset) + 1
+}
+
+// Runes converts a 1-based byte column of line into a 1-based column
+// counted in characters.
+func Runes(line string, column int) int {
+	if column-1 > len(line) {
+		return utf8.RuneCountInString(line) + column - len(line)
+	}
+	return utf8.RuneCountInString(line[:column-1]) + 1
+}
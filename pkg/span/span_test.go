@@ -0,0 +1,62 @@
+package span
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		column     int
+		start, end int
+		ok         bool
+	}{
+		{name: "identifier", line: "\tdefer f.Close()", column: 8, start: 8, end: 9, ok: true},
+		{name: "selector", line: "\tdefer f.Close()", column: 10, start: 10, end: 15, ok: true},
+		{name: "string", line: `	fmt.Printf("%d", s)`, column: 13, start: 13, end: 17, ok: true},
+		{name: "whitespace", line: "\tx := 1", column: 1, ok: false},
+		{name: "inside a token", line: "\tvalue := 1", column: 4, ok: false},
+		{name: "unterminated raw string", line: "\tq := `SELECT", column: 2, start: 2, end: 3, ok: true},
+		{name: "no column", line: "\tx := 1", column: 0, ok: false},
+		{name: "past the end", line: "x", column: 5, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := result.Issue{SourceLines: []string{tt.line}, Pos: token.Position{Line: 1, Column: tt.column}}
+			start, end, ok := Token(issue)
+			if ok != tt.ok || (ok && (start != tt.start || end != tt.end)) {
+				t.Errorf("Token = %d, %d, %v; want %d, %d, %v", start, end, ok, tt.start, tt.end, tt.ok)
+			}
+		})
+	}
+
+	if _, _, ok := Token(result.Issue{Pos: token.Position{Line: 1, Column: 3}}); ok {
+		t.Error("Token without source lines reported a token")
+	}
+}
+
+func TestColumns(t *testing.T) {
+	line := `s := "héllo 😀" + x`
+	tests := []struct {
+		column, utf16, runes int
+	}{
+		{1, 1, 1},
+		{6, 6, 6},
+		{10, 9, 9},   // after é, two bytes and one unit
+		{14, 13, 13}, // the emoji
+		{18, 15, 14}, // after the emoji, four bytes and two units
+		{30, 27, 26}, // past the end
+	}
+	for _, tt := range tests {
+		if got := UTF16(line, tt.column); got != tt.utf16 {
+			t.Errorf("UTF16(%d) = %d, want %d", tt.column, got, tt.utf16)
+		}
+		if got := Runes(line, tt.column); got != tt.runes {
+			t.Errorf("Runes(%d) = %d, want %d", tt.column, got, tt.runes)
+		}
+	}
+}
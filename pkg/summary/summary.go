@@ -0,0 +1,106 @@
+// Package summary records what a run did, such as how many issues it
+// found and how long each step took, for dashboards and CI analytics.
+package summary
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// Summary is written as JSON once the run is over.
+type Summary struct {
+	// Changed counts the files changed.
+	Changed int `json:"changed_files"`
+	// Found counts the issues golangci-lint reported, Reported those left
+	// after filtering, which Linters and Files break down.
+	Found    int            `json:"found"`
+	Reported int            `json:"reported"`
+	Linters  map[string]int `json:"linters"`
+	Files    map[string]int `json:"files"`
+	// Durations holds the milliseconds each step took, by step name.
+	Durations map[string]int64 `json:"durations_ms"`
+	ExitCode  int              `json:"exit_code"`
+	// ExitReason explains ExitCode in words.
+	ExitReason string `json:"exit_reason"`
+
+	mu     sync.Mutex
+	issues []result.Issue
+}
+
+func New() *Summary {
+	return &Summary{
+		Linters:   make(map[string]int),
+		Files:     make(map[string]int),
+		Durations: make(map[string]int64),
+	}
+}
+
+// Time adds d to the duration of step. Steps run in parallel, such as the
+// lint of several modules, add up.
+func (s *Summary) Time(step string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Durations[step] += d.Milliseconds()
+}
+
+// SetChanged records how many files changed.
+func (s *Summary) SetChanged(files int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Changed = files
+}
+
+// SetFound records how many issues golangci-lint reported.
+func (s *Summary) SetFound(found int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Found = found
+}
+
+// SetReported records the issues left after filtering.
+func (s *Summary) SetReported(issues []result.Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Reported = len(issues)
+	s.issues = issues
+	s.Linters = make(map[string]int)
+	s.Files = make(map[string]int)
+	for _, issue := range issues {
+		s.Linters[issue.FromLinter]++
+		s.Files[filepath.ToSlash(issue.FilePath())]++
+	}
+}
+
+// Issues returns the issues left after filtering.
+func (s *Summary) Issues() []result.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issues
+}
+
+// SetExit records the exit code of the run and why it was chosen.
+func (s *Summary) SetExit(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ExitCode = code
+	s.ExitReason = reason
+}
+
+// Save writes the summary to path, creating its directory.
+func (s *Summary) Save(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
@@ -0,0 +1,73 @@
+package summary
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func issue(linter, file string) result.Issue {
+	return result.Issue{FromLinter: linter, Pos: token.Position{Filename: file, Line: 1}}
+}
+
+func TestSave(t *testing.T) {
+	s := New()
+	s.Time("diff", 20*time.Millisecond)
+	s.Time("lint", time.Second)
+	s.Time("lint", 500*time.Millisecond)
+	s.SetChanged(4)
+	s.SetFound(7)
+	s.SetReported([]result.Issue{
+		issue("errcheck", "a.go"),
+		issue("errcheck", "pkg/b.go"),
+		issue("govet", "a.go"),
+	})
+	s.SetExit(1, "3 issues reported")
+
+	path := filepath.Join(t.TempDir(), "out", "summary.json")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"changed_files": 4.0,
+		"found":         7.0,
+		"reported":      3.0,
+		"linters":       map[string]any{"errcheck": 2.0, "govet": 1.0},
+		"files":         map[string]any{"a.go": 2.0, "pkg/b.go": 1.0},
+		"durations_ms":  map[string]any{"diff": 20.0, "lint": 1500.0},
+		"exit_code":     1.0,
+		"exit_reason":   "3 issues reported",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summary = %v, want %v", got, want)
+	}
+}
+
+func TestSetReportedReplaces(t *testing.T) {
+	s := New()
+	s.SetReported([]result.Issue{issue("errcheck", "a.go")})
+	s.SetReported([]result.Issue{issue("govet", "b.go")})
+
+	if s.Reported != 1 || !reflect.DeepEqual(s.Linters, map[string]int{"govet": 1}) ||
+		!reflect.DeepEqual(s.Files, map[string]int{"b.go": 1}) {
+		t.Errorf("summary = %+v, want only the govet issue in b.go", s)
+	}
+	if issues := s.Issues(); len(issues) != 1 || issues[0].FromLinter != "govet" {
+		t.Errorf("Issues = %+v, want only the govet issue", issues)
+	}
+}
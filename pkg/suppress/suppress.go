@@ -0,0 +1,213 @@
+// Package suppress hides accepted issues listed in a suppression file,
+// each entry with a justification and an optional expiry date.
+package suppress
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+	"gopkg.in/yaml.v3"
+
+	"linter/pkg/filter"
+	"linter/pkg/fingerprint"
+)
+
+const FileName = ".linter-suppressions.yml"
+
+// dateLayout is the format of expiry dates.
+const dateLayout = "2006-01-02"
+
+// Entry suppresses either the issue with a fingerprint, or every issue
+// matching all of the path glob, linter and rule it sets.
+type Entry struct {
+	// Fingerprint is a full fingerprint or at least its first
+	// fingerprint.ShortLength characters.
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+	// Path is a glob of the files, relative to --pwd like issue paths.
+	Path   string `yaml:"path,omitempty"`
+	Linter string `yaml:"linter,omitempty"`
+	Rule   string `yaml:"rule,omitempty"`
+	// Expires is the last day the entry applies, as YYYY-MM-DD.
+	Expires string `yaml:"expires,omitempty"`
+	Reason  string `yaml:"reason"`
+
+	expires time.Time
+}
+
+// File is the content of a suppression file.
+type File struct {
+	Suppressions []Entry `yaml:"suppressions"`
+}
+
+// Load reads and validates a suppression file.
+func Load(path string) (*File, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := yaml.Unmarshal(bytes, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range f.Suppressions {
+		if err := f.Suppressions[i].validate(); err != nil {
+			return nil, fmt.Errorf("%s: suppression %d: %w", path, i+1, err)
+		}
+	}
+	return &f, nil
+}
+
+// Add appends entry to the suppression file at path, creating the file if
+// needed. The comments and entries of an existing file are kept.
+func Add(path string, entry Entry) error {
+	if err := entry.validate(); err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping with suppressions", path)
+	}
+
+	var list *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "suppressions" {
+			list = root.Content[i+1]
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "suppressions"}, list)
+	}
+	switch {
+	case list.Kind == 0 || list.Tag == "!!null":
+		// A suppressions key without entries yet.
+		list.Kind, list.Tag, list.Value = yaml.SequenceNode, "!!seq", ""
+	case list.Kind != yaml.SequenceNode:
+		return fmt.Errorf("%s: suppressions is not a list", path)
+	}
+
+	var item yaml.Node
+	if err := item.Encode(entry); err != nil {
+		return err
+	}
+	list.Content = append(list.Content, &item)
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+func (e *Entry) validate() error {
+	switch {
+	case e.Fingerprint != "" && (e.Path != "" || e.Linter != "" || e.Rule != ""):
+		return errors.New("set either fingerprint or path, linter and rule")
+	case e.Fingerprint == "" && e.Path == "" && e.Linter == "" && e.Rule == "":
+		return errors.New("matches every issue, set fingerprint or path, linter and rule")
+	case e.Fingerprint != "" && len(e.Fingerprint) < fingerprint.ShortLength:
+		return fmt.Errorf("fingerprint %q is shorter than %d characters", e.Fingerprint, fingerprint.ShortLength)
+	case strings.TrimSpace(e.Reason) == "":
+		return errors.New("reason is required")
+	}
+
+	if e.Expires != "" {
+		expires, err := time.Parse(dateLayout, e.Expires)
+		if err != nil {
+			return fmt.Errorf("expires %q is not a YYYY-MM-DD date", e.Expires)
+		}
+		e.expires = expires
+	}
+	return nil
+}
+
+// Expired reports whether the last day of the entry is before today.
+func (e Entry) Expired(today time.Time) bool {
+	if e.expires.IsZero() {
+		return false
+	}
+	y, m, d := today.Date()
+	return e.expires.Before(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+}
+
+func (e Entry) matches(issue result.Issue) bool {
+	if e.Fingerprint != "" {
+		return strings.HasPrefix(fingerprint.Of(issue), e.Fingerprint)
+	}
+	return (e.Path == "" || filter.MatchPath(issue.FilePath(), e.Path)) &&
+		(e.Linter == "" || e.Linter == issue.FromLinter) &&
+		(e.Rule == "" || e.Rule == fingerprint.Rule(issue))
+}
+
+// String names the entry in messages.
+func (e Entry) String() string {
+	if e.Fingerprint != "" {
+		return "fingerprint " + e.Fingerprint
+	}
+	var parts []string
+	for _, part := range []struct{ key, value string }{
+		{"path", e.Path}, {"linter", e.Linter}, {"rule", e.Rule},
+	} {
+		if part.value != "" {
+			parts = append(parts, part.key+" "+part.value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply returns the issues no entry suppresses and how many were
+// suppressed. Expired entries no longer suppress anything; they are
+// returned so the run can fail until they are renewed or removed.
+func (f *File) Apply(issues []result.Issue, today time.Time) ([]result.Issue, int, []Entry) {
+	var active, expired []Entry
+	for _, entry := range f.Suppressions {
+		if entry.Expired(today) {
+			expired = append(expired, entry)
+		} else {
+			active = append(active, entry)
+		}
+	}
+
+	kept := make([]result.Issue, 0, len(issues))
+	suppressed := 0
+	for _, issue := range issues {
+		if matchesAny(active, issue) {
+			suppressed++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept, suppressed, expired
+}
+
+func matchesAny(entries []Entry, issue result.Issue) bool {
+	for _, entry := range entries {
+		if entry.matches(issue) {
+			return true
+		}
+	}
+	return false
+}
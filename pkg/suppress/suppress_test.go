@@ -0,0 +1,155 @@
+package suppress
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/filter"
+	"linter/pkg/fingerprint"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func issue(file, linter, text string) result.Issue {
+	return result.Issue{FromLinter: linter, Text: text, Pos: token.Position{Filename: file, Line: 1}}
+}
+
+func TestApply(t *testing.T) {
+	known := issue("pkg/a.go", "govet", "printf: bad verb")
+	f, err := Load(writeFile(t, `
+suppressions:
+  - fingerprint: `+fingerprint.Short(known)+`
+    reason: false positive, see #12
+  - path: "**/*_gen.go"
+    linter: lll
+    reason: generated
+  - linter: staticcheck
+    rule: SA1019
+    expires: 2025-12-31
+    reason: migrating off the old API
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []result.Issue{
+		known,
+		issue("api/types_gen.go", "lll", "line is 130 characters"),
+		issue("api/types.go", "lll", "line is 130 characters"),
+		issue("b.go", "staticcheck", "SA1019: old is deprecated"),
+		issue("b.go", "staticcheck", "SA4006: x is never used"),
+	}
+
+	kept, suppressed, expired := f.Apply(issues, time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC))
+	if suppressed != 3 || len(kept) != 2 || len(expired) != 0 {
+		t.Errorf("before expiry: kept %v, suppressed %d, expired %v", kept, suppressed, expired)
+	}
+
+	kept, suppressed, expired = f.Apply(issues, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if suppressed != 2 || len(kept) != 3 || len(expired) != 1 {
+		t.Fatalf("after expiry: kept %v, suppressed %d, expired %v", kept, suppressed, expired)
+	}
+	if got := expired[0].String(); got != "linter staticcheck, rule SA1019" {
+		t.Errorf("expired entry = %q", got)
+	}
+}
+
+func TestLoadRejectsInvalidEntries(t *testing.T) {
+	for name, content := range map[string]string{
+		"no reason":         "suppressions:\n  - linter: lll\n",
+		"matches all":       "suppressions:\n  - reason: why not\n",
+		"short fingerprint": "suppressions:\n  - fingerprint: abc\n    reason: x\n",
+		"mixed":             "suppressions:\n  - fingerprint: 0123456789abcdef\n    linter: lll\n    reason: x\n",
+		"bad date":          "suppressions:\n  - linter: lll\n    expires: 31/12/2025\n    reason: x\n",
+	} {
+		if _, err := Load(writeFile(t, content)); err == nil {
+			t.Errorf("%s: Load expected an error", name)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	known := issue("pkg/a.go", "govet", "printf: bad verb")
+	entry := Entry{Fingerprint: fingerprint.Short(known), Reason: "false positive"}
+
+	tests := []struct {
+		name     string
+		existing string
+		entries  int
+	}{
+		{name: "new file", entries: 1},
+		{name: "empty list", existing: "suppressions:\n", entries: 1},
+		{name: "kept comments", existing: "# accepted issues\nsuppressions:\n  - linter: lll\n    reason: long URLs\n", entries: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := Add(path, entry); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(f.Suppressions) != tt.entries {
+				t.Fatalf("suppressions = %+v, want %d", f.Suppressions, tt.entries)
+			}
+			if kept, _, _ := f.Apply([]result.Issue{known}, time.Now()); len(kept) != 0 {
+				t.Error("added entry does not suppress the issue")
+			}
+			content, _ := os.ReadFile(path)
+			if strings.HasPrefix(tt.existing, "#") && !strings.HasPrefix(string(content), "# accepted issues\n") {
+				t.Errorf("comment lost:\n%s", content)
+			}
+		})
+	}
+
+	if err := Add(filepath.Join(t.TempDir(), FileName), Entry{Linter: "lll"}); err == nil {
+		t.Error("Add without a reason expected an error")
+	}
+}
+
+func TestAddDeduped(t *testing.T) {
+	// Suppressing the issue printed, which names the linters that agreed
+	// with it, must suppress the issue golangci-lint reports next time.
+	reported := []result.Issue{
+		issue("pkg/a.go", "govet", "printf: fmt.Sprintf format %d has arg s of wrong type string"),
+		issue("pkg/a.go", "staticcheck", "SA5009: fmt.Sprintf format %d has arg s of wrong type string"),
+	}
+	printed, _ := filter.NewDeduper().Filter(reported)
+	if len(printed) != 1 || !strings.HasSuffix(printed[0].Text, " (also staticcheck)") {
+		t.Fatalf("deduplicated issues = %v", printed)
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := Add(path, Entry{Fingerprint: fingerprint.Short(printed[0]), Reason: "false positive"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, suppressed, _ := f.Apply(reported, time.Now())
+	if suppressed != 1 || len(kept) != 1 || kept[0].FromLinter != "staticcheck" {
+		t.Errorf("kept %v, suppressed %d, want the govet issue suppressed", kept, suppressed)
+	}
+}
@@ -0,0 +1,352 @@
+// Package trace records the steps of a run as OpenTelemetry spans and
+// exports them with OTLP over HTTP, in its JSON encoding, to the endpoint
+// the standard OTEL_* environment variables name.
+package trace
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultService is the service.name of the spans unless OTEL_SERVICE_NAME
+// sets another.
+const DefaultService = "linter"
+
+// exportTimeout bounds the export, so that an unreachable collector does not
+// hold up the end of the run.
+const exportTimeout = 5 * time.Second
+
+// Tracer collects the spans of one trace until Export sends them.
+type Tracer struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	version  string
+	http     *http.Client
+
+	traceID [16]byte
+	// parent is the span of a calling process, from $TRACEPARENT.
+	parent [8]byte
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// FromEnv returns a tracer exporting to $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+// or to /v1/traces under $OTEL_EXPORTER_OTLP_ENDPOINT, or nil when neither
+// is set or $OTEL_SDK_DISABLED is true. A trace started by the caller and
+// passed in $TRACEPARENT is continued.
+func FromEnv() (*Tracer, error) {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return nil, nil
+	}
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+	}
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("malformed OTLP endpoint %q: %w", endpoint, err)
+	}
+
+	headers, err := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+	traceHeaders, err := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range traceHeaders {
+		headers[key] = value
+	}
+
+	t := &Tracer{
+		endpoint: endpoint,
+		headers:  headers,
+		service:  DefaultService,
+		http:     &http.Client{Timeout: exportTimeout},
+	}
+	if service := os.Getenv("OTEL_SERVICE_NAME"); service != "" {
+		t.service = service
+	}
+	if !parseTraceparent(os.Getenv("TRACEPARENT"), &t.traceID, &t.parent) {
+		_, _ = rand.Read(t.traceID[:])
+	}
+	return t, nil
+}
+
+// parseHeaders reads the key=value,key=value list of the OTLP header
+// variables, whose values are URL encoded.
+func parseHeaders(list string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed OTLP header %q, expected key=value", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("malformed OTLP header %q: %w", pair, err)
+		}
+		headers[strings.TrimSpace(key)] = value
+	}
+	return headers, nil
+}
+
+// parseTraceparent reads a W3C traceparent, 00-<trace id>-<span id>-<flags>.
+func parseTraceparent(header string, traceID *[16]byte, parent *[8]byte) bool {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return false
+	}
+	trace, err := hex.DecodeString(parts[1])
+	if err != nil || len(trace) != len(traceID) {
+		return false
+	}
+	span, err := hex.DecodeString(parts[2])
+	if err != nil || len(span) != len(parent) {
+		return false
+	}
+	copy(traceID[:], trace)
+	copy(parent[:], span)
+	return true
+}
+
+// SetVersion sets the version reported as service.version.
+func (t *Tracer) SetVersion(version string) *Tracer {
+	t.version = version
+	return t
+}
+
+// SetHTTPClient sets the client the spans are exported with.
+func (t *Tracer) SetHTTPClient(client *http.Client) *Tracer {
+	t.http = client
+	return t
+}
+
+type contextKey int
+
+const (
+	tracerKey contextKey = iota
+	spanKey
+)
+
+// WithTracer returns a copy of ctx in which Start records spans with t.
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tracerKey, t)
+}
+
+// Span is a step of the run. The methods of a nil span do nothing, so code
+// runs the same whether tracing is on or not.
+type Span struct {
+	id, parent [8]byte
+	name       string
+	start, end time.Time
+	attributes map[string]any
+	err        error
+}
+
+// Start begins the span name, a child of the span in ctx if any, and
+// returns a copy of ctx holding it. Without a tracer in ctx, the span is
+// nil.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	t, _ := ctx.Value(tracerKey).(*Tracer)
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{name: name, start: time.Now(), parent: t.parent, attributes: make(map[string]any)}
+	if parent, ok := ctx.Value(spanKey).(*Span); ok {
+		span.parent = parent.id
+	}
+	_, _ = rand.Read(span.id[:])
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// SetAttribute records a string, bool or integer attribute of the span.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed with err, unless err is nil.
+func (s *Span) SetError(err error) {
+	if s == nil {
+		return
+	}
+	s.err = err
+}
+
+// End ends the span.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+}
+
+// Export sends the ended spans to the endpoint.
+func (t *Tracer) Export() error {
+	body, err := json.Marshal(t.request())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// The types below follow the JSON encoding of an OTLP
+// ExportTraceServiceRequest, where IDs are hex and times strings.
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource struct {
+		Attributes []attribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	} `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type span struct {
+	TraceID      string      `json:"traceId"`
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Name         string      `json:"name"`
+	Kind         int         `json:"kind"`
+	Start        string      `json:"startTimeUnixNano"`
+	End          string      `json:"endTimeUnixNano"`
+	Attributes   []attribute `json:"attributes,omitempty"`
+	Status       *status     `json:"status,omitempty"`
+}
+
+type attribute struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+func (t *Tracer) request() exportRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scope := scopeSpans{}
+	scope.Scope.Name = DefaultService
+	scope.Scope.Version = t.version
+	for _, s := range t.spans {
+		if s.end.IsZero() {
+			continue
+		}
+		out := span{
+			TraceID:    hex.EncodeToString(t.traceID[:]),
+			SpanID:     hex.EncodeToString(s.id[:]),
+			Name:       s.name,
+			Kind:       spanKindInternal,
+			Start:      strconv.FormatInt(s.start.UnixNano(), 10),
+			End:        strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes: attributes(s.attributes),
+		}
+		if s.parent != ([8]byte{}) {
+			out.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		if s.err != nil {
+			out.Status = &status{Code: statusCodeError, Message: s.err.Error()}
+		}
+		scope.Spans = append(scope.Spans, out)
+	}
+
+	service := map[string]any{"service.name": t.service}
+	if t.version != "" {
+		service["service.version"] = t.version
+	}
+	resource := resourceSpans{ScopeSpans: []scopeSpans{scope}}
+	resource.Resource.Attributes = attributes(service)
+	return exportRequest{ResourceSpans: []resourceSpans{resource}}
+}
+
+// attributes encodes values as OTLP AnyValues, sorted by key.
+func attributes(values map[string]any) []attribute {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]attribute, 0, len(keys))
+	for _, key := range keys {
+		var value map[string]any
+		switch v := values[key].(type) {
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int:
+			// int64 values are strings in the JSON encoding.
+			value = map[string]any{"intValue": strconv.Itoa(v)}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		list = append(list, attribute{Key: key, Value: value})
+	}
+	return list
+}
@@ -0,0 +1,157 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantEndpoint string
+		wantErr      bool
+	}{
+		{name: "unset"},
+		{
+			name:         "base endpoint",
+			env:          map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/"},
+			wantEndpoint: "http://collector:4318/v1/traces",
+		},
+		{
+			name: "traces endpoint",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://traces:4318/custom",
+			},
+			wantEndpoint: "http://traces:4318/custom",
+		},
+		{
+			name: "disabled",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_SDK_DISABLED":           "true",
+			},
+		},
+		{name: "malformed endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "collector"}, wantErr: true},
+		{
+			name: "malformed headers",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_HEADERS":  "token",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{
+				"OTEL_SDK_DISABLED", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS", "TRACEPARENT",
+			} {
+				t.Setenv(key, tt.env[key])
+			}
+			tracer, err := FromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			endpoint := ""
+			if tracer != nil {
+				endpoint = tracer.endpoint
+			}
+			if endpoint != tt.wantEndpoint {
+				t.Errorf("endpoint = %q, want %q", endpoint, tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+func TestStartWithoutTracer(t *testing.T) {
+	ctx, span := Start(context.Background(), "lint")
+	if span != nil || ctx != context.Background() {
+		t.Errorf("Start without a tracer = %v, %v", ctx, span)
+	}
+	// The methods of a nil span do nothing.
+	span.SetAttribute("issues", 1)
+	span.SetError(errors.New("failed"))
+	span.End()
+}
+
+func TestExport(t *testing.T) {
+	var (
+		request exportRequest
+		headers http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_SDK_DISABLED", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer%20secret")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "")
+	t.Setenv("OTEL_SERVICE_NAME", "ci-lint")
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	tracer, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer.SetVersion("1.2.3")
+
+	ctx, root := Start(WithTracer(context.Background(), tracer), "linter run")
+	_, lint := Start(ctx, "lint")
+	lint.SetAttribute("issues", 3)
+	lint.End()
+	// A span never ended is left out.
+	Start(ctx, "filter")
+	root.SetError(errors.New("golangci-lint failed"))
+	root.End()
+
+	if err := tracer.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if headers.Get("Authorization") != "Bearer secret" || headers.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", headers)
+	}
+
+	resource := request.ResourceSpans[0]
+	wantResource := []string{"service.name=ci-lint", "service.version=1.2.3"}
+	for i, attr := range resource.Resource.Attributes {
+		if got := attr.Key + "=" + attr.Value["stringValue"].(string); got != wantResource[i] {
+			t.Errorf("resource attribute %d = %s, want %s", i, got, wantResource[i])
+		}
+	}
+
+	spans := resource.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want the 2 ended ones: %+v", len(spans), spans)
+	}
+	run, lintSpan := spans[0], spans[1]
+	if run.Name != "linter run" || run.TraceID != "0af7651916cd43dd8448eb211c80319c" || run.ParentSpanID != "b7ad6b7169203331" {
+		t.Errorf("root span = %+v, want it under TRACEPARENT", run)
+	}
+	if run.Status == nil || run.Status.Code != statusCodeError || run.Status.Message != "golangci-lint failed" {
+		t.Errorf("root span status = %+v", run.Status)
+	}
+	if lintSpan.ParentSpanID != run.SpanID || lintSpan.TraceID != run.TraceID {
+		t.Errorf("lint span = %+v, want a child of %s", lintSpan, run.SpanID)
+	}
+	if len(lintSpan.Attributes) != 1 || lintSpan.Attributes[0].Value["intValue"] != "3" {
+		t.Errorf("lint span attributes = %+v", lintSpan.Attributes)
+	}
+}
@@ -0,0 +1,141 @@
+package triage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+	"gopkg.in/yaml.v3"
+
+	"linter/pkg/fingerprint"
+)
+
+// ReviewFileName is the review file written in --pwd by default.
+const ReviewFileName = ".linter-review.yml"
+
+// The decisions a review file can hold for an issue.
+const (
+	// Fix applies the replacement suggested for the issue.
+	Fix = "fix"
+	// Nolint adds a nolint directive with the reason to its line.
+	Nolint = "nolint"
+	// Suppress adds its fingerprint to the suppression file with the reason.
+	Suppress = "suppress"
+	// Ignore leaves it alone, as does an empty action.
+	Ignore = "ignore"
+)
+
+// reviewHeader explains the file to whoever fills it in.
+const reviewHeader = `# Set the action of each issue to fix, nolint, suppress or ignore, with a
+# reason for nolint and suppress, then run linter triage apply.
+`
+
+// ReviewEntry is an issue of a review file and the decision made for it.
+type ReviewEntry struct {
+	Fingerprint string `yaml:"fingerprint"`
+	File        string `yaml:"file"`
+	Line        int    `yaml:"line"`
+	Linter      string `yaml:"linter"`
+	Message     string `yaml:"message"`
+	// Fixable tells whether the linter suggested a fix.
+	Fixable bool   `yaml:"fixable,omitempty"`
+	Action  string `yaml:"action"`
+	Reason  string `yaml:"reason"`
+}
+
+// Review lists issues to decide on in bulk.
+type Review struct {
+	Issues []ReviewEntry `yaml:"issues"`
+}
+
+// NewReview lists issues by file and line, without decisions.
+func NewReview(issues []result.Issue) *Review {
+	r := &Review{Issues: make([]ReviewEntry, 0, len(issues))}
+	for _, issue := range issues {
+		r.Issues = append(r.Issues, ReviewEntry{
+			Fingerprint: fingerprint.Of(issue),
+			File:        filepath.ToSlash(issue.FilePath()),
+			Line:        issue.Line(),
+			Linter:      issue.FromLinter,
+			Message:     issue.Text,
+			Fixable:     issue.Replacement != nil,
+		})
+	}
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		if r.Issues[i].File != r.Issues[j].File {
+			return r.Issues[i].File < r.Issues[j].File
+		}
+		return r.Issues[i].Line < r.Issues[j].Line
+	})
+	return r
+}
+
+// Keep carries over the decisions of previous for the issues still listed,
+// so writing the review again does not lose them.
+func (r *Review) Keep(previous *Review) {
+	decided := make(map[string]ReviewEntry)
+	for _, entry := range previous.Issues {
+		if entry.Action != "" {
+			decided[entry.Fingerprint] = entry
+		}
+	}
+	for i, entry := range r.Issues {
+		if old, ok := decided[entry.Fingerprint]; ok {
+			r.Issues[i].Action, r.Issues[i].Reason = old.Action, old.Reason
+		}
+	}
+}
+
+// LoadReview reads and validates a review file.
+func LoadReview(path string) (*Review, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Review
+	if err := yaml.Unmarshal(content, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, entry := range r.Issues {
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("%s: issue %d (%s:%d): %w", path, i+1, entry.File, entry.Line, err)
+		}
+	}
+	return &r, nil
+}
+
+func (e ReviewEntry) validate() error {
+	switch e.Action {
+	case "", Ignore:
+	case Fix:
+		if !e.Fixable {
+			return fmt.Errorf("%s has no suggested fix", e.Linter)
+		}
+	case Nolint, Suppress:
+		if strings.TrimSpace(e.Reason) == "" {
+			return fmt.Errorf("%s needs a reason", e.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q, expected fix, nolint, suppress or ignore", e.Action)
+	}
+	return nil
+}
+
+// Save writes the review to path, explained by a header comment.
+func (r *Review) Save(path string) error {
+	var b bytes.Buffer
+	b.WriteString(reviewHeader)
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(r); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
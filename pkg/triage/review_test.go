@@ -0,0 +1,79 @@
+package triage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestReviewRoundTrip(t *testing.T) {
+	fixable := issueAt("a.go", 3, "unformatted")
+	fixable.Replacement = &result.Replacement{NewLines: []string{"x"}}
+	review := NewReview([]result.Issue{issueAt("b.go", 1, "unchecked"), fixable, issueAt("a.go", 9, "unchecked")})
+
+	var order []string
+	for _, entry := range review.Issues {
+		order = append(order, entry.File+":"+entry.Message)
+	}
+	if got := strings.Join(order, " "); got != "a.go:unformatted a.go:unchecked b.go:unchecked" {
+		t.Errorf("review order = %s", got)
+	}
+	if !review.Issues[0].Fixable || review.Issues[1].Fixable {
+		t.Errorf("fixable = %v, %v", review.Issues[0].Fixable, review.Issues[1].Fixable)
+	}
+
+	review.Issues[0].Action = Fix
+	review.Issues[1].Action, review.Issues[1].Reason = Suppress, "legacy"
+	path := filepath.Join(t.TempDir(), ReviewFileName)
+	if err := review.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "# Set the action") {
+		t.Errorf("review file starts with %q", strings.SplitN(string(content), "\n", 2)[0])
+	}
+	loaded, err := LoadReview(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Issues) != 3 || loaded.Issues[1].Reason != "legacy" || loaded.Issues[0].Fingerprint != review.Issues[0].Fingerprint {
+		t.Errorf("loaded review = %+v", loaded.Issues)
+	}
+
+	again := NewReview([]result.Issue{issueAt("a.go", 10, "unchecked"), issueAt("c.go", 1, "unchecked")})
+	again.Keep(loaded)
+	if again.Issues[0].Action != Suppress || again.Issues[0].Reason != "legacy" || again.Issues[1].Action != "" {
+		t.Errorf("kept decisions = %+v", again.Issues)
+	}
+}
+
+func TestLoadReviewValidates(t *testing.T) {
+	tests := []struct {
+		entry   string
+		wantErr string
+	}{
+		{entry: "action: ignore"},
+		{entry: "action: fix\n    fixable: true"},
+		{entry: "action: fix", wantErr: "no suggested fix"},
+		{entry: "action: nolint", wantErr: "nolint needs a reason"},
+		{entry: "action: suppress\n    reason: \" \"", wantErr: "suppress needs a reason"},
+		{entry: "action: delete", wantErr: `unknown action "delete"`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), ReviewFileName)
+		content := "issues:\n  - file: a.go\n    line: 1\n    linter: errcheck\n    " + tt.entry + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadReview(path)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%q: %v", tt.entry, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%q: error = %v, want %q", tt.entry, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package triage walks through lint issues one at a time, showing their
+// source, and applies the action picked for each.
+package triage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/snippet"
+)
+
+// Actions carries out what can be done with an issue. Any of them may be
+// nil, which leaves the action out of the menu.
+type Actions struct {
+	// Snippet returns the source lines to show with an issue.
+	Snippet func(issue result.Issue) ([]snippet.Line, error)
+	// Edit opens the file of an issue at its line.
+	Edit func(issue result.Issue) error
+	// Suppress adds an issue to the suppression file.
+	Suppress func(issue result.Issue, reason string) error
+	// Fix applies the replacement suggested for an issue.
+	Fix func(issue result.Issue) error
+	// Permalink returns a link to the line of an issue.
+	Permalink func(issue result.Issue) (string, error)
+}
+
+// Summary counts what a session did.
+type Summary struct {
+	Suppressed, Fixed int
+}
+
+// Session reads commands from in and writes to out.
+type Session struct {
+	in      *bufio.Scanner
+	out     io.Writer
+	actions Actions
+}
+
+func NewSession(in io.Reader, out io.Writer, actions Actions) *Session {
+	return &Session{in: bufio.NewScanner(in), out: out, actions: actions}
+}
+
+// errQuit ends the session early.
+var errQuit = errors.New("quit")
+
+// Run goes through the issues grouped by file, until the last one is done
+// with or the user quits. Suppressed and fixed issues are not shown again
+// when going back.
+func (s *Session) Run(issues []result.Issue) (Summary, error) {
+	var summary Summary
+	sorted := make([]result.Issue, len(issues))
+	copy(sorted, issues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].FilePath() != sorted[j].FilePath() {
+			return sorted[i].FilePath() < sorted[j].FilePath()
+		}
+		return sorted[i].Line() < sorted[j].Line()
+	})
+	perFile := make(map[string]int)
+	for _, issue := range sorted {
+		perFile[issue.FilePath()]++
+	}
+
+	done := make([]bool, len(sorted))
+	shownFile := ""
+	for i := 0; i < len(sorted); {
+		if done[i] {
+			i++
+			continue
+		}
+		issue := sorted[i]
+		if path := issue.FilePath(); path != shownFile {
+			shownFile = path
+			s.printf("\n== %s (%d issue(s))\n", filepath.ToSlash(path), perFile[path])
+		}
+		s.show(i, len(sorted), issue)
+
+		move, err := s.prompt(issue, &summary)
+		switch {
+		case errors.Is(err, errQuit):
+			return summary, nil
+		case err != nil:
+			return summary, err
+		}
+		switch move {
+		case moveDone:
+			done[i] = true
+			i++
+		case moveNext:
+			i++
+		case moveBack:
+			for j := i - 1; j >= 0; j-- {
+				if !done[j] {
+					i = j
+					// Show the file header again.
+					shownFile = ""
+					break
+				}
+			}
+		}
+	}
+	s.printf("\nNo more issues: %d suppressed, %d fixed.\n", summary.Suppressed, summary.Fixed)
+	return summary, nil
+}
+
+type move int
+
+const (
+	moveStay move = iota
+	moveNext
+	moveBack
+	moveDone
+)
+
+func (s *Session) show(i, total int, issue result.Issue) {
+	s.printf("\n[%d/%d] %s:%d", i+1, total, filepath.ToSlash(issue.FilePath()), issue.Line())
+	if issue.Column() > 0 {
+		s.printf(":%d", issue.Column())
+	}
+	s.printf(" %s: %s\n", issue.FromLinter, issue.Text)
+	if s.actions.Snippet == nil {
+		return
+	}
+	lines, err := s.actions.Snippet(issue)
+	if err != nil {
+		s.printf("(no source: %v)\n", err)
+		return
+	}
+	_ = snippet.Write(s.out, lines)
+}
+
+// prompt reads commands for issue until one moves on.
+func (s *Session) prompt(issue result.Issue, summary *Summary) (move, error) {
+	for {
+		s.printf("%s > ", s.menu(issue))
+		command, err := s.readLine()
+		if err != nil {
+			return moveStay, err
+		}
+
+		switch command {
+		case "", "n":
+			return moveNext, nil
+		case "b":
+			return moveBack, nil
+		case "q":
+			return moveStay, errQuit
+		case "e":
+			if s.actions.Edit != nil {
+				s.report(s.actions.Edit(issue), "")
+				continue
+			}
+		case "s":
+			if s.actions.Suppress != nil {
+				s.printf("reason: ")
+				reason, err := s.readLine()
+				if err != nil {
+					return moveStay, err
+				}
+				if reason == "" {
+					s.printf("not suppressed, a reason is required\n")
+					continue
+				}
+				if s.report(s.actions.Suppress(issue, reason), "suppressed") {
+					summary.Suppressed++
+					return moveDone, nil
+				}
+				continue
+			}
+		case "f":
+			if s.actions.Fix != nil && issue.Replacement != nil {
+				if s.report(s.actions.Fix(issue), "fixed") {
+					summary.Fixed++
+					return moveDone, nil
+				}
+				continue
+			}
+		case "p":
+			if s.actions.Permalink != nil {
+				link, err := s.actions.Permalink(issue)
+				s.report(err, link)
+				continue
+			}
+		}
+		s.printf("unknown command %q\n", command)
+	}
+}
+
+// menu lists the commands available for issue.
+func (s *Session) menu(issue result.Issue) string {
+	var items []string
+	if s.actions.Edit != nil {
+		items = append(items, "[e]dit")
+	}
+	if s.actions.Suppress != nil {
+		items = append(items, "[s]uppress")
+	}
+	if s.actions.Fix != nil && issue.Replacement != nil {
+		items = append(items, "[f]ix")
+	}
+	if s.actions.Permalink != nil {
+		items = append(items, "[p]ermalink")
+	}
+	items = append(items, "[n]ext", "[b]ack", "[q]uit")
+	return strings.Join(items, " ")
+}
+
+// report prints the outcome of an action and whether it succeeded.
+func (s *Session) report(err error, success string) bool {
+	if err != nil {
+		s.printf("error: %v\n", err)
+		return false
+	}
+	if success != "" {
+		s.printf("%s\n", success)
+	}
+	return true
+}
+
+// readLine returns the next trimmed line of input; the end of the input
+// quits.
+func (s *Session) readLine() (string, error) {
+	if !s.in.Scan() {
+		if err := s.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errQuit
+	}
+	return strings.TrimSpace(s.in.Text()), nil
+}
+
+func (s *Session) printf(format string, a ...interface{}) {
+	fmt.Fprintf(s.out, format, a...)
+}
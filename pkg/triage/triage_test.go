@@ -0,0 +1,114 @@
+package triage
+
+import (
+	"errors"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/snippet"
+)
+
+func issueAt(file string, line int, text string) result.Issue {
+	return result.Issue{FromLinter: "errcheck", Text: text, Pos: token.Position{Filename: file, Line: line}}
+}
+
+// recorder notes every action taken.
+type recorder struct {
+	taken []string
+}
+
+func (r *recorder) actions() Actions {
+	return Actions{
+		Snippet: func(issue result.Issue) ([]snippet.Line, error) {
+			return []snippet.Line{{Number: issue.Line(), Text: "source of " + issue.Text, Issue: true}}, nil
+		},
+		Edit: func(issue result.Issue) error {
+			r.taken = append(r.taken, "edit "+issue.Text)
+			return nil
+		},
+		Suppress: func(issue result.Issue, reason string) error {
+			r.taken = append(r.taken, "suppress "+issue.Text+": "+reason)
+			return nil
+		},
+		Fix: func(issue result.Issue) error {
+			r.taken = append(r.taken, "fix "+issue.Text)
+			return nil
+		},
+		Permalink: func(issue result.Issue) (string, error) {
+			if issue.Text == "c" {
+				return "", errors.New("no remote")
+			}
+			return "https://example.com/" + issue.FilePath(), nil
+		},
+	}
+}
+
+func TestSession(t *testing.T) {
+	color.NoColor = true
+	fixable := issueAt("b.go", 2, "c")
+	fixable.Replacement = &result.Replacement{NewLines: []string{"x"}}
+	issues := []result.Issue{issueAt("b.go", 9, "d"), issueAt("a.go", 5, "b"), fixable, issueAt("a.go", 1, "a")}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Summary
+		taken   []string
+		outputs []string
+	}{
+		{
+			name:    "grouped by file",
+			input:   "n\nn\nn\nn\n",
+			outputs: []string{"== a.go (2 issue(s))", "[1/4] a.go:1 errcheck: a", ">  1 | source of a", "== b.go (2 issue(s))", "[4/4] b.go:9", "0 suppressed, 0 fixed"},
+		},
+		{
+			name:    "actions",
+			input:   "e\ns\n\ns\naccepted\np\nn\nf\nq\n",
+			want:    Summary{Suppressed: 1, Fixed: 1},
+			taken:   []string{"edit a", "suppress a: accepted", "fix c"},
+			outputs: []string{"a reason is required", "suppressed", "https://example.com/a.go", "[e]dit [s]uppress [f]ix [p]ermalink [n]ext [b]ack [q]uit"},
+		},
+		{
+			name:    "back skips done issues",
+			input:   "s\nok\nn\nb\nq\n",
+			want:    Summary{Suppressed: 1},
+			taken:   []string{"suppress a: ok"},
+			outputs: []string{"[3/4] b.go:2 errcheck: c\n>  2 | source of c\n[e]dit [s]uppress [f]ix [p]ermalink [n]ext [b]ack [q]uit > \n== a.go (2 issue(s))\n\n[2/4] a.go:5"},
+		},
+		{
+			name:    "fix only when suggested",
+			input:   "f\nn\nn\np\nq\n",
+			outputs: []string{"unknown command \"f\"", "error: no remote"},
+		},
+		{
+			name:    "end of input quits",
+			input:   "n\n",
+			outputs: []string{"[2/4]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r recorder
+			var out strings.Builder
+			summary, err := NewSession(strings.NewReader(tt.input), &out, r.actions()).Run(issues)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary != tt.want {
+				t.Errorf("summary = %+v, want %+v", summary, tt.want)
+			}
+			if strings.Join(r.taken, "\n") != strings.Join(tt.taken, "\n") {
+				t.Errorf("actions = %q, want %q", r.taken, tt.taken)
+			}
+			for _, want := range tt.outputs {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output lacks %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
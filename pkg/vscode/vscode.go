@@ -0,0 +1,180 @@
+// Package vscode writes the VS Code task that runs the linter, with the
+// problem matcher listing the issues of --out vscode in the Problems panel.
+package vscode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"linter/pkg/output"
+)
+
+// Label names the task, which Init replaces when run again.
+const Label = "linter: changed lines"
+
+// Task is a VS Code task, with the fields the linter task uses.
+type Task struct {
+	Label          string         `json:"label"`
+	Type           string         `json:"type"`
+	Command        string         `json:"command"`
+	Args           []string       `json:"args"`
+	Options        TaskOptions    `json:"options"`
+	Presentation   Presentation   `json:"presentation"`
+	ProblemMatcher ProblemMatcher `json:"problemMatcher"`
+}
+
+type TaskOptions struct {
+	Cwd string `json:"cwd"`
+}
+
+type Presentation struct {
+	Reveal string `json:"reveal"`
+}
+
+// ProblemMatcher reads issues from the output of a task.
+type ProblemMatcher struct {
+	Owner        string   `json:"owner"`
+	Source       string   `json:"source"`
+	FileLocation []string `json:"fileLocation"`
+	Pattern      Pattern  `json:"pattern"`
+}
+
+// Pattern numbers the groups of Regexp each field of an issue is in.
+type Pattern struct {
+	Regexp   string `json:"regexp"`
+	File     int    `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity int    `json:"severity"`
+	Code     int    `json:"code"`
+	Message  int    `json:"message"`
+}
+
+// NewTask returns the task running command on the workspace folder, its
+// issues matched by output.VSCodePattern.
+func NewTask(command string) Task {
+	return Task{
+		Label:        Label,
+		Type:         "process",
+		Command:      command,
+		Args:         []string{"--pwd", "${workspaceFolder}", "--out", "vscode"},
+		Options:      TaskOptions{Cwd: "${workspaceFolder}"},
+		Presentation: Presentation{Reveal: "silent"},
+		ProblemMatcher: ProblemMatcher{
+			Owner:        "linter",
+			Source:       "linter",
+			FileLocation: []string{"relative", "${workspaceFolder}"},
+			Pattern: Pattern{
+				Regexp: output.VSCodePattern,
+				File:   1, Line: 2, Column: 3, Severity: 4, Code: 5, Message: 6,
+			},
+		},
+	}
+}
+
+// Init writes task into .vscode/tasks.json under dir and returns the path
+// of the file. Other tasks and settings of an existing file are kept, and
+// an earlier linter task is replaced; comments are not kept, as JSON has
+// none.
+func Init(dir string, task Task) (string, error) {
+	path := filepath.Join(dir, ".vscode", "tasks.json")
+	file := map[string]json.RawMessage{}
+	var tasks []json.RawMessage
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stripComments(content), &file); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		if raw, ok := file["tasks"]; ok {
+			if err := json.Unmarshal(raw, &tasks); err != nil {
+				return "", fmt.Errorf("%s: tasks: %w", path, err)
+			}
+		}
+	case !os.IsNotExist(err):
+		return "", err
+	}
+
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return "", err
+	}
+	replaced := false
+	for i, raw := range tasks {
+		var other struct {
+			Label string `json:"label"`
+		}
+		if json.Unmarshal(raw, &other) == nil && other.Label == task.Label {
+			tasks[i] = encoded
+			replaced = true
+		}
+	}
+	if !replaced {
+		tasks = append(tasks, encoded)
+	}
+	if file["tasks"], err = json.Marshal(tasks); err != nil {
+		return "", err
+	}
+	if _, ok := file["version"]; !ok {
+		file["version"] = json.RawMessage(`"2.0.0"`)
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(file); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// stripComments blanks the // and /* */ comments VS Code allows in its
+// JSON files, outside of strings, and the commas trailing the last element
+// of an object or array.
+func stripComments(content []byte) []byte {
+	out := make([]byte, 0, len(content))
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				out = append(out, content[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ']' || c == '}':
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = append(trimmed[:len(trimmed)-1], out[len(trimmed):]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
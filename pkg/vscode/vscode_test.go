@@ -0,0 +1,107 @@
+package vscode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInitKeepsOtherTasks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".vscode", "tasks.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `// See https://go.microsoft.com/fwlink/?LinkId=733558
+{
+	"version": "2.0.0",
+	/* build first */
+	"tasks": [
+		{"label": "build", "type": "shell", "command": "go build ./... // not a comment"},
+		{"label": "linter: changed lines", "type": "shell", "command": "old"},
+	],
+	"inputs": [],
+}
+`
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		written, err := Init(dir, NewTask("/usr/local/bin/linter"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != path {
+			t.Errorf("Init wrote %s, want %s", written, path)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Version string            `json:"version"`
+		Inputs  []any             `json:"inputs"`
+		Tasks   []json.RawMessage `json:"tasks"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatalf("%v in:\n%s", err, content)
+	}
+	if file.Version != "2.0.0" || file.Inputs == nil || len(file.Tasks) != 2 {
+		t.Fatalf("tasks.json =\n%s", content)
+	}
+	var build map[string]string
+	if err := json.Unmarshal(file.Tasks[0], &build); err != nil || build["command"] != "go build ./... // not a comment" {
+		t.Errorf("build task = %s", file.Tasks[0])
+	}
+	var linter Task
+	if err := json.Unmarshal(file.Tasks[1], &linter); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linter, NewTask("/usr/local/bin/linter")) {
+		t.Errorf("linter task = %s", file.Tasks[1])
+	}
+}
+
+func TestInitCreatesTasks(t *testing.T) {
+	dir := t.TempDir()
+	path, err := Init(dir, NewTask("linter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Version string `json:"version"`
+		Tasks   []Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Version != "2.0.0" || len(file.Tasks) != 1 || file.Tasks[0].ProblemMatcher.Pattern.Regexp == "" {
+		t.Errorf("tasks.json =\n%s", content)
+	}
+}
+
+func TestInitRejectsBrokenJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".vscode", "tasks.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"tasks": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Init(dir, NewTask("linter")); err == nil {
+		t.Error("Init over broken JSON expected an error")
+	}
+	if content, _ := os.ReadFile(path); string(content) != `{"tasks": [` {
+		t.Errorf("Init changed the broken file to %q", content)
+	}
+}
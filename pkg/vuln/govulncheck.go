@@ -0,0 +1,215 @@
+// Package vuln runs govulncheck and turns the calls it finds into known
+// vulnerabilities into issues.
+package vuln
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/command"
+)
+
+// Linter is the linter name vulnerabilities are reported under.
+const Linter = "govulncheck"
+
+// Govulncheck runs the govulncheck binary on packages of one module and
+// reads its JSON stream.
+type Govulncheck struct {
+	ctx      context.Context
+	bin      string
+	dir      string
+	packages []string
+}
+
+// NewGovulncheck returns a runner for govulncheck found on $PATH, run in
+// the current directory.
+func NewGovulncheck() *Govulncheck {
+	return &Govulncheck{
+		ctx: context.Background(),
+		bin: "govulncheck",
+	}
+}
+
+// SetBin sets the govulncheck binary to run.
+func (g *Govulncheck) SetBin(bin string) *Govulncheck {
+	g.bin = bin
+	return g
+}
+
+// SetContext sets the context govulncheck runs under.
+func (g *Govulncheck) SetContext(ctx context.Context) *Govulncheck {
+	g.ctx = ctx
+	return g
+}
+
+// SetDir sets the module directory govulncheck runs in. Issue paths are
+// relative to it.
+func (g *Govulncheck) SetDir(dir string) *Govulncheck {
+	g.dir = dir
+	return g
+}
+
+// SetPackages sets the package patterns to scan, such as ./pkg/a.
+func (g *Govulncheck) SetPackages(packages ...string) *Govulncheck {
+	g.packages = packages
+	return g
+}
+
+// Run scans the packages and returns an issue for every call, in the code
+// of the module, on the way to a vulnerable function. No packages means no
+// issues.
+func (g *Govulncheck) Run() ([]result.Issue, error) {
+	if len(g.packages) == 0 {
+		return nil, nil
+	}
+	output, err := command.New(g.bin, append([]string{"-json"}, g.packages...)...).
+		SetContext(g.ctx).
+		SetDir(g.dir).
+		Output()
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %w", err)
+	}
+	return g.parse(bytes.NewReader(output))
+}
+
+// message is one value of the govulncheck -json stream. Only vulnerability
+// entries and findings matter here.
+type message struct {
+	OSV     *entry   `json:"osv"`
+	Finding *finding `json:"finding"`
+}
+
+type entry struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases"`
+	Summary string   `json:"summary"`
+}
+
+type finding struct {
+	OSV          string  `json:"osv"`
+	FixedVersion string  `json:"fixed_version"`
+	Trace        []frame `json:"trace"`
+}
+
+// frame is a step of a finding: the vulnerable symbol first, then the
+// functions calling it up to the code scanned.
+type frame struct {
+	Module   string    `json:"module"`
+	Package  string    `json:"package"`
+	Function string    `json:"function"`
+	Receiver string    `json:"receiver"`
+	Position *position `json:"position"`
+}
+
+type position struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+func (g *Govulncheck) parse(r io.Reader) ([]result.Issue, error) {
+	var (
+		entries  = make(map[string]*entry)
+		findings []*finding
+	)
+	decoder := json.NewDecoder(r)
+	for {
+		var m message
+		err := decoder.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("govulncheck -json: %w", err)
+		}
+		if m.OSV != nil {
+			entries[m.OSV.ID] = m.OSV
+		}
+		// Findings naming no function are about a module or package the
+		// code imports without reaching the vulnerable symbol.
+		if m.Finding != nil && len(m.Finding.Trace) > 0 && m.Finding.Trace[0].Function != "" {
+			findings = append(findings, m.Finding)
+		}
+	}
+
+	var issues []result.Issue
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		text := describe(f, entries[f.OSV])
+		// The first frame is the vulnerable function itself.
+		for _, step := range f.Trace[1:] {
+			path, ok := g.local(step.Position)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%s:%s:%d", f.OSV, path, step.Position.Line)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			issues = append(issues, result.Issue{
+				FromLinter: Linter,
+				Text:       text,
+				Pos: token.Position{
+					Filename: path,
+					Line:     step.Position.Line,
+					Column:   step.Position.Column,
+				},
+			})
+		}
+	}
+	return issues, nil
+}
+
+// local returns the path of pos relative to the module directory, unless
+// it lies outside of it, as dependencies in the module cache do.
+func (g *Govulncheck) local(pos *position) (string, bool) {
+	if pos == nil || pos.Filename == "" || pos.Line == 0 {
+		return "", false
+	}
+	path := filepath.FromSlash(pos.Filename)
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path), !strings.HasPrefix(path, "..")
+	}
+	dir, err := filepath.Abs(g.dir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// describe names the vulnerability of f, the symbol reached and the fix.
+func describe(f *finding, e *entry) string {
+	id := f.OSV
+	summary := ""
+	if e != nil {
+		if len(e.Aliases) > 0 {
+			id += " (" + strings.Join(e.Aliases, ", ") + ")"
+		}
+		summary = ": " + e.Summary
+	}
+	vulnerable := f.Trace[0]
+	symbol := vulnerable.Function
+	if vulnerable.Receiver != "" {
+		symbol = strings.TrimPrefix(vulnerable.Receiver, "*") + "." + symbol
+	}
+	symbol = vulnerable.Package + "." + symbol
+	fix := "no fixed version"
+	if f.FixedVersion != "" {
+		fix = "fixed in " + vulnerable.Module + "@" + f.FixedVersion
+	}
+	return fmt.Sprintf("%s%s; calls %s, %s", id, summary, symbol, fix)
+}
@@ -0,0 +1,104 @@
+package vuln
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeGovulncheck writes a govulncheck recording its arguments into
+// args.txt of the directory it runs in and printing stream.
+func fakeGovulncheck(t *testing.T, stream string, code int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake govulncheck is a shell script")
+	}
+
+	script := "#!/bin/sh\necho \"$*\" > args.txt\ncat <<'EOF'\n" + stream + "EOF\nexit " + fmt.Sprint(code) + "\n"
+	path := filepath.Join(t.TempDir(), "govulncheck")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	stream := `{"config":{"protocol_version":"v1.0.0","scanner_name":"govulncheck"}}
+{"progress":{"message":"Scanning your code..."}}
+{"osv":{"id":"GO-2021-0113","aliases":["CVE-2021-38561"],"summary":"Out-of-bounds read in golang.org/x/text/language"}}
+{"osv":{"id":"GO-2023-1571","summary":"Denial of service via crafted HTTP/2 stream"}}
+{"finding":{"osv":"GO-2021-0113","fixed_version":"v0.3.7","trace":[{"module":"golang.org/x/text","version":"v0.3.5","package":"golang.org/x/text/language"}]}}
+{"finding":{"osv":"GO-2021-0113","fixed_version":"v0.3.7","trace":[
+	{"module":"golang.org/x/text","version":"v0.3.5","package":"golang.org/x/text/language","function":"Parse","position":{"filename":"/go/pkg/mod/golang.org/x/text@v0.3.5/language/parse.go","line":33,"column":6}},
+	{"module":"example.com/m","package":"example.com/m/lang","function":"Tag","position":{"filename":"` + filepath.ToSlash(filepath.Join(dir, "lang", "tag.go")) + `","line":12,"column":20}},
+	{"module":"example.com/m","package":"example.com/m","function":"main","position":{"filename":"main.go","line":7,"column":10}}]}}
+{"finding":{"osv":"GO-2021-0113","fixed_version":"v0.3.7","trace":[
+	{"module":"golang.org/x/text","version":"v0.3.5","package":"golang.org/x/text/language","function":"Parse"},
+	{"module":"example.com/m","package":"example.com/m/lang","function":"Tag","position":{"filename":"lang/tag.go","line":12,"column":20}}]}}
+{"finding":{"osv":"GO-2023-1571","trace":[
+	{"module":"stdlib","version":"v1.19.0","package":"net/http","function":"ServeHTTP","receiver":"*Server"},
+	{"module":"example.com/m","package":"example.com/m","function":"serve","position":{"filename":"serve.go","line":20,"column":2}}]}}
+`
+	issues, err := NewGovulncheck().
+		SetBin(fakeGovulncheck(t, stream, 0)).
+		SetDir(dir).
+		SetPackages("./lang", "./").
+		Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d:%d %s: %s", issue.FilePath(), issue.Line(), issue.Column(), issue.FromLinter, issue.Text))
+	}
+	want := []string{
+		"lang/tag.go:12:20 govulncheck: GO-2021-0113 (CVE-2021-38561): Out-of-bounds read in golang.org/x/text/language; calls golang.org/x/text/language.Parse, fixed in golang.org/x/text@v0.3.7",
+		"main.go:7:10 govulncheck: GO-2021-0113 (CVE-2021-38561): Out-of-bounds read in golang.org/x/text/language; calls golang.org/x/text/language.Parse, fixed in golang.org/x/text@v0.3.7",
+		"serve.go:20:2 govulncheck: GO-2023-1571: Denial of service via crafted HTTP/2 stream; calls net/http.Server.ServeHTTP, no fixed version",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	recorded, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(recorded)) != "-json ./lang ./" {
+		t.Errorf("govulncheck ran with %q", recorded)
+	}
+}
+
+func TestRunNoPackages(t *testing.T) {
+	issues, err := NewGovulncheck().SetBin("/nonexistent").Run()
+	if err != nil || issues != nil {
+		t.Errorf("Run() = %v, %v, want nothing", issues, err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		code   int
+	}{
+		{"failure", "", 1},
+		{"malformed stream", `{"finding":`, 0},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		_, err := NewGovulncheck().
+			SetBin(fakeGovulncheck(t, tt.stream+"\n", tt.code)).
+			SetDir(dir).
+			SetPackages("./...").
+			Run()
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package worktree checks out commits into temporary git worktrees, so
+// they can be linted without touching the working tree.
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"linter/pkg/command"
+	"linter/pkg/diff"
+)
+
+// Worktree is a detached checkout of one commit.
+type Worktree struct {
+	// Dir is the root of the checkout.
+	Dir    string
+	Commit string
+	repo   string
+}
+
+// Add checks ref out of the repository containing repo into a new
+// temporary directory.
+func Add(ctx context.Context, repo, ref string) (*Worktree, error) {
+	commit, err := diff.ResolveCommit(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "linter-worktree-")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := command.New("git", "worktree", "add", "--detach", "--quiet", dir, commit).
+		SetContext(ctx).
+		SetDir(repo).
+		Output(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("git worktree add %s: %w", ref, err)
+	}
+	return &Worktree{Dir: dir, Commit: commit, repo: repo}, nil
+}
+
+// Remove deletes the checkout and unregisters it from the repository. It
+// takes no context so that it still cleans up after a cancelled run.
+func (w *Worktree) Remove() error {
+	_, err := command.New("git", "worktree", "remove", "--force", w.Dir).
+		SetDir(w.repo).
+		Output()
+	if err != nil {
+		os.RemoveAll(w.Dir)
+		return fmt.Errorf("git worktree remove %s: %w", w.Dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+	}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestAddAndRemove(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, repo, "add", ".")
+	git(t, repo, "commit", "-q", "-m", "first")
+	first := git(t, repo, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("package a\n\nvar X = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, repo, "commit", "-q", "-am", "second")
+
+	w, err := Add(context.Background(), repo, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Commit != first {
+		t.Errorf("Commit = %q, want %q", w.Commit, first)
+	}
+	content, err := os.ReadFile(filepath.Join(w.Dir, "a.go"))
+	if err != nil || string(content) != "package a\n" {
+		t.Errorf("checked out a.go = %q, %v", content, err)
+	}
+
+	if err := w.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(w.Dir); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists: %v", err)
+	}
+	if list := git(t, repo, "worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Errorf("worktree still registered:\n%s", list)
+	}
+
+	if _, err := Add(context.Background(), repo, "no-such-ref"); err == nil {
+		t.Error("Add of an unknown ref expected an error")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"linter/pkg/diff"
+	"linter/pkg/ratchet"
+)
+
+// checkRatchet lints the whole repository and returns by how many issues
+// the linters grew beyond the --ratchet state. Runs on --ratchet-branch
+// record the new counts as long as none grew.
+func checkRatchet(ctx context.Context) (int, error) {
+	issues, err := lintIssues(ctx, args.Pwd, args.JsonFile, args.InspectDes, false)
+	if err != nil {
+		return 0, err
+	}
+	current := ratchet.New(issues)
+
+	stored, err := ratchet.Load(args.Ratchet)
+	if err != nil {
+		return 0, err
+	}
+	if stored != nil {
+		grown := 0
+		for _, increase := range stored.Increases(current) {
+			fmt.Fprintf(os.Stdout, "%s: %d issue(s), up from %d\n", increase.Linter, increase.After, increase.Before)
+			grown += increase.After - increase.Before
+		}
+		if grown > 0 || stored.Equal(current) {
+			return grown, nil
+		}
+	}
+
+	branch, err := currentBranch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if branch != args.RatchetBranch {
+		if stored == nil {
+			slog.Warn("no ratchet state yet, it is recorded by runs on "+args.RatchetBranch, "file", args.Ratchet)
+		}
+		return 0, nil
+	}
+	if err := current.Save(args.Ratchet); err != nil {
+		return 0, err
+	}
+	slog.Info("ratchet state updated", "file", args.Ratchet, "branch", branch)
+	return 0, nil
+}
+
+// currentBranch is the branch checked out in --pwd, or when HEAD is
+// detached, the one CI reports building.
+func currentBranch(ctx context.Context) (string, error) {
+	branch, err := diff.CurrentBranch(ctx, args.Pwd)
+	if err != nil {
+		return "", err
+	}
+	return firstNonEmpty(branch, os.Getenv("GITHUB_REF_NAME"), os.Getenv("CI_COMMIT_BRANCH")), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"linter/pkg/ratchet"
+)
+
+// reportingLinter writes a golangci-lint stand-in reporting one issue per
+// linter named, in the report file given with --out-format json:<file>.
+func reportingLinter(t *testing.T, linters ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+
+	issues := make([]string, 0, len(linters))
+	for _, linter := range linters {
+		issues = append(issues, `{"FromLinter":"`+linter+`","Text":"x","Pos":{"Filename":"a.go","Line":1}}`)
+	}
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+printf '%s' '{"Issues":[` + strings.Join(issues, ",") + `]}' > "$out"
+exit 1
+`
+	path := filepath.Join(t.TempDir(), "golangci-lint")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckRatchet(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "-C", dir, "init", "-q", "-b", "main").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	state := filepath.Join(dir, "ratchet.json")
+	ctx := context.Background()
+
+	run := func(linters ...string) int {
+		t.Helper()
+		args = options{
+			Pwd:           dir,
+			JsonFile:      filepath.Join(dir, "report.json"),
+			InspectDes:    []string{"./..."},
+			Bin:           reportingLinter(t, linters...),
+			Ratchet:       state,
+			RatchetBranch: "main",
+		}
+		grown, err := checkRatchet(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return grown
+	}
+
+	if grown := run("errcheck", "govet"); grown != 0 {
+		t.Errorf("first run grew by %d", grown)
+	}
+	if grown := run("errcheck", "errcheck", "govet"); grown != 1 {
+		t.Errorf("run with one more errcheck issue grew by %d, want 1", grown)
+	}
+	if grown := run("govet"); grown != 0 {
+		t.Errorf("run with fewer issues grew by %d", grown)
+	}
+
+	recorded, err := ratchet.Load(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&ratchet.State{Counts: map[string]int{"govet": 1}}); !recorded.Equal(want) {
+		t.Errorf("state after fixing errcheck = %v, want %v", recorded.Counts, want.Counts)
+	}
+}
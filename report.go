@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/output"
+)
+
+type reportCmd struct {
+	HTML string `arg:"--html" help:"write the issues as a standalone HTML page to this file"`
+}
+
+// runReport renders the issues on changed lines into a file meant to be
+// kept as a CI artifact, and returns how many there were.
+func runReport(ctx context.Context, cmd *reportCmd) (int, error) {
+	if cmd.HTML == "" {
+		return 0, errors.New("report requires --html")
+	}
+	if err := loadConfig(); err != nil {
+		return 0, err
+	}
+	if args.GroupBy == "owner" {
+		return 0, errors.New("the HTML report groups by file, linter or package, not owner")
+	}
+	release, err := lockShared(ctx, cmd.HTML)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	var link func(result.Issue) string
+	if args.LinkBase != "" {
+		var err error
+		if link, err = issueLinks(ctx); err != nil {
+			return 0, err
+		}
+	}
+	issues, _, err := check(ctx)
+	if err := timedOut(err); err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cmd.HTML), 0o755); err != nil {
+		return 0, err
+	}
+	file, err := os.Create(cmd.HTML)
+	if err != nil {
+		return 0, err
+	}
+	printer := output.NewHTML(file).(*output.HTML)
+	if link != nil {
+		printer.SetLink(link)
+	}
+	if args.GroupBy != "" {
+		printer.SetGroupBy(args.GroupBy)
+	}
+	if err := printer.Print(output.Sort(issues, args.Sort)); err != nil {
+		file.Close()
+		return 0, err
+	}
+	return failing(issues), file.Close()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/api"
+	"linter/pkg/lsp"
+)
+
+type serveCmd struct {
+	LSP           bool   `arg:"--lsp"            help:"speak the Language Server Protocol over stdio"`
+	HTTP          string `arg:"--http"           help:"serve POST /lint on this address, such as :8080"`
+	GitHubWebhook bool   `arg:"--github-webhook" help:"also check the pull requests of the GitHub pull_request events posted to /github, as check runs; the secret is read from $GITHUB_WEBHOOK_SECRET"`
+	GitHubAppID   int64  `arg:"--github-app-id"  help:"GitHub App to act as for --github-webhook, instead of using the token in --token-env"`
+	GitHubAppKey  string `arg:"--github-app-key" help:"private key file of --github-app-id"`
+}
+
+// shutdownTimeout is how long the HTTP server waits for running requests
+// once stopped.
+const shutdownTimeout = 10 * time.Second
+
+// runServe publishes the issues on changed lines to an editor, checking
+// again every time a file is saved, or answers lint requests over HTTP.
+func runServe(ctx context.Context, cmd *serveCmd) error {
+	if cmd.LSP == (cmd.HTTP != "") {
+		return errors.New("serve requires one of --lsp and --http")
+	}
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if cmd.GitHubWebhook && cmd.HTTP == "" {
+		return errors.New("--github-webhook requires --http")
+	}
+	if cmd.HTTP != "" {
+		server := api.NewServer(lintRequest)
+		if cmd.GitHubWebhook {
+			webhook, err := githubWebhook(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			server.Handle("/github", webhook)
+		}
+		listener, err := net.Listen("tcp", cmd.HTTP)
+		if err != nil {
+			return err
+		}
+		return serveHTTP(ctx, listener, server)
+	}
+
+	if args.DiffStdin {
+		return errors.New("--diff-stdin cannot be used with serve, stdin carries the protocol")
+	}
+	server := lsp.NewServer(args.Pwd, func() ([]result.Issue, error) {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		issues, _, err := check(ctx)
+		err = timedOut(err)
+		return issues, err
+	})
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+// serveHTTP answers requests on listener with handler until ctx is done.
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	stopped := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		stopped <- server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving lint requests", "addr", listener.Addr().String())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-stopped
+}
+
+// serving lets one request lint at a time, as each sets the options for
+// its duration.
+var serving sync.Mutex
+
+// lintRequest checks the changes of req.HeadRef since its merge base with
+// req.BaseRef, in a worktree of the repository at req.Repo or else --pwd.
+func lintRequest(ctx context.Context, req api.Request) ([]result.Issue, error) {
+	serving.Lock()
+	defer serving.Unlock()
+	return lintBetween(ctx, req)
+}
+
+// lintBetween is lintRequest for callers holding serving.
+func lintBetween(ctx context.Context, req api.Request) ([]result.Issue, error) {
+	tree, pwd, err := checkoutRef(ctx, firstNonEmpty(req.Repo, args.Pwd, "."), firstNonEmpty(req.HeadRef, "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	defer removeWorktree(tree)
+
+	saved := args
+	defer func() { args = saved }()
+	args.Pwd = pwd
+	args.BaseRef = req.BaseRef
+	args.Cmd, args.DiffFile, args.Staged, args.DiffStdin = "", "", false, false
+	args.Range, args.Commits = "", nil
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	slog.Info("lint requested", "repo", req.Repo, "base", req.BaseRef, "head", req.HeadRef, "commit", tree.Commit)
+	issues, _, err := check(ctx)
+	return issues, timedOut(err)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"linter/pkg/api"
+	"linter/pkg/config"
+)
+
+func TestLintRequest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	git := func(argv ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+		}, argv...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("a.go", "package a\n\nvar x = 1\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("checkout", "-q", "-b", "pr")
+	write("a.go", "package a\n\nvar x = 2\n")
+	// The stand-in linter reports whatever issues.json of the checkout holds.
+	write("issues.json", `{"Issues":[`+
+		`{"FromLinter":"lll","Text":"old","Pos":{"Filename":"a.go","Line":1}},`+
+		`{"FromLinter":"errcheck","Text":"new","Pos":{"Filename":"a.go","Line":3}}]}`)
+	git("add", ".")
+	git("commit", "-q", "-m", "change")
+	git("checkout", "-q", "main")
+
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+cat issues.json > "$out"
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	args = parseOptions(t, "--pwd", t.TempDir(), "--bin", bin, "--no-cache",
+		"-f", filepath.Join(t.TempDir(), "report.json"))
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	pwd := args.Pwd
+
+	issues, err := lintRequest(context.Background(), api.Request{Repo: repo, BaseRef: "main", HeadRef: "pr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].FromLinter != "errcheck" {
+		t.Errorf("issues = %v, want only the errcheck one on the changed line", issues)
+	}
+	if args.Pwd != pwd || args.BaseRef != "" {
+		t.Errorf("options not restored: pwd %q, base ref %q", args.Pwd, args.BaseRef)
+	}
+}
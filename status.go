@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/github"
+	"linter/pkg/gitlab"
+)
+
+// setStatus sets a commit status on the checked commit with the number of
+// issues found, failing when more than --max-issues remain.
+func setStatus(ctx context.Context, pwd string, issues []result.Issue) error {
+	token := os.Getenv(args.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("a commit status needs a token in $%s", args.TokenEnv)
+	}
+	passed := failing(issues) <= args.MaxIssues
+	description := statusDescription(len(issues))
+
+	if args.GitHubStatus != "" {
+		repo, err := github.ParseRepository(args.GitHubStatus)
+		if err != nil {
+			return err
+		}
+		commit, err := checkedCommit(ctx, pwd)
+		if err != nil {
+			return err
+		}
+		state := "failure"
+		if passed {
+			state = "success"
+		}
+		client := github.NewClient(token).
+			SetBaseURL(firstNonEmpty(os.Getenv("GITHUB_API_URL"), github.DefaultBaseURL))
+		err = client.SetStatus(repo, commit, github.Status{
+			State:       state,
+			TargetURL:   statusURL(),
+			Description: description,
+			Context:     args.StatusContext,
+		})
+		if err != nil {
+			return err
+		}
+		slog.Info("commit status set", "repo", repo.String(), "commit", commit, "state", state)
+		return nil
+	}
+
+	commit := os.Getenv("CI_COMMIT_SHA")
+	if commit == "" {
+		var err error
+		if commit, err = diff.ResolveCommit(ctx, pwd, "HEAD"); err != nil {
+			return err
+		}
+	}
+	state := "failed"
+	if passed {
+		state = "success"
+	}
+	client := gitlab.NewClient(token).
+		SetBaseURL(firstNonEmpty(os.Getenv("CI_API_V4_URL"), gitlab.DefaultBaseURL))
+	err := client.SetStatus(args.GitLabStatus, commit, gitlab.Status{
+		State:       state,
+		TargetURL:   statusURL(),
+		Description: description,
+		Name:        args.StatusContext,
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("commit status set", "project", args.GitLabStatus, "commit", commit, "state", state)
+	return nil
+}
+
+// statusDescription counts the issues, as in "3 issues".
+func statusDescription(issues int) string {
+	switch issues {
+	case 0:
+		return "no issues"
+	case 1:
+		return "1 issue"
+	default:
+		return fmt.Sprintf("%d issues", issues)
+	}
+}
+
+// statusURL is --status-url, or else the page of the running GitHub Actions
+// run or GitLab CI job.
+func statusURL() string {
+	if args.StatusURL != "" {
+		return args.StatusURL
+	}
+	if run := os.Getenv("GITHUB_RUN_ID"); run != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s",
+			firstNonEmpty(os.Getenv("GITHUB_SERVER_URL"), "https://github.com"), os.Getenv("GITHUB_REPOSITORY"), run)
+	}
+	return os.Getenv("CI_JOB_URL")
+}
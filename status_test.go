@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/config"
+	"linter/pkg/filter"
+)
+
+func TestSetStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		argv      []string
+		issues    int
+		wantPath  string
+		wantState string
+	}{
+		{
+			name: "github passed", argv: []string{"--github-status", "o/r"},
+			wantPath: "/repos/o/r/statuses/abc123", wantState: "success",
+		},
+		{
+			name: "github failed", argv: []string{"--github-status", "o/r"}, issues: 3,
+			wantPath: "/repos/o/r/statuses/abc123", wantState: "failure",
+		},
+		{
+			name: "github passed by --fail-on", argv: []string{"--github-status", "o/r", "--fail-on", "severity=error"}, issues: 3,
+			wantPath: "/repos/o/r/statuses/abc123", wantState: "success",
+		},
+		{
+			name: "gitlab failed", argv: []string{"--gitlab-status", "g/p", "--status-context", "lint"}, issues: 1,
+			wantPath: "/projects/g%2Fp/statuses/abc123", wantState: "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				path string
+				body map[string]string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.EscapedPath()
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+			t.Setenv("GITHUB_API_URL", server.URL)
+			t.Setenv("CI_API_V4_URL", server.URL)
+			t.Setenv("GITHUB_EVENT_PATH", "")
+			t.Setenv("GITHUB_SHA", "abc123")
+			t.Setenv("CI_COMMIT_SHA", "abc123")
+			t.Setenv("GITHUB_RUN_ID", "")
+			t.Setenv("CI_JOB_URL", "https://gitlab.example.com/-/jobs/9")
+			t.Setenv("GITHUB_TOKEN", "secret")
+			t.Setenv("GITLAB_TOKEN", "secret")
+
+			saved := args
+			t.Cleanup(func() { args = saved })
+			args = parseOptions(t, tt.argv...)
+			if err := args.applyConfig(&config.Config{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := setStatus(context.Background(), ".", make([]result.Issue, tt.issues)); err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("posted to %s, want %s", path, tt.wantPath)
+			}
+			if body["state"] != tt.wantState || body["description"] != statusDescription(tt.issues) ||
+				body["target_url"] != "https://gitlab.example.com/-/jobs/9" {
+				t.Errorf("status = %v, want state %s", body, tt.wantState)
+			}
+			if got := firstNonEmpty(body["context"], body["name"]); got != args.StatusContext {
+				t.Errorf("context = %q, want %q", got, args.StatusContext)
+			}
+		})
+	}
+}
+
+func TestStatusDescription(t *testing.T) {
+	for issues, want := range map[int]string{0: "no issues", 1: "1 issue", 3: "3 issues"} {
+		if got := statusDescription(issues); got != want {
+			t.Errorf("statusDescription(%d) = %q, want %q", issues, got, want)
+		}
+	}
+}
+
+func TestStatusURL(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+	args = options{}
+	t.Setenv("GITHUB_SERVER_URL", "https://github.example.com")
+	t.Setenv("GITHUB_REPOSITORY", "o/r")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	if got, want := statusURL(), "https://github.example.com/o/r/actions/runs/42"; got != want {
+		t.Errorf("statusURL = %q, want %q", got, want)
+	}
+	args.StatusURL = "https://reports.example.com/1"
+	if got := statusURL(); got != args.StatusURL {
+		t.Errorf("statusURL = %q, want --status-url", got)
+	}
+}
+
+func TestPublishLimited(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	issues := []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked a", Pos: token.Position{Filename: "a.go", Line: 1}},
+		{FromLinter: "errcheck", Text: "unchecked b", Pos: token.Position{Filename: "a.go", Line: 2}},
+	}
+
+	tests := []struct {
+		name    string
+		argv    []string
+		publish func(ctx context.Context, pwd string, issues, shown []result.Issue) error
+		want    string
+	}{
+		{"github check", []string{"--github-check", "o/r"}, publishCheck, "failure 2 issue(s) on changed lines, the first 1 annotated 1"},
+		{"bitbucket report", []string{"--bitbucket", "PROJ/repo"}, publishInsights, "FAIL 2 issue(s) on changed lines, the first 1 annotated 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				conclusion, title string
+				annotations       int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					ID          int64         `json:"id"`
+					Conclusion  string        `json:"conclusion"`
+					Result      string        `json:"result"`
+					Details     string        `json:"details"`
+					Annotations []interface{} `json:"annotations"`
+					Output      struct {
+						Title       string        `json:"title"`
+						Annotations []interface{} `json:"annotations"`
+					} `json:"output"`
+				}
+				if r.Method != http.MethodDelete {
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+				}
+				switch {
+				case r.Method == http.MethodPatch:
+					conclusion, title = body.Conclusion, body.Output.Title
+					annotations += len(body.Output.Annotations)
+				case r.Method == http.MethodPut:
+					conclusion, title = body.Result, body.Details
+				case strings.HasSuffix(r.URL.Path, "/annotations"):
+					annotations += len(body.Annotations)
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":42}`))
+			}))
+			defer server.Close()
+			t.Setenv("GITHUB_API_URL", server.URL)
+			t.Setenv("GITHUB_EVENT_PATH", "")
+			t.Setenv("GITHUB_SHA", "abc123")
+			t.Setenv("BITBUCKET_COMMIT", "abc123")
+			t.Setenv("GITHUB_TOKEN", "secret")
+			t.Setenv("BITBUCKET_TOKEN", "secret")
+
+			saved := args
+			t.Cleanup(func() { args = saved })
+			args = parseOptions(t, append(tt.argv, "--max-total", "1", "--bitbucket-url", server.URL)...)
+			if err := args.applyConfig(&config.Config{}); err != nil {
+				t.Fatal(err)
+			}
+			shown, _ := filter.Limit(issues, args.MaxPerFile, args.MaxTotal)
+			if err := tt.publish(context.Background(), repo, issues, shown); err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprintf("%s %s %d", conclusion, title, annotations); got != tt.want {
+				t.Errorf("published %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
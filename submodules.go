@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/lint"
+)
+
+// findSubmoduleChanges replaces the submodules among changes, which git
+// diffs as a change of commit, with the files changed inside them, diffed
+// in the repository of each submodule.
+func findSubmoduleChanges(ctx context.Context, root string, changes []diff.FileChange) ([]diff.FileChange, error) {
+	files, submodules := diff.SplitSubmodules(changes)
+	for _, submodule := range submodules {
+		found, err := diff.FindSubmodule(ctx, root, submodule, args.diffArgs()...)
+		if errors.Is(err, diff.ErrNotCheckedOut) {
+			slog.Warn("changed submodule skipped", "submodule", submodule.Path, "error", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("submodule diffed", "submodule", submodule.Path, "files", len(found))
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// submoduleModules takes the files of the Go modules inside submodules out
+// of files, since golangci-lint run in --pwd does not reach them, and
+// returns those modules for runs of their own.
+func submoduleModules(ctx context.Context, files []string) (rest []string, modules []lint.Module, err error) {
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		// Outside of a repository there are no submodules.
+		return files, nil, nil
+	}
+	submodules, err := lint.ChangedSubmodules(root, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	moved := make(map[string]bool)
+	for _, submodule := range submodules {
+		found, err := lint.ChangedModules(submodule.Dir, relativeFiles(root, submodule.Dir, submodule.Files))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, module := range found {
+			module.Files = relativeFiles(submodule.Dir, root, module.Files)
+			for _, file := range module.Files {
+				moved[file] = true
+			}
+			modules = append(modules, module)
+		}
+	}
+	if len(moved) == 0 {
+		return files, nil, nil
+	}
+	rest = make([]string, 0, len(files)-len(moved))
+	for _, file := range files {
+		if !moved[file] {
+			rest = append(rest, file)
+		}
+	}
+	return rest, modules, nil
+}
+
+// relativeFiles rebases files, slash-separated and relative to from, onto
+// to.
+func relativeFiles(from, to string, files []string) []string {
+	rebased := make([]string, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(to, filepath.Join(from, filepath.FromSlash(file)))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rebased = append(rebased, filepath.ToSlash(rel))
+	}
+	return rebased
+}
+
+// lintSubmodules runs golangci-lint in each of modules, with paths made
+// relative to --pwd, and hands the issues of each to emit.
+func lintSubmodules(ctx context.Context, modules []lint.Module, emit func([]result.Issue) error) error {
+	if len(modules) == 0 {
+		return nil
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return err
+	}
+	for i, module := range modules {
+		issues, err := lintModule(ctx, root, pwd, module, moduleReport(pwd, i), false)
+		if err != nil {
+			name, _ := filepath.Rel(root, module.Dir)
+			return fmt.Errorf("submodule %s: %w", filepath.ToSlash(name), err)
+		}
+		if err := emit(issues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"linter/pkg/diff"
+	"linter/pkg/lint"
+)
+
+func TestSubmoduleModules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	files := map[string]string{
+		"go.mod":            "module example.com/app\n",
+		"lib/.git":          "gitdir: ../.git/modules/lib\n",
+		"lib/go.mod":        "module example.com/lib\n",
+		"lib/tools/go.mod":  "module example.com/tools\n",
+		"vendored/.git":     "gitdir: ../.git/modules/vendored\n",
+		"vendored/plain.go": "package vendored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args = options{Pwd: repo, vcs: diff.Git}
+	rest, modules, err := submoduleModules(context.Background(), []string{
+		"main.go",
+		"lib/lib.go",
+		"lib/tools/tool.go",
+		"vendored/plain.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.go", "vendored/plain.go"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("rest = %v, want %v", rest, want)
+	}
+	root, err := filepath.EvalSymlinks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lint.Module{
+		{Dir: filepath.Join(root, "lib"), Files: []string{"lib/lib.go"}},
+		{Dir: filepath.Join(root, "lib", "tools"), Files: []string{"lib/tools/tool.go"}},
+	}
+	if !reflect.DeepEqual(modules, want) {
+		t.Errorf("modules = %+v, want %+v", modules, want)
+	}
+}
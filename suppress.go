@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+	"linter/pkg/fix"
+	"linter/pkg/nolint"
+)
+
+type suppressCmd struct {
+	Issue  string `arg:"--issue"  help:"fingerprint of the issue on changed lines to suppress, or its beginning as in the markdown and HTML outputs"`
+	Reason string `arg:"--reason" help:"why the issue is not worth fixing, written after the nolint directive"`
+}
+
+// runSuppress checks the changes and adds a //nolint directive for its
+// linter to the line of the issue picked by fingerprint.
+func runSuppress(ctx context.Context, cmd *suppressCmd) error {
+	if cmd.Issue == "" {
+		return errors.New("suppress requires --issue")
+	}
+	if strings.TrimSpace(cmd.Reason) == "" {
+		return errors.New("suppress requires --reason")
+	}
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	issues, _, err := check(ctx)
+	if err := timedOut(err); err != nil {
+		return err
+	}
+	return suppressIssue(issues, cmd.Issue, cmd.Reason)
+}
+
+// suppressIssue annotates the line of every issue with the fingerprint,
+// which identical issues share, in the files under --pwd.
+func suppressIssue(issues []result.Issue, prefix, reason string) error {
+	var (
+		matched []result.Issue
+		full    string
+	)
+	for _, issue := range issues {
+		sum := fingerprint.Of(issue)
+		if !strings.HasPrefix(sum, prefix) {
+			continue
+		}
+		if full != "" && sum != full {
+			return fmt.Errorf("several issues have a fingerprint starting with %s, give more of it", prefix)
+		}
+		full = sum
+
+		suppressed, err := nolint.Suppress(issue, reason)
+		if err != nil {
+			return err
+		}
+		matched = append(matched, suppressed)
+	}
+	if len(matched) == 0 {
+		return fmt.Errorf("no issue on changed lines has the fingerprint %s", prefix)
+	}
+
+	plan, err := fix.NewPlan(args.Pwd, matched)
+	if err != nil {
+		return err
+	}
+	if err := plan.Write(); err != nil {
+		return err
+	}
+	for _, index := range plan.Fixed {
+		issue := matched[index]
+		slog.Info("issue suppressed", "file", issue.FilePath(), "line", issue.Line(), "linter", issue.FromLinter)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/fingerprint"
+)
+
+func TestSuppressIssue(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	source := "package a\n\nfunc A() {\n\tf.Close()\n\tg.Close()\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args = options{Pwd: dir}
+
+	issueAt := func(line int, text string) result.Issue {
+		return result.Issue{
+			FromLinter:  "errcheck",
+			Text:        "Error return value is not checked",
+			Pos:         token.Position{Filename: "a.go", Line: line},
+			SourceLines: []string{text},
+		}
+	}
+	issues := []result.Issue{issueAt(4, "\tf.Close()"), issueAt(5, "\tg.Close()")}
+
+	if err := suppressIssue(issues, "", "closed twice"); err == nil {
+		t.Error("suppressing by an empty prefix of several fingerprints expected an error")
+	}
+	if err := suppressIssue(issues, "unknown", "closed twice"); err == nil {
+		t.Error("suppressing an unknown fingerprint expected an error")
+	}
+	if err := suppressIssue(issues, fingerprint.Short(issues[1]), "closed twice"); err != nil {
+		t.Fatal(err)
+	}
+	want := "package a\n\nfunc A() {\n\tf.Close()\n\tg.Close() //nolint:errcheck // closed twice\n}\n"
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(content) != want {
+		t.Errorf("suppressed file = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/config"
+	"linter/pkg/diff"
+	"linter/pkg/external"
+	"linter/pkg/filter"
+)
+
+// validateTools checks the tools of the config file, each of which needs a
+// unique name, a command, the files it lints and a complete mapping.
+func validateTools(tools []config.Tool) error {
+	names := make(map[string]bool)
+	for i, tool := range tools {
+		if tool.Name == "" {
+			return fmt.Errorf("tool %d has no name", i+1)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("tool %s is configured twice", tool.Name)
+		}
+		names[tool.Name] = true
+		if strings.TrimSpace(tool.Command) == "" {
+			return fmt.Errorf("tool %s has no command", tool.Name)
+		}
+		if len(tool.Files) == 0 {
+			return fmt.Errorf("tool %s lints no files, set files such as [\"**/*.sh\"]", tool.Name)
+		}
+		if err := toolMapping(tool).Validate(); err != nil {
+			return fmt.Errorf("tool %s: %w", tool.Name, err)
+		}
+	}
+	return nil
+}
+
+func toolMapping(tool config.Tool) external.Mapping {
+	return external.Mapping{
+		Issues:   tool.Issues,
+		File:     tool.Fields.File,
+		Line:     tool.Fields.Line,
+		EndLine:  tool.Fields.EndLine,
+		Column:   tool.Fields.Column,
+		Message:  tool.Fields.Message,
+		Severity: tool.Fields.Severity,
+		Rule:     tool.Fields.Rule,
+	}
+}
+
+// toolChanges keeps the changes of the files tool lints.
+func toolChanges(tool config.Tool, changes []diff.FileChange) []diff.FileChange {
+	var kept []diff.FileChange
+	for _, change := range changes {
+		for _, pattern := range tool.Files {
+			if filter.MatchGlob(change.Path, pattern) {
+				kept = append(kept, change)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// toolFiles keeps the changes of files other than Go ones that one of the
+// tools lints, which golangci-lint leaves alone.
+func toolFiles(changes []diff.FileChange) []diff.FileChange {
+	seen := make(map[string]bool)
+	var kept []diff.FileChange
+	for _, tool := range args.tools {
+		for _, change := range toolChanges(tool, changes) {
+			if !strings.HasSuffix(change.Path, ".go") && !seen[change.Path] {
+				seen[change.Path] = true
+				kept = append(kept, change)
+			}
+		}
+	}
+	return kept
+}
+
+// toolIssues runs every tool of the config file from the repository root
+// on the changed files it lints, reporting its issues with paths relative
+// to --pwd like those of golangci-lint.
+func toolIssues(ctx context.Context, changes []diff.FileChange) ([]result.Issue, error) {
+	if len(args.tools) == 0 {
+		return nil, nil
+	}
+	root, err := args.vcs.Root(ctx, args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+	pwd, err := filepath.Abs(args.Pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []result.Issue
+	for _, tool := range args.tools {
+		files := diff.Paths(toolChanges(tool, changes))
+		found, err := external.New(tool.Name, tool.Command).
+			SetMapping(toolMapping(tool)).
+			SetContext(ctx).
+			SetDir(root).
+			Run(files...)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	return relativeTo(pwd, root, issues)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"linter/pkg/config"
+	"linter/pkg/diff"
+)
+
+func TestValidateTools(t *testing.T) {
+	valid := config.Tool{
+		Name:    "shellcheck",
+		Command: "shellcheck -f json {files}",
+		Files:   []string{"**/*.sh"},
+		Issues:  "$[*]",
+		Fields:  config.ToolFields{File: "file", Line: "line", Message: "message"},
+	}
+	tests := []struct {
+		name    string
+		change  func(tool *config.Tool)
+		wantErr string
+	}{
+		{"valid", func(*config.Tool) {}, ""},
+		{"no name", func(tool *config.Tool) { tool.Name = "" }, "tool 1 has no name"},
+		{"no command", func(tool *config.Tool) { tool.Command = " " }, "tool shellcheck has no command"},
+		{"no files", func(tool *config.Tool) { tool.Files = nil }, "tool shellcheck lints no files"},
+		{"no line", func(tool *config.Tool) { tool.Fields.Line = "" }, "tool shellcheck: no line path"},
+		{"bad path", func(tool *config.Tool) { tool.Fields.Column = "col[x]" }, `tool shellcheck: path "col[x]"`},
+	}
+	for _, tt := range tests {
+		tool := valid
+		tt.change(&tool)
+		err := validateTools([]config.Tool{tool})
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if err := validateTools([]config.Tool{valid, valid}); err == nil {
+		t.Error("a tool configured twice expected an error")
+	}
+}
+
+func TestToolIssues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.Mkdir(filepath.Join(repo, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(t.TempDir(), "shellcheck")
+	script := `#!/bin/sh
+echo "$*" > ` + filepath.Join(filepath.Dir(bin), "args.txt") + `
+echo '[{"file":"sub/run.sh","line":4,"level":"warning","message":"Double quote to prevent globbing."}]'
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	args = parseOptions(t, "--pwd", filepath.Join(repo, "sub"))
+	err := args.applyConfig(&config.Config{Tools: []config.Tool{{
+		Name:    "shellcheck",
+		Command: bin + " -f json {files}",
+		Files:   []string{"**/*.sh"},
+		Issues:  "$[*]",
+		Fields:  config.ToolFields{File: "file", Line: "line", Message: "message", Severity: "level"},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []diff.FileChange{{Path: "main.go"}, {Path: "sub/run.sh"}, {Path: "README.md"}, {Path: "deploy.sh"}}
+	if got := diff.Paths(toolFiles(changes)); !reflect.DeepEqual(got, []string{"sub/run.sh", "deploy.sh"}) {
+		t.Errorf("toolFiles kept %v", got)
+	}
+	issues, err := toolIssues(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d %s %s: %s", issue.FilePath(), issue.Line(), issue.Severity, issue.FromLinter, issue.Text))
+	}
+	want := []string{"run.sh:4 warning shellcheck: Double quote to prevent globbing."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %q, want %q", got, want)
+	}
+	recorded, err := os.ReadFile(filepath.Join(filepath.Dir(bin), "args.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(recorded)) != "-f json sub/run.sh deploy.sh" {
+		t.Errorf("shellcheck ran with %q", recorded)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/fingerprint"
+	"linter/pkg/fix"
+	"linter/pkg/nolint"
+	"linter/pkg/triage"
+)
+
+type triageCmd struct {
+	File  string          `arg:"--file" help:"review file [default: .linter-review.yml in --pwd]"`
+	Apply *triageApplyCmd `arg:"subcommand:apply" help:"carry out the decisions of the review file on the issues still reported"`
+}
+
+type triageApplyCmd struct{}
+
+// runTriage checks the changes and writes their issues to a review file,
+// keeping the decisions already in it, or applies the file.
+func runTriage(ctx context.Context, cmd *triageCmd) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	path := cmd.File
+	if path == "" {
+		path = filepath.Join(args.Pwd, triage.ReviewFileName)
+	}
+
+	// Decisions are checked before linting, not to lint for nothing.
+	var previous *triage.Review
+	if _, err := os.Stat(path); err == nil || cmd.Apply != nil {
+		if previous, err = triage.LoadReview(path); err != nil {
+			return err
+		}
+	}
+
+	checkCtx, cancel := withTimeout(ctx)
+	issues, changes, err := check(checkCtx)
+	cancel()
+	if err := timedOut(err); err != nil {
+		return err
+	}
+
+	if cmd.Apply != nil {
+		return applyReview(previous, issues, changes)
+	}
+	review := triage.NewReview(issues)
+	if previous != nil {
+		review.Keep(previous)
+	}
+	if err := review.Save(path); err != nil {
+		return err
+	}
+	slog.Info("review written", "file", path, "issues", len(review.Issues))
+	return nil
+}
+
+// applyReview fixes, annotates with nolint and suppresses the issues as
+// decided. Entries whose issue is no longer reported are skipped, so
+// applying a review twice does nothing more.
+func applyReview(review *triage.Review, issues []result.Issue, changes []diff.FileChange) error {
+	byFingerprint := make(map[string][]result.Issue)
+	for _, issue := range issues {
+		sum := fingerprint.Of(issue)
+		byFingerprint[sum] = append(byFingerprint[sum], issue)
+	}
+	t := &triageActions{changed: filter.NewIssueFilter(changes)}
+
+	var edits []result.Issue
+	applied := make(map[string]bool)
+	suppressed, ignored := 0, 0
+	for _, entry := range review.Issues {
+		if entry.Action == "" || entry.Action == triage.Ignore {
+			ignored++
+			continue
+		}
+		// Identical issues share a fingerprint, the first entry decides
+		// for all of them.
+		if applied[entry.Fingerprint] {
+			continue
+		}
+		applied[entry.Fingerprint] = true
+		matched := byFingerprint[entry.Fingerprint]
+		if len(matched) == 0 {
+			slog.Warn("issue no longer reported", "file", entry.File, "line", entry.Line, "action", entry.Action)
+			continue
+		}
+		for _, issue := range matched {
+			switch entry.Action {
+			case triage.Fix:
+				if issue.Replacement == nil || !t.changed.Covers(issue) {
+					slog.Warn("fix skipped, it reaches beyond the changed lines", "file", issue.FilePath(), "line", issue.Line())
+					continue
+				}
+				edits = append(edits, issue)
+			case triage.Nolint:
+				annotated, err := nolint.Suppress(issue, entry.Reason)
+				if err != nil {
+					return err
+				}
+				edits = append(edits, annotated)
+			case triage.Suppress:
+				if err := t.suppress(issue, entry.Reason); err != nil {
+					return err
+				}
+				suppressed++
+			}
+		}
+	}
+
+	plan, err := fix.NewPlan(args.Pwd, edits)
+	if err != nil {
+		return err
+	}
+	if plan.Skipped > 0 {
+		slog.Warn("edits skipped for overlapping an earlier one on the same lines", "count", plan.Skipped)
+	}
+	if err := plan.Write(); err != nil {
+		return err
+	}
+	slog.Info("review applied", "edited", len(plan.Fixed), "suppressed", suppressed, "ignored", ignored)
+	if len(edits) > 0 && len(plan.Fixed) == 0 {
+		return errors.New("no edit of the review could be applied")
+	}
+	return nil
+}
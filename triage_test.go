@@ -0,0 +1,69 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/suppress"
+	"linter/pkg/triage"
+)
+
+func TestApplyReview(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	source := "package a\n\nfunc A() {\n\tf.Close()\n\tx := 1\n\tg.Close()\n\th.Close()\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args = options{Pwd: dir}
+
+	issueAt := func(linter string, line int, text string) result.Issue {
+		return result.Issue{
+			FromLinter:  linter,
+			Text:        linter + " issue",
+			Pos:         token.Position{Filename: "a.go", Line: line},
+			SourceLines: []string{text},
+		}
+	}
+	unformatted := issueAt("gofmt", 5, "\tx := 1")
+	unformatted.Replacement = &result.Replacement{NewLines: []string{"\tx := 2"}}
+	issues := []result.Issue{
+		issueAt("errcheck", 4, "\tf.Close()"),
+		unformatted,
+		issueAt("errcheck", 6, "\tg.Close()"),
+		issueAt("errcheck", 7, "\th.Close()"),
+	}
+	review := triage.NewReview(issues)
+	review.Issues[0].Action, review.Issues[0].Reason = triage.Nolint, "closed twice"
+	review.Issues[1].Action = triage.Fix
+	review.Issues[2].Action, review.Issues[2].Reason = triage.Suppress, "legacy"
+	review.Issues[3].Action = triage.Ignore
+	gone := review.Issues[0]
+	gone.Fingerprint = "0123456789abcdef"
+	review.Issues = append(review.Issues, gone)
+
+	changes := []diff.FileChange{{Path: "a.go", Changes: []*diff.Change{{Start: 4, End: 7}}}}
+	if err := applyReview(review, issues, changes); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "package a\n\nfunc A() {\n\tf.Close() //nolint:errcheck // closed twice\n\tx := 2\n\tg.Close()\n\th.Close()\n}\n"
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(content) != want {
+		t.Errorf("applied file = %q, want %q", content, want)
+	}
+	file, err := suppress.Load(filepath.Join(dir, suppress.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept, suppressed, _ := file.Apply(issues, time.Now()); suppressed != 1 || kept[2].Line() != 7 {
+		t.Errorf("suppression file kept %d issue(s) and suppressed %d", len(kept), suppressed)
+	}
+}
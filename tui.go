@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/command"
+	"linter/pkg/config"
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/fingerprint"
+	"linter/pkg/fix"
+	"linter/pkg/permalink"
+	"linter/pkg/snippet"
+	"linter/pkg/suppress"
+	"linter/pkg/triage"
+)
+
+type tuiCmd struct {
+	Remote string `arg:"--remote" help:"git remote permalinks point to [default: origin]"`
+}
+
+// tuiContext is how many source lines are shown around an issue when
+// --context is not set.
+const tuiContext = 3
+
+// runTui checks the changes once, then walks through the issues on the
+// terminal, offering to edit, suppress, fix or link each of them.
+func runTui(ctx context.Context, cmd *tuiCmd) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if args.DiffStdin {
+		return errors.New("--diff-stdin cannot be used with tui, stdin carries the commands")
+	}
+
+	checkCtx, cancel := withTimeout(ctx)
+	issues, changes, err := check(checkCtx)
+	cancel()
+	if err := timedOut(err); err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		slog.Info("no issues on changed lines")
+		return nil
+	}
+
+	t := &triageActions{
+		ctx:     ctx,
+		remote:  firstNonEmpty(cmd.Remote, "origin"),
+		changed: filter.NewIssueFilter(changes),
+		touched: make(map[string]bool),
+	}
+	session := triage.NewSession(os.Stdin, os.Stdout, triage.Actions{
+		Snippet:   t.snippet,
+		Edit:      t.edit,
+		Suppress:  t.suppress,
+		Fix:       t.fix,
+		Permalink: t.permalink,
+	})
+	summary, err := session.Run(issues)
+	if err != nil {
+		return err
+	}
+	slog.Info("triage finished", "issues", len(issues), "suppressed", summary.Suppressed, "fixed", summary.Fixed)
+	return nil
+}
+
+// triageActions carries out the actions of a triage session.
+type triageActions struct {
+	ctx     context.Context
+	remote  string
+	changed *filter.IssueFilter
+	// touched holds the files edited or fixed since the check, whose
+	// issue positions may be stale.
+	touched map[string]bool
+}
+
+func (t *triageActions) snippet(issue result.Issue) ([]snippet.Line, error) {
+	around := args.Context
+	if around <= 0 {
+		around = tuiContext
+	}
+	return snippet.Around(args.Pwd, issue, around, func(line int) bool {
+		return t.changed.Changed(issue.FilePath(), line)
+	})
+}
+
+// edit opens $VISUAL or $EDITOR, vi when neither is set, at the line of
+// the issue, which most editors accept as +line.
+func (t *triageActions) edit(issue result.Issue) error {
+	editor, err := command.Split(firstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi"))
+	if err != nil {
+		return err
+	}
+	if len(editor) == 0 {
+		return errors.New("no editor set in $VISUAL or $EDITOR")
+	}
+
+	cmd := exec.CommandContext(t.ctx, editor[0], append(editor[1:], "+"+strconv.Itoa(issue.Line()), issue.FilePath())...)
+	cmd.Dir = args.Pwd
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	t.touched[issue.FilePath()] = true
+	return cmd.Run()
+}
+
+// suppress adds the fingerprint of the issue to the suppression file in
+// use, or creates one in --pwd.
+func (t *triageActions) suppress(issue result.Issue, reason string) error {
+	path := args.Suppressions
+	if path == "" {
+		found, err := config.FindFile(args.Pwd, suppress.FileName)
+		if err != nil {
+			return err
+		}
+		path = firstNonEmpty(found, filepath.Join(args.Pwd, suppress.FileName))
+	}
+	return suppress.Add(path, suppress.Entry{Fingerprint: fingerprint.Short(issue), Reason: reason})
+}
+
+// fix applies the suggested fix of the issue under the same rule as --fix:
+// it may only rewrite changed lines.
+func (t *triageActions) fix(issue result.Issue) error {
+	if t.touched[issue.FilePath()] {
+		return errors.New("the file changed since the check, run tui again to fix it")
+	}
+	if !t.changed.Covers(issue) {
+		return errors.New("the fix reaches beyond the changed lines")
+	}
+	plan, err := fix.NewPlan(args.Pwd, []result.Issue{issue})
+	if err != nil {
+		return err
+	}
+	if err := plan.Write(); err != nil {
+		return err
+	}
+	t.touched[issue.FilePath()] = true
+	return nil
+}
+
+// permalink links the line of the issue at HEAD on the web page of the
+// remote, and copies the link to the clipboard when it can.
+func (t *triageActions) permalink(issue result.Issue) (string, error) {
+	remote, err := diff.RemoteURL(t.ctx, args.Pwd, t.remote)
+	if err != nil {
+		return "", err
+	}
+	commit, err := diff.ResolveCommit(t.ctx, args.Pwd, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	root, err := args.vcs.Root(t.ctx, args.Pwd)
+	if err != nil {
+		return "", err
+	}
+	fromRoot, err := relativeTo(root, args.Pwd, []result.Issue{issue})
+	if err != nil {
+		return "", err
+	}
+
+	base, err := permalink.FromRemote(remote)
+	if err != nil {
+		return "", err
+	}
+	linker, err := permalink.New(base, commit)
+	if err != nil {
+		return "", err
+	}
+	link := linker.Link(filepath.ToSlash(fromRoot[0].FilePath()), issue.Line(), 0)
+	if copyToClipboard(t.ctx, link) {
+		return link + " (copied)", nil
+	}
+	return link, nil
+}
+
+// copyToClipboard hands text to the first clipboard tool found.
+func copyToClipboard(ctx context.Context, text string) bool {
+	for _, tool := range [][]string{
+		{"pbcopy"},
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"},
+	} {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/diff"
+	"linter/pkg/filter"
+	"linter/pkg/suppress"
+)
+
+func TestTriageActions(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	dir := t.TempDir()
+	source := "package a\n\nvar x = 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args = options{Pwd: dir}
+
+	actions := &triageActions{
+		ctx: context.Background(),
+		changed: filter.NewIssueFilter([]diff.FileChange{{
+			Path:    "a.go",
+			Changes: []*diff.Change{{Start: 3, End: 3}},
+		}}),
+		touched: make(map[string]bool),
+	}
+	issue := result.Issue{
+		FromLinter:  "gofmt",
+		Text:        "not formatted",
+		Pos:         token.Position{Filename: "a.go", Line: 3},
+		Replacement: &result.Replacement{NewLines: []string{"var x = 2"}},
+	}
+
+	lines, err := actions.snippet(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || !lines[2].Changed || !lines[2].Issue || lines[0].Changed {
+		t.Errorf("snippet = %+v", lines)
+	}
+
+	if err := actions.suppress(issue, "generated"); err != nil {
+		t.Fatal(err)
+	}
+	file, err := suppress.Load(filepath.Join(dir, suppress.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept, _, _ := file.Apply([]result.Issue{issue}, time.Now()); len(kept) != 0 {
+		t.Error("suppressed issue is still reported")
+	}
+
+	outside := issue
+	outside.Pos.Line = 1
+	if err := actions.fix(outside); err == nil {
+		t.Error("fix of an unchanged line expected an error")
+	}
+	if err := actions.fix(issue); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(content) != "package a\n\nvar x = 2\n" {
+		t.Errorf("fixed file = %q", content)
+	}
+	if err := actions.fix(issue); err == nil {
+		t.Error("second fix in a fixed file expected an error")
+	}
+}
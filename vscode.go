@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+
+	"linter/pkg/vscode"
+)
+
+type vscodeCmd struct {
+	Init *vscodeInitCmd `arg:"subcommand:init" help:"add a task to .vscode/tasks.json that shows the issues on changed lines in the Problems panel"`
+}
+
+type vscodeInitCmd struct {
+	Command string `arg:"--command" help:"linter command the task runs [default: this executable]"`
+}
+
+func runVSCode(cmd *vscodeCmd, pwd string) error {
+	if cmd.Init == nil {
+		return errors.New("vscode requires init")
+	}
+	command := cmd.Init.Command
+	if command == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		command = executable
+	}
+	path, err := vscode.Init(pwd, vscode.NewTask(command))
+	if err != nil {
+		return err
+	}
+	slog.Info("wrote task", "path", path, "label", vscode.Label)
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+
+	"linter/pkg/api"
+	"linter/pkg/command"
+	"linter/pkg/github"
+)
+
+// webhookSecretEnv holds the secret GitHub signs webhook deliveries with.
+const webhookSecretEnv = "GITHUB_WEBHOOK_SECRET"
+
+// githubWebhook returns the handler of GitHub webhook deliveries. Each pull
+// request opened or pushed to is checked in the background and reported as
+// a check run, so GitHub gets its answer at once.
+func githubWebhook(ctx context.Context, cmd *serveCmd) (http.Handler, error) {
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("--github-webhook needs the webhook secret in $%s", webhookSecretEnv)
+	}
+	var app *github.App
+	switch {
+	case cmd.GitHubAppID != 0:
+		if cmd.GitHubAppKey == "" {
+			return nil, errors.New("--github-app-id requires --github-app-key")
+		}
+		var err error
+		if app, err = github.LoadApp(cmd.GitHubAppID, cmd.GitHubAppKey); err != nil {
+			return nil, err
+		}
+	case os.Getenv(args.TokenEnv) == "":
+		return nil, fmt.Errorf("--github-webhook needs a token in $%s, or --github-app-id", args.TokenEnv)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, r.Method+" not allowed, use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		event, err := github.ReadPullRequestEvent(r, secret)
+		switch {
+		case errors.Is(err, github.ErrSignature):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case event == nil:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		go func() {
+			if err := checkPullRequest(ctx, app, event); err != nil {
+				slog.Error("pull request check failed", "pr", event.PR().String(), "error", err)
+			}
+		}()
+	}), nil
+}
+
+// checkPullRequest lints the changes of the pull request of event and
+// reports them as a check run on its head commit, acting as app when set.
+func checkPullRequest(ctx context.Context, app *github.App, event *github.PullRequestEvent) error {
+	pr := event.PR()
+	baseURL := firstNonEmpty(os.Getenv("GITHUB_API_URL"), github.DefaultBaseURL)
+	token := os.Getenv(args.TokenEnv)
+	if app != nil {
+		var err error
+		token, err = github.NewClient("").SetBaseURL(baseURL).InstallationToken(app, event.Installation.ID)
+		if err != nil {
+			return err
+		}
+	}
+	client := github.NewClient(token).SetBaseURL(baseURL)
+
+	id, err := client.StartCheck(pr.Repository(), event.PullRequest.Head.SHA)
+	if err != nil {
+		return err
+	}
+	issues, err := lintPullRequest(ctx, event, token)
+	if err != nil {
+		conclusion := "failure"
+		if errors.Is(err, context.DeadlineExceeded) {
+			conclusion = "timed_out"
+		}
+		if failErr := client.FailCheck(pr.Repository(), id, conclusion, err); failErr != nil {
+			return errors.Join(err, failErr)
+		}
+		return err
+	}
+	summary, err := checkSummary(issues)
+	if err != nil {
+		return err
+	}
+	if err := client.CompleteCheck(pr.Repository(), id, issues, len(issues), summary, failing(issues) <= args.MaxIssues); err != nil {
+		return err
+	}
+	slog.Info("pull request checked", "pr", pr.String(), "issues", len(issues))
+	return nil
+}
+
+// lintPullRequest brings the clone of the repository of event up to date
+// and lints the changes of the pull request in it.
+func lintPullRequest(ctx context.Context, event *github.PullRequestEvent, token string) ([]result.Issue, error) {
+	serving.Lock()
+	defer serving.Unlock()
+
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, "repos", event.Repository.Owner.Login, event.Repository.Name)
+	if err := fetchPullRequest(ctx, dir, event, token); err != nil {
+		return nil, err
+	}
+	return lintBetween(ctx, api.Request{
+		Repo:    dir,
+		BaseRef: event.PullRequest.Base.SHA,
+		HeadRef: event.PullRequest.Head.SHA,
+	})
+}
+
+// fetchPullRequest clones the repository of event into dir unless done
+// before, then fetches the base branch and the head of the pull request.
+// The token goes through the environment of git, never its arguments, so
+// it shows in no log or error.
+func fetchPullRequest(ctx context.Context, dir string, event *github.PullRequestEvent, token string) error {
+	var auth []string
+	if token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		auth = []string{
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic " + credentials,
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+			return err
+		}
+		_, err := command.New("git", "clone", "--quiet", "--no-checkout", event.Repository.CloneURL, dir).
+			SetContext(ctx).
+			SetEnv(auth...).
+			Output()
+		if err != nil {
+			return err
+		}
+	}
+
+	head := "refs/pull/" + strconv.Itoa(event.Number) + "/head"
+	_, err := command.New("git", "fetch", "--quiet", "--force", "origin",
+		event.PullRequest.Base.Ref, "+"+head+":"+head).
+		SetContext(ctx).
+		SetDir(dir).
+		SetEnv(auth...).
+		Output()
+	return err
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"linter/pkg/config"
+	"linter/pkg/github"
+)
+
+func TestGitHubWebhookRejects(t *testing.T) {
+	saved := args
+	t.Cleanup(func() { args = saved })
+	args = options{TokenEnv: "LINTER_TEST_TOKEN"}
+	t.Setenv("LINTER_TEST_TOKEN", "secret")
+
+	t.Setenv(webhookSecretEnv, "")
+	if _, err := githubWebhook(context.Background(), &serveCmd{}); err == nil {
+		t.Error("githubWebhook without a secret expected an error")
+	}
+
+	t.Setenv(webhookSecretEnv, "hook-secret")
+	handler, err := githubWebhook(context.Background(), &serveCmd{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		method     string
+		event      string
+		signature  string
+		wantStatus int
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "unsigned", method: http.MethodPost, event: "pull_request", wantStatus: http.StatusUnauthorized},
+		{name: "other event", method: http.MethodPost, event: "push", signature: signPayload("{}", "hook-secret"), wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/github", strings.NewReader("{}"))
+			r.Header.Set("X-GitHub-Event", tt.event)
+			r.Header.Set("X-Hub-Signature-256", tt.signature)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func signPayload(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestCheckPullRequest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter is a shell script")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	saved := args
+	t.Cleanup(func() { args = saved })
+
+	// origin stands in for the GitHub repository, with the pull request
+	// head under refs/pull/1/head as GitHub keeps it.
+	origin := t.TempDir()
+	git := func(argv ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+		}, argv...)...)
+		cmd.Dir = origin
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(argv, " "), err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(origin, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("a.go", "package a\n\nvar x = 1\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	git("checkout", "-q", "-b", "feature")
+	write("a.go", "package a\n\nvar x = 2\n")
+	// The stand-in linter reports whatever issues.json of the checkout holds.
+	write("issues.json", `{"Issues":[`+
+		`{"FromLinter":"lll","Text":"old","Pos":{"Filename":"a.go","Line":1}},`+
+		`{"FromLinter":"errcheck","Text":"new","Pos":{"Filename":"a.go","Line":3}}]}`)
+	git("add", ".")
+	git("commit", "-q", "-m", "change")
+	head := git("rev-parse", "HEAD")
+	git("update-ref", "refs/pull/1/head", head)
+	git("checkout", "-q", "main")
+
+	bin := filepath.Join(t.TempDir(), "golangci-lint")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--out-format) out="${2#json:}"; shift ;;
+	esac
+	shift
+done
+cat issues.json > "$out"
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu      sync.Mutex
+		updates []map[string]any
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/check-runs" && body["head_sha"] == head:
+			_, _ = w.Write([]byte(`{"id":5}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/check-runs/5":
+			updates = append(updates, body)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.Error(w, "unexpected "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+	t.Setenv("GITHUB_API_URL", api.URL)
+	t.Setenv("LINTER_TEST_TOKEN", "secret")
+
+	args = parseOptions(t, "--pwd", t.TempDir(), "--bin", bin, "--no-cache", "--token-env", "LINTER_TEST_TOKEN",
+		"--cache-dir", t.TempDir(), "-f", filepath.Join(t.TempDir(), "report.json"))
+	if err := args.applyConfig(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	event := &github.PullRequestEvent{Action: "synchronize", Number: 1}
+	event.PullRequest.Head.SHA = head
+	event.PullRequest.Base.SHA = base
+	event.PullRequest.Base.Ref = "main"
+	event.Repository.Name = "r"
+	event.Repository.Owner.Login = "o"
+	event.Repository.CloneURL = origin
+	// Twice, the second run fetching into the existing clone.
+	for run := 1; run <= 2; run++ {
+		if err := checkPullRequest(context.Background(), nil, event); err != nil {
+			t.Fatal(err)
+		}
+		update := updates[len(updates)-1]
+		annotations := update["output"].(map[string]any)["annotations"].([]any)
+		if update["conclusion"] != "failure" || len(annotations) != 1 ||
+			annotations[0].(map[string]any)["title"] != "errcheck" {
+			t.Errorf("run %d concluded with %v", run, update)
+		}
+	}
+}